@@ -0,0 +1,187 @@
+// Backtest harness: replays historical users under an alternate scoring model
+// or eligibility rules and reports how their matches would change, without
+// saving anything. Stored LLM verdicts stand in for the LLM stage.
+//
+//	go run ./cmd/backtest -batch batch_20240101 -scoring config/scoring_v2.json
+//	go run ./cmd/backtest -from 2024-01-01 -to 2024-01-31 -rules rules.json -csv changes.csv
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"loan-eligibility-engine/internal/config"
+	"loan-eligibility-engine/internal/models"
+	"loan-eligibility-engine/internal/services/database"
+	"loan-eligibility-engine/internal/services/matcher"
+	"loan-eligibility-engine/internal/services/scoring"
+	"loan-eligibility-engine/internal/utils"
+)
+
+const dateLayout = "2006-01-02"
+
+func main() {
+	batchID := flag.String("batch", "", "batch ID to replay")
+	from := flag.String("from", "", "replay users created on or after this date (YYYY-MM-DD)")
+	to := flag.String("to", "", "replay users created up to and including this date (YYYY-MM-DD)")
+	scoringPath := flag.String("scoring", "", "alternate scoring model file (default: the current model)")
+	rulesPath := flag.String("rules", "", `alternate rules file: {"<product_id>": [{"name": ..., "expression": ...}]}`)
+	jsonOut := flag.String("json", "-", "write the JSON report to this file, - for stdout")
+	csvOut := flag.String("csv", "", "write the changed matches as CSV to this file, - for stdout")
+	flag.Parse()
+
+	opts, err := backtestOptions(*batchID, *from, *to, *scoringPath, *rulesPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := utils.InitLogger("warn"); err != nil {
+		log.Fatalf("Failed to initialize logger: %v", err)
+	}
+	defer utils.Sync()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	db, err := database.New(cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	matcherSvc, err := matcher.NewMatcherService(db)
+	if err != nil {
+		log.Fatalf("Failed to initialize matcher service: %v", err)
+	}
+
+	report, err := matcherSvc.Backtest(context.Background(), opts)
+	if err != nil {
+		log.Fatalf("Backtest failed: %v", err)
+	}
+
+	if *jsonOut != "" {
+		if err := writeOutput(*jsonOut, func(w io.Writer) error { return writeJSON(w, report) }); err != nil {
+			log.Fatalf("Failed to write JSON report: %v", err)
+		}
+	}
+	if *csvOut != "" {
+		if err := writeOutput(*csvOut, func(w io.Writer) error { return writeCSV(w, report.Changes) }); err != nil {
+			log.Fatalf("Failed to write CSV report: %v", err)
+		}
+	}
+}
+
+// backtestOptions validates the flags and loads the alternate configuration
+func backtestOptions(batchID, from, to, scoringPath, rulesPath string) (matcher.BacktestOptions, error) {
+	opts := matcher.BacktestOptions{BatchID: batchID}
+
+	if batchID == "" {
+		if from == "" || to == "" {
+			return opts, fmt.Errorf("either -batch or both -from and -to are required")
+		}
+		start, err := time.Parse(dateLayout, from)
+		if err != nil {
+			return opts, fmt.Errorf("invalid -from date: %w", err)
+		}
+		end, err := time.Parse(dateLayout, to)
+		if err != nil {
+			return opts, fmt.Errorf("invalid -to date: %w", err)
+		}
+		opts.From, opts.To = start, end.AddDate(0, 0, 1)
+	}
+
+	if scoringPath != "" {
+		model, err := scoring.Load(scoringPath)
+		if err != nil {
+			return opts, err
+		}
+		opts.Model = model
+	}
+
+	if rulesPath != "" {
+		data, err := os.ReadFile(rulesPath)
+		if err != nil {
+			return opts, fmt.Errorf("failed to read rules file: %w", err)
+		}
+		if err := json.Unmarshal(data, &opts.Rules); err != nil {
+			return opts, fmt.Errorf("invalid rules file: %w", err)
+		}
+	}
+
+	return opts, nil
+}
+
+// writeOutput runs write against the named file, or stdout for "-"
+func writeOutput(path string, write func(io.Writer) error) error {
+	if path == "-" {
+		return write(os.Stdout)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func writeJSON(w io.Writer, report *models.BacktestReport) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+// writeCSV writes one row per added, dropped or re-ranked match
+func writeCSV(w io.Writer, changes []models.BacktestChange) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"user_id", "product_id", "change_type", "old_score", "new_score", "old_rank", "new_rank"}); err != nil {
+		return err
+	}
+
+	for _, c := range changes {
+		err := cw.Write([]string{
+			strconv.FormatInt(c.UserID, 10),
+			strconv.FormatInt(c.ProductID, 10),
+			string(c.ChangeType),
+			formatScore(c.OldScore),
+			formatScore(c.NewScore),
+			formatRank(c.OldRank),
+			formatRank(c.NewRank),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatScore(score *float64) string {
+	if score == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*score, 'f', 2, 64)
+}
+
+func formatRank(rank int) string {
+	if rank == 0 {
+		return ""
+	}
+	return strconv.Itoa(rank)
+}
@@ -0,0 +1,91 @@
+// Loan product crawler: crawls the registered crawler sources and saves their
+// products, recording the run in crawler_runs. With -dry-run it only fetches
+// and extracts one source and prints the products, which is the way to try
+// out a source's extraction rules.
+//
+//	go run ./cmd/crawl
+//	go run ./cmd/crawl -source hdfc-personal
+//	go run ./cmd/crawl -source hdfc-personal -dry-run
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"loan-eligibility-engine/internal/config"
+	"loan-eligibility-engine/internal/services/crawler"
+	"loan-eligibility-engine/internal/services/database"
+	"loan-eligibility-engine/internal/utils"
+)
+
+// dryRunResult is what a dry run prints
+type dryRunResult struct {
+	Source   string      `json:"source"`
+	Products interface{} `json:"products"`
+	Errors   []string    `json:"errors,omitempty"`
+}
+
+func main() {
+	sourceName := flag.String("source", "", "name of the source to crawl; every active source if empty")
+	dryRun := flag.Bool("dry-run", false, "extract the source's products and print them without saving")
+	flag.Parse()
+
+	if *dryRun && *sourceName == "" {
+		log.Fatal("-dry-run needs -source")
+	}
+
+	if err := utils.InitLogger("info"); err != nil {
+		log.Fatalf("Failed to initialize logger: %v", err)
+	}
+	defer utils.Sync()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	db, err := database.New(cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	var result interface{}
+	if *dryRun {
+		source, err := database.NewCrawlerRepository(db).GetSource(ctx, *sourceName)
+		if err != nil {
+			log.Fatalf("Failed to get source: %v", err)
+		}
+		if source == nil {
+			log.Fatalf("Source %q not found", *sourceName)
+		}
+
+		fetcher := crawler.NewFetcher(crawler.OptionsFromConfig(cfg))
+		products, productErrors, err := crawler.Crawl(ctx, fetcher, source)
+		if err != nil {
+			log.Fatalf("Crawl failed: %v", err)
+		}
+
+		dry := dryRunResult{Source: source.Name, Products: products}
+		for _, err := range productErrors {
+			dry.Errors = append(dry.Errors, err.Error())
+		}
+		result = dry
+	} else {
+		run, err := crawler.New(db, cfg).Run(ctx, *sourceName)
+		if err != nil {
+			log.Fatalf("Crawl failed: %v", err)
+		}
+		result = run
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(result); err != nil {
+		log.Fatalf("Failed to write result: %v", err)
+	}
+}
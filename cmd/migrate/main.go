@@ -0,0 +1,118 @@
+// Schema migration tool: applies, rolls back and lists the versioned
+// migrations embedded in the binary.
+//
+//	go run ./cmd/migrate up
+//	go run ./cmd/migrate status
+//	go run ./cmd/migrate -steps 1 down
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"loan-eligibility-engine/internal/config"
+	"loan-eligibility-engine/internal/services/database"
+	"loan-eligibility-engine/internal/services/migrations"
+)
+
+func main() {
+	steps := flag.Int("steps", 1, "migrations to roll back with down")
+	allowDataLoss := flag.Bool("allow-data-loss", false, "allow down in production, where rolling back drops data")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: migrate [flags] up|down|status")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	command := flag.Arg(0)
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	db, err := database.New(cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	migrator, err := migrations.NewMigrator(db)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+
+	ctx := context.Background()
+	switch command {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, migration := range applied {
+			fmt.Printf("applied   %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("schema is up to date")
+		}
+
+	case "down":
+		if isProduction(cfg.Stage) && !*allowDataLoss {
+			log.Fatalf("Refusing to roll back in stage %q: down migrations drop data; pass -allow-data-loss to proceed", cfg.Stage)
+		}
+		rolledBack, err := migrator.Down(ctx, *steps)
+		for _, migration := range rolledBack {
+			fmt.Printf("rolled back %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			log.Fatalf("Rollback failed: %v", err)
+		}
+		if len(rolledBack) == 0 {
+			fmt.Println("no migrations to roll back")
+		}
+
+	case "status":
+		states, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatalf("Failed to read migration status: %v", err)
+		}
+		for _, state := range states {
+			status := "pending"
+			switch {
+			case state.Unknown:
+				status = "applied by a newer release"
+			case state.Modified:
+				status = "MODIFIED since applied " + state.AppliedAt.Format("2006-01-02 15:04:05")
+			case state.Applied:
+				status = "applied " + state.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%-40s %s\n", state.Version, state.Name, status)
+		}
+		if err := migrator.Check(ctx); err != nil {
+			fmt.Println()
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+// isProduction reports whether a stage holds production data
+func isProduction(stage string) bool {
+	switch strings.ToLower(stage) {
+	case "prod", "production":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"loan-eligibility-engine/internal/models"
+)
+
+// defaultBatchPageSize is the number of batches listed when no limit is given
+const defaultBatchPageSize = 50
+
+// batchStatuses are the statuses batches can be filtered by
+var batchStatuses = map[models.BatchStatus]bool{
+	models.BatchStatusReceived:  true,
+	models.BatchStatusParsing:   true,
+	models.BatchStatusInserting: true,
+	models.BatchStatusMatching:  true,
+	models.BatchStatusCompleted: true,
+	models.BatchStatusFailed:    true,
+}
+
+// batchesHandler lists upload batches, newest first, with their progress.
+// ?status= filters by lifecycle stage.
+func (s *Server) batchesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.batchRepo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, Response{
+			Success: false,
+			Error:   "Database not available",
+		})
+		return
+	}
+
+	limit := defaultBatchPageSize
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeJSON(w, http.StatusBadRequest, Response{
+				Success: false,
+				Error:   "Invalid limit",
+			})
+			return
+		}
+		limit = n
+	}
+
+	status := models.BatchStatus(r.URL.Query().Get("status"))
+	if status != "" && !batchStatuses[status] {
+		writeJSON(w, http.StatusBadRequest, Response{
+			Success: false,
+			Error:   "Invalid status",
+		})
+		return
+	}
+
+	batches, err := s.batchRepo.List(r.Context(), status, limit)
+	if err != nil {
+		log.Printf("Error listing batches: %v", err)
+		writeJSON(w, http.StatusInternalServerError, Response{
+			Success: false,
+			Error:   "Failed to list batches",
+		})
+		return
+	}
+	if batches == nil {
+		batches = []*models.UploadBatch{}
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Data:    batches,
+	})
+}
+
+// batchHandler returns an upload batch with its progress, row errors and
+// match funnel
+func (s *Server) batchHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.batchRepo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, Response{
+			Success: false,
+			Error:   "Database not available",
+		})
+		return
+	}
+
+	batchID := r.PathValue("id")
+	batch, err := s.batchRepo.Get(r.Context(), batchID)
+	if err != nil {
+		log.Printf("Error getting batch %s: %v", batchID, err)
+		writeJSON(w, http.StatusInternalServerError, Response{
+			Success: false,
+			Error:   "Failed to get batch",
+		})
+		return
+	}
+	if batch == nil {
+		writeJSON(w, http.StatusNotFound, Response{
+			Success: false,
+			Error:   "Batch not found",
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Data:    batch,
+	})
+}
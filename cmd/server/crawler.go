@@ -0,0 +1,290 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"loan-eligibility-engine/internal/models"
+	"loan-eligibility-engine/internal/services/crawler"
+	"loan-eligibility-engine/internal/services/jobs"
+)
+
+// defaultCrawlerRunPageSize is the number of crawler runs listed when no
+// limit is given
+const defaultCrawlerRunPageSize = 20
+
+// CrawlPayload is the payload of a crawl job: one source by name, or every
+// active source
+type CrawlPayload struct {
+	Source string `json:"source,omitempty"`
+}
+
+// CrawlResult is the result of a crawl job. Products whose terms changed are
+// re-matched by a follow-up rematch job.
+type CrawlResult struct {
+	Run          *models.CrawlerRun `json:"run"`
+	RematchJobID int64              `json:"rematch_job_id,omitempty"`
+}
+
+// crawlerSourcesHandler lists (GET) the registered crawler sources, or
+// registers a source or replaces its definition (POST)
+func (s *Server) crawlerSourcesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.crawlerRepo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, Response{
+			Success: false,
+			Error:   "Database not available",
+		})
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		sources, err := s.crawlerRepo.ListSources(r.Context(), false)
+		if err != nil {
+			log.Printf("Error listing crawler sources: %v", err)
+			writeJSON(w, http.StatusInternalServerError, Response{
+				Success: false,
+				Error:   "Failed to list crawler sources",
+			})
+			return
+		}
+		if sources == nil {
+			sources = []*models.CrawlerSource{}
+		}
+
+		writeJSON(w, http.StatusOK, Response{
+			Success: true,
+			Data:    sources,
+		})
+		return
+	}
+
+	var req models.CrawlerSourceCreate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, Response{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+		return
+	}
+	if err := models.ValidateCrawlerSource(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, Response{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+	if _, err := crawler.CompileRules(req.Rules); err != nil {
+		writeJSON(w, http.StatusBadRequest, Response{
+			Success: false,
+			Error:   "Invalid extraction rules: " + err.Error(),
+		})
+		return
+	}
+
+	source, err := s.crawlerRepo.UpsertSource(r.Context(), &req)
+	if err != nil {
+		log.Printf("Error saving crawler source %s: %v", req.Name, err)
+		writeJSON(w, http.StatusInternalServerError, Response{
+			Success: false,
+			Error:   "Failed to save crawler source",
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Crawler source saved",
+		Data:    source,
+	})
+}
+
+// crawlerRunsHandler lists recent crawler runs (GET) or queues a crawl (POST)
+func (s *Server) crawlerRunsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.crawlerRepo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, Response{
+			Success: false,
+			Error:   "Database not available",
+		})
+		return
+	}
+
+	if r.Method == http.MethodPost {
+		s.queueCrawl(w, r)
+		return
+	}
+
+	limit := defaultCrawlerRunPageSize
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeJSON(w, http.StatusBadRequest, Response{
+				Success: false,
+				Error:   "Invalid limit",
+			})
+			return
+		}
+		limit = n
+	}
+
+	runs, err := s.crawlerRepo.ListRuns(r.Context(), limit)
+	if err != nil {
+		log.Printf("Error listing crawler runs: %v", err)
+		writeJSON(w, http.StatusInternalServerError, Response{
+			Success: false,
+			Error:   "Failed to list crawler runs",
+		})
+		return
+	}
+	if runs == nil {
+		runs = []*models.CrawlerRun{}
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Data:    runs,
+	})
+}
+
+// queueCrawl queues a crawl job for the source named in the body, or for
+// every active source
+func (s *Server) queueCrawl(w http.ResponseWriter, r *http.Request) {
+	if s.crawler == nil || s.jobRepo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, Response{
+			Success: false,
+			Error:   "Crawler not available",
+		})
+		return
+	}
+
+	var req CrawlPayload
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeJSON(w, http.StatusBadRequest, Response{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+		return
+	}
+
+	if req.Source != "" {
+		source, err := s.crawlerRepo.GetSource(r.Context(), req.Source)
+		if err != nil {
+			log.Printf("Error getting crawler source %s: %v", req.Source, err)
+			writeJSON(w, http.StatusInternalServerError, Response{
+				Success: false,
+				Error:   "Failed to get crawler source",
+			})
+			return
+		}
+		if source == nil {
+			writeJSON(w, http.StatusNotFound, Response{
+				Success: false,
+				Error:   "Crawler source not found",
+			})
+			return
+		}
+	}
+
+	job, err := s.enqueueJob(r.Context(), models.JobTypeCrawl, req, nil)
+	if err != nil {
+		log.Printf("Error queueing crawl: %v", err)
+		writeJSON(w, http.StatusInternalServerError, Response{
+			Success: false,
+			Error:   "Failed to queue crawl",
+		})
+		return
+	}
+
+	writeJobAccepted(w, job, "", "Crawl queued")
+}
+
+// crawlerRunHandler returns a crawler run with the outcome of each source
+func (s *Server) crawlerRunHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.crawlerRepo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, Response{
+			Success: false,
+			Error:   "Database not available",
+		})
+		return
+	}
+
+	runID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, Response{
+			Success: false,
+			Error:   "Invalid run ID",
+		})
+		return
+	}
+
+	run, err := s.crawlerRepo.GetRun(r.Context(), runID)
+	if err != nil {
+		log.Printf("Error getting crawler run %d: %v", runID, err)
+		writeJSON(w, http.StatusInternalServerError, Response{
+			Success: false,
+			Error:   "Failed to get crawler run",
+		})
+		return
+	}
+	if run == nil {
+		writeJSON(w, http.StatusNotFound, Response{
+			Success: false,
+			Error:   "Crawler run not found",
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Data:    run,
+	})
+}
+
+// runCrawlJob crawls the requested source, or every active source, and
+// queues a re-match of the products whose terms changed. Sources that fail
+// are recorded on the run rather than failing the job, so a retry does not
+// re-crawl the sources that succeeded.
+func (s *Server) runCrawlJob(ctx context.Context, job *models.Job, progress jobs.ProgressFunc) (interface{}, error) {
+	var payload CrawlPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return nil, jobs.Permanent(fmt.Errorf("invalid crawl payload: %w", err))
+	}
+
+	run, err := s.crawler.Run(ctx, payload.Source)
+	if errors.Is(err, crawler.ErrUnknownSource) {
+		return nil, jobs.Permanent(err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CrawlResult{Run: run}
+	if s.matcher != nil && run.ProductsAdded+run.ProductsUpdated > 0 {
+		rematchJob, err := s.enqueueJob(ctx, models.JobTypeRematch, RematchRequest{}, nil)
+		if err != nil {
+			return nil, err
+		}
+		result.RematchJobID = rematchJob.ID
+	}
+	return result, nil
+}
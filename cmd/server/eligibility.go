@@ -0,0 +1,54 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// userEligibilityHandler returns the per-product pass/fail breakdown for a user
+func (s *Server) userEligibilityHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.matcher == nil {
+		writeJSON(w, http.StatusServiceUnavailable, Response{
+			Success: false,
+			Error:   "Matcher service not available",
+		})
+		return
+	}
+
+	userID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, Response{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+		return
+	}
+
+	report, err := s.matcher.ExplainUser(r.Context(), userID)
+	if err != nil {
+		log.Printf("Error explaining eligibility for user %d: %v", userID, err)
+		writeJSON(w, http.StatusInternalServerError, Response{
+			Success: false,
+			Error:   "Failed to compute eligibility",
+		})
+		return
+	}
+	if report == nil {
+		writeJSON(w, http.StatusNotFound, Response{
+			Success: false,
+			Error:   "User not found",
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Data:    report,
+	})
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"loan-eligibility-engine/internal/models"
+)
+
+// fxRatesHandler lists (GET) or updates (PUT) the exchange rates used to
+// compare users and products in different currencies
+func (s *Server) fxRatesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.fxRepo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, Response{
+			Success: false,
+			Error:   "Database not available",
+		})
+		return
+	}
+
+	changed := false
+	if r.Method == http.MethodPut {
+		var req []*models.FXRate
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, Response{
+				Success: false,
+				Error:   "Invalid request body: expected a JSON array of exchange rates",
+			})
+			return
+		}
+
+		// Reject the whole set if any rate is invalid
+		var problems []string
+		for i, rate := range req {
+			if err := models.ValidateFXRate(rate); err != nil {
+				problems = append(problems, fmt.Sprintf("rate %d: %v", i+1, err))
+			}
+		}
+		if len(problems) > 0 {
+			writeJSON(w, http.StatusBadRequest, Response{
+				Success: false,
+				Error:   "Invalid exchange rates: " + strings.Join(problems, "; "),
+			})
+			return
+		}
+
+		var err error
+		changed, err = s.fxRepo.Upsert(r.Context(), req)
+		if err != nil {
+			log.Printf("Error saving exchange rates: %v", err)
+			writeJSON(w, http.StatusInternalServerError, Response{
+				Success: false,
+				Error:   "Failed to save exchange rates",
+			})
+			return
+		}
+	}
+
+	rates, err := s.fxRepo.GetAll(r.Context())
+	if err != nil {
+		log.Printf("Error fetching exchange rates: %v", err)
+		writeJSON(w, http.StatusInternalServerError, Response{
+			Success: false,
+			Error:   "Failed to fetch exchange rates",
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Data: map[string]interface{}{
+			"base_currency":    models.DefaultCurrency,
+			"fx_rates":         rates,
+			"products_flagged": changed,
+		},
+	})
+}
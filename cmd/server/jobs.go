@@ -0,0 +1,411 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"loan-eligibility-engine/internal/models"
+	"loan-eligibility-engine/internal/services/database"
+	"loan-eligibility-engine/internal/services/jobs"
+	"loan-eligibility-engine/internal/utils"
+)
+
+// ingestChunkSize is the number of CSV rows saved per transaction by an
+// ingest job
+const ingestChunkSize = 500
+
+// IngestPayload is the payload of an ingest job; the CSV itself is stored as
+// the job's data
+type IngestPayload struct {
+	BatchID  string `json:"batch_id"`
+	Filename string `json:"filename"`
+}
+
+// IngestResult is the result of an ingest job
+type IngestResult struct {
+	BatchID    string   `json:"batch_id"`
+	TotalRows  int      `json:"total_rows"`
+	ValidUsers int      `json:"valid_users"`
+	Errors     int      `json:"errors"`
+	SavedUsers int      `json:"saved_users"`
+	SaveErrors []string `json:"save_errors,omitempty"`
+	MatchJobID int64    `json:"match_job_id,omitempty"`
+}
+
+// MatchPayload is the payload of a match job: a whole upload batch, or a list
+// of user IDs
+type MatchPayload struct {
+	BatchID string  `json:"batch_id,omitempty"`
+	UserIDs []int64 `json:"user_ids,omitempty"`
+}
+
+// NotifyPayload is the payload of a notify job. RankBy lists the offers by
+// match score (the default) or by total cost of borrowing Amount over
+// TenureMonths, which default to the terms the user requested.
+type NotifyPayload struct {
+	UserEmail    string              `json:"user_email"`
+	UserName     string              `json:"user_name"`
+	RankBy       models.OfferRanking `json:"rank_by,omitempty"`
+	Amount       float64             `json:"amount,omitempty"`
+	TenureMonths int                 `json:"tenure_months,omitempty"`
+}
+
+// JobAccepted is returned by endpoints that queue work instead of doing it
+// inside the request
+type JobAccepted struct {
+	JobID    int64          `json:"job_id"`
+	Type     models.JobType `json:"type"`
+	BatchID  string         `json:"batch_id,omitempty"`
+	Status   string         `json:"status_url"`
+	BatchURL string         `json:"batch_url,omitempty"`
+}
+
+// enqueueJob queues a job with the configured retry limit
+func (s *Server) enqueueJob(ctx context.Context, jobType models.JobType, payload interface{}, data []byte) (*models.Job, error) {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode job payload: %w", err)
+	}
+	return s.jobRepo.Enqueue(ctx, &models.JobCreate{
+		Type:        jobType,
+		Payload:     encoded,
+		Data:        data,
+		MaxAttempts: s.config.JobMaxAttempts,
+	})
+}
+
+// writeJobAccepted responds 202 with the queued job and where to poll it
+func writeJobAccepted(w http.ResponseWriter, job *models.Job, batchID, message string) {
+	accepted := JobAccepted{
+		JobID:   job.ID,
+		Type:    job.Type,
+		BatchID: batchID,
+		Status:  fmt.Sprintf("/api/jobs/%d", job.ID),
+	}
+	if batchID != "" {
+		accepted.BatchURL = "/api/batches/" + batchID
+	}
+
+	writeJSON(w, http.StatusAccepted, Response{
+		Success: true,
+		Message: message,
+		Data:    accepted,
+	})
+}
+
+// startJobWorkers starts polling the job queue. Match and rematch jobs are
+// only claimed when the matcher is available, so another instance can run
+// them.
+func (s *Server) startJobWorkers(ctx context.Context) {
+	hostname, _ := os.Hostname()
+	runner := jobs.NewRunner(s.jobRepo, fmt.Sprintf("%s-%d", hostname, os.Getpid()), jobs.Options{
+		Workers:      s.config.JobWorkers,
+		PollInterval: time.Duration(s.config.JobPollIntervalSeconds) * time.Second,
+		Lease:        time.Duration(s.config.JobLeaseSeconds) * time.Second,
+	})
+
+	runner.Handle(models.JobTypeIngest, s.runIngestJob)
+	runner.Handle(models.JobTypeNotify, s.runNotifyJob)
+	runner.Handle(models.JobTypeCrawl, s.runCrawlJob)
+	if s.matcher != nil {
+		runner.Handle(models.JobTypeMatch, s.runMatchJob)
+		runner.Handle(models.JobTypeRematch, s.runRematchJob)
+	}
+
+	go runner.Run(ctx)
+}
+
+// runIngestJob parses an uploaded CSV, saves its users in chunks and queues
+// a match job for the batch, recording each stage on the upload batch. Users
+// are upserted on user_id, so a retried ingest does not duplicate them.
+func (s *Server) runIngestJob(ctx context.Context, job *models.Job, progress jobs.ProgressFunc) (interface{}, error) {
+	var payload IngestPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return nil, jobs.Permanent(fmt.Errorf("invalid ingest payload: %w", err))
+	}
+
+	result, err := s.ingest(ctx, payload, job.Data, progress)
+	if err != nil {
+		s.failBatch(ctx, job, payload.BatchID, err)
+		return nil, err
+	}
+	return result, nil
+}
+
+// ingest runs an ingest job's stages for its batch
+func (s *Server) ingest(ctx context.Context, payload IngestPayload, content []byte, progress jobs.ProgressFunc) (*IngestResult, error) {
+	if err := s.batchRepo.SetStatus(ctx, payload.BatchID, models.BatchStatusParsing); err != nil {
+		return nil, err
+	}
+
+	users, parseErrors := parseCSV(content, payload.Filename, payload.BatchID)
+	result := &IngestResult{
+		BatchID:    payload.BatchID,
+		TotalRows:  len(users) + len(parseErrors),
+		ValidUsers: len(users),
+		Errors:     len(parseErrors),
+	}
+
+	rowErrors := make([]string, len(parseErrors))
+	for i, err := range parseErrors {
+		rowErrors[i] = err.Error()
+	}
+	if err := s.batchRepo.RecordParsed(ctx, payload.BatchID, result.TotalRows, result.ValidUsers, rowErrors); err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		if err := s.batchRepo.Fail(ctx, payload.BatchID, "No valid users found in CSV"); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	for start := 0; start < len(users); start += ingestChunkSize {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		chunk := users[start:min(start+ingestChunkSize, len(users))]
+		saved, err := s.userRepo.BulkInsert(ctx, chunk)
+		if err != nil {
+			return nil, err
+		}
+		result.SavedUsers += saved.InsertedCount
+		result.SaveErrors = append(result.SaveErrors, saved.Errors...)
+		if err := s.batchRepo.RecordSaved(ctx, payload.BatchID, result.SavedUsers); err != nil {
+			return nil, err
+		}
+		progress(start+len(chunk), len(users))
+	}
+
+	log.Printf("💾 Saved %d users to database (batch %s)", result.SavedUsers, payload.BatchID)
+
+	next := models.BatchStatusCompleted
+	if s.matcher != nil && result.SavedUsers > 0 {
+		matchJob, err := s.enqueueJob(ctx, models.JobTypeMatch, MatchPayload{BatchID: payload.BatchID}, nil)
+		if err != nil {
+			return nil, err
+		}
+		result.MatchJobID = matchJob.ID
+		next = models.BatchStatusMatching
+	}
+
+	if err := s.batchRepo.FinishInsert(ctx, payload.BatchID, result.SavedUsers, result.SaveErrors, next); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// failBatch marks a job's batch failed once the job will not be retried:
+// the error is permanent or the attempt was the last. A job interrupted by
+// cancellation or shutdown leaves its batch as it was.
+func (s *Server) failBatch(ctx context.Context, job *models.Job, batchID string, err error) {
+	if ctx.Err() != nil || (!jobs.IsPermanent(err) && job.Attempts < job.MaxAttempts) {
+		return
+	}
+	if failErr := s.batchRepo.Fail(ctx, batchID, err.Error()); failErr != nil {
+		log.Printf("Error marking batch %s failed: %v", batchID, failErr)
+	}
+}
+
+// runMatchJob matches a batch, resuming from its checkpoint on retry, or a
+// list of users
+func (s *Server) runMatchJob(ctx context.Context, job *models.Job, progress jobs.ProgressFunc) (interface{}, error) {
+	var payload MatchPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return nil, jobs.Permanent(fmt.Errorf("invalid match payload: %w", err))
+	}
+
+	if payload.BatchID != "" {
+		if err := s.batchRepo.SetStatus(ctx, payload.BatchID, models.BatchStatusMatching); err != nil {
+			return nil, err
+		}
+		result, err := s.matcher.ProcessBatchWithProgress(ctx, payload.BatchID, progress)
+		if err != nil {
+			s.failBatch(ctx, job, payload.BatchID, err)
+			return nil, err
+		}
+		if err := s.batchRepo.SetStatus(ctx, payload.BatchID, models.BatchStatusCompleted); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+	if len(payload.UserIDs) == 0 {
+		return nil, jobs.Permanent(errors.New("match job needs a batch_id or user_ids"))
+	}
+
+	progress(0, len(payload.UserIDs))
+	result, err := s.matcher.ProcessNewUsers(ctx, payload.UserIDs)
+	if err != nil {
+		return nil, err
+	}
+	progress(len(payload.UserIDs), len(payload.UserIDs))
+	return result, nil
+}
+
+// runNotifyJob sends a user's matched loans through the n8n notification
+// workflow
+func (s *Server) runNotifyJob(ctx context.Context, job *models.Job, progress jobs.ProgressFunc) (interface{}, error) {
+	var payload NotifyPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return nil, jobs.Permanent(fmt.Errorf("invalid notify payload: %w", err))
+	}
+	return s.notifyUser(ctx, payload)
+}
+
+// runRematchJob re-matches the requested products, or every changed product
+func (s *Server) runRematchJob(ctx context.Context, job *models.Job, progress jobs.ProgressFunc) (interface{}, error) {
+	var req RematchRequest
+	if err := json.Unmarshal(job.Payload, &req); err != nil {
+		return nil, jobs.Permanent(fmt.Errorf("invalid rematch payload: %w", err))
+	}
+
+	if len(req.ProductIDs) > 0 {
+		return s.matcher.RematchProducts(ctx, req.ProductIDs, models.RematchTriggerManual)
+	}
+	run, err := s.matcher.RematchChangedProducts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if run == nil {
+		return map[string]string{"message": "No product changes to re-match"}, nil
+	}
+	return run, nil
+}
+
+// parseCSV parses uploaded CSV content into users of a batch, logging the
+// first few rows that failed to parse
+func parseCSV(content []byte, filename, batchID string) ([]*models.UserCreate, []error) {
+	log.Printf("Processing CSV: %s (BatchID: %s)", filename, batchID)
+
+	parser := utils.NewCSVParser()
+	users, parseErrors := parser.ParseUsers(string(content), batchID)
+
+	log.Printf("Parsed: %d valid users, %d errors", len(users), len(parseErrors))
+
+	if len(parseErrors) > 0 {
+		log.Printf("Parse errors:")
+		for i, err := range parseErrors {
+			if i >= 5 { // Only log first 5 errors
+				log.Printf("   ... and %d more errors", len(parseErrors)-5)
+				break
+			}
+			log.Printf("   - %v", err)
+		}
+	}
+
+	return users, parseErrors
+}
+
+// jobHandler returns a job's status, progress and result
+func (s *Server) jobHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.jobRepo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, Response{
+			Success: false,
+			Error:   "Database not available",
+		})
+		return
+	}
+
+	jobID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, Response{
+			Success: false,
+			Error:   "Invalid job ID",
+		})
+		return
+	}
+
+	job, err := s.jobRepo.Get(r.Context(), jobID)
+	if err != nil {
+		log.Printf("Error getting job %d: %v", jobID, err)
+		writeJSON(w, http.StatusInternalServerError, Response{
+			Success: false,
+			Error:   "Failed to get job",
+		})
+		return
+	}
+	if job == nil {
+		writeJSON(w, http.StatusNotFound, Response{
+			Success: false,
+			Error:   "Job not found",
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Data:    job,
+	})
+}
+
+// cancelJobHandler cancels a queued job, or asks the worker running it to stop
+func (s *Server) cancelJobHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.jobRepo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, Response{
+			Success: false,
+			Error:   "Database not available",
+		})
+		return
+	}
+
+	jobID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, Response{
+			Success: false,
+			Error:   "Invalid job ID",
+		})
+		return
+	}
+
+	job, err := s.jobRepo.Cancel(r.Context(), jobID)
+	if errors.Is(err, database.ErrJobFinished) {
+		writeJSON(w, http.StatusConflict, Response{
+			Success: false,
+			Error:   "Job already finished",
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Error cancelling job %d: %v", jobID, err)
+		writeJSON(w, http.StatusInternalServerError, Response{
+			Success: false,
+			Error:   "Failed to cancel job",
+		})
+		return
+	}
+	if job == nil {
+		writeJSON(w, http.StatusNotFound, Response{
+			Success: false,
+			Error:   "Job not found",
+		})
+		return
+	}
+
+	message := "Job cancelled"
+	if job.Status == models.JobStatusRunning {
+		message = "Cancellation requested; the job stops at its next checkpoint"
+	}
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: message,
+		Data:    job,
+	})
+}
@@ -17,8 +17,12 @@ import (
 
 	"loan-eligibility-engine/internal/config"
 	"loan-eligibility-engine/internal/models"
+	"loan-eligibility-engine/internal/services/crawler"
 	"loan-eligibility-engine/internal/services/database"
+	"loan-eligibility-engine/internal/services/jobs"
 	"loan-eligibility-engine/internal/services/matcher"
+	"loan-eligibility-engine/internal/services/migrations"
+	"loan-eligibility-engine/internal/services/ranking"
 	"loan-eligibility-engine/internal/utils"
 
 	"github.com/rs/cors"
@@ -26,12 +30,22 @@ import (
 
 // Server holds all dependencies
 type Server struct {
-	db        *database.DB
-	userRepo  *database.UserRepository
-	prodRepo  *database.ProductRepository
-	matchRepo *database.MatchRepository
-	matcher   *matcher.MatcherService
-	config    *config.Config
+	db          *database.DB
+	userRepo    *database.UserRepository
+	prodRepo    *database.ProductRepository
+	versionRepo *database.ProductVersionRepository
+	matchRepo   *database.MatchRepository
+	ruleRepo    *database.RuleRepository
+	slabRepo    *database.RateSlabRepository
+	fxRepo      *database.FXRateRepository
+	rematchRepo *database.RematchRepository
+	reviewRepo  *database.ReviewRepository
+	jobRepo     *database.JobRepository
+	batchRepo   *database.BatchRepository
+	crawlerRepo *database.CrawlerRepository
+	crawler     *crawler.Crawler
+	matcher     *matcher.MatcherService
+	config      *config.Config
 }
 
 // Response represents a standard API response
@@ -86,6 +100,13 @@ func main() {
 		log.Println("Server will run in demo mode without database")
 	}
 
+	// Refuse to serve against a schema older than this build expects
+	if db != nil && cfg.RequireCurrentSchema {
+		if err := migrations.Check(context.Background(), db); err != nil {
+			log.Fatalf("Database schema check failed: %v", err)
+		}
+	}
+
 	server := &Server{
 		db:     db,
 		config: cfg,
@@ -94,7 +115,16 @@ func main() {
 	if db != nil {
 		server.userRepo = database.NewUserRepository(db)
 		server.prodRepo = database.NewProductRepository(db)
+		server.versionRepo = database.NewProductVersionRepository(db)
 		server.matchRepo = database.NewMatchRepository(db)
+		server.ruleRepo = database.NewRuleRepository(db)
+		server.slabRepo = database.NewRateSlabRepository(db)
+		server.fxRepo = database.NewFXRateRepository(db)
+		server.rematchRepo = database.NewRematchRepository(db)
+		server.reviewRepo = database.NewReviewRepository(db)
+		server.batchRepo = database.NewBatchRepository(db)
+		server.crawlerRepo = database.NewCrawlerRepository(db)
+		server.crawler = crawler.New(db, cfg)
 
 		// Initialize matcher (may fail if no Gemini API key)
 		matcherSvc, err := matcher.NewMatcherService(db)
@@ -102,6 +132,11 @@ func main() {
 			log.Printf("Warning: Could not initialize matcher service: %v", err)
 		}
 		server.matcher = matcherSvc
+
+		// Run queued jobs in the background; every server instance polls
+		// the same queue
+		server.jobRepo = database.NewJobRepository(db)
+		server.startJobWorkers(context.Background())
 	}
 
 	// Setup routes
@@ -123,10 +158,48 @@ func main() {
 	// Get products
 	mux.HandleFunc("/api/products", server.productsHandler)
 
+	// Per-product eligibility rules
+	mux.HandleFunc("/api/products/{id}/rules", server.productRulesHandler)
+
+	// Per-product interest rate slabs by credit score and income band
+	mux.HandleFunc("/api/products/{id}/rate-slabs", server.productRateSlabsHandler)
+
+	// Exchange rates for comparing users and products in different currencies
+	mux.HandleFunc("/api/fx-rates", server.fxRatesHandler)
+
+	// Product version history and interest rate timelines by lender
+	mux.HandleFunc("/api/products/{id}/history", server.productHistoryHandler)
+	mux.HandleFunc("/api/products/rate-timeline", server.rateTimelineHandler)
+
+	// Project the effect of a product policy change without saving it
+	mux.HandleFunc("/api/products/{id}/simulate", server.productSimulationHandler)
+
+	// Re-match changed products and inspect past runs
+	mux.HandleFunc("/api/products/rematch", server.rematchProductsHandler)
+	mux.HandleFunc("/api/rematch-runs/{id}", server.rematchRunHandler)
+
 	// Get matches
 	mux.HandleFunc("/api/matches", server.matchesHandler)
 
-	// Trigger n8n workflows
+	// Background job status and cancellation
+	mux.HandleFunc("/api/jobs/{id}", server.jobHandler)
+	mux.HandleFunc("/api/jobs/{id}/cancel", server.cancelJobHandler)
+
+	// Upload batch lifecycle, row errors and match funnel
+	mux.HandleFunc("/api/batches", server.batchesHandler)
+	mux.HandleFunc("/api/batches/{id}", server.batchHandler)
+
+	// Human review queue for low-confidence and failed LLM verdicts
+	mux.HandleFunc("/api/review", server.reviewQueueHandler)
+	mux.HandleFunc("/api/review/{id}/approve", server.approveReviewHandler)
+	mux.HandleFunc("/api/review/{id}/reject", server.rejectReviewHandler)
+
+	// Crawler sources and runs
+	mux.HandleFunc("/api/crawler/sources", server.crawlerSourcesHandler)
+	mux.HandleFunc("/api/crawler/runs", server.crawlerRunsHandler)
+	mux.HandleFunc("/api/crawler/runs/{id}", server.crawlerRunHandler)
+
+	// Trigger workflows
 	mux.HandleFunc("/api/trigger/crawler", server.triggerCrawlerHandler)
 	mux.HandleFunc("/api/trigger/matching", server.triggerMatchingHandler)
 	mux.HandleFunc("/api/trigger/notification", server.triggerNotificationHandler)
@@ -134,6 +207,12 @@ func main() {
 	// Get users with matches (for notification dropdown)
 	mux.HandleFunc("/api/users-with-matches", server.usersWithMatchesHandler)
 
+	// Per-product eligibility breakdown for a user
+	mux.HandleFunc("/api/users/{id}/eligibility", server.userEligibilityHandler)
+
+	// A user's matched products priced for an amount and tenure
+	mux.HandleFunc("/api/users/{id}/offers", server.userOffersHandler)
+
 	// Clear data endpoint
 	mux.HandleFunc("/api/clear-data", server.clearDataHandler)
 
@@ -280,21 +359,7 @@ func (s *Server) uploadHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Process the CSV
-	result, err := s.processCSVContent(r.Context(), content, header.Filename)
-	if err != nil {
-		writeJSON(w, http.StatusInternalServerError, Response{
-			Success: false,
-			Error:   err.Error(),
-		})
-		return
-	}
-
-	writeJSON(w, http.StatusOK, Response{
-		Success: true,
-		Message: "CSV processed successfully",
-		Data:    result,
-	})
+	s.acceptCSV(r.Context(), w, content, header.Filename)
 }
 
 func (s *Server) handlePresignedUpload(w http.ResponseWriter, r *http.Request) {
@@ -355,89 +420,72 @@ func (s *Server) processHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Process
-	result, err := s.processCSVContent(r.Context(), content, filename)
-	if err != nil {
-		writeJSON(w, http.StatusInternalServerError, Response{
-			Success: false,
-			Error:   err.Error(),
-		})
-		return
+	// The queued job keeps its own copy of the CSV
+	if s.acceptCSV(r.Context(), w, content, filename) {
+		os.Remove(tempFile)
 	}
-
-	// Cleanup temp file
-	os.Remove(tempFile)
-
-	writeJSON(w, http.StatusOK, Response{
-		Success: true,
-		Message: "CSV processed successfully",
-		Data:    result,
-	})
 }
 
-func (s *Server) processCSVContent(ctx context.Context, content []byte, filename string) (*UploadResponse, error) {
-	startTime := time.Now()
-	batchID := fmt.Sprintf("batch_%d", time.Now().Unix())
-
-	log.Printf("Processing CSV: %s (BatchID: %s)", filename, batchID)
-
-	// Parse CSV
-	parser := utils.NewCSVParser()
-	users, parseErrors := parser.ParseUsers(string(content), batchID)
-
-	log.Printf("Parsed: %d valid users, %d errors", len(users), len(parseErrors))
-
-	// Log first few errors for debugging
-	if len(parseErrors) > 0 {
-		log.Printf("Parse errors:")
-		for i, err := range parseErrors {
-			if i >= 5 { // Only log first 5 errors
-				log.Printf("   ... and %d more errors", len(parseErrors)-5)
-				break
-			}
-			log.Printf("   - %v", err)
-		}
-	}
+// acceptCSV records an upload batch, queues an ingest job for it and responds
+// 202 with the job and batch to poll; saving users and matching them happen
+// in the background. Without a database the CSV is parsed in the request and
+// demo results are returned. Reports whether the CSV was accepted.
+func (s *Server) acceptCSV(ctx context.Context, w http.ResponseWriter, content []byte, filename string) bool {
+	batchID := models.NewBatchID(time.Now())
 
-	result := &UploadResponse{
-		BatchID:    batchID,
-		TotalRows:  len(users) + len(parseErrors),
-		ValidUsers: len(users),
-		Errors:     len(parseErrors),
+	if s.jobRepo == nil {
+		writeJSON(w, http.StatusOK, Response{
+			Success: true,
+			Message: "CSV processed successfully",
+			Data:    s.processCSVContent(content, filename, batchID),
+		})
+		return true
 	}
 
-	// If no database connection, return demo results
-	if s.db == nil || s.userRepo == nil {
-		result.MatchesFound = len(users) * 2 // Demo: assume 2 matches per user
-		result.ProcessingMs = time.Since(startTime).Milliseconds()
-		return result, nil
+	err := s.batchRepo.Create(ctx, &models.UploadBatchCreate{
+		BatchID:  batchID,
+		Source:   models.BatchSourceUpload,
+		FileName: filename,
+	})
+	if err != nil {
+		log.Printf("Error recording batch for CSV %s: %v", filename, err)
+		writeJSON(w, http.StatusInternalServerError, Response{
+			Success: false,
+			Error:   "Failed to record upload",
+		})
+		return false
 	}
 
-	// Save users to database and collect IDs
-	var userIDs []int64
-	for _, u := range users {
-		id, err := s.userRepo.Create(ctx, u)
-		if err != nil {
-			log.Printf("Warning: Could not save user %s: %v", u.Email, err)
-			continue
+	job, err := s.enqueueJob(ctx, models.JobTypeIngest, IngestPayload{BatchID: batchID, Filename: filename}, content)
+	if err != nil {
+		log.Printf("Error queueing CSV %s: %v", filename, err)
+		if failErr := s.batchRepo.Fail(ctx, batchID, "Failed to queue CSV for processing"); failErr != nil {
+			log.Printf("Error marking batch %s failed: %v", batchID, failErr)
 		}
-		userIDs = append(userIDs, id)
+		writeJSON(w, http.StatusInternalServerError, Response{
+			Success: false,
+			Error:   "Failed to queue CSV for processing",
+		})
+		return false
 	}
 
-	log.Printf("💾 Saved %d users to database (IDs: %v)", len(userIDs), userIDs)
+	writeJobAccepted(w, job, batchID, "CSV queued for processing")
+	return true
+}
 
-	// Run matching if we have a matcher service
-	if s.matcher != nil && len(userIDs) > 0 {
-		matchResult, err := s.matcher.ProcessNewUsers(ctx, userIDs)
-		if err != nil {
-			log.Printf("Warning: Matching failed: %v", err)
-		} else {
-			result.MatchesFound = matchResult.FinalMatches
-		}
-	}
+// processCSVContent parses a CSV without saving it and returns demo results
+func (s *Server) processCSVContent(content []byte, filename, batchID string) *UploadResponse {
+	startTime := time.Now()
+	users, parseErrors := parseCSV(content, filename, batchID)
 
-	result.ProcessingMs = time.Since(startTime).Milliseconds()
-	return result, nil
+	return &UploadResponse{
+		BatchID:      batchID,
+		TotalRows:    len(users) + len(parseErrors),
+		ValidUsers:   len(users),
+		Errors:       len(parseErrors),
+		MatchesFound: len(users) * 2, // Demo: assume 2 matches per user
+		ProcessingMs: time.Since(startTime).Milliseconds(),
+	}
 }
 
 func (s *Server) productsHandler(w http.ResponseWriter, r *http.Request) {
@@ -486,6 +534,20 @@ func (s *Server) matchesHandler(w http.ResponseWriter, r *http.Request) {
 
 	ctx := r.Context()
 
+	// Amounts are in the product's currency and are formatted in the user's
+	// locale unless ?locale= overrides it
+	locale := ""
+	if v := r.URL.Query().Get("locale"); v != "" {
+		var ok bool
+		if locale, ok = utils.NormalizeLocale(v); !ok {
+			writeJSON(w, http.StatusBadRequest, Response{
+				Success: false,
+				Error:   "Unsupported locale",
+			})
+			return
+		}
+	}
+
 	// Get enriched matches with user and product information
 	query := `
 		SELECT 
@@ -494,10 +556,20 @@ func (s *Server) matchesHandler(w http.ResponseWriter, r *http.Request) {
 			m.product_id,
 			m.match_score,
 			m.status,
+			COALESCE(m.max_eligible_amount, 0),
+			COALESCE(m.emi_min, 0),
+			COALESCE(m.emi_max, 0),
+			COALESCE(m.foir, 0),
+			COALESCE(m.estimated_rate, 0),
+			m.processing_fee_percent,
+			COALESCE(m.scoring_version, ''),
+			m.product_version,
 			u.user_id as user_name,
 			u.email as user_email,
+			u.locale,
 			lp.product_name,
-			lp.provider_name
+			lp.provider_name,
+			lp.currency
 		FROM matches m
 		JOIN users u ON m.user_id = u.id
 		JOIN loan_products lp ON m.product_id = lp.id
@@ -519,24 +591,48 @@ func (s *Server) matchesHandler(w http.ResponseWriter, r *http.Request) {
 	var matches []map[string]interface{}
 	for rows.Next() {
 		var id, userID, productID int64
-		var matchScore float64
-		var status, userName, userEmail, productName, providerName string
-
-		if err := rows.Scan(&id, &userID, &productID, &matchScore, &status, &userName, &userEmail, &productName, &providerName); err != nil {
+		var matchScore, maxEligible, emiMin, emiMax, foir, estimatedRate float64
+		var processingFee *float64
+		var productVersion *int
+		var status, scoringVersion, userName, userEmail, userLocale, productName, providerName, currencyCode string
+
+		if err := rows.Scan(&id, &userID, &productID, &matchScore, &status, &maxEligible, &emiMin, &emiMax, &foir,
+			&estimatedRate, &processingFee, &scoringVersion, &productVersion, &userName, &userEmail, &userLocale,
+			&productName, &providerName, &currencyCode); err != nil {
 			log.Printf("Failed to scan match: %v", err)
 			continue
 		}
 
+		currency := models.Currency(currencyCode).OrDefault()
+		matchLocale := locale
+		if matchLocale == "" {
+			matchLocale = userLocale
+		}
+
 		matches = append(matches, map[string]interface{}{
-			"id":            id,
-			"user_id":       userID,
-			"product_id":    productID,
-			"match_score":   matchScore,
-			"status":        status,
-			"user_name":     userName,
-			"user_email":    userEmail,
-			"product_name":  productName,
-			"provider_name": providerName,
+			"id":                     id,
+			"user_id":                userID,
+			"product_id":             productID,
+			"match_score":            matchScore,
+			"status":                 status,
+			"max_eligible_amount":    maxEligible,
+			"emi_min":                emiMin,
+			"emi_max":                emiMax,
+			"foir":                   foir,
+			"estimated_rate":         estimatedRate,
+			"processing_fee_percent": processingFee,
+			"scoring_version":        scoringVersion,
+			"product_version":        productVersion,
+			"user_name":              userName,
+			"user_email":             userEmail,
+			"product_name":           productName,
+			"provider_name":          providerName,
+			"currency":               currency,
+			"formatted": map[string]string{
+				"max_eligible_amount": utils.FormatMoney(maxEligible, currency, matchLocale),
+				"emi_min":             utils.FormatMoney(emiMin, currency, matchLocale),
+				"emi_max":             utils.FormatMoney(emiMax, currency, matchLocale),
+			},
 		})
 	}
 
@@ -546,40 +642,22 @@ func (s *Server) matchesHandler(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// triggerCrawlerHandler queues a crawl of every active crawler source
 func (s *Server) triggerCrawlerHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	// Trigger n8n crawler workflow
-	n8nURL := getEnvOrDefault("N8N_WEBHOOK_URL", "http://localhost:5678")
-	webhookURL := fmt.Sprintf("%s/webhook/trigger-crawler", n8nURL)
-
-	resp, err := http.Post(webhookURL, "application/json", strings.NewReader("{}"))
-	if err != nil {
-		writeJSON(w, http.StatusOK, Response{
-			Success: true,
-			Message: "Crawler trigger sent (n8n may be offline)",
-			Data: map[string]interface{}{
-				"n8n_url": webhookURL,
-				"status":  "queued",
-			},
+	if s.crawlerRepo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, Response{
+			Success: false,
+			Error:   "Database not available",
 		})
 		return
 	}
-	defer resp.Body.Close()
 
-	body, _ := io.ReadAll(resp.Body)
-
-	writeJSON(w, http.StatusOK, Response{
-		Success: true,
-		Message: "Crawler workflow triggered",
-		Data: map[string]interface{}{
-			"n8n_status": resp.StatusCode,
-			"response":   string(body),
-		},
-	})
+	s.queueCrawl(w, r)
 }
 
 func (s *Server) triggerMatchingHandler(w http.ResponseWriter, r *http.Request) {
@@ -606,22 +684,19 @@ func (s *Server) triggerMatchingHandler(w http.ResponseWriter, r *http.Request)
 	payload, _ := json.Marshal(req)
 	resp, err := http.Post(webhookURL, "application/json", strings.NewReader(string(payload)))
 	if err != nil {
-		// Fallback to local matcher
-		if s.matcher != nil && len(req.UserIDs) > 0 {
-			result, err := s.matcher.ProcessNewUsers(r.Context(), req.UserIDs)
+		// Fallback to a local match job
+		if s.matcher != nil && s.jobRepo != nil && (req.BatchID != "" || len(req.UserIDs) > 0) {
+			job, err := s.enqueueJob(r.Context(), models.JobTypeMatch, MatchPayload{BatchID: req.BatchID, UserIDs: req.UserIDs}, nil)
 			if err != nil {
+				log.Printf("Error queueing match job: %v", err)
 				writeJSON(w, http.StatusInternalServerError, Response{
 					Success: false,
-					Error:   "Matching failed: " + err.Error(),
+					Error:   "Failed to queue matching",
 				})
 				return
 			}
 
-			writeJSON(w, http.StatusOK, Response{
-				Success: true,
-				Message: "Matching completed (local)",
-				Data:    result,
-			})
+			writeJobAccepted(w, job, req.BatchID, "Matching queued (local)")
 			return
 		}
 
@@ -651,26 +726,27 @@ func (s *Server) triggerNotificationHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	ctx := r.Context()
-
 	// Parse request body to get user email
-	var reqBody struct {
-		UserEmail string `json:"user_email"`
-		UserName  string `json:"user_name"`
-	}
-
-	if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+	var reqBody NotifyPayload
+	if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil || reqBody.UserEmail == "" {
 		writeJSON(w, http.StatusBadRequest, Response{
 			Success: false,
 			Error:   "Invalid request body",
 		})
 		return
 	}
+	if reqBody.RankBy != "" && !reqBody.RankBy.IsValid() {
+		writeJSON(w, http.StatusBadRequest, Response{
+			Success: false,
+			Error:   "Invalid rank_by: expected cost or score",
+		})
+		return
+	}
 
 	log.Printf("Notification request for: %s", reqBody.UserEmail)
 
 	// Check if database is available
-	if s.db == nil {
+	if s.jobRepo == nil {
 		writeJSON(w, http.StatusServiceUnavailable, Response{
 			Success: false,
 			Error:   "Database not available",
@@ -678,109 +754,195 @@ func (s *Server) triggerNotificationHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	job, err := s.enqueueJob(r.Context(), models.JobTypeNotify, reqBody, nil)
+	if err != nil {
+		log.Printf("Error queueing notification for %s: %v", reqBody.UserEmail, err)
+		writeJSON(w, http.StatusInternalServerError, Response{
+			Success: false,
+			Error:   "Failed to queue notification",
+		})
+		return
+	}
+
+	writeJobAccepted(w, job, "", "Notification queued")
+}
+
+// notifyUser sends a user's matched loans to the n8n notification workflow.
+func (s *Server) notifyUser(ctx context.Context, notify NotifyPayload) (map[string]interface{}, error) {
+	userEmail, requestedName := notify.UserEmail, notify.UserName
+
 	// Fetch user's matched loans from database (case-insensitive email)
-	// Note: Removed status filter to get all matches regardless of status
+	// Note: No status filter so notified matches are included; only matches
+	// the LLM never reviewed or that await a reviewer are left out. Offers are
+	// listed by score, cheaper estimated rate first on ties; matches stored
+	// before rates were estimated fall back to the product's maximum rate.
+	// The list is then diversified by provider and product type.
 	query := `
 		SELECT 
+			m.id,
+			u.id,
 			u.user_id,
 			u.email,
 			lp.product_name,
 			lp.provider_name,
+			lp.product_type,
+			lp.currency,
 			lp.interest_rate_min,
 			lp.interest_rate_max,
+			COALESCE(m.estimated_rate, lp.interest_rate_max) AS estimated_rate,
+			COALESCE(m.processing_fee_percent, lp.processing_fee_percent),
 			lp.loan_amount_min,
 			lp.loan_amount_max,
-			m.match_score
+			m.match_score,
+			COALESCE(m.max_eligible_amount, 0),
+			COALESCE(m.emi_min, 0),
+			COALESCE(m.emi_max, 0)
 		FROM matches m
 		JOIN users u ON m.user_id = u.id
 		JOIN loan_products lp ON m.product_id = lp.id
-		WHERE LOWER(u.email) = LOWER($1)
-		ORDER BY m.match_score DESC
-		LIMIT 10
+		WHERE LOWER(u.email) = LOWER($1) AND m.status NOT IN ('unreviewed', 'pending_review')
+		ORDER BY m.match_score DESC, estimated_rate
 	`
 
-	log.Printf("Querying matches for email: %s", reqBody.UserEmail)
-	log.Printf("Query: %s", query)
+	log.Printf("Querying matches for email: %s", userEmail)
 
-	rows, err := s.db.QueryContext(ctx, query, reqBody.UserEmail)
+	rows, err := s.db.QueryContext(ctx, query, userEmail)
 	if err != nil {
-		log.Printf("Failed to fetch matches: %v", err)
-		writeJSON(w, http.StatusInternalServerError, Response{
-			Success: false,
-			Error:   "Failed to fetch user matches",
-		})
-		return
+		return nil, fmt.Errorf("failed to fetch user matches: %w", err)
 	}
 	defer rows.Close()
 
-	log.Printf("🔍 Rows returned, starting to scan...")
-
 	var matchedProducts []map[string]interface{}
+	var matchIDs []int64
+	var entries []ranking.Entry
+	var userDBID int64
 	var userName string
 	var rowCount int
 
 	for rows.Next() {
 		rowCount++
-		var userID, email, productName, providerName string
-		var interestMin, interestMax, amountMin, amountMax, matchScore float64
-
-		if err := rows.Scan(&userID, &email, &productName, &providerName,
-			&interestMin, &interestMax, &amountMin, &amountMax, &matchScore); err != nil {
+		var matchID int64
+		var userID, email, productName, providerName, productType, currency string
+		var rateMin, rateMax, estimatedRate, amountMin, amountMax, matchScore float64
+		var processingFee *float64
+		var maxEligible, emiMin, emiMax float64
+
+		if err := rows.Scan(&matchID, &userDBID, &userID, &email, &productName, &providerName, &productType, &currency,
+			&rateMin, &rateMax, &estimatedRate, &processingFee, &amountMin, &amountMax, &matchScore,
+			&maxEligible, &emiMin, &emiMax); err != nil {
 			log.Printf("Failed to scan match row %d: %v", rowCount, err)
 			continue
 		}
 
-		log.Printf("Scanned row %d: userID=%s, email=%s, product=%s", rowCount, userID, email, productName)
 		if userName == "" {
 			userName = userID // Use user_id as name if not provided
 		}
 
+		matchIDs = append(matchIDs, matchID)
+		entries = append(entries, ranking.Entry{
+			Provider:    providerName,
+			ProductType: models.LoanProductType(productType),
+			RateMin:     rateMin,
+			RateMax:     rateMax,
+			AmountMin:   amountMin,
+			AmountMax:   amountMax,
+		})
 		matchedProducts = append(matchedProducts, map[string]interface{}{
-			"product_name":  productName,
-			"provider":      providerName,
-			"interest_rate": (interestMin + interestMax) / 2, // Average rate
-			"min_amount":    amountMin,
-			"max_amount":    amountMax,
-			"match_score":   int(matchScore),
+			"product_name":           productName,
+			"provider":               providerName,
+			"currency":               models.Currency(currency).OrDefault(),
+			"interest_rate":          estimatedRate,
+			"processing_fee_percent": processingFee,
+			"min_amount":             amountMin,
+			"max_amount":             amountMax,
+			"match_score":            int(matchScore),
+			"max_eligible_amount":    maxEligible,
+			"emi_min":                emiMin,
+			"emi_max":                emiMax,
 		})
 	}
+	rows.Close()
 
 	log.Printf("🔍 Total rows scanned: %d, Products collected: %d", rowCount, len(matchedProducts))
 
 	if len(matchedProducts) == 0 {
-		log.Printf("No matches found in database for: %s", reqBody.UserEmail)
-
 		// Debug: Check if user exists at all
 		var userCount int
 		countQuery := `SELECT COUNT(*) FROM users WHERE LOWER(email) = LOWER($1)`
-		s.db.QueryRowContext(ctx, countQuery, reqBody.UserEmail).Scan(&userCount)
-		log.Printf("Debug: Found %d users with this email", userCount)
+		s.db.QueryRowContext(ctx, countQuery, userEmail).Scan(&userCount)
 
 		// Debug: Check total matches
 		var totalMatches int
 		s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM matches`).Scan(&totalMatches)
-		log.Printf("Debug: Total matches in database: %d", totalMatches)
 
-		writeJSON(w, http.StatusOK, Response{
-			Success: false,
-			Error:   fmt.Sprintf("No matches found for this user. Users with email: %d, Total matches: %d", userCount, totalMatches),
-		})
-		return
+		return nil, jobs.Permanent(fmt.Errorf("no matches found for this user. Users with email: %d, Total matches: %d", userCount, totalMatches))
 	}
 
 	// Use provided user_name or fallback to user_id from database
-	if reqBody.UserName != "" {
-		userName = reqBody.UserName
+	if requestedName != "" {
+		userName = requestedName
 	}
 
-	log.Printf("Found %d matches for %s", len(matchedProducts), reqBody.UserEmail)
+	log.Printf("Found %d matches for %s", len(matchedProducts), userEmail)
+
+	user, err := s.userRepo.GetByID(ctx, userDBID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user: %w", err)
+	}
+	if user == nil {
+		return nil, jobs.Permanent(fmt.Errorf("user %d no longer exists", userDBID))
+	}
+
+	rankedBy := models.OfferRankingScore
+	var costTerms map[string]interface{}
+	order := make([]int, len(matchedProducts))
+	for i := range order {
+		order[i] = i
+	}
+	if notify.RankBy == models.OfferRankingCost {
+		byCost, terms, err := s.costOrder(ctx, user, notify, matchIDs, matchedProducts)
+		if err != nil {
+			return nil, err
+		}
+		if byCost != nil {
+			order, costTerms, rankedBy = byCost, terms, models.OfferRankingCost
+		}
+	}
+
+	// Diversify by provider and product type; near-identical products are
+	// listed by name on the offer they were folded into
+	opts, _ := s.rankingOptions(nil)
+	opts.Limit = maxNotifiedOffers
+	ordered := make([]ranking.Entry, len(order))
+	for i, idx := range order {
+		ordered[i] = entries[idx]
+	}
+	result := ranking.Diversify(ordered, opts)
+	notified := make([]map[string]interface{}, len(result.Picks))
+	for i, pick := range result.Picks {
+		product := matchedProducts[order[pick.Index]]
+		if len(pick.Duplicates) > 0 {
+			similar := make([]interface{}, len(pick.Duplicates))
+			for j, d := range pick.Duplicates {
+				similar[j] = matchedProducts[order[d]]["product_name"]
+			}
+			product["similar_products"] = similar
+		}
+		notified[i] = product
+	}
+	matchedProducts = notified
 
 	// Prepare payload for n8n
 	payload := map[string]interface{}{
-		"user_email":       reqBody.UserEmail,
+		"user_email":       userEmail,
 		"user_name":        userName,
 		"match_id":         fmt.Sprintf("match-%d", time.Now().Unix()),
 		"matched_products": matchedProducts,
+		"ranked_by":        rankedBy,
+		"locale":           userLocale(user),
+	}
+	if costTerms != nil {
+		payload["cost_terms"] = costTerms
 	}
 
 	payloadJSON, _ := json.Marshal(payload)
@@ -791,31 +953,28 @@ func (s *Server) triggerNotificationHandler(w http.ResponseWriter, r *http.Reque
 
 	log.Printf("Calling n8n webhook: %s", webhookURL)
 
-	resp, err := http.Post(webhookURL, "application/json", strings.NewReader(string(payloadJSON)))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, strings.NewReader(string(payloadJSON)))
 	if err != nil {
-		writeJSON(w, http.StatusOK, Response{
-			Success: false,
-			Message: "Notification trigger failed (n8n may be offline)",
-			Data: map[string]interface{}{
-				"n8n_url": webhookURL,
-				"error":   err.Error(),
-			},
-		})
-		return
+		return nil, jobs.Permanent(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("notification trigger failed (n8n may be offline): %w", err)
 	}
 	defer resp.Body.Close()
 
 	respBody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("notification workflow returned %d: %s", resp.StatusCode, respBody)
+	}
 
-	writeJSON(w, http.StatusOK, Response{
-		Success: resp.StatusCode == 200,
-		Message: "Notification workflow triggered",
-		Data: map[string]interface{}{
-			"n8n_status":    resp.StatusCode,
-			"response":      string(respBody),
-			"matched_count": len(matchedProducts),
-		},
-	})
+	return map[string]interface{}{
+		"n8n_status":    resp.StatusCode,
+		"response":      string(respBody),
+		"matched_count": len(matchedProducts),
+	}, nil
 }
 
 func (s *Server) clearDataHandler(w http.ResponseWriter, r *http.Request) {
@@ -915,18 +1074,37 @@ func (s *Server) usersWithMatchesHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Query to get users who have matches with their match details
+	opts, err := s.rankingOptions(r.URL.Query())
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, Response{
+			Success: false,
+			Error:   "Invalid ranking options: " + err.Error(),
+		})
+		return
+	}
+
+	// Users who have matches, with every match best first; each user's
+	// eligible matches are diversified into their top offers
 	query := `
-		SELECT DISTINCT 
+		SELECT
 			u.id,
 			u.user_id,
 			u.email,
-			COUNT(m.id) as match_count
+			m.id,
+			m.status,
+			m.match_score,
+			lp.product_name,
+			lp.provider_name,
+			lp.product_type,
+			lp.interest_rate_min,
+			lp.interest_rate_max,
+			lp.loan_amount_min,
+			lp.loan_amount_max
 		FROM users u
 		INNER JOIN matches m ON u.id = m.user_id
+		INNER JOIN loan_products lp ON m.product_id = lp.id
 		WHERE u.is_active = true
-		GROUP BY u.id, u.user_id, u.email
-		ORDER BY u.user_id
+		ORDER BY u.user_id, u.id, m.match_score DESC, COALESCE(m.estimated_rate, lp.interest_rate_max), m.id
 	`
 
 	rows, err := s.db.QueryContext(ctx, query)
@@ -940,22 +1118,74 @@ func (s *Server) usersWithMatchesHandler(w http.ResponseWriter, r *http.Request)
 	}
 	defer rows.Close()
 
-	var users []map[string]interface{}
+	// userOffers collects one user's rows
+	type userOffers struct {
+		id         int64
+		userID     string
+		email      string
+		matchCount int
+		offers     []map[string]interface{}
+		entries    []ranking.Entry
+	}
+
+	var grouped []*userOffers
 	for rows.Next() {
-		var id int64
-		var userID, email string
-		var matchCount int
+		var id, matchID int64
+		var userID, email, status, productName, providerName, productType string
+		var matchScore, rateMin, rateMax, amountMin, amountMax float64
 
-		if err := rows.Scan(&id, &userID, &email, &matchCount); err != nil {
+		if err := rows.Scan(&id, &userID, &email, &matchID, &status, &matchScore, &productName, &providerName,
+			&productType, &rateMin, &rateMax, &amountMin, &amountMax); err != nil {
 			log.Printf("Failed to scan user row: %v", err)
 			continue
 		}
 
+		if len(grouped) == 0 || grouped[len(grouped)-1].id != id {
+			grouped = append(grouped, &userOffers{id: id, userID: userID, email: email})
+		}
+		u := grouped[len(grouped)-1]
+		u.matchCount++
+
+		if status != string(models.MatchStatusEligible) && status != string(models.MatchStatusNotified) {
+			continue
+		}
+		u.offers = append(u.offers, map[string]interface{}{
+			"match_id":      matchID,
+			"product_name":  productName,
+			"provider_name": providerName,
+			"product_type":  productType,
+			"match_score":   matchScore,
+		})
+		u.entries = append(u.entries, ranking.Entry{
+			Provider:    providerName,
+			ProductType: models.LoanProductType(productType),
+			RateMin:     rateMin,
+			RateMax:     rateMax,
+			AmountMin:   amountMin,
+			AmountMax:   amountMax,
+		})
+	}
+
+	users := make([]map[string]interface{}, 0, len(grouped))
+	for _, u := range grouped {
+		result := ranking.Diversify(u.entries, opts)
+		top := make([]map[string]interface{}, 0, topOffersPerUser)
+		for _, pick := range result.Picks {
+			if len(top) == topOffersPerUser {
+				break
+			}
+			offer := u.offers[pick.Index]
+			offer["similar_count"] = len(pick.Duplicates)
+			top = append(top, offer)
+		}
+
 		users = append(users, map[string]interface{}{
-			"id":          id,
-			"user_id":     userID,
-			"email":       email,
-			"match_count": matchCount,
+			"id":          u.id,
+			"user_id":     u.userID,
+			"email":       u.email,
+			"match_count": u.matchCount,
+			"offer_count": len(result.Picks),
+			"top_offers":  top,
 		})
 	}
 
@@ -0,0 +1,400 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"loan-eligibility-engine/internal/models"
+	"loan-eligibility-engine/internal/services/fx"
+	"loan-eligibility-engine/internal/services/pricing"
+	"loan-eligibility-engine/internal/services/ranking"
+	"loan-eligibility-engine/internal/utils"
+)
+
+// maxNotifiedOffers is the number of offers a notification email lists
+const maxNotifiedOffers = 10
+
+// topOffersPerUser is the number of offers listed per user by
+// /api/users-with-matches
+const topOffersPerUser = 3
+
+// OffersResponse lists a user's matched products priced for one amount and
+// tenure in the user's currency. Excluded counts matched products that do not
+// lend those terms or whose currency has no exchange rate, Collapsed those
+// folded into a near-identical offer and Capped those over the per-provider
+// cap.
+type OffersResponse struct {
+	UserID       int64               `json:"user_id"`
+	Currency     models.Currency     `json:"currency"`
+	Locale       string              `json:"locale"`
+	Amount       float64             `json:"amount"`
+	TenureMonths int                 `json:"tenure_months"`
+	RankBy       models.OfferRanking `json:"rank_by"`
+	Offers       []*models.Offer     `json:"offers"`
+	Excluded     int                 `json:"excluded"`
+	Collapsed    int                 `json:"collapsed"`
+	Capped       int                 `json:"capped"`
+}
+
+// offerSet is a user's priced offers with the products they are for
+type offerSet struct {
+	offers   []*models.Offer
+	products map[int64]*models.LoanProduct
+	excluded int
+}
+
+// userOffersHandler prices a user's matched products for an amount and tenure,
+// cheapest first unless rank_by=score. Amount and tenure default to the terms
+// the user requested; amounts are in the user's currency and are formatted in
+// the user's locale unless ?locale= overrides it. Offers are diversified by
+// provider and product type as configured, unless max_per_provider, diversify
+// or collapse override it.
+func (s *Server) userOffersHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.userRepo == nil || s.matchRepo == nil || s.prodRepo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, Response{
+			Success: false,
+			Error:   "Database not available",
+		})
+		return
+	}
+
+	userID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, Response{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+		return
+	}
+
+	query := r.URL.Query()
+	var amount float64
+	if v := query.Get("amount"); v != "" {
+		amount, err = strconv.ParseFloat(v, 64)
+		if err != nil || amount <= 0 {
+			writeJSON(w, http.StatusBadRequest, Response{
+				Success: false,
+				Error:   "Invalid amount",
+			})
+			return
+		}
+	}
+	var tenure int
+	if v := query.Get("tenure"); v != "" {
+		tenure, err = strconv.Atoi(v)
+		if err != nil || tenure <= 0 {
+			writeJSON(w, http.StatusBadRequest, Response{
+				Success: false,
+				Error:   "Invalid tenure",
+			})
+			return
+		}
+	}
+	locale := ""
+	if v := query.Get("locale"); v != "" {
+		var ok bool
+		if locale, ok = utils.NormalizeLocale(v); !ok {
+			writeJSON(w, http.StatusBadRequest, Response{
+				Success: false,
+				Error:   "Unsupported locale",
+			})
+			return
+		}
+	}
+	rankBy := models.OfferRankingCost
+	if v := query.Get("rank_by"); v != "" {
+		rankBy = models.OfferRanking(v)
+		if !rankBy.IsValid() {
+			writeJSON(w, http.StatusBadRequest, Response{
+				Success: false,
+				Error:   "Invalid rank_by: expected cost or score",
+			})
+			return
+		}
+	}
+	opts, err := s.rankingOptions(query)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, Response{
+			Success: false,
+			Error:   "Invalid ranking options: " + err.Error(),
+		})
+		return
+	}
+
+	user, err := s.userRepo.GetByID(r.Context(), userID)
+	if err != nil {
+		log.Printf("Error fetching user %d: %v", userID, err)
+		writeJSON(w, http.StatusInternalServerError, Response{
+			Success: false,
+			Error:   "Failed to fetch user",
+		})
+		return
+	}
+	if user == nil {
+		writeJSON(w, http.StatusNotFound, Response{
+			Success: false,
+			Error:   "User not found",
+		})
+		return
+	}
+
+	if amount == 0 {
+		amount = user.RequestedAmount
+	}
+	if tenure == 0 {
+		tenure = user.RequestedTenureMonths
+	}
+	if amount <= 0 || tenure <= 0 {
+		writeJSON(w, http.StatusBadRequest, Response{
+			Success: false,
+			Error:   "amount and tenure are required when the user has not requested them",
+		})
+		return
+	}
+
+	if locale == "" {
+		locale = userLocale(user)
+	}
+
+	set, err := s.userOffers(r.Context(), user, amount, tenure)
+	if err != nil {
+		log.Printf("Error pricing offers for user %d: %v", userID, err)
+		writeJSON(w, http.StatusInternalServerError, Response{
+			Success: false,
+			Error:   "Failed to price offers",
+		})
+		return
+	}
+	pricing.Rank(set.offers, rankBy)
+	offers, result := set.diversify(opts)
+	for _, offer := range offers {
+		offer.Formatted = formatCost(offer.BorrowingCost, offer.Currency, locale)
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Data: OffersResponse{
+			UserID:       user.ID,
+			Currency:     user.Currency.OrDefault(),
+			Locale:       locale,
+			Amount:       amount,
+			TenureMonths: tenure,
+			RankBy:       rankBy,
+			Offers:       offers,
+			Excluded:     set.excluded,
+			Collapsed:    result.Collapsed,
+			Capped:       result.Capped,
+		},
+	})
+}
+
+// userOffers prices the user's eligible and notified matches for amount, in
+// the user's currency, over tenure months, unranked. Each product is priced
+// in its own currency and the cost converted back. The set also counts the
+// matches for products that do not lend those terms or whose currency has no
+// exchange rate.
+func (s *Server) userOffers(ctx context.Context, user *models.User, amount float64, tenure int) (*offerSet, error) {
+	matches, err := s.matchRepo.GetByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch matches: %w", err)
+	}
+
+	rates, err := s.fxTable(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	products, err := s.prodRepo.GetAllActive(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch products: %w", err)
+	}
+	byID := make(map[int64]*models.LoanProduct, len(products))
+	for _, product := range products {
+		byID[product.ID] = product
+	}
+
+	offers := []*models.Offer{}
+	excluded := 0
+	for i := range matches {
+		match := &matches[i]
+		if match.Status != models.MatchStatusEligible && match.Status != models.MatchStatusNotified {
+			continue
+		}
+		product, ok := byID[match.ProductID]
+		if !ok {
+			continue
+		}
+		productAmount, ok := rates.Convert(amount, user.Currency, product.Currency)
+		if !ok {
+			excluded++
+			continue
+		}
+		offer, ok := pricing.Price(match, product, productAmount, tenure)
+		if !ok {
+			excluded++
+			continue
+		}
+		offer.BorrowingCost, _ = rates.CostIn(offer.BorrowingCost, product.Currency, user.Currency)
+		offer.Amount = amount
+		offer.Currency = user.Currency.OrDefault()
+		offers = append(offers, offer)
+	}
+
+	return &offerSet{offers: offers, products: byID, excluded: excluded}, nil
+}
+
+// diversify applies the ranking options to the offers, which must already be
+// ranked. Collapsed offers are listed on the one they were folded into.
+func (set *offerSet) diversify(opts ranking.Options) ([]*models.Offer, ranking.Result) {
+	entries := make([]ranking.Entry, len(set.offers))
+	for i, offer := range set.offers {
+		entries[i] = ranking.EntryFor(set.products[offer.ProductID])
+	}
+
+	result := ranking.Diversify(entries, opts)
+	offers := make([]*models.Offer, len(result.Picks))
+	for i, pick := range result.Picks {
+		offer := set.offers[pick.Index]
+		offer.SimilarMatchIDs = nil
+		for _, d := range pick.Duplicates {
+			offer.SimilarMatchIDs = append(offer.SimilarMatchIDs, set.offers[d].MatchID)
+		}
+		offers[i] = offer
+	}
+	return offers, result
+}
+
+// rankingOptions returns the configured offer diversification, overridden by
+// the max_per_provider, diversify and collapse query parameters
+func (s *Server) rankingOptions(query url.Values) (ranking.Options, error) {
+	opts := ranking.Options{
+		MaxPerProvider:     s.config.OfferMaxPerProvider,
+		DiversifyTypes:     s.config.OfferDiversifyTypes,
+		CollapseDuplicates: s.config.OfferCollapseDuplicates,
+	}
+
+	if v := query.Get("max_per_provider"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return opts, errors.New("invalid max_per_provider")
+		}
+		opts.MaxPerProvider = n
+	}
+	if v := query.Get("diversify"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return opts, errors.New("invalid diversify: expected true or false")
+		}
+		opts.DiversifyTypes = b
+	}
+	if v := query.Get("collapse"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return opts, errors.New("invalid collapse: expected true or false")
+		}
+		opts.CollapseDuplicates = b
+	}
+
+	return opts, nil
+}
+
+// costOrder prices the products of a notification, listed in matchIDs order,
+// adds each priced product's cost and APR in the user's currency and returns
+// the product indexes cheapest first. Products that do not lend the terms
+// follow the priced ones in their original order. It returns a nil order
+// when neither the request nor the user gives an amount and tenure, leaving
+// the score order in place.
+func (s *Server) costOrder(ctx context.Context, user *models.User, notify NotifyPayload, matchIDs []int64, products []map[string]interface{}) ([]int, map[string]interface{}, error) {
+	amount, tenure := notify.Amount, notify.TenureMonths
+	if amount <= 0 {
+		amount = user.RequestedAmount
+	}
+	if tenure <= 0 {
+		tenure = user.RequestedTenureMonths
+	}
+	if amount <= 0 || tenure <= 0 {
+		log.Printf("No amount and tenure for user %d, ranking notification by score", user.ID)
+		return nil, nil, nil
+	}
+
+	set, err := s.userOffers(ctx, user, amount, tenure)
+	if err != nil {
+		return nil, nil, err
+	}
+	pricing.Rank(set.offers, models.OfferRankingCost)
+
+	index := make(map[int64]int, len(matchIDs))
+	for i, id := range matchIDs {
+		index[id] = i
+	}
+
+	order := make([]int, 0, len(products))
+	priced := make(map[int64]bool, len(set.offers))
+	for _, offer := range set.offers {
+		i, ok := index[offer.MatchID]
+		if !ok {
+			continue
+		}
+		product := products[i]
+		product["emi"] = offer.EMI
+		product["total_interest"] = offer.TotalInterest
+		product["total_fees"] = offer.TotalFees
+		product["total_cost"] = offer.TotalCost
+		product["apr"] = offer.APR
+		order = append(order, i)
+		priced[offer.MatchID] = true
+	}
+	for i, id := range matchIDs {
+		if !priced[id] {
+			order = append(order, i)
+		}
+	}
+
+	return order, map[string]interface{}{
+		"amount":        amount,
+		"tenure_months": tenure,
+		"currency":      user.Currency.OrDefault(),
+	}, nil
+}
+
+// fxTable loads the configured exchange rates
+func (s *Server) fxTable(ctx context.Context) (*fx.Table, error) {
+	if s.fxRepo == nil {
+		return nil, nil
+	}
+	rates, err := s.fxRepo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch exchange rates: %w", err)
+	}
+	return fx.NewTable(rates), nil
+}
+
+// userLocale is the locale a user's amounts are shown in: the user's own if
+// supported, otherwise the default locale of the user's currency
+func userLocale(user *models.User) string {
+	if locale, ok := utils.NormalizeLocale(user.Locale); ok {
+		return locale
+	}
+	return user.Currency.OrDefault().DefaultLocale()
+}
+
+// formatCost writes out the amounts of a borrowing cost for display
+func formatCost(cost models.BorrowingCost, currency models.Currency, locale string) *models.FormattedCost {
+	return &models.FormattedCost{
+		Amount:        utils.FormatMoney(cost.Amount, currency, locale),
+		EMI:           utils.FormatMoney(cost.EMI, currency, locale),
+		TotalInterest: utils.FormatMoney(cost.TotalInterest, currency, locale),
+		TotalFees:     utils.FormatMoney(cost.TotalFees, currency, locale),
+		TotalCost:     utils.FormatMoney(cost.TotalCost, currency, locale),
+	}
+}
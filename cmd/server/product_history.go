@@ -0,0 +1,157 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"loan-eligibility-engine/internal/models"
+)
+
+// productHistoryHandler returns a product with its versions, newest first:
+// the fields each version changed, where the change came from and how many
+// matches were computed against it
+func (s *Server) productHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.prodRepo == nil || s.versionRepo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, Response{
+			Success: false,
+			Error:   "Database not available",
+		})
+		return
+	}
+
+	productID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, Response{
+			Success: false,
+			Error:   "Invalid product ID",
+		})
+		return
+	}
+
+	product, err := s.prodRepo.GetByID(r.Context(), productID)
+	if err != nil {
+		log.Printf("Error fetching product %d: %v", productID, err)
+		writeJSON(w, http.StatusInternalServerError, Response{
+			Success: false,
+			Error:   "Failed to fetch product",
+		})
+		return
+	}
+	if product == nil {
+		writeJSON(w, http.StatusNotFound, Response{
+			Success: false,
+			Error:   "Product not found",
+		})
+		return
+	}
+
+	versions, err := s.versionRepo.GetByProductID(r.Context(), productID)
+	if err != nil {
+		log.Printf("Error fetching history of product %d: %v", productID, err)
+		writeJSON(w, http.StatusInternalServerError, Response{
+			Success: false,
+			Error:   "Failed to fetch product history",
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Data: models.ProductHistory{
+			Product:  product,
+			Versions: versions,
+		},
+	})
+}
+
+// rateTimelineHandler returns the interest rate timeline of every product by
+// lender, optionally for one lender (?provider=) and a period (?from=, ?to=,
+// as dates or RFC 3339 times). Each product has a point wherever its rate
+// range or active flag changed; the first point of a period gives the rates
+// in effect at its start.
+func (s *Server) rateTimelineHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.versionRepo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, Response{
+			Success: false,
+			Error:   "Database not available",
+		})
+		return
+	}
+
+	query := r.URL.Query()
+	from, err := parseTimeParam(query.Get("from"), false)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, Response{
+			Success: false,
+			Error:   "Invalid from: expected a date (2006-01-02) or an RFC 3339 time",
+		})
+		return
+	}
+	to, err := parseTimeParam(query.Get("to"), true)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, Response{
+			Success: false,
+			Error:   "Invalid to: expected a date (2006-01-02) or an RFC 3339 time",
+		})
+		return
+	}
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		writeJSON(w, http.StatusBadRequest, Response{
+			Success: false,
+			Error:   "to is before from",
+		})
+		return
+	}
+
+	versions, err := s.versionRepo.GetRateHistory(r.Context(), query.Get("provider"), to)
+	if err != nil {
+		log.Printf("Error fetching rate history: %v", err)
+		writeJSON(w, http.StatusInternalServerError, Response{
+			Success: false,
+			Error:   "Failed to fetch rate history",
+		})
+		return
+	}
+
+	timelines := models.BuildRateTimelines(versions, from)
+	if timelines == nil {
+		timelines = []*models.ProviderRateTimeline{}
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Data:    timelines,
+	})
+}
+
+// parseTimeParam parses a date or RFC 3339 time in UTC. A date is the start
+// of that day, or its end if endOfDay is set. Empty is the zero time.
+func parseTimeParam(v string, endOfDay bool) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t.UTC(), nil
+	}
+
+	t, err := time.Parse(time.DateOnly, v)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Microsecond)
+	}
+	return t, nil
+}
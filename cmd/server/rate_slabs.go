@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"loan-eligibility-engine/internal/models"
+)
+
+// productRateSlabsHandler lists (GET) or replaces (PUT) a product's rate slabs
+func (s *Server) productRateSlabsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.slabRepo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, Response{
+			Success: false,
+			Error:   "Database not available",
+		})
+		return
+	}
+
+	productID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, Response{
+			Success: false,
+			Error:   "Invalid product ID",
+		})
+		return
+	}
+
+	if r.Method == http.MethodPut {
+		var req []*models.RateSlabCreate
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, Response{
+				Success: false,
+				Error:   "Invalid request body: expected a JSON array of rate slabs",
+			})
+			return
+		}
+
+		// Reject the whole set if any slab is invalid
+		var problems []string
+		for i, slab := range req {
+			if err := models.ValidateRateSlab(slab); err != nil {
+				problems = append(problems, fmt.Sprintf("slab %d: %v", i+1, err))
+			}
+		}
+		if len(problems) > 0 {
+			writeJSON(w, http.StatusBadRequest, Response{
+				Success: false,
+				Error:   "Invalid rate slabs: " + strings.Join(problems, "; "),
+			})
+			return
+		}
+
+		if err := s.slabRepo.ReplaceForProduct(r.Context(), productID, req); err != nil {
+			log.Printf("Error saving rate slabs for product %d: %v", productID, err)
+			writeJSON(w, http.StatusInternalServerError, Response{
+				Success: false,
+				Error:   "Failed to save rate slabs",
+			})
+			return
+		}
+	}
+
+	stored, err := s.slabRepo.GetByProductID(r.Context(), productID)
+	if err != nil {
+		log.Printf("Error fetching rate slabs for product %d: %v", productID, err)
+		writeJSON(w, http.StatusInternalServerError, Response{
+			Success: false,
+			Error:   "Failed to fetch rate slabs",
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Data: map[string]interface{}{
+			"product_id": productID,
+			"rate_slabs": stored,
+		},
+	})
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"loan-eligibility-engine/internal/models"
+)
+
+// RematchRequest selects the products to re-match. An empty list re-matches
+// every product whose terms changed since its last run.
+type RematchRequest struct {
+	ProductIDs []int64 `json:"product_ids"`
+}
+
+// rematchProductsHandler queues a job that re-runs matching for changed
+// products; the run is available from the job's result
+func (s *Server) rematchProductsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.matcher == nil || s.jobRepo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, Response{
+			Success: false,
+			Error:   "Matcher service not available",
+		})
+		return
+	}
+
+	var req RematchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeJSON(w, http.StatusBadRequest, Response{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+		return
+	}
+
+	job, err := s.enqueueJob(r.Context(), models.JobTypeRematch, req, nil)
+	if err != nil {
+		log.Printf("Error queueing re-match: %v", err)
+		writeJSON(w, http.StatusInternalServerError, Response{
+			Success: false,
+			Error:   "Failed to queue re-match",
+		})
+		return
+	}
+
+	writeJobAccepted(w, job, "", "Re-match queued")
+}
+
+// rematchRunHandler returns a re-match run with the match changes it made
+func (s *Server) rematchRunHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.rematchRepo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, Response{
+			Success: false,
+			Error:   "Database not available",
+		})
+		return
+	}
+
+	runID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, Response{
+			Success: false,
+			Error:   "Invalid run ID",
+		})
+		return
+	}
+
+	run, err := s.rematchRepo.GetRun(r.Context(), runID)
+	if err != nil {
+		log.Printf("Error getting re-match run %d: %v", runID, err)
+		writeJSON(w, http.StatusInternalServerError, Response{
+			Success: false,
+			Error:   "Failed to get re-match run",
+		})
+		return
+	}
+	if run == nil {
+		writeJSON(w, http.StatusNotFound, Response{
+			Success: false,
+			Error:   "Re-match run not found",
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Data:    run,
+	})
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"loan-eligibility-engine/internal/models"
+	"loan-eligibility-engine/internal/services/database"
+)
+
+// defaultReviewPageSize is the number of queued matches returned when no
+// limit is given
+const defaultReviewPageSize = 50
+
+// ReviewRequest is the body of an approve or reject call. Both fields are
+// required so every decision can be traced back to a person and a reason.
+type ReviewRequest struct {
+	Reviewer string `json:"reviewer"`
+	Comment  string `json:"comment"`
+}
+
+// reviewQueueHandler lists matches awaiting human review, oldest first
+func (s *Server) reviewQueueHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.reviewRepo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, Response{
+			Success: false,
+			Error:   "Database not available",
+		})
+		return
+	}
+
+	limit, offset := defaultReviewPageSize, 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeJSON(w, http.StatusBadRequest, Response{
+				Success: false,
+				Error:   "Invalid limit",
+			})
+			return
+		}
+		limit = n
+	}
+	if v := r.URL.Query().Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeJSON(w, http.StatusBadRequest, Response{
+				Success: false,
+				Error:   "Invalid offset",
+			})
+			return
+		}
+		offset = n
+	}
+
+	queue, err := s.reviewRepo.ListPending(r.Context(), limit, offset)
+	if err != nil {
+		log.Printf("Error listing review queue: %v", err)
+		writeJSON(w, http.StatusInternalServerError, Response{
+			Success: false,
+			Error:   "Failed to list review queue",
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Data:    queue,
+	})
+}
+
+// approveReviewHandler approves a match awaiting review
+func (s *Server) approveReviewHandler(w http.ResponseWriter, r *http.Request) {
+	s.decideReview(w, r, models.ReviewDecisionApproved)
+}
+
+// rejectReviewHandler rejects a match awaiting review
+func (s *Server) rejectReviewHandler(w http.ResponseWriter, r *http.Request) {
+	s.decideReview(w, r, models.ReviewDecisionRejected)
+}
+
+// decideReview records a reviewer's decision on the match in the path
+func (s *Server) decideReview(w http.ResponseWriter, r *http.Request, decision models.ReviewDecision) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.reviewRepo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, Response{
+			Success: false,
+			Error:   "Database not available",
+		})
+		return
+	}
+
+	matchID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, Response{
+			Success: false,
+			Error:   "Invalid match ID",
+		})
+		return
+	}
+
+	var req ReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, Response{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+		return
+	}
+	req.Reviewer = strings.TrimSpace(req.Reviewer)
+	req.Comment = strings.TrimSpace(req.Comment)
+	if req.Reviewer == "" || req.Comment == "" {
+		writeJSON(w, http.StatusBadRequest, Response{
+			Success: false,
+			Error:   "reviewer and comment are required",
+		})
+		return
+	}
+
+	review, err := s.reviewRepo.Decide(r.Context(), matchID, decision, req.Reviewer, req.Comment)
+	if errors.Is(err, database.ErrNotPendingReview) {
+		writeJSON(w, http.StatusConflict, Response{
+			Success: false,
+			Error:   "Match is not awaiting review",
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Error recording review for match %d: %v", matchID, err)
+		writeJSON(w, http.StatusInternalServerError, Response{
+			Success: false,
+			Error:   "Failed to record review",
+		})
+		return
+	}
+	if review == nil {
+		writeJSON(w, http.StatusNotFound, Response{
+			Success: false,
+			Error:   "Match not found",
+		})
+		return
+	}
+
+	log.Printf("Match %d %s by %s", matchID, decision, req.Reviewer)
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Message: "Review recorded",
+		Data:    review,
+	})
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"loan-eligibility-engine/internal/models"
+	"loan-eligibility-engine/internal/services/rules"
+)
+
+// productRulesHandler lists (GET) or replaces (PUT) a product's eligibility rules
+func (s *Server) productRulesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.ruleRepo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, Response{
+			Success: false,
+			Error:   "Database not available",
+		})
+		return
+	}
+
+	productID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, Response{
+			Success: false,
+			Error:   "Invalid product ID",
+		})
+		return
+	}
+
+	if r.Method == http.MethodPut {
+		var req []*models.EligibilityRuleCreate
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, Response{
+				Success: false,
+				Error:   "Invalid request body: expected a JSON array of rules",
+			})
+			return
+		}
+
+		// Reject the whole set if any rule is invalid
+		var problems []string
+		for _, rule := range req {
+			if strings.TrimSpace(rule.Name) == "" {
+				problems = append(problems, "rule name is required")
+				continue
+			}
+			if err := rules.Validate(rule.Name, rule.Expression); err != nil {
+				problems = append(problems, err.Error())
+			}
+		}
+		if len(problems) > 0 {
+			writeJSON(w, http.StatusBadRequest, Response{
+				Success: false,
+				Error:   "Invalid rules: " + strings.Join(problems, "; "),
+			})
+			return
+		}
+
+		if err := s.ruleRepo.ReplaceForProduct(r.Context(), productID, req); err != nil {
+			log.Printf("Error saving rules for product %d: %v", productID, err)
+			writeJSON(w, http.StatusInternalServerError, Response{
+				Success: false,
+				Error:   "Failed to save rules",
+			})
+			return
+		}
+	}
+
+	stored, err := s.ruleRepo.GetByProductID(r.Context(), productID)
+	if err != nil {
+		log.Printf("Error fetching rules for product %d: %v", productID, err)
+		writeJSON(w, http.StatusInternalServerError, Response{
+			Success: false,
+			Error:   "Failed to fetch rules",
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Data: map[string]interface{}{
+			"product_id": productID,
+			"rules":      stored,
+			"variables":  rules.Variables,
+		},
+	})
+}
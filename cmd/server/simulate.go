@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"loan-eligibility-engine/internal/models"
+)
+
+// SimulationRequest carries a proposed change to a product's terms, as a
+// partial LoanProductCreate
+type SimulationRequest struct {
+	Changes    json.RawMessage `json:"changes"`
+	SampleSize int             `json:"sample_size"`
+}
+
+// productSimulationHandler projects the effect of a policy change on existing
+// users without saving anything
+func (s *Server) productSimulationHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.matcher == nil {
+		writeJSON(w, http.StatusServiceUnavailable, Response{
+			Success: false,
+			Error:   "Matcher service not available",
+		})
+		return
+	}
+
+	productID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, Response{
+			Success: false,
+			Error:   "Invalid product ID",
+		})
+		return
+	}
+
+	var req SimulationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Changes) == 0 {
+		writeJSON(w, http.StatusBadRequest, Response{
+			Success: false,
+			Error:   "Request body must include the proposed changes",
+		})
+		return
+	}
+
+	var terms models.LoanProductCreate
+	if err := json.Unmarshal(req.Changes, &terms); err != nil {
+		writeJSON(w, http.StatusBadRequest, Response{
+			Success: false,
+			Error:   "Invalid product changes: " + err.Error(),
+		})
+		return
+	}
+
+	sim, err := s.matcher.SimulateProductChange(r.Context(), productID, req.Changes, req.SampleSize)
+	if err != nil {
+		log.Printf("Error simulating changes to product %d: %v", productID, err)
+		writeJSON(w, http.StatusInternalServerError, Response{
+			Success: false,
+			Error:   "Failed to simulate product change",
+		})
+		return
+	}
+	if sim == nil {
+		writeJSON(w, http.StatusNotFound, Response{
+			Success: false,
+			Error:   "Product not found",
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Success: true,
+		Data:    sim,
+	})
+}
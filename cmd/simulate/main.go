@@ -0,0 +1,65 @@
+// Product policy simulator: projects how changing a loan product's terms would
+// affect existing users, without saving anything.
+//
+//	go run ./cmd/simulate -product 3 -changes '{"min_credit_score": 720}'
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"loan-eligibility-engine/internal/config"
+	"loan-eligibility-engine/internal/services/database"
+	"loan-eligibility-engine/internal/services/matcher"
+	"loan-eligibility-engine/internal/utils"
+)
+
+func main() {
+	productID := flag.Int64("product", 0, "ID of the product to change")
+	changes := flag.String("changes", "", "proposed changes as a partial loan product JSON object")
+	samples := flag.Int("samples", matcher.DefaultSimulationSampleSize, "example users to list per outcome")
+	flag.Parse()
+
+	if *productID <= 0 || *changes == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := utils.InitLogger("warn"); err != nil {
+		log.Fatalf("Failed to initialize logger: %v", err)
+	}
+	defer utils.Sync()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	db, err := database.New(cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	matcherSvc, err := matcher.NewMatcherService(db)
+	if err != nil {
+		log.Fatalf("Failed to initialize matcher service: %v", err)
+	}
+
+	sim, err := matcherSvc.SimulateProductChange(context.Background(), *productID, json.RawMessage(*changes), *samples)
+	if err != nil {
+		log.Fatalf("Simulation failed: %v", err)
+	}
+	if sim == nil {
+		log.Fatalf("Product %d not found", *productID)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(sim); err != nil {
+		log.Fatalf("Failed to write result: %v", err)
+	}
+}
@@ -184,7 +184,7 @@ async function uploadFile() {
             body: formData
         });
 
-        updateProgress(70, 'Processing file...');
+        updateProgress(40, 'Processing file...');
 
         if (!response.ok) {
             throw new Error('Upload failed');
@@ -196,15 +196,31 @@ async function uploadFile() {
             throw new Error(result.error || 'Upload failed');
         }
 
+        // The server queues the CSV; follow the ingest job, then the match job
+        let stats = result.data;
+        if (result.data.job_id) {
+            const ingest = await waitForJob(CONFIG.apiBaseUrl, result.data.job_id, job => {
+                updateProgress(40 + Math.round(jobFraction(job) * 30), 'Saving users...');
+            });
+            stats = ingest.result;
+
+            if (stats.match_job_id) {
+                const match = await waitForJob(CONFIG.apiBaseUrl, stats.match_job_id, job => {
+                    updateProgress(70 + Math.round(jobFraction(job) * 29), 'Matching users...');
+                });
+                stats.matches_found = match.result?.FinalMatches || 0;
+            }
+        }
+
         updateProgress(100, 'Complete!');
 
         // Show success
         setTimeout(() => {
             showSuccess({
-                total: result.data.total_rows || 0,
-                valid: result.data.valid_users || 0,
-                errors: result.data.errors || 0,
-                matches: result.data.matches_found || 0
+                total: stats.total_rows || 0,
+                valid: stats.valid_users || 0,
+                errors: stats.errors || 0,
+                matches: stats.matches_found || 0
             });
         }, 500);
 
@@ -43,6 +43,7 @@ const elements = {
     sendTestEmail: document.getElementById('sendTestEmail'),
     testEmail: document.getElementById('testEmail'),
     testName: document.getElementById('testName'),
+    testRankBy: document.getElementById('testRankBy'),
     
     // Toast
     toastContainer: document.getElementById('toastContainer'),
@@ -172,11 +173,11 @@ function renderProducts(products) {
                 </div>
                 <div class="product-detail">
                     <span class="product-detail-label">Loan Amount</span>
-                    <span class="product-detail-value">₹${formatCurrency(product.loan_amount_min || product.loanAmountMin)} - ₹${formatCurrency(product.loan_amount_max || product.loanAmountMax)}</span>
+                    <span class="product-detail-value">${formatCurrency(product.loan_amount_min || product.loanAmountMin, product.currency)} - ${formatCurrency(product.loan_amount_max || product.loanAmountMax, product.currency)}</span>
                 </div>
                 <div class="product-detail">
                     <span class="product-detail-label">Min Income</span>
-                    <span class="product-detail-value">₹${formatCurrency(product.min_monthly_income || product.minMonthlyIncome)}/mo</span>
+                    <span class="product-detail-value">${formatCurrency(product.min_monthly_income || product.minMonthlyIncome, product.currency)}/mo</span>
                 </div>
                 <div class="product-detail">
                     <span class="product-detail-label">Min Credit Score</span>
@@ -235,7 +236,7 @@ function updateWorkflowStatus(status) {
 }
 
 /**
- * Trigger the loan crawler workflow
+ * Queue a crawl of every active crawler source and wait for it to finish
  */
 async function triggerCrawler() {
     elements.triggerCrawler.disabled = true;
@@ -250,16 +251,30 @@ async function triggerCrawler() {
             body: JSON.stringify({ trigger: 'manual' })
         });
         
-        if (response.ok) {
-            showToast('Crawler workflow triggered successfully!', 'success');
-            elements.crawlerStatus.textContent = 'Completed';
-            elements.crawlerStatus.className = 'workflow-status active';
-        } else {
-            throw new Error('Workflow trigger failed');
+        const data = await response.json();
+        if (!response.ok || !data.success) {
+            throw new Error(data.error || 'Crawler trigger failed');
         }
+
+        elements.crawlerStatus.textContent = 'Queued...';
+        const job = await waitForJob(CONFIG.apiBaseUrl, data.data.job_id, () => {
+            elements.crawlerStatus.textContent = 'Crawling...';
+        });
+        const run = job.result?.run || {};
+
+        const added = run.products_added || 0;
+        const updated = run.products_updated || 0;
+        const failed = run.products_failed || 0;
+        const message = `Crawl ${run.status || 'completed'}: ${added} added, ${updated} updated, ${failed} failed.`;
+        showToast(message, run.status === 'completed' ? 'success' : 'warning');
+        elements.crawlerStatus.textContent = run.status === 'failed' ? 'Error' : 'Completed';
+        elements.crawlerStatus.className = run.status === 'failed' ? 'workflow-status error' : 'workflow-status active';
+
+        // Reload data to show the crawled products
+        setTimeout(() => loadDashboardData(), 1000);
     } catch (error) {
         console.error('Failed to trigger crawler:', error);
-        showToast('Crawler not available. Check if n8n is running.', 'error');
+        showToast(`Crawler failed: ${error.message}`, 'error');
         elements.crawlerStatus.textContent = 'Error';
         elements.crawlerStatus.className = 'workflow-status error';
     } finally {
@@ -307,6 +322,13 @@ async function triggerMatcher() {
                     console.error('Failed to parse n8n response:', e);
                     matchCount = data.data.response.match(/\d+/)?.[0] || 0;
                 }
+            } else if (data.data?.job_id) {
+                // n8n is offline and the server queued a local match job
+                elements.matcherStatus.textContent = 'Queued...';
+                const job = await waitForJob(CONFIG.apiBaseUrl, data.data.job_id, job => {
+                    elements.matcherStatus.textContent = `Running ${Math.round(jobFraction(job) * 100)}%`;
+                });
+                matchCount = job.result?.FinalMatches || 0;
             } else {
                 matchCount = data.total_matches || data.matches?.length || 0;
             }
@@ -370,7 +392,8 @@ async function sendTestNotification() {
     try {
         const payload = {
             user_email: email,
-            user_name: name
+            user_name: name,
+            rank_by: elements.testRankBy.value
         };
         
         console.log('Sending notification payload:', payload);
@@ -397,7 +420,9 @@ async function sendTestNotification() {
         console.log('Notification response:', data);
         
         if (response.ok && data.success) {
-            const matchCount = data.data?.matched_count || 0;
+            // The notification is sent by a background job
+            const job = await waitForJob(CONFIG.apiBaseUrl, data.data.job_id);
+            const matchCount = job.result?.matched_count || 0;
             showToast(`Test email sent to ${email} with ${matchCount} matched loans!`, 'success');
             elements.notifierStatus.textContent = 'Sent';
             elements.notifierStatus.className = 'workflow-status active';
@@ -483,9 +508,12 @@ function showToast(message, type = 'info') {
 /**
  * Format currency in Indian format
  */
-function formatCurrency(amount) {
-    if (!amount) return '0';
-    return new Intl.NumberFormat('en-IN').format(amount);
+function formatCurrency(amount, currency) {
+    if (!currency) {
+        if (!amount) return '0';
+        return new Intl.NumberFormat('en-IN').format(amount);
+    }
+    return new Intl.NumberFormat(undefined, { style: 'currency', currency, maximumFractionDigits: 0 }).format(amount || 0);
 }
 
 /**
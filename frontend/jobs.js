@@ -0,0 +1,43 @@
+/**
+ * Loan Eligibility Engine - Background Job Polling
+ * Long-running work (CSV ingest, matching, notifications) is queued on the
+ * server; these helpers poll GET /api/jobs/{id} until the job finishes.
+ */
+
+const JOB_POLL_INTERVAL_MS = 1000;
+
+/**
+ * Poll a job until it finishes.
+ * Calls onProgress(job) after every poll and resolves with the finished job;
+ * rejects if the job failed or was cancelled.
+ */
+async function waitForJob(apiBaseUrl, jobId, onProgress) {
+    for (;;) {
+        const response = await fetch(`${apiBaseUrl}/api/jobs/${jobId}`);
+        const result = await response.json();
+        if (!response.ok || !result.success) {
+            throw new Error(result.error || `Failed to get job ${jobId}`);
+        }
+
+        const job = result.data;
+        if (onProgress) {
+            onProgress(job);
+        }
+
+        if (job.status === 'succeeded') {
+            return job;
+        }
+        if (job.status === 'failed' || job.status === 'cancelled') {
+            throw new Error(job.last_error || `Job ${job.status}`);
+        }
+
+        await new Promise(resolve => setTimeout(resolve, JOB_POLL_INTERVAL_MS));
+    }
+}
+
+/**
+ * Fraction of a job's work that is done, between 0 and 1
+ */
+function jobFraction(job) {
+    return job.progress_total > 0 ? Math.min(job.progress_done / job.progress_total, 1) : 0;
+}
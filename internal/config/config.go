@@ -21,6 +21,10 @@ type Config struct {
 	DBUser     string
 	DBPassword string
 
+	// RequireCurrentSchema makes the server and Lambdas refuse to start
+	// while schema migrations are pending
+	RequireCurrentSchema bool
+
 	// n8n
 	N8NWebhookURL             string
 	N8NMatchingWebhookURL     string
@@ -33,6 +37,46 @@ type Config struct {
 	GeminiAPIKey string
 	OpenAIAPIKey string
 
+	// LLM stage
+	LLMProvider          string
+	LLMModel             string
+	LLMBaseURL           string
+	LLMConcurrency       int
+	LLMRequestsPerMinute int
+	LLMMaxRetries        int
+	LLMTimeoutSeconds    int
+	LLMCacheTTLHours     int
+	LLMTopKPerUser       int
+	LLMBatchBudget       int
+	LLMReviewConfidence  float64
+
+	// Affordability
+	MaxFOIR float64
+
+	// Matching pipeline
+	MatchChunkSize int
+	MatchWorkers   int
+
+	// Scoring
+	ScoringModelPath string
+
+	// Offer ranking
+	OfferMaxPerProvider     int
+	OfferDiversifyTypes     bool
+	OfferCollapseDuplicates bool
+
+	// Loan product crawler
+	CrawlerUserAgent         string
+	CrawlerTimeoutSeconds    int
+	CrawlerRequestsPerMinute int
+	CrawlerMaxPageKB         int
+
+	// Background jobs
+	JobWorkers             int
+	JobMaxAttempts         int
+	JobLeaseSeconds        int
+	JobPollIntervalSeconds int
+
 	// Application
 	Stage    string
 	LogLevel string
@@ -55,6 +99,8 @@ func Load() (*Config, error) {
 		DBUser:     getEnv("DB_USER", getEnv("LOAN_DB_USER", "postgres")),
 		DBPassword: getEnv("DB_PASSWORD", getEnv("LOAN_DB_PASSWORD", "")),
 
+		RequireCurrentSchema: getEnvBool("REQUIRE_CURRENT_SCHEMA", false),
+
 		// n8n
 		N8NWebhookURL:             getEnv("N8N_WEBHOOK_URL", ""),
 		N8NMatchingWebhookURL:     getEnv("N8N_MATCHING_WEBHOOK_URL", ""),
@@ -67,6 +113,46 @@ func Load() (*Config, error) {
 		GeminiAPIKey: getEnv("GEMINI_API_KEY", ""),
 		OpenAIAPIKey: getEnv("OPENAI_API_KEY", ""),
 
+		// LLM stage
+		LLMProvider:          getEnv("LLM_PROVIDER", ""),
+		LLMModel:             getEnv("LLM_MODEL", ""),
+		LLMBaseURL:           getEnv("LLM_BASE_URL", ""),
+		LLMConcurrency:       getEnvInt("LLM_CONCURRENCY", 4),
+		LLMRequestsPerMinute: getEnvInt("LLM_REQUESTS_PER_MINUTE", 60),
+		LLMMaxRetries:        getEnvInt("LLM_MAX_RETRIES", 3),
+		LLMTimeoutSeconds:    getEnvInt("LLM_TIMEOUT_SECONDS", 30),
+		LLMCacheTTLHours:     getEnvInt("LLM_CACHE_TTL_HOURS", 168),
+		LLMTopKPerUser:       getEnvInt("LLM_TOP_K_PER_USER", 3),
+		LLMBatchBudget:       getEnvInt("LLM_BATCH_BUDGET", 100),
+		LLMReviewConfidence:  getEnvFloat("LLM_REVIEW_CONFIDENCE", 0.5),
+
+		// Affordability
+		MaxFOIR: getEnvFloat("MAX_FOIR", 0.5),
+
+		// Matching pipeline
+		MatchChunkSize: getEnvInt("MATCH_CHUNK_SIZE", 1000),
+		MatchWorkers:   getEnvInt("MATCH_WORKERS", 4),
+
+		// Scoring
+		ScoringModelPath: getEnv("SCORING_MODEL_PATH", "config/scoring_model.json"),
+
+		// Offer ranking
+		OfferMaxPerProvider:     getEnvInt("OFFER_MAX_PER_PROVIDER", 2),
+		OfferDiversifyTypes:     getEnvBool("OFFER_DIVERSIFY_TYPES", true),
+		OfferCollapseDuplicates: getEnvBool("OFFER_COLLAPSE_DUPLICATES", true),
+
+		// Loan product crawler
+		CrawlerUserAgent:         getEnv("CRAWLER_USER_AGENT", "LoanEligibilityBot/1.0"),
+		CrawlerTimeoutSeconds:    getEnvInt("CRAWLER_TIMEOUT_SECONDS", 30),
+		CrawlerRequestsPerMinute: getEnvInt("CRAWLER_REQUESTS_PER_MINUTE", 6),
+		CrawlerMaxPageKB:         getEnvInt("CRAWLER_MAX_PAGE_KB", 5120),
+
+		// Background jobs
+		JobWorkers:             getEnvInt("JOB_WORKERS", 2),
+		JobMaxAttempts:         getEnvInt("JOB_MAX_ATTEMPTS", 3),
+		JobLeaseSeconds:        getEnvInt("JOB_LEASE_SECONDS", 60),
+		JobPollIntervalSeconds: getEnvInt("JOB_POLL_INTERVAL_SECONDS", 2),
+
 		// Application
 		Stage:    getEnv("STAGE", "dev"),
 		LogLevel: getEnv("LOG_LEVEL", "info"),
@@ -101,3 +187,23 @@ func getEnvInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+// getEnvBool retrieves an environment variable as bool or returns a default value.
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolVal, err := strconv.ParseBool(value); err == nil {
+			return boolVal
+		}
+	}
+	return defaultValue
+}
+
+// getEnvFloat retrieves an environment variable as float64 or returns a default value.
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatVal, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatVal
+		}
+	}
+	return defaultValue
+}
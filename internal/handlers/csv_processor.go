@@ -4,14 +4,13 @@ package handlers
 import (
 	"bytes"
 	"context"
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"os"
+	"path"
 	"time"
 
 	"github.com/aws/aws-lambda-go/events"
@@ -19,6 +18,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 
 	appConfig "loan-eligibility-engine/internal/config"
+	"loan-eligibility-engine/internal/models"
 	"loan-eligibility-engine/internal/services/database"
 	"loan-eligibility-engine/internal/utils"
 )
@@ -28,6 +28,7 @@ type CSVProcessorHandler struct {
 	s3Client   *s3.Client
 	db         *database.DB
 	userRepo   *database.UserRepository
+	batchRepo  *database.BatchRepository
 	webhookURL string
 }
 
@@ -47,11 +48,16 @@ func NewCSVProcessorHandler() (*CSVProcessorHandler, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
+	if err := requireCurrentSchema(cfg, db); err != nil {
+		db.Close()
+		return nil, err
+	}
 
 	return &CSVProcessorHandler{
 		s3Client:   s3.NewFromConfig(awsCfg),
 		db:         db,
 		userRepo:   database.NewUserRepository(db),
+		batchRepo:  database.NewBatchRepository(db),
 		webhookURL: cfg.N8NWebhookURL,
 	}, nil
 }
@@ -65,7 +71,8 @@ type CSVProcessResult struct {
 	Errors   []string `json:"errors,omitempty"`
 }
 
-// Handle processes S3 events for uploaded CSV files.
+// Handle processes S3 events for uploaded CSV files, recording each stage on
+// the file's upload batch.
 func (h *CSVProcessorHandler) Handle(ctx context.Context, s3Event events.S3Event) (CSVProcessResult, error) {
 	logger := utils.GetLogger()
 
@@ -80,9 +87,36 @@ func (h *CSVProcessorHandler) Handle(ctx context.Context, s3Event events.S3Event
 		return CSVProcessResult{}, fmt.Errorf("failed to decode S3 key: %w", err)
 	}
 
+	batchID := models.NewBatchID(time.Now())
+
 	logger.Info("Processing CSV file",
 		utils.String("bucket", bucket),
-		utils.String("key", key))
+		utils.String("key", key),
+		utils.String("batchID", batchID))
+
+	err = h.batchRepo.Create(ctx, &models.UploadBatchCreate{
+		BatchID:  batchID,
+		Source:   models.BatchSourceS3,
+		S3Key:    key,
+		FileName: path.Base(key),
+	})
+	if err != nil {
+		return CSVProcessResult{}, err
+	}
+
+	result, err := h.process(ctx, bucket, key, batchID)
+	if err != nil {
+		if failErr := h.batchRepo.Fail(context.WithoutCancel(ctx), batchID, err.Error()); failErr != nil {
+			logger.Warn("Failed to mark batch failed", utils.Error(failErr))
+		}
+		return CSVProcessResult{}, err
+	}
+	return result, nil
+}
+
+// process downloads, parses and saves a CSV as a batch and triggers matching
+func (h *CSVProcessorHandler) process(ctx context.Context, bucket, key, batchID string) (CSVProcessResult, error) {
+	logger := utils.GetLogger()
 
 	// Download CSV from S3
 	csvContent, err := h.downloadCSV(ctx, bucket, key)
@@ -91,22 +125,29 @@ func (h *CSVProcessorHandler) Handle(ctx context.Context, s3Event events.S3Event
 		return CSVProcessResult{}, fmt.Errorf("failed to download CSV: %w", err)
 	}
 
-	// Generate batch ID
-	batchID := generateBatchID(key)
-
 	// Parse CSV
+	if err := h.batchRepo.SetStatus(ctx, batchID, models.BatchStatusParsing); err != nil {
+		return CSVProcessResult{}, err
+	}
 	parser := utils.NewCSVParser()
 	users, parseErrors := parser.ParseUsers(csvContent, batchID)
 
+	parseMsgs := make([]string, len(parseErrors))
+	for i, e := range parseErrors {
+		parseMsgs[i] = e.Error()
+	}
+	if err := h.batchRepo.RecordParsed(ctx, batchID, len(users)+len(parseErrors), len(users), parseMsgs); err != nil {
+		return CSVProcessResult{}, err
+	}
+
 	if len(users) == 0 {
-		errMsgs := make([]string, len(parseErrors))
-		for i, e := range parseErrors {
-			errMsgs[i] = e.Error()
+		if err := h.batchRepo.Fail(ctx, batchID, "No valid users found in CSV"); err != nil {
+			return CSVProcessResult{}, err
 		}
 		return CSVProcessResult{
 			Message: "No valid users found in CSV",
 			BatchID: batchID,
-			Errors:  errMsgs,
+			Errors:  parseMsgs,
 		}, nil
 	}
 
@@ -127,10 +168,22 @@ func (h *CSVProcessorHandler) Handle(ctx context.Context, s3Event events.S3Event
 		utils.Int("inserted", result.InsertedCount),
 		utils.Int("failed", result.FailedCount))
 
-	// Trigger n8n webhook if users were inserted
-	if result.InsertedCount > 0 && h.webhookURL != "" {
+	// Trigger n8n webhook if users were inserted. The batch moves to matching
+	// first, since the matching workflow completes it.
+	matching := result.InsertedCount > 0 && h.webhookURL != ""
+	next := models.BatchStatusCompleted
+	if matching {
+		next = models.BatchStatusMatching
+	}
+	if err := h.batchRepo.FinishInsert(ctx, batchID, result.InsertedCount, result.Errors, next); err != nil {
+		return CSVProcessResult{}, err
+	}
+	if matching {
 		if err := h.triggerWebhook(ctx, batchID, result.InsertedCount); err != nil {
 			logger.Warn("Failed to trigger n8n webhook", utils.Error(err))
+			if err := h.batchRepo.Fail(ctx, batchID, "failed to trigger matching: "+err.Error()); err != nil {
+				logger.Warn("Failed to mark batch failed", utils.Error(err))
+			}
 		}
 	}
 
@@ -140,11 +193,7 @@ func (h *CSVProcessorHandler) Handle(ctx context.Context, s3Event events.S3Event
 	}
 
 	// Combine parse errors with insert errors
-	allErrors := make([]string, 0)
-	for _, e := range parseErrors {
-		allErrors = append(allErrors, e.Error())
-	}
-	allErrors = append(allErrors, result.Errors...)
+	allErrors := append(parseMsgs, result.Errors...)
 
 	// Limit errors in response
 	if len(allErrors) > 10 {
@@ -184,13 +233,6 @@ func (h *CSVProcessorHandler) downloadCSV(ctx context.Context, bucket, key strin
 	return content, nil
 }
 
-// generateBatchID generates a unique batch ID for this upload.
-func generateBatchID(key string) string {
-	timestamp := time.Now().UTC().Format(time.RFC3339)
-	hash := sha256.Sum256([]byte(key + timestamp))
-	return hex.EncodeToString(hash[:])[:16]
-}
-
 // triggerWebhook triggers the n8n matching workflow.
 func (h *CSVProcessorHandler) triggerWebhook(ctx context.Context, batchID string, userCount int) error {
 	payload := map[string]interface{}{
@@ -276,6 +318,7 @@ func HandleWithConfig(ctx context.Context, s3Event events.S3Event, dbURL, webhoo
 		s3Client:   s3.NewFromConfig(awsCfg),
 		db:         db,
 		userRepo:   database.NewUserRepository(db),
+		batchRepo:  database.NewBatchRepository(db),
 		webhookURL: webhookURL,
 	}
 
@@ -4,6 +4,7 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"os"
 	"time"
@@ -12,6 +13,7 @@ import (
 
 	appConfig "loan-eligibility-engine/internal/config"
 	"loan-eligibility-engine/internal/services/database"
+	"loan-eligibility-engine/internal/services/migrations"
 )
 
 // HealthHandler handles health check requests.
@@ -30,10 +32,29 @@ func NewHealthHandler() (*HealthHandler, error) {
 	if err != nil {
 		return &HealthHandler{}, nil // Return handler without DB
 	}
+	if err := requireCurrentSchema(cfg, db); err != nil {
+		db.Close()
+		return nil, err
+	}
 
 	return &HealthHandler{db: db}, nil
 }
 
+// requireCurrentSchema refuses to start a Lambda against a schema with
+// pending migrations when REQUIRE_CURRENT_SCHEMA is set.
+func requireCurrentSchema(cfg *appConfig.Config, db *database.DB) error {
+	if !cfg.RequireCurrentSchema {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := migrations.Check(ctx, db); err != nil {
+		return fmt.Errorf("database schema check failed: %w", err)
+	}
+	return nil
+}
+
 // HealthResponse is the response structure for health checks.
 type HealthResponse struct {
 	Status    string `json:"status"`
@@ -0,0 +1,10 @@
+// Package models defines the data structures for the loan eligibility engine.
+package models
+
+// Affordability describes what a user can afford on a loan product.
+type Affordability struct {
+	EMIMin            float64 `json:"emi_min" db:"emi_min"`
+	EMIMax            float64 `json:"emi_max" db:"emi_max"`
+	FOIR              float64 `json:"foir" db:"foir"`
+	MaxEligibleAmount float64 `json:"max_eligible_amount" db:"max_eligible_amount"`
+}
@@ -0,0 +1,95 @@
+// Package models defines the data structures for the loan eligibility engine.
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// scoreBuckets is the number of 10-point buckets in a score distribution.
+const scoreBuckets = 10
+
+// BacktestChangeType describes how a replayed match differs from the stored one.
+type BacktestChangeType string
+
+const (
+	BacktestChangeAdded   BacktestChangeType = "added"
+	BacktestChangeDropped BacktestChangeType = "dropped"
+	BacktestChangeRank    BacktestChangeType = "rank_changed"
+)
+
+// BacktestChange is one match the replayed configuration adds, drops or ranks
+// differently among the user's matches. Ranks start at 1 for the user's best
+// scoring product.
+type BacktestChange struct {
+	UserID     int64              `json:"user_id"`
+	ProductID  int64              `json:"product_id"`
+	ChangeType BacktestChangeType `json:"change_type"`
+	OldScore   *float64           `json:"old_score,omitempty"`
+	NewScore   *float64           `json:"new_score,omitempty"`
+	OldRank    int                `json:"old_rank,omitempty"`
+	NewRank    int                `json:"new_rank,omitempty"`
+}
+
+// ScoreDistribution summarizes a set of match scores. Buckets counts scores
+// in 10-point bands, from 0-10 up to 90-100.
+type ScoreDistribution struct {
+	Count   int               `json:"count"`
+	Mean    float64           `json:"mean"`
+	Median  float64           `json:"median"`
+	Buckets [scoreBuckets]int `json:"buckets"`
+}
+
+// NewScoreDistribution summarizes the given scores.
+func NewScoreDistribution(scores []float64) ScoreDistribution {
+	d := ScoreDistribution{Count: len(scores)}
+	if len(scores) == 0 {
+		return d
+	}
+
+	sorted := append([]float64(nil), scores...)
+	sort.Float64s(sorted)
+
+	var sum float64
+	for _, s := range sorted {
+		sum += s
+		bucket := int(s / 10)
+		if bucket >= scoreBuckets {
+			bucket = scoreBuckets - 1
+		}
+		if bucket < 0 {
+			bucket = 0
+		}
+		d.Buckets[bucket]++
+	}
+	d.Mean = sum / float64(len(sorted))
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		d.Median = (sorted[mid-1] + sorted[mid]) / 2
+	} else {
+		d.Median = sorted[mid]
+	}
+
+	return d
+}
+
+// BacktestReport compares the matches produced by replaying historical users
+// under an alternate scoring model or rules with the matches stored for them.
+type BacktestReport struct {
+	BatchID         string            `json:"batch_id,omitempty"`
+	From            *time.Time        `json:"from,omitempty"`
+	To              *time.Time        `json:"to,omitempty"`
+	ScoringVersion  string            `json:"scoring_version"`
+	UsersEvaluated  int               `json:"users_evaluated"`
+	StoredMatches   int               `json:"stored_matches"`
+	ReplayedMatches int               `json:"replayed_matches"`
+	Unreviewed      int               `json:"unreviewed"`
+	Added           int               `json:"added"`
+	Dropped         int               `json:"dropped"`
+	RankChanged     int               `json:"rank_changed"`
+	AvgScoreDelta   float64           `json:"avg_score_delta"`
+	Before          ScoreDistribution `json:"before"`
+	After           ScoreDistribution `json:"after"`
+	Changes         []BacktestChange  `json:"changes"`
+}
@@ -0,0 +1,115 @@
+// Package models defines the data structures for the loan eligibility engine.
+package models
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"math"
+	"time"
+)
+
+// BatchStatus is the lifecycle stage of an upload batch.
+type BatchStatus string
+
+const (
+	BatchStatusReceived  BatchStatus = "received"
+	BatchStatusParsing   BatchStatus = "parsing"
+	BatchStatusInserting BatchStatus = "inserting"
+	BatchStatusMatching  BatchStatus = "matching"
+	BatchStatusCompleted BatchStatus = "completed"
+	BatchStatusFailed    BatchStatus = "failed"
+)
+
+// IsFinished reports whether the batch has completed or failed
+func (s BatchStatus) IsFinished() bool {
+	return s == BatchStatusCompleted || s == BatchStatusFailed
+}
+
+// BatchSource identifies the ingestion path of an upload batch.
+type BatchSource string
+
+const (
+	BatchSourceUpload BatchSource = "upload" // uploaded to the server
+	BatchSourceS3     BatchSource = "s3"     // processed by the CSV processor Lambda
+)
+
+// MaxBatchRowErrors caps the row errors stored per batch; further failed rows
+// are still counted
+const MaxBatchRowErrors = 100
+
+// UploadBatch tracks a CSV upload from receipt to matching.
+type UploadBatch struct {
+	ID          int64       `json:"id" db:"id"`
+	BatchID     string      `json:"batch_id" db:"batch_id"`
+	Source      BatchSource `json:"source" db:"source"`
+	S3Key       string      `json:"s3_key,omitempty" db:"s3_key"`
+	FileName    string      `json:"file_name,omitempty" db:"file_name"`
+	Status      BatchStatus `json:"status" db:"status"`
+	TotalRows   int         `json:"total_rows" db:"total_rows"`
+	ValidRows   int         `json:"valid_rows" db:"valid_rows"`
+	SavedRows   int         `json:"saved_rows" db:"successful_rows"`
+	FailedRows  int         `json:"failed_rows" db:"failed_rows"`
+	RowErrors   []string    `json:"row_errors,omitempty" db:"row_errors"`
+	Error       string      `json:"error,omitempty" db:"error_details"`
+	StartedAt   time.Time   `json:"started_at" db:"started_at"`
+	CompletedAt *time.Time  `json:"completed_at,omitempty" db:"completed_at"`
+	UpdatedAt   time.Time   `json:"updated_at" db:"updated_at"`
+
+	Progress BatchProgress `json:"progress"`
+	Funnel   *BatchFunnel  `json:"funnel,omitempty"`
+}
+
+// UploadBatchCreate is used to record a newly received upload.
+type UploadBatchCreate struct {
+	BatchID  string      `json:"batch_id"`
+	Source   BatchSource `json:"source"`
+	S3Key    string      `json:"s3_key,omitempty"`
+	FileName string      `json:"file_name,omitempty"`
+}
+
+// BatchProgress is how far the current stage of a batch has got: rows saved
+// while inserting, users matched while matching.
+type BatchProgress struct {
+	Stage   BatchStatus `json:"stage"`
+	Done    int         `json:"done"`
+	Total   int         `json:"total"`
+	Percent float64     `json:"percent"`
+}
+
+// NewBatchProgress computes a batch's progress from its saved rows and, while
+// matching, the users matched so far out of the users to match.
+func NewBatchProgress(batch *UploadBatch, usersMatched, usersToMatch int) BatchProgress {
+	progress := BatchProgress{Stage: batch.Status}
+	switch batch.Status {
+	case BatchStatusInserting:
+		progress.Done, progress.Total = batch.SavedRows, batch.ValidRows
+	case BatchStatusMatching:
+		progress.Done, progress.Total = usersMatched, usersToMatch
+	case BatchStatusCompleted:
+		progress.Percent = 100
+		return progress
+	}
+
+	if progress.Total > 0 {
+		progress.Percent = math.Round(math.Min(1, float64(progress.Done)/float64(progress.Total))*1000) / 10
+	}
+	return progress
+}
+
+// BatchFunnel counts where a batch's users and their user-product pairs
+// ended up: pairs rejected by each pipeline stage, and matches by status.
+type BatchFunnel struct {
+	Users           int                 `json:"users"`
+	UsersMatched    int                 `json:"users_matched"`
+	RejectedByStage map[MatchSource]int `json:"rejected_by_stage"`
+	Matches         int                 `json:"matches"`
+	MatchesByStatus map[MatchStatus]int `json:"matches_by_status"`
+}
+
+// NewBatchID returns a unique, time-ordered batch ID such as
+// "batch_20261016T093000_3f9a2c1b"
+func NewBatchID(now time.Time) string {
+	var suffix [4]byte
+	_, _ = rand.Read(suffix[:])
+	return "batch_" + now.UTC().Format("20060102T150405") + "_" + hex.EncodeToString(suffix[:])
+}
@@ -0,0 +1,30 @@
+// Package models defines the data structures for the loan eligibility engine.
+package models
+
+import (
+	"time"
+)
+
+// CheckpointStatus represents the state of a chunked matching run.
+type CheckpointStatus string
+
+const (
+	CheckpointStatusRunning   CheckpointStatus = "running"
+	CheckpointStatusCompleted CheckpointStatus = "completed"
+)
+
+// MatchCheckpoint records how far chunked matching of a batch has progressed.
+// Users are processed in ascending ID order, so every user up to LastUserID
+// has been matched and saved.
+type MatchCheckpoint struct {
+	BatchID        string           `json:"batch_id" db:"batch_id"`
+	Status         CheckpointStatus `json:"status" db:"status"`
+	LastUserID     int64            `json:"last_user_id" db:"last_user_id"`
+	UsersProcessed int              `json:"users_processed" db:"users_processed"`
+	TotalUsers     int              `json:"total_users" db:"total_users"`
+	MatchesSaved   int              `json:"matches_saved" db:"matches_saved"`
+	LLMCalls       int              `json:"llm_calls" db:"llm_calls"`
+	StartedAt      time.Time        `json:"started_at" db:"started_at"`
+	UpdatedAt      time.Time        `json:"updated_at" db:"updated_at"`
+	CompletedAt    *time.Time       `json:"completed_at,omitempty" db:"completed_at"`
+}
@@ -0,0 +1,128 @@
+// Package models defines the data structures for the loan eligibility engine.
+package models
+
+import (
+	"time"
+)
+
+// CrawlerSource is a registered page the crawler extracts loan products from.
+type CrawlerSource struct {
+	ID            int64           `json:"id" db:"id"`
+	Name          string          `json:"name" db:"name"`
+	URL           string          `json:"url" db:"url"`
+	ProviderName  string          `json:"provider_name" db:"provider_name"`
+	ProductType   LoanProductType `json:"product_type" db:"product_type"`
+	Currency      Currency        `json:"currency" db:"currency"`
+	Rules         ExtractionRules `json:"rules" db:"rules"`
+	IsActive      bool            `json:"is_active" db:"is_active"`
+	LastCrawledAt *time.Time      `json:"last_crawled_at,omitempty" db:"last_crawled_at"`
+	LastStatus    string          `json:"last_status,omitempty" db:"last_status"`
+	LastError     string          `json:"last_error,omitempty" db:"last_error"`
+	CreatedAt     time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// CrawlerSourceCreate is used to register a source or replace its definition.
+type CrawlerSourceCreate struct {
+	Name         string          `json:"name" validate:"required,max=255"`
+	URL          string          `json:"url" validate:"required,url"`
+	ProviderName string          `json:"provider_name" validate:"required,max=200"`
+	ProductType  LoanProductType `json:"product_type"`
+	Currency     Currency        `json:"currency,omitempty"`
+	Rules        ExtractionRules `json:"rules"`
+	IsActive     *bool           `json:"is_active,omitempty"`
+}
+
+// ExtractionRules describe how to read loan products from a source page.
+// Products selects one element per product; when empty the whole page is a
+// single product. Fields are keyed by LoanProductCreate JSON field name and
+// are evaluated within each product's element.
+type ExtractionRules struct {
+	Products  string               `json:"products,omitempty"`
+	Fields    map[string]FieldRule `json:"fields"`
+	RateSlabs []RateSlabRule       `json:"rate_slabs,omitempty"`
+}
+
+// FieldRule extracts one field. Selector picks elements by a CSS-like
+// selector and reads their text, or Attr when set; without a selector the
+// product element's text is used. Regex, when set, is matched against that
+// text and capture group Group (default 1, or the whole match if the pattern
+// has no groups) is kept. Numbers are parsed from the result, understanding
+// thousands separators and lakh and crore amounts, and multiplied by Scale
+// when set. Default is used when the rule has no selector or regex, or when
+// they match nothing.
+type FieldRule struct {
+	Selector string  `json:"selector,omitempty"`
+	Attr     string  `json:"attr,omitempty"`
+	Regex    string  `json:"regex,omitempty"`
+	Group    int     `json:"group,omitempty"`
+	Scale    float64 `json:"scale,omitempty"`
+	Default  string  `json:"default,omitempty"`
+}
+
+// RateSlabRule extracts the interest rate of one credit score band of a
+// product's rate card.
+type RateSlabRule struct {
+	MinCreditScore       int        `json:"min_credit_score"`
+	MaxCreditScore       int        `json:"max_credit_score"`
+	InterestRate         FieldRule  `json:"interest_rate"`
+	ProcessingFeePercent *FieldRule `json:"processing_fee_percent,omitempty"`
+}
+
+// CrawlerRunStatus represents the state of a crawler run.
+type CrawlerRunStatus string
+
+const (
+	CrawlerRunStatusRunning   CrawlerRunStatus = "running"
+	CrawlerRunStatusCompleted CrawlerRunStatus = "completed"
+	CrawlerRunStatusPartial   CrawlerRunStatus = "partial"
+	CrawlerRunStatusFailed    CrawlerRunStatus = "failed"
+)
+
+// CrawlerRun records one crawl of the registered sources.
+type CrawlerRun struct {
+	ID              int64                 `json:"id" db:"id"`
+	SourceName      string                `json:"source_name,omitempty" db:"source_name"`
+	Status          CrawlerRunStatus      `json:"status" db:"status"`
+	SourcesCrawled  int                   `json:"sources_crawled" db:"sources_crawled"`
+	SourcesFailed   int                   `json:"sources_failed" db:"sources_failed"`
+	ProductsFound   int                   `json:"products_found" db:"products_found"`
+	ProductsAdded   int                   `json:"products_added" db:"products_added"`
+	ProductsUpdated int                   `json:"products_updated" db:"products_updated"`
+	ProductsFailed  int                   `json:"products_failed" db:"products_failed"`
+	Error           string                `json:"error,omitempty" db:"error_message"`
+	StartedAt       time.Time             `json:"started_at" db:"started_at"`
+	CompletedAt     *time.Time            `json:"completed_at,omitempty" db:"completed_at"`
+	Sources         []CrawlerSourceResult `json:"sources,omitempty" db:"details"`
+}
+
+// CrawlerSourceResult is the outcome of crawling one source in a run.
+// Products counts the products found, Added those that were new, Updated
+// existing ones whose terms or rate slabs changed and Failed those that could
+// not be extracted or saved. Errors says why, or why the page could not be
+// crawled at all.
+type CrawlerSourceResult struct {
+	Source   string           `json:"source"`
+	URL      string           `json:"url"`
+	Status   CrawlerRunStatus `json:"status"`
+	Products int              `json:"products"`
+	Added    int              `json:"added"`
+	Updated  int              `json:"updated"`
+	Failed   int              `json:"failed"`
+	Errors   []string         `json:"errors,omitempty"`
+}
+
+// CrawledProduct is a loan product extracted from a source page, with the
+// rate slabs of its rate card when the source defines them.
+type CrawledProduct struct {
+	LoanProductCreate
+	RateSlabs []*RateSlabCreate `json:"rate_slabs,omitempty"`
+}
+
+// ProductUpsert is the outcome of saving a crawled product: whether it was
+// new, and whether it is new or its matching-relevant terms changed.
+type ProductUpsert struct {
+	ID       int64 `json:"id"`
+	Inserted bool  `json:"inserted"`
+	Changed  bool  `json:"changed"`
+}
@@ -0,0 +1,80 @@
+// Package models defines the data structures for the loan eligibility engine.
+package models
+
+import (
+	"time"
+)
+
+// Currency is an ISO 4217 currency code.
+type Currency string
+
+const (
+	CurrencyINR Currency = "INR"
+	CurrencyUSD Currency = "USD"
+	CurrencyEUR Currency = "EUR"
+	CurrencyGBP Currency = "GBP"
+	CurrencyAED Currency = "AED"
+	CurrencySGD Currency = "SGD"
+)
+
+// DefaultCurrency applies to users and products that do not state one.
+const DefaultCurrency = CurrencyINR
+
+// currencyDetails holds the display symbol and default locale of each
+// supported currency.
+var currencyDetails = map[Currency]struct {
+	symbol string
+	locale string
+}{
+	CurrencyINR: {"₹", "en-IN"},
+	CurrencyUSD: {"$", "en-US"},
+	CurrencyEUR: {"€", "en-IE"},
+	CurrencyGBP: {"£", "en-GB"},
+	CurrencyAED: {"AED ", "en-AE"},
+	CurrencySGD: {"S$", "en-SG"},
+}
+
+// ValidCurrencies returns all supported currencies.
+func ValidCurrencies() []Currency {
+	return []Currency{CurrencyINR, CurrencyUSD, CurrencyEUR, CurrencyGBP, CurrencyAED, CurrencySGD}
+}
+
+// IsValid checks if the currency is supported.
+func (c Currency) IsValid() bool {
+	_, ok := currencyDetails[c]
+	return ok
+}
+
+// OrDefault returns the currency, or DefaultCurrency when it is empty.
+func (c Currency) OrDefault() Currency {
+	if c == "" {
+		return DefaultCurrency
+	}
+	return c
+}
+
+// Symbol returns the symbol amounts in the currency are written with.
+func (c Currency) Symbol() string {
+	if d, ok := currencyDetails[c.OrDefault()]; ok {
+		return d.symbol
+	}
+	return string(c) + " "
+}
+
+// DefaultLocale returns the locale amounts in the currency are formatted for
+// when no other locale is known.
+func (c Currency) DefaultLocale() string {
+	if d, ok := currencyDetails[c.OrDefault()]; ok {
+		return d.locale
+	}
+	return "en-US"
+}
+
+// FXRate is the value of one unit of a currency in the base currency, the
+// one whose rate is 1. Amounts convert between two currencies through their
+// rates.
+type FXRate struct {
+	Currency   Currency  `json:"currency" db:"currency"`
+	RateToBase float64   `json:"rate_to_base" db:"rate_to_base"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
@@ -0,0 +1,58 @@
+// Package models defines the data structures for the loan eligibility engine.
+package models
+
+import (
+	"time"
+)
+
+// EligibilityCheck is the outcome of a single hard eligibility criterion.
+type EligibilityCheck struct {
+	Criterion string `json:"criterion"`
+	Passed    bool   `json:"passed"`
+	Message   string `json:"message"`
+}
+
+// MatchRejection records why a user-product pair did not become a match.
+type MatchRejection struct {
+	ID        int64              `json:"id" db:"id"`
+	UserID    int64              `json:"user_id" db:"user_id"`
+	ProductID int64              `json:"product_id" db:"product_id"`
+	Stage     MatchSource        `json:"stage" db:"stage"`
+	Reasons   []EligibilityCheck `json:"reasons" db:"reasons"`
+	BatchID   string             `json:"batch_id,omitempty" db:"batch_id"`
+	CreatedAt time.Time          `json:"created_at" db:"created_at"`
+}
+
+// EligibilityOutcome summarises where a user-product pair ended up.
+type EligibilityOutcome string
+
+const (
+	EligibilityOutcomeMatched         EligibilityOutcome = "matched"
+	EligibilityOutcomeRejectedSQL     EligibilityOutcome = "rejected_prefilter"
+	EligibilityOutcomeRejectedRules   EligibilityOutcome = "rejected_rules"
+	EligibilityOutcomeRejectedLLM     EligibilityOutcome = "rejected_llm"
+	EligibilityOutcomePendingReview   EligibilityOutcome = "pending_review"
+	EligibilityOutcomeRejectedReview  EligibilityOutcome = "rejected_review"
+	EligibilityOutcomeNotYetEvaluated EligibilityOutcome = "not_evaluated"
+)
+
+// ProductEligibility is the full pass/fail breakdown of a user against one product.
+type ProductEligibility struct {
+	ProductID    int64              `json:"product_id"`
+	ProductName  string             `json:"product_name"`
+	ProviderName string             `json:"provider_name"`
+	Outcome      EligibilityOutcome `json:"outcome"`
+	Checks       []EligibilityCheck `json:"checks"`
+	RuleResults  []RuleResult       `json:"rule_results,omitempty"`
+	MatchStatus  MatchStatus        `json:"match_status,omitempty"`
+	MatchScore   *float64           `json:"match_score,omitempty"`
+	LLMAnalysis  string             `json:"llm_analysis,omitempty"`
+}
+
+// UserEligibilityReport is the per-product eligibility breakdown for a user.
+type UserEligibilityReport struct {
+	UserID        int64                 `json:"user_id"`
+	ExternalID    string                `json:"external_user_id"`
+	EligibleCount int                   `json:"eligible_count"`
+	Products      []*ProductEligibility `json:"products"`
+}
@@ -14,6 +14,21 @@ var (
 	ErrInvalidIncome           = errors.New("monthly income cannot be negative")
 	ErrInvalidEmail            = errors.New("invalid email address")
 	ErrEmptyUserID             = errors.New("user_id cannot be empty")
+	ErrInvalidObligations      = errors.New("existing obligations cannot be negative")
+	ErrInvalidRequestedTerms   = errors.New("requested amount and tenure cannot be negative")
+	ErrInvalidLoanPurpose      = errors.New("invalid loan purpose")
+	ErrInvalidSlabCreditBand   = errors.New("rate slab credit score band must be within 300-900 with min <= max")
+	ErrInvalidSlabIncomeBand   = errors.New("rate slab income band cannot be negative or have max below min")
+	ErrInvalidSlabRate         = errors.New("rate slab interest rate and processing fee must be between 0 and 100")
+	ErrInvalidCurrency         = errors.New("unsupported currency")
+	ErrInvalidFXRate           = errors.New("exchange rate must be positive")
+	ErrInvalidProductName      = errors.New("product and provider names cannot be empty")
+	ErrInvalidProductType      = errors.New("invalid loan product type")
+	ErrInvalidProductRate      = errors.New("interest rates must be between 0 and 100 with min <= max")
+	ErrInvalidProductAmount    = errors.New("loan amounts must be positive with min <= max")
+	ErrInvalidProductTenure    = errors.New("tenures must be at least 1 month with min <= max")
+	ErrInvalidProductCriteria  = errors.New("product credit score must be within 300-900 and ages within 18-120 with min <= max")
+	ErrInvalidCrawlerSource    = errors.New("crawler source needs a name, an http(s) URL and a provider")
 )
 
 // NormalizeEmploymentStatus converts various employment status formats to standard values.
@@ -56,6 +71,92 @@ func NormalizeEmploymentStatus(status string) EmploymentStatus {
 	return EmploymentStatus(normalized)
 }
 
+// NormalizeLoanPurpose converts a stated loan purpose to the product type that
+// serves it. Anything a personal loan is typically used for maps to personal.
+func NormalizeLoanPurpose(purpose string) LoanProductType {
+	normalized := strings.ToLower(strings.TrimSpace(purpose))
+	normalized = strings.ReplaceAll(normalized, " ", "_")
+	normalized = strings.ReplaceAll(normalized, "-", "_")
+	normalized = strings.TrimSuffix(normalized, "_loan")
+
+	purposeMap := map[string]LoanProductType{
+		"personal":        LoanProductTypePersonal,
+		"phone":           LoanProductTypePersonal,
+		"mobile":          LoanProductTypePersonal,
+		"electronics":     LoanProductTypePersonal,
+		"consumer":        LoanProductTypePersonal,
+		"travel":          LoanProductTypePersonal,
+		"wedding":         LoanProductTypePersonal,
+		"marriage":        LoanProductTypePersonal,
+		"medical":         LoanProductTypePersonal,
+		"debt":            LoanProductTypePersonal,
+		"consolidation":   LoanProductTypePersonal,
+		"renovation":      LoanProductTypePersonal,
+		"home":            LoanProductTypeHome,
+		"house":           LoanProductTypeHome,
+		"housing":         LoanProductTypeHome,
+		"property":        LoanProductTypeHome,
+		"mortgage":        LoanProductTypeHome,
+		"auto":            LoanProductTypeAuto,
+		"car":             LoanProductTypeAuto,
+		"vehicle":         LoanProductTypeAuto,
+		"bike":            LoanProductTypeAuto,
+		"two_wheeler":     LoanProductTypeAuto,
+		"education":       LoanProductTypeEducation,
+		"student":         LoanProductTypeEducation,
+		"study":           LoanProductTypeEducation,
+		"tuition":         LoanProductTypeEducation,
+		"business":        LoanProductTypeBusiness,
+		"working_capital": LoanProductTypeBusiness,
+		"msme":            LoanProductTypeBusiness,
+	}
+
+	if mapped, ok := purposeMap[normalized]; ok {
+		return mapped
+	}
+
+	// Return as-is if no mapping found (will fail validation)
+	return LoanProductType(normalized)
+}
+
+// NormalizeCurrency converts a currency code, symbol or name to a Currency.
+// Unknown values are returned upper-cased and fail validation.
+func NormalizeCurrency(currency string) Currency {
+	normalized := strings.ToUpper(strings.TrimSpace(currency))
+	normalized = strings.TrimSuffix(normalized, ".")
+
+	currencyMap := map[string]Currency{
+		"₹":         CurrencyINR,
+		"RS":        CurrencyINR,
+		"RUPEE":     CurrencyINR,
+		"RUPEES":    CurrencyINR,
+		"$":         CurrencyUSD,
+		"US$":       CurrencyUSD,
+		"DOLLAR":    CurrencyUSD,
+		"DOLLARS":   CurrencyUSD,
+		"€":         CurrencyEUR,
+		"EURO":      CurrencyEUR,
+		"EUROS":     CurrencyEUR,
+		"£":         CurrencyGBP,
+		"POUND":     CurrencyGBP,
+		"POUNDS":    CurrencyGBP,
+		"DHS":       CurrencyAED,
+		"DIRHAM":    CurrencyAED,
+		"DIRHAMS":   CurrencyAED,
+		"د.إ":       CurrencyAED,
+		"S$":        CurrencySGD,
+		"SG$":       CurrencySGD,
+		"SGD$":      CurrencySGD,
+		"SG DOLLAR": CurrencySGD,
+	}
+
+	if mapped, ok := currencyMap[normalized]; ok {
+		return mapped
+	}
+
+	return Currency(normalized)
+}
+
 // ValidateUserCreate validates user creation data.
 func ValidateUserCreate(u *UserCreate) error {
 	if strings.TrimSpace(u.UserID) == "" {
@@ -82,6 +183,144 @@ func ValidateUserCreate(u *UserCreate) error {
 		return ErrInvalidEmploymentStatus
 	}
 
+	if u.ExistingEMI < 0 || u.CreditCardOutstanding < 0 || u.ActiveLoans < 0 {
+		return ErrInvalidObligations
+	}
+
+	if u.RequestedAmount < 0 || u.RequestedTenureMonths < 0 {
+		return ErrInvalidRequestedTerms
+	}
+
+	if u.LoanPurpose != "" && !u.LoanPurpose.IsValid() {
+		return ErrInvalidLoanPurpose
+	}
+
+	if u.Currency != "" && !u.Currency.IsValid() {
+		return ErrInvalidCurrency
+	}
+
+	return nil
+}
+
+// ValidateFXRate validates an exchange rate, normalizing its currency.
+func ValidateFXRate(r *FXRate) error {
+	r.Currency = NormalizeCurrency(string(r.Currency))
+	if !r.Currency.IsValid() {
+		return ErrInvalidCurrency
+	}
+	if r.RateToBase <= 0 {
+		return ErrInvalidFXRate
+	}
+	return nil
+}
+
+// ValidateRateSlab validates a rate slab, defaulting zero credit score bounds
+// to the full 300-900 range.
+func ValidateRateSlab(s *RateSlabCreate) error {
+	if s.MinCreditScore == 0 {
+		s.MinCreditScore = 300
+	}
+	if s.MaxCreditScore == 0 {
+		s.MaxCreditScore = 900
+	}
+	if s.MinCreditScore < 300 || s.MaxCreditScore > 900 || s.MinCreditScore > s.MaxCreditScore {
+		return ErrInvalidSlabCreditBand
+	}
+
+	if s.MinMonthlyIncome < 0 || (s.MaxMonthlyIncome != nil && *s.MaxMonthlyIncome < s.MinMonthlyIncome) {
+		return ErrInvalidSlabIncomeBand
+	}
+
+	if s.InterestRate <= 0 || s.InterestRate > 100 {
+		return ErrInvalidSlabRate
+	}
+	if s.ProcessingFeePercent != nil && (*s.ProcessingFeePercent < 0 || *s.ProcessingFeePercent > 100) {
+		return ErrInvalidSlabRate
+	}
+
+	return nil
+}
+
+// ValidateLoanProduct validates a loan product's terms, normalizing its
+// employment statuses and currency.
+func ValidateLoanProduct(p *LoanProductCreate) error {
+	if strings.TrimSpace(p.ProductName) == "" || strings.TrimSpace(p.ProviderName) == "" {
+		return ErrInvalidProductName
+	}
+
+	if !p.ProductType.IsValid() {
+		return ErrInvalidProductType
+	}
+
+	if p.InterestRateMin <= 0 || p.InterestRateMax > 100 || p.InterestRateMin > p.InterestRateMax {
+		return ErrInvalidProductRate
+	}
+
+	if p.LoanAmountMin <= 0 || p.LoanAmountMin > p.LoanAmountMax {
+		return ErrInvalidProductAmount
+	}
+
+	if p.TenureMinMonths < 1 || p.TenureMinMonths > p.TenureMaxMonths {
+		return ErrInvalidProductTenure
+	}
+
+	if p.MinMonthlyIncome < 0 {
+		return ErrInvalidIncome
+	}
+
+	if p.MinCreditScore < 300 || p.MinCreditScore > 900 ||
+		(p.MaxCreditScore != nil && (*p.MaxCreditScore < p.MinCreditScore || *p.MaxCreditScore > 900)) {
+		return ErrInvalidProductCriteria
+	}
+	if p.MinAge < 18 || p.MaxAge > 120 || p.MinAge > p.MaxAge {
+		return ErrInvalidProductCriteria
+	}
+
+	if len(p.AcceptedEmploymentStatus) == 0 {
+		return ErrInvalidEmploymentStatus
+	}
+	for i, status := range p.AcceptedEmploymentStatus {
+		p.AcceptedEmploymentStatus[i] = NormalizeEmploymentStatus(string(status))
+		if !p.AcceptedEmploymentStatus[i].IsValid() {
+			return ErrInvalidEmploymentStatus
+		}
+	}
+
+	if p.Currency != "" {
+		p.Currency = NormalizeCurrency(string(p.Currency))
+		if !p.Currency.IsValid() {
+			return ErrInvalidCurrency
+		}
+	}
+
+	return nil
+}
+
+// ValidateCrawlerSource validates a crawler source's registration, defaulting
+// its product type to personal and normalizing its currency. Its extraction
+// rules are checked by the crawler, which compiles them.
+func ValidateCrawlerSource(s *CrawlerSourceCreate) error {
+	s.Name = strings.TrimSpace(s.Name)
+	s.ProviderName = strings.TrimSpace(s.ProviderName)
+	if s.Name == "" || s.ProviderName == "" ||
+		!(strings.HasPrefix(s.URL, "https://") || strings.HasPrefix(s.URL, "http://")) {
+		return ErrInvalidCrawlerSource
+	}
+
+	if s.ProductType == "" {
+		s.ProductType = LoanProductTypePersonal
+	}
+	if !s.ProductType.IsValid() {
+		return ErrInvalidProductType
+	}
+
+	if s.Currency != "" {
+		s.Currency = NormalizeCurrency(string(s.Currency))
+		if !s.Currency.IsValid() {
+			return ErrInvalidCurrency
+		}
+	}
+
 	return nil
 }
 
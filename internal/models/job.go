@@ -0,0 +1,63 @@
+// Package models defines the data structures for the loan eligibility engine.
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// JobType identifies the work a background job performs.
+type JobType string
+
+const (
+	JobTypeIngest  JobType = "ingest"
+	JobTypeMatch   JobType = "match"
+	JobTypeNotify  JobType = "notify"
+	JobTypeRematch JobType = "rematch"
+	JobTypeCrawl   JobType = "crawl"
+)
+
+// JobStatus represents the state of a background job.
+type JobStatus string
+
+const (
+	JobStatusQueued    JobStatus = "queued"
+	JobStatusRunning   JobStatus = "running"
+	JobStatusSucceeded JobStatus = "succeeded"
+	JobStatusFailed    JobStatus = "failed"
+	JobStatusCancelled JobStatus = "cancelled"
+)
+
+// Job is a unit of background work stored in the jobs table. A running job is
+// leased to one worker until LockedUntil; a worker that stops renewing its
+// lease loses the job to the next worker that polls.
+type Job struct {
+	ID              int64           `json:"id" db:"id"`
+	Type            JobType         `json:"type" db:"job_type"`
+	Status          JobStatus       `json:"status" db:"status"`
+	Payload         json.RawMessage `json:"payload,omitempty" db:"payload"`
+	Data            []byte          `json:"-" db:"data"`
+	Result          json.RawMessage `json:"result,omitempty" db:"result"`
+	ProgressDone    int             `json:"progress_done" db:"progress_done"`
+	ProgressTotal   int             `json:"progress_total" db:"progress_total"`
+	Attempts        int             `json:"attempts" db:"attempts"`
+	MaxAttempts     int             `json:"max_attempts" db:"max_attempts"`
+	LastError       string          `json:"last_error,omitempty" db:"last_error"`
+	CancelRequested bool            `json:"cancel_requested" db:"cancel_requested"`
+	LockedBy        string          `json:"locked_by,omitempty" db:"locked_by"`
+	LockedUntil     *time.Time      `json:"locked_until,omitempty" db:"locked_until"`
+	RunAfter        time.Time       `json:"run_after" db:"run_after"`
+	CreatedAt       time.Time       `json:"created_at" db:"created_at"`
+	StartedAt       *time.Time      `json:"started_at,omitempty" db:"started_at"`
+	FinishedAt      *time.Time      `json:"finished_at,omitempty" db:"finished_at"`
+	UpdatedAt       time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// JobCreate is used to enqueue a job. Data carries large inputs, such as an
+// uploaded CSV, that are not returned with the job's status.
+type JobCreate struct {
+	Type        JobType         `json:"type"`
+	Payload     json.RawMessage `json:"payload"`
+	Data        []byte          `json:"-"`
+	MaxAttempts int             `json:"max_attempts"`
+}
@@ -2,6 +2,8 @@
 package models
 
 import (
+	"encoding/json"
+	"fmt"
 	"time"
 )
 
@@ -16,12 +18,34 @@ const (
 	LoanProductTypeBusiness  LoanProductType = "business"
 )
 
+// ValidLoanProductTypes returns all valid loan product types.
+func ValidLoanProductTypes() []LoanProductType {
+	return []LoanProductType{
+		LoanProductTypePersonal,
+		LoanProductTypeHome,
+		LoanProductTypeAuto,
+		LoanProductTypeEducation,
+		LoanProductTypeBusiness,
+	}
+}
+
+// IsValid checks if the loan product type is valid.
+func (t LoanProductType) IsValid() bool {
+	for _, valid := range ValidLoanProductTypes() {
+		if t == valid {
+			return true
+		}
+	}
+	return false
+}
+
 // LoanProduct represents a loan product from a financial institution.
 type LoanProduct struct {
 	ID                       int64              `json:"id" db:"id"`
 	ProductName              string             `json:"product_name" db:"product_name"`
 	ProviderName             string             `json:"provider_name" db:"provider_name"`
 	ProductType              LoanProductType    `json:"product_type" db:"product_type"`
+	Currency                 Currency           `json:"currency" db:"currency"`
 	InterestRateMin          float64            `json:"interest_rate_min" db:"interest_rate_min"`
 	InterestRateMax          float64            `json:"interest_rate_max" db:"interest_rate_max"`
 	LoanAmountMin            float64            `json:"loan_amount_min" db:"loan_amount_min"`
@@ -35,11 +59,13 @@ type LoanProduct struct {
 	MaxAge                   int                `json:"max_age" db:"max_age"`
 	AcceptedEmploymentStatus []EmploymentStatus `json:"accepted_employment_status" db:"accepted_employment_status"`
 	ProcessingFeePercent     *float64           `json:"processing_fee_percent,omitempty" db:"processing_fee_percent"`
+	MaxFOIR                  *float64           `json:"max_foir,omitempty" db:"max_foir"`
 	SourceURL                string             `json:"source_url,omitempty" db:"source_url"`
 	CreatedAt                time.Time          `json:"created_at" db:"created_at"`
 	UpdatedAt                time.Time          `json:"updated_at" db:"updated_at"`
 	IsActive                 bool               `json:"is_active" db:"is_active"`
 	LastCrawledAt            *time.Time         `json:"last_crawled_at,omitempty" db:"last_crawled_at"`
+	Version                  int                `json:"version" db:"version"`
 }
 
 // LoanProductCreate represents data needed to create a new loan product.
@@ -47,6 +73,7 @@ type LoanProductCreate struct {
 	ProductName              string             `json:"product_name" validate:"required,min=1,max=200"`
 	ProviderName             string             `json:"provider_name" validate:"required,min=1,max=200"`
 	ProductType              LoanProductType    `json:"product_type"`
+	Currency                 Currency           `json:"currency,omitempty"`
 	InterestRateMin          float64            `json:"interest_rate_min" validate:"required,gte=0,lte=100"`
 	InterestRateMax          float64            `json:"interest_rate_max" validate:"required,gte=0,lte=100"`
 	LoanAmountMin            float64            `json:"loan_amount_min" validate:"required,gte=0"`
@@ -60,6 +87,7 @@ type LoanProductCreate struct {
 	MaxAge                   int                `json:"max_age" validate:"lte=120"`
 	AcceptedEmploymentStatus []EmploymentStatus `json:"accepted_employment_status"`
 	ProcessingFeePercent     *float64           `json:"processing_fee_percent,omitempty"`
+	MaxFOIR                  *float64           `json:"max_foir,omitempty" validate:"omitempty,gt=0,lte=1"`
 	SourceURL                string             `json:"source_url,omitempty"`
 }
 
@@ -91,6 +119,74 @@ func (p *LoanProduct) ToSummary() LoanProductSummary {
 	}
 }
 
+// ToCreate returns the product's terms as a LoanProductCreate. Pointer and
+// slice fields are copied so the result can be modified independently.
+func (p *LoanProduct) ToCreate() *LoanProductCreate {
+	terms := &LoanProductCreate{
+		ProductName:              p.ProductName,
+		ProviderName:             p.ProviderName,
+		ProductType:              p.ProductType,
+		Currency:                 p.Currency,
+		InterestRateMin:          p.InterestRateMin,
+		InterestRateMax:          p.InterestRateMax,
+		LoanAmountMin:            p.LoanAmountMin,
+		LoanAmountMax:            p.LoanAmountMax,
+		TenureMinMonths:          p.TenureMinMonths,
+		TenureMaxMonths:          p.TenureMaxMonths,
+		MinMonthlyIncome:         p.MinMonthlyIncome,
+		MinCreditScore:           p.MinCreditScore,
+		MinAge:                   p.MinAge,
+		MaxAge:                   p.MaxAge,
+		AcceptedEmploymentStatus: append([]EmploymentStatus(nil), p.AcceptedEmploymentStatus...),
+		SourceURL:                p.SourceURL,
+	}
+	if p.MaxCreditScore != nil {
+		v := *p.MaxCreditScore
+		terms.MaxCreditScore = &v
+	}
+	if p.ProcessingFeePercent != nil {
+		v := *p.ProcessingFeePercent
+		terms.ProcessingFeePercent = &v
+	}
+	if p.MaxFOIR != nil {
+		v := *p.MaxFOIR
+		terms.MaxFOIR = &v
+	}
+	return terms
+}
+
+// WithChanges returns a copy of the product with a JSON-encoded
+// LoanProductCreate diff applied. Fields absent from the diff keep their
+// current values; the product itself is not modified.
+func (p *LoanProduct) WithChanges(diff []byte) (*LoanProduct, error) {
+	terms := p.ToCreate()
+	if err := json.Unmarshal(diff, terms); err != nil {
+		return nil, fmt.Errorf("invalid product changes: %w", err)
+	}
+
+	updated := *p
+	updated.ProductName = terms.ProductName
+	updated.ProviderName = terms.ProviderName
+	updated.ProductType = terms.ProductType
+	updated.Currency = terms.Currency
+	updated.InterestRateMin = terms.InterestRateMin
+	updated.InterestRateMax = terms.InterestRateMax
+	updated.LoanAmountMin = terms.LoanAmountMin
+	updated.LoanAmountMax = terms.LoanAmountMax
+	updated.TenureMinMonths = terms.TenureMinMonths
+	updated.TenureMaxMonths = terms.TenureMaxMonths
+	updated.MinMonthlyIncome = terms.MinMonthlyIncome
+	updated.MinCreditScore = terms.MinCreditScore
+	updated.MaxCreditScore = terms.MaxCreditScore
+	updated.MinAge = terms.MinAge
+	updated.MaxAge = terms.MaxAge
+	updated.AcceptedEmploymentStatus = terms.AcceptedEmploymentStatus
+	updated.ProcessingFeePercent = terms.ProcessingFeePercent
+	updated.MaxFOIR = terms.MaxFOIR
+	updated.SourceURL = terms.SourceURL
+	return &updated, nil
+}
+
 // EligibilityCriteria contains the eligibility requirements for a loan product.
 type EligibilityCriteria struct {
 	ProductID                int64              `json:"product_id"`
@@ -114,15 +210,3 @@ func (p *LoanProduct) GetEligibilityCriteria() EligibilityCriteria {
 		AcceptedEmploymentStatus: p.AcceptedEmploymentStatus,
 	}
 }
-
-// CrawledProduct represents a loan product extracted from web crawling.
-type CrawledProduct struct {
-	ProductName         string    `json:"product_name"`
-	ProviderName        string    `json:"provider_name"`
-	InterestRate        string    `json:"interest_rate"`
-	MinIncome           string    `json:"min_income"`
-	MinCreditScore      string    `json:"min_credit_score"`
-	EligibilityCriteria string    `json:"eligibility_criteria"`
-	SourceURL           string    `json:"source_url"`
-	CrawledAt           time.Time `json:"crawled_at"`
-}
@@ -14,6 +14,11 @@ const (
 	MatchStatusNotEligible MatchStatus = "not_eligible"
 	MatchStatusNotified    MatchStatus = "notified"
 	MatchStatusExpired     MatchStatus = "expired"
+	MatchStatusUnreviewed  MatchStatus = "unreviewed" // passed stages 1-2, not sent to the LLM
+
+	// MatchStatusPendingReview awaits a human decision: the LLM verdict was
+	// low-confidence or the LLM check failed
+	MatchStatusPendingReview MatchStatus = "pending_review"
 )
 
 // MatchSource indicates how the match was determined.
@@ -28,38 +33,48 @@ const (
 
 // Match represents a user-loan product match.
 type Match struct {
-	ID                  int64       `json:"id" db:"id"`
-	UserID              int64       `json:"user_id" db:"user_id"`
-	ProductID           int64       `json:"product_id" db:"product_id"`
-	MatchScore          float64     `json:"match_score" db:"match_score"`
-	Status              MatchStatus `json:"status" db:"status"`
-	MatchSource         MatchSource `json:"match_source" db:"match_source"`
-	IncomeEligible      bool        `json:"income_eligible" db:"income_eligible"`
-	CreditScoreEligible bool        `json:"credit_score_eligible" db:"credit_score_eligible"`
-	AgeEligible         bool        `json:"age_eligible" db:"age_eligible"`
-	EmploymentEligible  bool        `json:"employment_eligible" db:"employment_eligible"`
-	LLMAnalysis         string      `json:"llm_analysis,omitempty" db:"llm_analysis"`
-	LLMConfidence       *float64    `json:"llm_confidence,omitempty" db:"llm_confidence"`
-	BatchID             string      `json:"batch_id,omitempty" db:"batch_id"`
-	CreatedAt           time.Time   `json:"created_at" db:"created_at"`
-	UpdatedAt           time.Time   `json:"updated_at" db:"updated_at"`
-	NotifiedAt          *time.Time  `json:"notified_at,omitempty" db:"notified_at"`
+	ID                  int64        `json:"id" db:"id"`
+	UserID              int64        `json:"user_id" db:"user_id"`
+	ProductID           int64        `json:"product_id" db:"product_id"`
+	MatchScore          float64      `json:"match_score" db:"match_score"`
+	Status              MatchStatus  `json:"status" db:"status"`
+	MatchSource         MatchSource  `json:"match_source" db:"match_source"`
+	IncomeEligible      bool         `json:"income_eligible" db:"income_eligible"`
+	CreditScoreEligible bool         `json:"credit_score_eligible" db:"credit_score_eligible"`
+	AgeEligible         bool         `json:"age_eligible" db:"age_eligible"`
+	EmploymentEligible  bool         `json:"employment_eligible" db:"employment_eligible"`
+	LLMAnalysis         string       `json:"llm_analysis,omitempty" db:"llm_analysis"`
+	LLMConfidence       *float64     `json:"llm_confidence,omitempty" db:"llm_confidence"`
+	RuleResults         []RuleResult `json:"rule_results,omitempty" db:"rule_results"`
+	Affordability
+	RateEstimate
+	ScoringVersion string     `json:"scoring_version,omitempty" db:"scoring_version"`
+	ProductVersion *int       `json:"product_version,omitempty" db:"product_version"` // nil for matches made before versions were tracked
+	BatchID        string     `json:"batch_id,omitempty" db:"batch_id"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+	NotifiedAt     *time.Time `json:"notified_at,omitempty" db:"notified_at"`
 }
 
 // MatchCreate represents data needed to create a new match.
 type MatchCreate struct {
-	UserID              int64       `json:"user_id" validate:"required"`
-	ProductID           int64       `json:"product_id" validate:"required"`
-	MatchScore          float64     `json:"match_score" validate:"gte=0,lte=100"`
-	Status              MatchStatus `json:"status"`
-	MatchSource         MatchSource `json:"match_source"`
-	IncomeEligible      bool        `json:"income_eligible"`
-	CreditScoreEligible bool        `json:"credit_score_eligible"`
-	AgeEligible         bool        `json:"age_eligible"`
-	EmploymentEligible  bool        `json:"employment_eligible"`
-	LLMAnalysis         string      `json:"llm_analysis,omitempty"`
-	LLMConfidence       *float64    `json:"llm_confidence,omitempty"`
-	BatchID             string      `json:"batch_id,omitempty"`
+	UserID              int64        `json:"user_id" validate:"required"`
+	ProductID           int64        `json:"product_id" validate:"required"`
+	MatchScore          float64      `json:"match_score" validate:"gte=0,lte=100"`
+	Status              MatchStatus  `json:"status"`
+	MatchSource         MatchSource  `json:"match_source"`
+	IncomeEligible      bool         `json:"income_eligible"`
+	CreditScoreEligible bool         `json:"credit_score_eligible"`
+	AgeEligible         bool         `json:"age_eligible"`
+	EmploymentEligible  bool         `json:"employment_eligible"`
+	LLMAnalysis         string       `json:"llm_analysis,omitempty"`
+	LLMConfidence       *float64     `json:"llm_confidence,omitempty"`
+	RuleResults         []RuleResult `json:"rule_results,omitempty"`
+	Affordability
+	RateEstimate
+	ScoringVersion string `json:"scoring_version,omitempty"`
+	ProductVersion int    `json:"product_version,omitempty"` // 0 means the product's current version
+	BatchID        string `json:"batch_id,omitempty"`
 }
 
 // MatchWithDetails contains full match information with user and product details.
@@ -100,10 +115,19 @@ type MatchCandidate struct {
 	EmploymentStatus EmploymentStatus `json:"employment_status"`
 	Age              int              `json:"age"`
 
+	ExistingEMI           float64         `json:"existing_emi"`
+	CreditCardOutstanding float64         `json:"credit_card_outstanding"`
+	ActiveLoans           int             `json:"active_loans"`
+	RequestedAmount       float64         `json:"requested_amount"`
+	RequestedTenureMonths int             `json:"requested_tenure_months"`
+	LoanPurpose           LoanProductType `json:"loan_purpose"`
+
 	// Product fields
 	ProductID                int64              `json:"product_id"`
 	ProductName              string             `json:"product_name"`
 	ProviderName             string             `json:"provider_name"`
+	ProductType              LoanProductType    `json:"product_type"`
+	Currency                 Currency           `json:"currency"`
 	MinMonthlyIncome         float64            `json:"min_monthly_income"`
 	MinCreditScore           int                `json:"min_credit_score"`
 	MaxCreditScore           *int               `json:"max_credit_score"`
@@ -112,6 +136,10 @@ type MatchCandidate struct {
 	AcceptedEmploymentStatus []EmploymentStatus `json:"accepted_employment_status"`
 	InterestRateMin          float64            `json:"interest_rate_min"`
 	InterestRateMax          float64            `json:"interest_rate_max"`
+	LoanAmountMin            float64            `json:"loan_amount_min"`
+	LoanAmountMax            float64            `json:"loan_amount_max"`
+	TenureMinMonths          int                `json:"tenure_min_months"`
+	TenureMaxMonths          int                `json:"tenure_max_months"`
 }
 
 // IsFullyEligible checks if the candidate passes all basic eligibility checks.
@@ -148,53 +176,17 @@ func (c *MatchCandidate) IsFullyEligible() bool {
 		}
 	}
 
-	return true
-}
-
-// CalculateMatchScore calculates a match score based on eligibility factors.
-func (c *MatchCandidate) CalculateMatchScore() float64 {
-	score := 0.0
-	maxScore := 100.0
-
-	// Income score (30 points max)
-	if c.MonthlyIncome >= c.MinMonthlyIncome {
-		incomeRatio := c.MonthlyIncome / c.MinMonthlyIncome
-		if incomeRatio > 2 {
-			incomeRatio = 2
-		}
-		score += 15 * incomeRatio
-	}
-
-	// Credit score (40 points max)
-	if c.CreditScore >= c.MinCreditScore {
-		// Normalize credit score (300-900 range)
-		normalizedScore := float64(c.CreditScore-300) / 600.0
-		score += 40 * normalizedScore
-	}
-
-	// Age score (15 points max)
-	if c.Age >= c.MinAge && c.Age <= c.MaxAge {
-		score += 15
+	// Requested purpose and amount
+	if c.LoanPurpose != "" && c.LoanPurpose != c.ProductType {
+		return false
 	}
-
-	// Employment score (15 points max)
-	if len(c.AcceptedEmploymentStatus) > 0 {
-		for _, status := range c.AcceptedEmploymentStatus {
-			if status == c.EmploymentStatus {
-				score += 15
-				break
-			}
+	if c.RequestedAmount > 0 {
+		if c.RequestedAmount < c.LoanAmountMin || (c.LoanAmountMax > 0 && c.RequestedAmount > c.LoanAmountMax) {
+			return false
 		}
-	} else {
-		score += 15 // No restriction
 	}
 
-	// Normalize to 0-100
-	if score > maxScore {
-		score = maxScore
-	}
-
-	return score
+	return true
 }
 
 // NotificationRecord represents a record of an email notification sent.
@@ -0,0 +1,60 @@
+// Package models defines the data structures for the loan eligibility engine.
+package models
+
+// OfferRanking is the order in which a user's offers are listed.
+type OfferRanking string
+
+const (
+	OfferRankingScore OfferRanking = "score" // highest match score first
+	OfferRankingCost  OfferRanking = "cost"  // lowest total cost of borrowing first
+)
+
+// IsValid checks if the ranking is a known value.
+func (r OfferRanking) IsValid() bool {
+	return r == OfferRankingScore || r == OfferRankingCost
+}
+
+// BorrowingCost is what borrowing an amount over a tenure costs: the interest
+// and processing fee paid on top of the principal, and the APR, the annual
+// rate that accounts for the fee as well as the interest.
+type BorrowingCost struct {
+	Amount        float64 `json:"amount"`
+	TenureMonths  int     `json:"tenure_months"`
+	InterestRate  float64 `json:"interest_rate"`
+	EMI           float64 `json:"emi"`
+	TotalInterest float64 `json:"total_interest"`
+	TotalFees     float64 `json:"total_fees"`
+	TotalCost     float64 `json:"total_cost"`
+	APR           float64 `json:"apr"`
+}
+
+// FormattedCost holds the amounts of a BorrowingCost written out for display
+// in a currency and locale, e.g. "₹5,00,000".
+type FormattedCost struct {
+	Amount        string `json:"amount"`
+	EMI           string `json:"emi"`
+	TotalInterest string `json:"total_interest"`
+	TotalFees     string `json:"total_fees"`
+	TotalCost     string `json:"total_cost"`
+}
+
+// Offer is a matched loan product priced for the amount and tenure a user
+// wants to borrow. Amounts are in Currency, the user's currency, whatever the
+// currency of the product.
+type Offer struct {
+	MatchID         int64           `json:"match_id"`
+	ProductID       int64           `json:"product_id"`
+	ProductName     string          `json:"product_name"`
+	ProviderName    string          `json:"provider_name"`
+	ProductType     LoanProductType `json:"product_type"`
+	ProductCurrency Currency        `json:"product_currency"`
+	MatchScore      float64         `json:"match_score"`
+	Status          MatchStatus     `json:"status"`
+	Currency        Currency        `json:"currency"`
+	BorrowingCost
+	Formatted *FormattedCost `json:"formatted,omitempty"`
+
+	// SimilarMatchIDs are matches for near-identical products of the same
+	// provider, collapsed into this offer
+	SimilarMatchIDs []int64 `json:"similar_match_ids,omitempty"`
+}
@@ -0,0 +1,137 @@
+// Package models defines the data structures for the loan eligibility engine.
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ProductChangeSource identifies where a change to a loan product came from.
+type ProductChangeSource string
+
+const (
+	ProductChangeSourceCrawler ProductChangeSource = "crawler"
+	ProductChangeSourceAdmin   ProductChangeSource = "admin"
+	ProductChangeSourceImport  ProductChangeSource = "import"
+)
+
+// IsValid checks if the change source is valid.
+func (s ProductChangeSource) IsValid() bool {
+	switch s {
+	case ProductChangeSourceCrawler, ProductChangeSourceAdmin, ProductChangeSourceImport:
+		return true
+	}
+	return false
+}
+
+// LoanProductVersion is a loan product as it stood from one change to the
+// next. Version 1 is the product as first saved; each later version records
+// the fields that changed from the one before.
+type LoanProductVersion struct {
+	ID                   int64               `json:"id" db:"id"`
+	ProductID            int64               `json:"product_id" db:"product_id"`
+	Version              int                 `json:"version" db:"version"`
+	Source               ProductChangeSource `json:"source" db:"source"`
+	ChangedFields        []string            `json:"changed_fields" db:"changed_fields"`
+	ProductName          string              `json:"product_name" db:"product_name"`
+	ProviderName         string              `json:"provider_name" db:"provider_name"`
+	InterestRateMin      float64             `json:"interest_rate_min" db:"interest_rate_min"`
+	InterestRateMax      float64             `json:"interest_rate_max" db:"interest_rate_max"`
+	ProcessingFeePercent *float64            `json:"processing_fee_percent,omitempty" db:"processing_fee_percent"`
+	IsActive             bool                `json:"is_active" db:"is_active"`
+	Terms                json.RawMessage     `json:"terms" db:"terms"`
+	Matches              int                 `json:"matches" db:"matches"` // matches computed against this version
+	CreatedAt            time.Time           `json:"created_at" db:"created_at"`
+}
+
+// ProductHistory is a loan product with its versions, newest first.
+type ProductHistory struct {
+	Product  *LoanProduct          `json:"product"`
+	Versions []*LoanProductVersion `json:"versions"`
+}
+
+// RatePoint is a product's interest rate range from a point in time until the
+// next point.
+type RatePoint struct {
+	At              time.Time `json:"at"`
+	Version         int       `json:"version"`
+	InterestRateMin float64   `json:"interest_rate_min"`
+	InterestRateMax float64   `json:"interest_rate_max"`
+	IsActive        bool      `json:"is_active"`
+}
+
+// ProductRateSeries is the rate timeline of one product.
+type ProductRateSeries struct {
+	ProductID   int64       `json:"product_id"`
+	ProductName string      `json:"product_name"`
+	Points      []RatePoint `json:"points"`
+}
+
+// ProviderRateTimeline is the rate timeline of one lender's products.
+type ProviderRateTimeline struct {
+	ProviderName string               `json:"provider_name"`
+	Products     []*ProductRateSeries `json:"products"`
+}
+
+// BuildRateTimelines turns product versions, ordered by product and version,
+// into rate timelines by lender. A point is kept only where a product's rate
+// range or active flag changed, so versions that changed other terms do not
+// show up as flat steps. With a non-zero from, the versions up to from are
+// folded into one point at from giving the rates then in effect. Lenders and
+// products are listed in the order first seen; a product that moved lender
+// appears under each. The product name is the latest one seen.
+func BuildRateTimelines(versions []*LoanProductVersion, from time.Time) []*ProviderRateTimeline {
+	var timelines []*ProviderRateTimeline
+	byProvider := make(map[string]*ProviderRateTimeline)
+	type seriesKey struct {
+		provider  string
+		productID int64
+	}
+	bySeries := make(map[seriesKey]*ProductRateSeries)
+
+	for _, v := range versions {
+		timeline := byProvider[v.ProviderName]
+		if timeline == nil {
+			timeline = &ProviderRateTimeline{ProviderName: v.ProviderName}
+			byProvider[v.ProviderName] = timeline
+			timelines = append(timelines, timeline)
+		}
+
+		key := seriesKey{v.ProviderName, v.ProductID}
+		series := bySeries[key]
+		if series == nil {
+			series = &ProductRateSeries{ProductID: v.ProductID}
+			bySeries[key] = series
+			timeline.Products = append(timeline.Products, series)
+		}
+		series.ProductName = v.ProductName
+
+		point := RatePoint{
+			At:              v.CreatedAt,
+			Version:         v.Version,
+			InterestRateMin: v.InterestRateMin,
+			InterestRateMax: v.InterestRateMax,
+			IsActive:        v.IsActive,
+		}
+		if !from.IsZero() && !point.At.After(from) {
+			point.At = from
+		}
+
+		n := len(series.Points)
+		switch {
+		case n > 0 && series.Points[n-1].At.Equal(point.At):
+			// Superseded before from: only the last version counts
+			series.Points[n-1] = point
+		case n > 0 && sameRates(series.Points[n-1], point):
+			continue
+		default:
+			series.Points = append(series.Points, point)
+		}
+	}
+
+	return timelines
+}
+
+func sameRates(a, b RatePoint) bool {
+	return a.InterestRateMin == b.InterestRateMin && a.InterestRateMax == b.InterestRateMax && a.IsActive == b.IsActive
+}
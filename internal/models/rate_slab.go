@@ -0,0 +1,51 @@
+// Package models defines the data structures for the loan eligibility engine.
+package models
+
+import (
+	"time"
+)
+
+// RateSlab prices a loan product for a band of credit scores and monthly
+// incomes. Both bands are inclusive; a nil MaxMonthlyIncome leaves the income
+// band open-ended.
+type RateSlab struct {
+	ID                   int64     `json:"id" db:"id"`
+	ProductID            int64     `json:"product_id" db:"product_id"`
+	MinCreditScore       int       `json:"min_credit_score" db:"min_credit_score"`
+	MaxCreditScore       int       `json:"max_credit_score" db:"max_credit_score"`
+	MinMonthlyIncome     float64   `json:"min_monthly_income" db:"min_monthly_income"`
+	MaxMonthlyIncome     *float64  `json:"max_monthly_income,omitempty" db:"max_monthly_income"`
+	InterestRate         float64   `json:"interest_rate" db:"interest_rate"`
+	ProcessingFeePercent *float64  `json:"processing_fee_percent,omitempty" db:"processing_fee_percent"`
+	CreatedAt            time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// RateSlabCreate represents data needed to create a rate slab. Zero credit
+// score bounds mean 300 and 900.
+type RateSlabCreate struct {
+	MinCreditScore       int      `json:"min_credit_score" validate:"omitempty,gte=300,lte=900"`
+	MaxCreditScore       int      `json:"max_credit_score" validate:"omitempty,gte=300,lte=900"`
+	MinMonthlyIncome     float64  `json:"min_monthly_income" validate:"gte=0"`
+	MaxMonthlyIncome     *float64 `json:"max_monthly_income,omitempty"`
+	InterestRate         float64  `json:"interest_rate" validate:"required,gt=0,lte=100"`
+	ProcessingFeePercent *float64 `json:"processing_fee_percent,omitempty" validate:"omitempty,gte=0,lte=100"`
+}
+
+// Covers reports whether the slab applies to a credit score and monthly income.
+func (s *RateSlab) Covers(creditScore int, monthlyIncome float64) bool {
+	if creditScore < s.MinCreditScore || creditScore > s.MaxCreditScore {
+		return false
+	}
+	if monthlyIncome < s.MinMonthlyIncome {
+		return false
+	}
+	return s.MaxMonthlyIncome == nil || monthlyIncome <= *s.MaxMonthlyIncome
+}
+
+// RateEstimate is the interest rate and processing fee a user can expect on a
+// loan product.
+type RateEstimate struct {
+	EstimatedRate        float64  `json:"estimated_rate" db:"estimated_rate"`
+	ProcessingFeePercent *float64 `json:"processing_fee_percent,omitempty" db:"processing_fee_percent"`
+}
@@ -0,0 +1,59 @@
+// Package models defines the data structures for the loan eligibility engine.
+package models
+
+import (
+	"time"
+)
+
+// RematchTrigger identifies what started a re-matching run.
+type RematchTrigger string
+
+const (
+	RematchTriggerProductChange RematchTrigger = "product_change"
+	RematchTriggerManual        RematchTrigger = "manual"
+)
+
+// RematchRunStatus represents the state of a re-matching run.
+type RematchRunStatus string
+
+const (
+	RematchRunStatusRunning   RematchRunStatus = "running"
+	RematchRunStatusCompleted RematchRunStatus = "completed"
+	RematchRunStatusFailed    RematchRunStatus = "failed"
+)
+
+// RematchChangeType describes how a match changed in a re-matching run.
+type RematchChangeType string
+
+const (
+	RematchChangeAdded    RematchChangeType = "added"
+	RematchChangeRemoved  RematchChangeType = "removed"
+	RematchChangeRescored RematchChangeType = "rescored"
+)
+
+// RematchRun records a product-triggered re-matching run.
+type RematchRun struct {
+	ID          int64            `json:"id" db:"id"`
+	Trigger     RematchTrigger   `json:"trigger" db:"trigger_source"`
+	ProductIDs  []int64          `json:"product_ids" db:"product_ids"`
+	Status      RematchRunStatus `json:"status" db:"status"`
+	Added       int              `json:"matches_added" db:"matches_added"`
+	Removed     int              `json:"matches_removed" db:"matches_removed"`
+	Rescored    int              `json:"matches_rescored" db:"matches_rescored"`
+	Error       string           `json:"error,omitempty" db:"error_message"`
+	StartedAt   time.Time        `json:"started_at" db:"started_at"`
+	CompletedAt *time.Time       `json:"completed_at,omitempty" db:"completed_at"`
+	Changes     []RematchChange  `json:"changes,omitempty"`
+}
+
+// RematchChange is a single match added, removed or rescored by a run.
+type RematchChange struct {
+	ID         int64             `json:"id" db:"id"`
+	RunID      int64             `json:"run_id" db:"run_id"`
+	UserID     int64             `json:"user_id" db:"user_id"`
+	ProductID  int64             `json:"product_id" db:"product_id"`
+	ChangeType RematchChangeType `json:"change_type" db:"change_type"`
+	OldScore   *float64          `json:"old_score,omitempty" db:"old_score"`
+	NewScore   *float64          `json:"new_score,omitempty" db:"new_score"`
+	CreatedAt  time.Time         `json:"created_at" db:"created_at"`
+}
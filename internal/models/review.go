@@ -0,0 +1,33 @@
+// Package models defines the data structures for the loan eligibility engine.
+package models
+
+import (
+	"time"
+)
+
+// ReviewDecision is a reviewer's decision on a match awaiting review.
+type ReviewDecision string
+
+const (
+	ReviewDecisionApproved ReviewDecision = "approved"
+	ReviewDecisionRejected ReviewDecision = "rejected"
+)
+
+// Status returns the match status a decision results in.
+func (d ReviewDecision) Status() MatchStatus {
+	if d == ReviewDecisionApproved {
+		return MatchStatusEligible
+	}
+	return MatchStatusNotEligible
+}
+
+// MatchReview records a reviewer's decision on a match.
+type MatchReview struct {
+	ID             int64          `json:"id" db:"id"`
+	MatchID        int64          `json:"match_id" db:"match_id"`
+	Decision       ReviewDecision `json:"decision" db:"decision"`
+	Reviewer       string         `json:"reviewer" db:"reviewer"`
+	Comment        string         `json:"comment" db:"comment"`
+	PreviousStatus MatchStatus    `json:"previous_status" db:"previous_status"`
+	CreatedAt      time.Time      `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,37 @@
+// Package models defines the data structures for the loan eligibility engine.
+package models
+
+import (
+	"time"
+)
+
+// EligibilityRule is a declarative eligibility rule attached to a loan product.
+// The expression is evaluated by the logic filter against the user's profile
+// and the product terms, e.g. "foir <= 0.55" or "age + tenure_years <= 60".
+type EligibilityRule struct {
+	ID          int64     `json:"id" db:"id"`
+	ProductID   int64     `json:"product_id" db:"product_id"`
+	Name        string    `json:"name" db:"name"`
+	Expression  string    `json:"expression" db:"expression"`
+	Description string    `json:"description,omitempty" db:"description"`
+	Priority    int       `json:"priority" db:"priority"`
+	IsActive    bool      `json:"is_active" db:"is_active"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// EligibilityRuleCreate represents data needed to create an eligibility rule.
+type EligibilityRuleCreate struct {
+	Name        string `json:"name" validate:"required,min=1,max=100"`
+	Expression  string `json:"expression" validate:"required"`
+	Description string `json:"description,omitempty"`
+	Priority    int    `json:"priority"`
+}
+
+// RuleResult records the outcome of evaluating a single eligibility rule.
+type RuleResult struct {
+	Rule       string `json:"rule"`
+	Expression string `json:"expression"`
+	Passed     bool   `json:"passed"`
+	Error      string `json:"error,omitempty"`
+}
@@ -0,0 +1,77 @@
+// Package models defines the data structures for the loan eligibility engine.
+package models
+
+import (
+	"math"
+)
+
+// simulationScoreTolerance is the smallest score shift counted as a change.
+const simulationScoreTolerance = 0.005
+
+// SimulatedUser is one user whose eligibility or score a policy change affects.
+type SimulatedUser struct {
+	UserID     int64              `json:"user_id"`
+	ExternalID string             `json:"external_user_id"`
+	OldScore   *float64           `json:"old_score,omitempty"`
+	NewScore   *float64           `json:"new_score,omitempty"`
+	Reasons    []EligibilityCheck `json:"reasons,omitempty"`
+}
+
+// PolicySimulation is the projected effect of changing a product's terms on
+// existing users. Eligibility means passing the prefilter and the product's
+// rules; the LLM stage is not simulated.
+type PolicySimulation struct {
+	ProductID          int64           `json:"product_id"`
+	ScoringVersion     string          `json:"scoring_version"`
+	Proposed           *LoanProduct    `json:"proposed"`
+	UsersEvaluated     int             `json:"users_evaluated"`
+	EligibleBefore     int             `json:"eligible_before"`
+	EligibleAfter      int             `json:"eligible_after"`
+	Gained             int             `json:"gained"`
+	Lost               int             `json:"lost"`
+	StillEligible      int             `json:"still_eligible"`
+	ScoreChanged       int             `json:"score_changed"`
+	AvgScoreDelta      float64         `json:"avg_score_delta"`
+	SampleGained       []SimulatedUser `json:"sample_gained"`
+	SampleLost         []SimulatedUser `json:"sample_lost"`
+	SampleScoreChanged []SimulatedUser `json:"sample_score_changed"`
+}
+
+// Record adds one user's outcome to the simulation. before and after are the
+// user's scores under the current and proposed terms, nil if ineligible.
+// reasons explain why the user fails the proposed terms. At most sampleSize
+// users are kept per sample.
+func (s *PolicySimulation) Record(user *User, before, after *float64, reasons []EligibilityCheck, sampleSize int) {
+	s.UsersEvaluated++
+	if before != nil {
+		s.EligibleBefore++
+	}
+	if after != nil {
+		s.EligibleAfter++
+	}
+
+	sample := SimulatedUser{UserID: user.ID, ExternalID: user.UserID, OldScore: before, NewScore: after}
+	switch {
+	case before == nil && after != nil:
+		s.Gained++
+		if len(s.SampleGained) < sampleSize {
+			s.SampleGained = append(s.SampleGained, sample)
+		}
+	case before != nil && after == nil:
+		s.Lost++
+		if len(s.SampleLost) < sampleSize {
+			sample.Reasons = reasons
+			s.SampleLost = append(s.SampleLost, sample)
+		}
+	case before != nil && after != nil:
+		delta := *after - *before
+		s.StillEligible++
+		s.AvgScoreDelta += (delta - s.AvgScoreDelta) / float64(s.StillEligible)
+		if math.Abs(delta) > simulationScoreTolerance {
+			s.ScoreChanged++
+			if len(s.SampleScoreChanged) < sampleSize {
+				s.SampleScoreChanged = append(s.SampleScoreChanged, sample)
+			}
+		}
+	}
+}
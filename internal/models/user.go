@@ -50,6 +50,21 @@ type User struct {
 	CreatedAt        time.Time        `json:"created_at" db:"created_at"`
 	UpdatedAt        time.Time        `json:"updated_at" db:"updated_at"`
 	IsActive         bool             `json:"is_active" db:"is_active"`
+
+	// Existing obligations, zero when the upload did not include them
+	ExistingEMI           float64 `json:"existing_emi" db:"existing_emi"`
+	CreditCardOutstanding float64 `json:"credit_card_outstanding" db:"credit_card_outstanding"`
+	ActiveLoans           int     `json:"active_loans" db:"active_loans"`
+
+	// What the user asked for, zero or empty when not stated
+	RequestedAmount       float64         `json:"requested_amount" db:"requested_amount"`
+	RequestedTenureMonths int             `json:"requested_tenure_months" db:"requested_tenure_months"`
+	LoanPurpose           LoanProductType `json:"loan_purpose,omitempty" db:"loan_purpose"`
+
+	// Currency of the user's amounts, and the locale they are shown in;
+	// an empty locale uses the currency's default
+	Currency Currency `json:"currency" db:"currency"`
+	Locale   string   `json:"locale,omitempty" db:"locale"`
 }
 
 // UserCreate represents the data needed to create a new user.
@@ -61,6 +76,17 @@ type UserCreate struct {
 	EmploymentStatus EmploymentStatus `json:"employment_status" validate:"required"`
 	Age              int              `json:"age" validate:"required,gte=18,lte=120"`
 	BatchID          string           `json:"batch_id,omitempty"`
+
+	ExistingEMI           float64 `json:"existing_emi,omitempty" validate:"gte=0"`
+	CreditCardOutstanding float64 `json:"credit_card_outstanding,omitempty" validate:"gte=0"`
+	ActiveLoans           int     `json:"active_loans,omitempty" validate:"gte=0"`
+
+	RequestedAmount       float64         `json:"requested_amount,omitempty" validate:"gte=0"`
+	RequestedTenureMonths int             `json:"requested_tenure_months,omitempty" validate:"gte=0"`
+	LoanPurpose           LoanProductType `json:"loan_purpose,omitempty"`
+
+	Currency Currency `json:"currency,omitempty"`
+	Locale   string   `json:"locale,omitempty"`
 }
 
 // UserSummary is a lightweight view of user for matching operations.
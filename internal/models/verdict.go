@@ -0,0 +1,20 @@
+// Package models defines the data structures for the loan eligibility engine.
+package models
+
+import (
+	"time"
+)
+
+// LLMVerdict is a cached stage 3 verdict for a user profile and product terms.
+type LLMVerdict struct {
+	CacheKey      string    `json:"cache_key" db:"cache_key"`
+	ProductID     int64     `json:"product_id" db:"product_id"`
+	Provider      string    `json:"provider" db:"provider"`
+	PromptVersion int       `json:"prompt_version" db:"prompt_version"`
+	Qualified     bool      `json:"qualified" db:"qualified"`
+	Confidence    float64   `json:"confidence" db:"confidence"`
+	Reasoning     string    `json:"reasoning" db:"reasoning"`
+	RiskFactors   []string  `json:"risk_factors" db:"risk_factors"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	ExpiresAt     time.Time `json:"expires_at" db:"expires_at"`
+}
@@ -0,0 +1,156 @@
+// Package affordability computes what a user can afford on a loan product:
+// the EMI range, the fixed obligations-to-income ratio (FOIR) and the largest
+// amount a lender would sanction.
+package affordability
+
+import (
+	"math"
+
+	"loan-eligibility-engine/internal/models"
+)
+
+// DefaultTenureMonths is assumed when a product does not specify a tenure
+const DefaultTenureMonths = 60
+
+// DefaultMaxFOIR is the share of monthly income that EMIs may take up when
+// no other limit is configured
+const DefaultMaxFOIR = 0.5
+
+// CardOutstandingShare is the share of credit card outstanding counted as a
+// monthly obligation, the usual minimum due
+const CardOutstandingShare = 0.05
+
+// maxReportedFOIR caps the FOIR in an Assessment so that it stays finite for
+// users without income
+const maxReportedFOIR = 99
+
+// EMI computes the equated monthly instalment for a principal at an annual
+// rate (in percent) over a tenure in months
+func EMI(principal, annualRate float64, months int) float64 {
+	if months <= 0 {
+		return 0
+	}
+	r := annualRate / 100 / 12
+	n := float64(months)
+	if r == 0 {
+		return principal / n
+	}
+	factor := math.Pow(1+r, n)
+	return principal * r * factor / (factor - 1)
+}
+
+// Principal is the inverse of EMI: the largest principal whose EMI at an
+// annual rate (in percent) over a tenure in months does not exceed emi
+func Principal(emi, annualRate float64, months int) float64 {
+	if months <= 0 || emi <= 0 {
+		return 0
+	}
+	r := annualRate / 100 / 12
+	n := float64(months)
+	if r == 0 {
+		return emi * n
+	}
+	factor := math.Pow(1+r, n)
+	return emi * (factor - 1) / (r * factor)
+}
+
+// Tenures returns the product's tenure range in months, falling back to
+// DefaultTenureMonths for a missing bound
+func Tenures(product *models.LoanProduct) (minMonths, maxMonths int) {
+	minMonths, maxMonths = product.TenureMinMonths, product.TenureMaxMonths
+	if maxMonths <= 0 {
+		maxMonths = DefaultTenureMonths
+	}
+	if minMonths <= 0 || minMonths > maxMonths {
+		minMonths = maxMonths
+	}
+	return minMonths, maxMonths
+}
+
+// Obligations returns the user's existing monthly obligations: EMIs on
+// running loans plus CardOutstandingShare of credit card outstanding
+func Obligations(user *models.User) float64 {
+	return user.ExistingEMI + user.CreditCardOutstanding*CardOutstandingShare
+}
+
+// ExistingFOIR returns the share of monthly income already taken by existing
+// obligations. It is +Inf for a user with obligations but no income.
+func ExistingFOIR(user *models.User) float64 {
+	return ratio(Obligations(user), user.MonthlyIncome)
+}
+
+// FOIR returns the share of monthly income taken by existing obligations plus
+// the EMI on the product's minimum amount at its maximum rate over its longest
+// tenure. It is +Inf for a user without income.
+func FOIR(user *models.User, product *models.LoanProduct) float64 {
+	_, maxTenure := Tenures(product)
+	emi := EMI(product.LoanAmountMin, product.InterestRateMax, maxTenure)
+	return ratio(Obligations(user)+emi, user.MonthlyIncome)
+}
+
+// MaxFOIR returns the FOIR limit for a product: its own MaxFOIR when set,
+// otherwise fallback, or DefaultMaxFOIR when fallback is not positive
+func MaxFOIR(product *models.LoanProduct, fallback float64) float64 {
+	if product.MaxFOIR != nil && *product.MaxFOIR > 0 {
+		return *product.MaxFOIR
+	}
+	if fallback > 0 {
+		return fallback
+	}
+	return DefaultMaxFOIR
+}
+
+// Assess computes the affordability of a product for a user. The share of
+// income that existing obligations and the new EMI may take together is the
+// product's MaxFOIR, or maxFOIR for products without one; a non-positive
+// maxFOIR uses DefaultMaxFOIR.
+//
+// The maximum eligible amount is the largest principal whose EMI at the
+// product's maximum rate over its longest tenure fits within the income left
+// under that limit after existing obligations, capped at LoanAmountMax. It is
+// zero when even LoanAmountMin is unaffordable. The EMI range is quoted on
+// that amount, or on LoanAmountMin when nothing is affordable: EMIMin at the
+// minimum rate over the longest tenure, EMIMax at the maximum rate over the
+// shortest tenure.
+func Assess(user *models.User, product *models.LoanProduct, maxFOIR float64) models.Affordability {
+	maxFOIR = MaxFOIR(product, maxFOIR)
+	minTenure, maxTenure := Tenures(product)
+
+	available := user.MonthlyIncome*maxFOIR - Obligations(user)
+	eligible := Principal(available, product.InterestRateMax, maxTenure)
+	if product.LoanAmountMax > 0 && eligible > product.LoanAmountMax {
+		eligible = product.LoanAmountMax
+	}
+	if eligible < product.LoanAmountMin {
+		eligible = 0
+	}
+	eligible = math.Floor(eligible)
+
+	quoted := eligible
+	if quoted == 0 {
+		quoted = product.LoanAmountMin
+	}
+
+	return models.Affordability{
+		EMIMin:            roundRupees(EMI(quoted, product.InterestRateMin, maxTenure)),
+		EMIMax:            roundRupees(EMI(quoted, product.InterestRateMax, minTenure)),
+		FOIR:              math.Min(FOIR(user, product), maxReportedFOIR),
+		MaxEligibleAmount: eligible,
+	}
+}
+
+// ratio divides, returning +Inf for a positive amount over a zero base
+func ratio(amount, base float64) float64 {
+	if base > 0 {
+		return amount / base
+	}
+	if amount > 0 {
+		return math.Inf(1)
+	}
+	return 0
+}
+
+// roundRupees rounds to two decimal places
+func roundRupees(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,287 @@
+// Package crawler reads loan products from the provider pages registered in
+// crawler_sources. Each source's extraction rules map page content to product
+// fields through CSS-like selectors and regexes; pages are fetched politely,
+// products are saved through the product repository and every run is
+// recorded in crawler_runs.
+package crawler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+
+	"loan-eligibility-engine/internal/config"
+	"loan-eligibility-engine/internal/models"
+	"loan-eligibility-engine/internal/services/database"
+	"loan-eligibility-engine/internal/utils"
+)
+
+// ErrUnknownSource is returned when a crawl names a source that is not
+// registered.
+var ErrUnknownSource = errors.New("unknown crawler source")
+
+// Crawler crawls the registered sources and saves their products.
+type Crawler struct {
+	sources  *database.CrawlerRepository
+	products *database.ProductRepository
+	slabs    *database.RateSlabRepository
+	options  FetcherOptions
+}
+
+// New creates a crawler that fetches pages as configured
+func New(db *database.DB, cfg *config.Config) *Crawler {
+	return &Crawler{
+		sources:  database.NewCrawlerRepository(db),
+		products: database.NewProductRepository(db),
+		slabs:    database.NewRateSlabRepository(db),
+		options:  OptionsFromConfig(cfg),
+	}
+}
+
+// OptionsFromConfig returns the fetcher options set in the configuration
+func OptionsFromConfig(cfg *config.Config) FetcherOptions {
+	return FetcherOptions{
+		UserAgent:         cfg.CrawlerUserAgent,
+		Timeout:           time.Duration(cfg.CrawlerTimeoutSeconds) * time.Second,
+		RequestsPerMinute: cfg.CrawlerRequestsPerMinute,
+		MaxPageBytes:      int64(cfg.CrawlerMaxPageKB) << 10,
+	}
+}
+
+// Crawl fetches a source's page and extracts its products without saving
+// them. Products that fail extraction are returned as errors; an error means
+// the page could not be fetched or held no products.
+func Crawl(ctx context.Context, fetcher *Fetcher, source *models.CrawlerSource) ([]*models.CrawledProduct, []error, error) {
+	rules, err := CompileRules(source.Rules)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid extraction rules: %w", err)
+	}
+
+	page, err := fetcher.Fetch(ctx, source.URL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	products, productErrors, err := rules.Extract(source, page)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(products) == 0 && len(productErrors) == 0 {
+		return nil, nil, errors.New("no products found")
+	}
+	return products, productErrors, nil
+}
+
+// Run crawls one source by name, active or not, or every active source when
+// name is empty, and records the run. Sources are crawled one at a time with
+// a fresh fetcher, so robots.txt is re-read on every run. The run is
+// recorded even if the context is cancelled part-way.
+func (c *Crawler) Run(ctx context.Context, name string) (*models.CrawlerRun, error) {
+	var sources []*models.CrawlerSource
+	if name != "" {
+		source, err := c.sources.GetSource(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if source == nil {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownSource, name)
+		}
+		sources = append(sources, source)
+	} else {
+		var err error
+		if sources, err = c.sources.ListSources(ctx, true); err != nil {
+			return nil, err
+		}
+	}
+
+	run := &models.CrawlerRun{
+		SourceName: name,
+		StartedAt:  time.Now().UTC(),
+	}
+	if err := c.sources.CreateRun(ctx, run); err != nil {
+		return nil, err
+	}
+
+	utils.Logger.Info("Starting crawler run",
+		zap.Int64("run_id", run.ID),
+		zap.Int("sources", len(sources)),
+	)
+
+	var runErr error
+	fetcher := NewFetcher(c.options)
+	for _, source := range sources {
+		if runErr = ctx.Err(); runErr != nil {
+			break
+		}
+
+		result := c.crawlSource(ctx, fetcher, source)
+		run.Sources = append(run.Sources, result)
+		run.SourcesCrawled++
+		if result.Status == models.CrawlerRunStatusFailed {
+			run.SourcesFailed++
+		}
+		run.ProductsFound += result.Products
+		run.ProductsAdded += result.Added
+		run.ProductsUpdated += result.Updated
+		run.ProductsFailed += result.Failed
+	}
+
+	switch {
+	case runErr != nil:
+		run.Status = models.CrawlerRunStatusFailed
+		run.Error = runErr.Error()
+	case run.SourcesCrawled > 0 && run.SourcesFailed == run.SourcesCrawled:
+		run.Status = models.CrawlerRunStatusFailed
+		run.Error = "every source failed"
+	case run.SourcesFailed > 0 || run.ProductsFailed > 0:
+		run.Status = models.CrawlerRunStatusPartial
+	default:
+		run.Status = models.CrawlerRunStatusCompleted
+	}
+
+	if err := c.sources.CompleteRun(context.WithoutCancel(ctx), run); err != nil {
+		return run, err
+	}
+
+	utils.Logger.Info("Crawler run complete",
+		zap.Int64("run_id", run.ID),
+		zap.String("status", string(run.Status)),
+		zap.Int("products_found", run.ProductsFound),
+		zap.Int("products_added", run.ProductsAdded),
+		zap.Int("products_updated", run.ProductsUpdated),
+		zap.Int("products_failed", run.ProductsFailed),
+	)
+
+	return run, runErr
+}
+
+// crawlSource crawls one source, saves its products and records the outcome
+// on the source
+func (c *Crawler) crawlSource(ctx context.Context, fetcher *Fetcher, source *models.CrawlerSource) models.CrawlerSourceResult {
+	result := models.CrawlerSourceResult{
+		Source: source.Name,
+		URL:    source.URL,
+		Status: models.CrawlerRunStatusCompleted,
+	}
+
+	products, productErrors, err := Crawl(ctx, fetcher, source)
+	if err != nil {
+		result.Status = models.CrawlerRunStatusFailed
+		result.Errors = []string{err.Error()}
+	}
+
+	result.Products = len(products) + len(productErrors)
+	result.Failed = len(productErrors)
+	for _, err := range productErrors {
+		result.Errors = append(result.Errors, err.Error())
+	}
+
+	for _, product := range products {
+		inserted, updated, err := c.save(ctx, product)
+		switch {
+		case err != nil:
+			result.Failed++
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", product.ProductName, err))
+		case inserted:
+			result.Added++
+		case updated:
+			result.Updated++
+		}
+	}
+
+	if result.Status != models.CrawlerRunStatusFailed && result.Failed > 0 {
+		result.Status = models.CrawlerRunStatusPartial
+		if result.Failed == result.Products {
+			result.Status = models.CrawlerRunStatusFailed
+		}
+	}
+
+	if result.Status != models.CrawlerRunStatusCompleted {
+		utils.Logger.Warn("Crawler source had errors",
+			zap.String("source", source.Name),
+			zap.String("status", string(result.Status)),
+			zap.Strings("errors", result.Errors),
+		)
+	}
+
+	lastError := ""
+	if len(result.Errors) > 0 {
+		lastError = result.Errors[0]
+	}
+	if err := c.sources.RecordSourceCrawl(context.WithoutCancel(ctx), source.ID, result.Status, lastError); err != nil {
+		utils.Logger.Error("Failed to record crawler source crawl", zap.String("source", source.Name), zap.Error(err))
+	}
+
+	return result
+}
+
+// save upserts a crawled product and, when the source has a rate card,
+// replaces its rate slabs if they changed. It reports whether the product
+// was new, or existed and its terms or rate slabs changed.
+func (c *Crawler) save(ctx context.Context, product *models.CrawledProduct) (inserted, updated bool, err error) {
+	saved, err := c.products.Upsert(ctx, &product.LoanProductCreate, models.ProductChangeSourceCrawler)
+	if err != nil {
+		return false, false, err
+	}
+
+	slabsChanged := false
+	if len(product.RateSlabs) > 0 {
+		current, err := c.slabs.GetByProductID(ctx, saved.ID)
+		if err != nil {
+			return false, false, err
+		}
+		if !sameSlabs(current, product.RateSlabs) {
+			if err := c.slabs.ReplaceForProduct(ctx, saved.ID, product.RateSlabs); err != nil {
+				return false, false, err
+			}
+			slabsChanged = true
+		}
+	}
+
+	return saved.Inserted, !saved.Inserted && (saved.Changed || slabsChanged), nil
+}
+
+// sameSlabs reports whether crawled rate slabs match the stored ones to the
+// cent, so an unchanged rate card is not rewritten and the product is not
+// flagged for re-matching on every crawl
+func sameSlabs(current []*models.RateSlab, crawled []*models.RateSlabCreate) bool {
+	if len(current) != len(crawled) {
+		return false
+	}
+
+	sorted := append([]*models.RateSlabCreate(nil), crawled...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].MinCreditScore != sorted[j].MinCreditScore {
+			return sorted[i].MinCreditScore < sorted[j].MinCreditScore
+		}
+		return sorted[i].MinMonthlyIncome < sorted[j].MinMonthlyIncome
+	})
+
+	for i, slab := range sorted {
+		stored := current[i]
+		if slab.MinCreditScore != stored.MinCreditScore || slab.MaxCreditScore != stored.MaxCreditScore ||
+			!sameAmount(slab.MinMonthlyIncome, stored.MinMonthlyIncome) ||
+			!sameOptional(slab.MaxMonthlyIncome, stored.MaxMonthlyIncome) ||
+			!sameAmount(slab.InterestRate, stored.InterestRate) ||
+			!sameOptional(slab.ProcessingFeePercent, stored.ProcessingFeePercent) {
+			return false
+		}
+	}
+	return true
+}
+
+func sameAmount(a, b float64) bool {
+	return math.Abs(a-b) < 0.005
+}
+
+func sameOptional(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return sameAmount(*a, *b)
+}
@@ -0,0 +1,426 @@
+package crawler
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+
+	"loan-eligibility-engine/internal/models"
+)
+
+// fieldKind is how an extracted field's text is converted
+type fieldKind int
+
+const (
+	kindText fieldKind = iota
+	kindNumber
+	kindInteger
+	kindList
+)
+
+// productFields are the LoanProductCreate fields extraction rules can set,
+// by JSON name
+var productFields = map[string]fieldKind{
+	"product_name":               kindText,
+	"interest_rate_min":          kindNumber,
+	"interest_rate_max":          kindNumber,
+	"loan_amount_min":            kindNumber,
+	"loan_amount_max":            kindNumber,
+	"tenure_min_months":          kindInteger,
+	"tenure_max_months":          kindInteger,
+	"min_monthly_income":         kindNumber,
+	"min_credit_score":           kindInteger,
+	"max_credit_score":           kindInteger,
+	"min_age":                    kindInteger,
+	"max_age":                    kindInteger,
+	"accepted_employment_status": kindList,
+	"processing_fee_percent":     kindNumber,
+	"max_foir":                   kindNumber,
+}
+
+// numberPattern finds the first number in text, with an optional thousand,
+// lakh or crore suffix
+var numberPattern = regexp.MustCompile(`(?i)(\d+(?:,\d+)*(?:\.\d+)?|\.\d+)(?:\s*(thousand|k|lakhs?|lacs?|l|crores?|cr)\b)?`)
+
+// numberScales are the multipliers of numberPattern's suffixes
+var numberScales = map[string]float64{
+	"thousand": 1e3, "k": 1e3,
+	"lakh": 1e5, "lakhs": 1e5, "lac": 1e5, "lacs": 1e5, "l": 1e5,
+	"crore": 1e7, "crores": 1e7, "cr": 1e7,
+}
+
+// listSeparator splits a list field's text into items
+var listSeparator = regexp.MustCompile(`(?i)\s*(?:,|;|\||/|\band\b|\bor\b|&)\s*`)
+
+// Rules are a source's compiled extraction rules.
+type Rules struct {
+	products *Selector
+	fields   map[string]*fieldExtractor
+	slabs    []slabExtractor
+}
+
+type fieldExtractor struct {
+	rule     models.FieldRule
+	selector *Selector
+	regex    *regexp.Regexp
+}
+
+type slabExtractor struct {
+	rule models.RateSlabRule
+	rate *fieldExtractor
+	fee  *fieldExtractor
+}
+
+// CompileRules checks and compiles extraction rules: every field must be a
+// known product field with a valid selector and regex, or a default.
+func CompileRules(rules models.ExtractionRules) (*Rules, error) {
+	compiled := &Rules{fields: make(map[string]*fieldExtractor)}
+	if rules.Products != "" {
+		sel, err := CompileSelector(rules.Products)
+		if err != nil {
+			return nil, fmt.Errorf("products: %w", err)
+		}
+		compiled.products = sel
+	}
+
+	if len(rules.Fields) == 0 {
+		return nil, errors.New("rules define no fields")
+	}
+	for name, rule := range rules.Fields {
+		if _, ok := productFields[name]; !ok {
+			return nil, fmt.Errorf("unknown field %q", name)
+		}
+		field, err := compileField(rule)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		compiled.fields[name] = field
+	}
+
+	for i, rule := range rules.RateSlabs {
+		slab := slabExtractor{rule: rule}
+		var err error
+		if slab.rate, err = compileField(rule.InterestRate); err != nil {
+			return nil, fmt.Errorf("rate slab %d interest_rate: %w", i+1, err)
+		}
+		if rule.ProcessingFeePercent != nil {
+			if slab.fee, err = compileField(*rule.ProcessingFeePercent); err != nil {
+				return nil, fmt.Errorf("rate slab %d processing_fee_percent: %w", i+1, err)
+			}
+		}
+		compiled.slabs = append(compiled.slabs, slab)
+	}
+
+	return compiled, nil
+}
+
+func compileField(rule models.FieldRule) (*fieldExtractor, error) {
+	field := &fieldExtractor{rule: rule}
+	if rule.Selector == "" && rule.Regex == "" && rule.Default == "" {
+		return nil, errors.New("needs a selector, a regex or a default")
+	}
+	if rule.Selector != "" {
+		sel, err := CompileSelector(rule.Selector)
+		if err != nil {
+			return nil, err
+		}
+		field.selector = sel
+	}
+	if rule.Regex != "" {
+		re, err := regexp.Compile(rule.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex: %w", err)
+		}
+		if rule.Group < 0 || rule.Group > re.NumSubexp() {
+			return nil, fmt.Errorf("regex has no group %d", rule.Group)
+		}
+		field.regex = re
+	}
+	if rule.Scale < 0 {
+		return nil, errors.New("scale cannot be negative")
+	}
+	return field, nil
+}
+
+// Extract reads a source's products from a page. Products whose fields do
+// not match or do not validate are returned as errors, so one bad product
+// does not hide the rest; a page with no products at all is an error.
+func (r *Rules) Extract(source *models.CrawlerSource, page []byte) ([]*models.CrawledProduct, []error, error) {
+	doc, err := ParseHTML(string(page))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	scopes := []*html.Node{doc}
+	if r.products != nil {
+		scopes = r.products.MatchAll(doc)
+		if len(scopes) == 0 {
+			return nil, nil, fmt.Errorf("no elements match products selector")
+		}
+	}
+
+	var products []*models.CrawledProduct
+	var productErrors []error
+	for i, scope := range scopes {
+		product, err := r.extractProduct(source, scope)
+		if err != nil {
+			productErrors = append(productErrors, fmt.Errorf("product %d: %w", i+1, err))
+			continue
+		}
+		products = append(products, product)
+	}
+	return products, productErrors, nil
+}
+
+// extractProduct reads one product from its element. The source provides
+// the provider, product type and currency, and a name when the rules do not.
+func (r *Rules) extractProduct(source *models.CrawlerSource, scope *html.Node) (*models.CrawledProduct, error) {
+	product := &models.CrawledProduct{
+		LoanProductCreate: models.LoanProductCreate{
+			ProviderName: source.ProviderName,
+			ProductType:  source.ProductType,
+			Currency:     source.Currency,
+			SourceURL:    source.URL,
+		},
+	}
+	if product.ProductType == "" {
+		product.ProductType = models.LoanProductTypePersonal
+	}
+
+	// Extract fields in a fixed order so errors are reported consistently
+	names := make([]string, 0, len(r.fields))
+	for name := range r.fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var problems []string
+	for _, name := range names {
+		if err := r.fields[name].apply(&product.LoanProductCreate, name, scope); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+	if len(problems) > 0 {
+		return nil, errors.New(strings.Join(problems, "; "))
+	}
+
+	if product.ProductName == "" {
+		product.ProductName = fmt.Sprintf("%s %s Loan", product.ProviderName, titleCase(string(product.ProductType)))
+	}
+	if _, ok := r.fields["interest_rate_max"]; !ok {
+		product.InterestRateMax = product.InterestRateMin
+	}
+	if err := models.ValidateLoanProduct(&product.LoanProductCreate); err != nil {
+		return nil, fmt.Errorf("%s: %w", product.ProductName, err)
+	}
+
+	for i, slab := range r.slabs {
+		extracted, err := slab.extract(scope)
+		if err != nil {
+			return nil, fmt.Errorf("%s: rate slab %d: %w", product.ProductName, i+1, err)
+		}
+		product.RateSlabs = append(product.RateSlabs, extracted)
+	}
+
+	return product, nil
+}
+
+// apply extracts the field and sets it on the product
+func (f *fieldExtractor) apply(product *models.LoanProductCreate, name string, scope *html.Node) error {
+	kind := productFields[name]
+	texts := f.texts(scope, kind == kindList)
+	if len(texts) == 0 {
+		return errors.New("no match")
+	}
+
+	switch kind {
+	case kindText:
+		product.ProductName = texts[0]
+	case kindList:
+		seen := make(map[models.EmploymentStatus]bool)
+		for _, text := range texts {
+			for _, item := range listSeparator.Split(text, -1) {
+				if item = strings.TrimSpace(item); item == "" {
+					continue
+				}
+				status := models.NormalizeEmploymentStatus(item)
+				if !status.IsValid() {
+					return fmt.Errorf("unknown employment status %q", item)
+				}
+				if !seen[status] {
+					seen[status] = true
+					product.AcceptedEmploymentStatus = append(product.AcceptedEmploymentStatus, status)
+				}
+			}
+		}
+	default:
+		value, err := f.number(texts[0])
+		if err != nil {
+			return err
+		}
+		setNumber(product, name, value)
+	}
+	return nil
+}
+
+// texts returns the field's extracted text: that of the first matching
+// element, or of every matching element for a list, falling back to the
+// rule's default
+func (f *fieldExtractor) texts(scope *html.Node, all bool) []string {
+	if f.selector == nil && f.regex == nil {
+		return []string{f.rule.Default}
+	}
+
+	var sources []string
+	if f.selector == nil {
+		sources = []string{Text(scope)}
+	} else {
+		for _, n := range f.selector.MatchAll(scope) {
+			text := Text(n)
+			if f.rule.Attr != "" {
+				text, _ = Attr(n, f.rule.Attr)
+				text = strings.TrimSpace(text)
+			}
+			sources = append(sources, text)
+			if !all {
+				break
+			}
+		}
+	}
+
+	var texts []string
+	for _, text := range sources {
+		if f.regex != nil {
+			match := f.regex.FindStringSubmatch(text)
+			if match == nil {
+				continue
+			}
+			group := f.rule.Group
+			if group == 0 && len(match) > 1 {
+				group = 1
+			}
+			text = strings.TrimSpace(match[group])
+		}
+		if text != "" {
+			texts = append(texts, text)
+		}
+	}
+
+	if len(texts) == 0 && f.rule.Default != "" {
+		return []string{f.rule.Default}
+	}
+	return texts
+}
+
+// number parses the first number in text and applies the rule's scale
+func (f *fieldExtractor) number(text string) (float64, error) {
+	value, err := ParseNumber(text)
+	if err != nil {
+		return 0, err
+	}
+	if f.rule.Scale > 0 {
+		value *= f.rule.Scale
+	}
+	return value, nil
+}
+
+// extract reads one rate slab
+func (s slabExtractor) extract(scope *html.Node) (*models.RateSlabCreate, error) {
+	slab := &models.RateSlabCreate{
+		MinCreditScore: s.rule.MinCreditScore,
+		MaxCreditScore: s.rule.MaxCreditScore,
+	}
+
+	texts := s.rate.texts(scope, false)
+	if len(texts) == 0 {
+		return nil, errors.New("interest_rate: no match")
+	}
+	rate, err := s.rate.number(texts[0])
+	if err != nil {
+		return nil, fmt.Errorf("interest_rate: %w", err)
+	}
+	slab.InterestRate = rate
+
+	if s.fee != nil {
+		texts := s.fee.texts(scope, false)
+		if len(texts) == 0 {
+			return nil, errors.New("processing_fee_percent: no match")
+		}
+		fee, err := s.fee.number(texts[0])
+		if err != nil {
+			return nil, fmt.Errorf("processing_fee_percent: %w", err)
+		}
+		slab.ProcessingFeePercent = &fee
+	}
+
+	if err := models.ValidateRateSlab(slab); err != nil {
+		return nil, err
+	}
+	return slab, nil
+}
+
+// setNumber sets a numeric product field by JSON name
+func setNumber(p *models.LoanProductCreate, name string, value float64) {
+	whole := int(math.Round(value))
+	switch name {
+	case "interest_rate_min":
+		p.InterestRateMin = value
+	case "interest_rate_max":
+		p.InterestRateMax = value
+	case "loan_amount_min":
+		p.LoanAmountMin = value
+	case "loan_amount_max":
+		p.LoanAmountMax = value
+	case "tenure_min_months":
+		p.TenureMinMonths = whole
+	case "tenure_max_months":
+		p.TenureMaxMonths = whole
+	case "min_monthly_income":
+		p.MinMonthlyIncome = math.Round(value*100) / 100
+	case "min_credit_score":
+		p.MinCreditScore = whole
+	case "max_credit_score":
+		p.MaxCreditScore = &whole
+	case "min_age":
+		p.MinAge = whole
+	case "max_age":
+		p.MaxAge = whole
+	case "processing_fee_percent":
+		p.ProcessingFeePercent = &value
+	case "max_foir":
+		p.MaxFOIR = &value
+	}
+}
+
+// ParseNumber parses the first number in text, such as "10.50% p.a.",
+// "₹40,00,000", "Rs. 40 lakh" or "1 crore", ignoring thousands separators
+// and applying thousand, lakh and crore suffixes.
+func ParseNumber(text string) (float64, error) {
+	match := numberPattern.FindStringSubmatch(text)
+	if match == nil {
+		return 0, fmt.Errorf("no number in %q", text)
+	}
+
+	value, err := strconv.ParseFloat(strings.ReplaceAll(match[1], ",", ""), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q: %w", match[1], err)
+	}
+	if scale, ok := numberScales[strings.ToLower(match[2])]; ok {
+		value *= scale
+	}
+	return value, nil
+}
+
+// titleCase capitalizes the first letter of a word
+func titleCase(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
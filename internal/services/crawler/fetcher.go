@@ -0,0 +1,212 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/temoto/robotstxt"
+
+	"loan-eligibility-engine/internal/utils"
+)
+
+// ErrDisallowed is returned when robots.txt forbids fetching a URL.
+var ErrDisallowed = errors.New("disallowed by robots.txt")
+
+// maxRedirects is the number of redirects followed for one page
+const maxRedirects = 5
+
+// maxRobotsBytes caps the robots.txt read per host; rules past it are ignored
+const maxRobotsBytes = 512 << 10
+
+// FetcherOptions configures a Fetcher.
+type FetcherOptions struct {
+	UserAgent         string
+	Timeout           time.Duration // per request, including reading the body
+	RequestsPerMinute int           // per host, or unlimited if not positive; robots.txt may ask for fewer
+	MaxPageBytes      int64
+}
+
+// Fetcher fetches pages politely: it identifies itself, obeys each host's
+// robots.txt, spaces requests to a host by the configured rate or the host's
+// crawl delay if longer, and bounds each request's time and size. A host's
+// robots.txt is read once per Fetcher, so a Fetcher should live for one crawl.
+type Fetcher struct {
+	client  *http.Client
+	robots  *http.Client
+	options FetcherOptions
+
+	mu    sync.Mutex
+	hosts map[string]*host
+}
+
+// host is the robots.txt rules and rate limiter of one scheme and host
+type host struct {
+	once    sync.Once
+	rules   *robotstxt.Group // the group that applies to the user agent
+	err     error
+	limiter *utils.TokenBucket
+}
+
+// NewFetcher creates a fetcher
+func NewFetcher(options FetcherOptions) *Fetcher {
+	if options.UserAgent == "" {
+		options.UserAgent = "LoanEligibilityBot/1.0"
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = 30 * time.Second
+	}
+	if options.MaxPageBytes <= 0 {
+		options.MaxPageBytes = 5 << 20
+	}
+	f := &Fetcher{
+		options: options,
+		hosts:   make(map[string]*host),
+		robots:  &http.Client{Timeout: options.Timeout},
+	}
+	f.client = &http.Client{
+		Timeout: options.Timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			return f.wait(req.Context(), req.URL)
+		},
+	}
+	return f
+}
+
+// Fetch returns the body of a page. Anything but a 200 response is an error.
+func (f *Fetcher) Fetch(ctx context.Context, pageURL string) ([]byte, error) {
+	u, err := url.Parse(pageURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid page URL %q", pageURL)
+	}
+
+	if err := f.wait(ctx, u); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, f.options.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", f.options.UserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.5")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", pageURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: %s", pageURL, resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, f.options.MaxPageBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", pageURL, err)
+	}
+	if int64(len(body)) > f.options.MaxPageBytes {
+		return nil, fmt.Errorf("page %s is larger than %d bytes", pageURL, f.options.MaxPageBytes)
+	}
+	return body, nil
+}
+
+// wait checks that robots.txt allows the URL and waits for the host's rate
+// limit
+func (f *Fetcher) wait(ctx context.Context, u *url.URL) error {
+	h := f.host(ctx, u)
+	if h.err != nil {
+		return h.err
+	}
+
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	if !h.rules.Test(path) {
+		return fmt.Errorf("%s: %w", u.Redacted(), ErrDisallowed)
+	}
+
+	return h.limiter.Wait(ctx)
+}
+
+// host returns a URL's host, reading its robots.txt on first use
+func (f *Fetcher) host(ctx context.Context, u *url.URL) *host {
+	key := u.Scheme + "://" + u.Host
+	f.mu.Lock()
+	h, ok := f.hosts[key]
+	if !ok {
+		h = &host{}
+		f.hosts[key] = h
+	}
+	f.mu.Unlock()
+
+	h.once.Do(func() {
+		h.rules, h.err = f.readRobots(ctx, key)
+		perMinute := f.options.RequestsPerMinute
+		if h.err == nil {
+			if delay := h.rules.CrawlDelay; delay > 0 {
+				delayed := max(1, int(time.Minute/delay))
+				if perMinute <= 0 || delayed < perMinute {
+					perMinute = delayed
+				}
+			}
+		}
+		h.limiter = utils.NewTokenBucket(perMinute, 1)
+	})
+	return h
+}
+
+// readRobots fetches a host's robots.txt and returns the group of rules for
+// the user agent. A missing file, or any other 4xx response, allows
+// everything; a server error, an unreachable host or a file that does not
+// parse makes the host off limits for this crawl, since its rules are unknown.
+func (f *Fetcher) readRobots(ctx context.Context, origin string) (*robotstxt.Group, error) {
+	ctx, cancel := context.WithTimeout(ctx, f.options.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, origin+"/robots.txt", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create robots.txt request: %w", err)
+	}
+	req.Header.Set("User-Agent", f.options.UserAgent)
+
+	resp, err := f.robots.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s/robots.txt: %w", origin, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 500:
+		return nil, fmt.Errorf("failed to fetch %s/robots.txt: %s", origin, resp.Status)
+	case resp.StatusCode >= 400:
+		return &robotstxt.Group{}, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("failed to fetch %s/robots.txt: %s", origin, resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxRobotsBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s/robots.txt: %w", origin, err)
+	}
+	robots, err := robotstxt.FromBytes(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s/robots.txt: %w", origin, err)
+	}
+	return robots.FindGroup(f.options.UserAgent), nil
+}
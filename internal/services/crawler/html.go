@@ -0,0 +1,76 @@
+package crawler
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// inlineElements do not separate their text from their neighbours'
+var inlineElements = map[string]bool{
+	"a": true, "abbr": true, "b": true, "bdi": true, "bdo": true, "cite": true, "code": true,
+	"data": true, "dfn": true, "em": true, "i": true, "kbd": true, "label": true, "mark": true,
+	"q": true, "s": true, "samp": true, "small": true, "span": true, "strong": true, "sub": true,
+	"sup": true, "time": true, "u": true, "var": true,
+}
+
+// hiddenElements hold no visible text
+var hiddenElements = map[string]bool{
+	"script": true, "style": true, "noscript": true, "template": true,
+}
+
+// ParseHTML parses an HTML document the way browsers do. It does not run
+// scripts, so content rendered by JavaScript is not seen.
+func ParseHTML(doc string) (*html.Node, error) {
+	root, err := html.Parse(strings.NewReader(doc))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+	return root, nil
+}
+
+// Text returns a node's visible text with whitespace collapsed. Block
+// elements are separated by a space, so adjacent table cells do not run
+// together; scripts, styles and comments are left out.
+func Text(n *html.Node) string {
+	var b strings.Builder
+	writeText(n, &b)
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+func writeText(n *html.Node, b *strings.Builder) {
+	switch n.Type {
+	case html.TextNode:
+		b.WriteString(n.Data)
+		return
+	case html.ElementNode:
+		if hiddenElements[n.Data] {
+			return
+		}
+	case html.DocumentNode:
+	default:
+		return
+	}
+
+	block := n.Type == html.ElementNode && !inlineElements[n.Data]
+	if block {
+		b.WriteByte(' ')
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		writeText(child, b)
+	}
+	if block {
+		b.WriteByte(' ')
+	}
+}
+
+// Attr returns an attribute's value and whether it is set
+func Attr(n *html.Node, name string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Namespace == "" && a.Key == name {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,34 @@
+package crawler
+
+import (
+	"fmt"
+
+	"github.com/andybalholm/cascadia"
+	"golang.org/x/net/html"
+)
+
+// Selector is a compiled CSS selector group. Besides standard CSS it has
+// :contains("text"), which matches case-insensitively against an element's
+// text.
+type Selector struct {
+	group cascadia.SelectorGroup
+}
+
+// CompileSelector parses a selector
+func CompileSelector(s string) (*Selector, error) {
+	group, err := cascadia.ParseGroup(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector %q: %w", s, err)
+	}
+	return &Selector{group: group}, nil
+}
+
+// MatchAll returns the elements under root that match, in document order
+func (s *Selector) MatchAll(root *html.Node) []*html.Node {
+	return cascadia.QueryAll(root, s.group)
+}
+
+// Matches reports whether an element matches the selector
+func (s *Selector) Matches(n *html.Node) bool {
+	return s.group.Match(n)
+}
@@ -0,0 +1,304 @@
+// Package database provides database operations for the loan eligibility engine.
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"loan-eligibility-engine/internal/models"
+)
+
+// BatchRepository handles upload batch database operations.
+type BatchRepository struct {
+	db *DB
+}
+
+// NewBatchRepository creates a new batch repository.
+func NewBatchRepository(db *DB) *BatchRepository {
+	return &BatchRepository{db: db}
+}
+
+// batchColumns are the upload_batches columns read by scanBatch, followed by
+// the progress of the batch's match checkpoint
+const batchColumns = `
+	b.id, b.batch_id, b.source, COALESCE(b.s3_key, ''), COALESCE(b.file_name, ''), b.status,
+	b.total_rows, b.valid_rows, b.successful_rows, b.failed_rows, COALESCE(b.error_details, ''),
+	b.started_at, b.completed_at, b.updated_at,
+	COALESCE(c.users_processed, 0), COALESCE(c.total_users, 0)`
+
+// Create records a received upload. Recording a batch ID again is a no-op,
+// so a retried ingest keeps the original record.
+func (r *BatchRepository) Create(ctx context.Context, batch *models.UploadBatchCreate) error {
+	now := time.Now().UTC()
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO upload_batches (batch_id, source, s3_key, file_name, status, started_at, created_at, updated_at)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), $5, $6, $6, $6)
+		ON CONFLICT (batch_id) DO NOTHING`,
+		batch.BatchID, string(batch.Source), batch.S3Key, batch.FileName, string(models.BatchStatusReceived), now,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create upload batch: %w", err)
+	}
+	return nil
+}
+
+// SetStatus moves a batch to a stage. A finished status sets completed_at
+// and an unfinished one clears it, so a retried batch is in progress again.
+func (r *BatchRepository) SetStatus(ctx context.Context, batchID string, status models.BatchStatus) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE upload_batches SET
+			status = $2,
+			completed_at = CASE WHEN $3 THEN $4 ELSE NULL END
+		WHERE batch_id = $1`,
+		batchID, string(status), status.IsFinished(), time.Now().UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update upload batch status: %w", err)
+	}
+	return nil
+}
+
+// RecordParsed stores the outcome of parsing a batch's CSV and moves it to
+// inserting. Row errors beyond models.MaxBatchRowErrors are dropped.
+func (r *BatchRepository) RecordParsed(ctx context.Context, batchID string, totalRows, validRows int, rowErrors []string) error {
+	encoded, err := encodeRowErrors(rowErrors)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		UPDATE upload_batches SET
+			status = $2, total_rows = $3, valid_rows = $4, successful_rows = 0,
+			failed_rows = $3 - $4, row_errors = $5, error_details = NULL, completed_at = NULL
+		WHERE batch_id = $1`,
+		batchID, string(models.BatchStatusInserting), totalRows, validRows, encoded,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record parsed upload batch: %w", err)
+	}
+	return nil
+}
+
+// RecordSaved records how many of a batch's valid rows have been saved so far.
+func (r *BatchRepository) RecordSaved(ctx context.Context, batchID string, saved int) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE upload_batches SET successful_rows = $2 WHERE batch_id = $1", batchID, saved)
+	if err != nil {
+		return fmt.Errorf("failed to record upload batch progress: %w", err)
+	}
+	return nil
+}
+
+// FinishInsert records the rows saved, appends the errors of rows that
+// failed to save and moves the batch to next: matching, or completed when
+// nothing is left to match.
+func (r *BatchRepository) FinishInsert(ctx context.Context, batchID string, saved int, saveErrors []string, next models.BatchStatus) error {
+	encoded, err := encodeRowErrors(saveErrors)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		UPDATE upload_batches SET
+			status = $2, successful_rows = $3, failed_rows = GREATEST(total_rows - $3, 0),
+			row_errors = COALESCE((
+				SELECT jsonb_agg(e) FROM (
+					SELECT e FROM jsonb_array_elements(row_errors || $4::jsonb) e LIMIT $5
+				) capped
+			), '[]'::jsonb),
+			completed_at = CASE WHEN $6 THEN $7 ELSE NULL END
+		WHERE batch_id = $1`,
+		batchID, string(next), saved, encoded, models.MaxBatchRowErrors, next.IsFinished(), time.Now().UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record saved upload batch: %w", err)
+	}
+	return nil
+}
+
+// Fail marks a batch failed with the error that stopped it.
+func (r *BatchRepository) Fail(ctx context.Context, batchID, errMsg string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE upload_batches SET status = $2, error_details = $3, completed_at = $4
+		WHERE batch_id = $1`,
+		batchID, string(models.BatchStatusFailed), errMsg, time.Now().UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to mark upload batch failed: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves a batch with its progress, row errors and match funnel.
+// Returns nil if the batch does not exist.
+func (r *BatchRepository) Get(ctx context.Context, batchID string) (*models.UploadBatch, error) {
+	query := `
+		SELECT ` + batchColumns + `, b.row_errors
+		FROM upload_batches b
+		LEFT JOIN match_checkpoints c ON c.batch_id = b.batch_id
+		WHERE b.batch_id = $1`
+
+	var rowErrors []byte
+	batch, err := scanBatch(r.db.QueryRowContext(ctx, query, batchID), &rowErrors)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get upload batch: %w", err)
+	}
+	if err := json.Unmarshal(rowErrors, &batch.RowErrors); err != nil {
+		return nil, fmt.Errorf("failed to decode upload batch row errors: %w", err)
+	}
+
+	if batch.Funnel, err = r.funnel(ctx, batchID); err != nil {
+		return nil, err
+	}
+	return batch, nil
+}
+
+// List retrieves the most recent batches, optionally of one status, without
+// their row errors or funnels.
+func (r *BatchRepository) List(ctx context.Context, status models.BatchStatus, limit int) ([]*models.UploadBatch, error) {
+	query := `
+		SELECT ` + batchColumns + `
+		FROM upload_batches b
+		LEFT JOIN match_checkpoints c ON c.batch_id = b.batch_id
+		WHERE $1 = '' OR b.status = $1
+		ORDER BY b.created_at DESC, b.id DESC
+		LIMIT $2`
+
+	rows, err := r.db.QueryContext(ctx, query, string(status), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query upload batches: %w", err)
+	}
+	defer rows.Close()
+
+	var batches []*models.UploadBatch
+	for rows.Next() {
+		batch, err := scanBatch(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan upload batch: %w", err)
+		}
+		batches = append(batches, batch)
+	}
+	return batches, rows.Err()
+}
+
+// funnel counts a batch's users, the stage that rejected each of their
+// rejected pairs and their matches by status
+func (r *BatchRepository) funnel(ctx context.Context, batchID string) (*models.BatchFunnel, error) {
+	funnel := &models.BatchFunnel{
+		RejectedByStage: make(map[models.MatchSource]int),
+		MatchesByStatus: make(map[models.MatchStatus]int),
+	}
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT
+			COUNT(*),
+			COUNT(*) FILTER (WHERE EXISTS (
+				SELECT 1 FROM matches m WHERE m.user_id = u.id AND m.status IN ($2, $3)
+			))
+		FROM users u
+		WHERE u.batch_id = $1`,
+		batchID, string(models.MatchStatusEligible), string(models.MatchStatusNotified),
+	).Scan(&funnel.Users, &funnel.UsersMatched)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count batch users: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT mr.stage, COUNT(*)
+		FROM match_rejections mr
+		JOIN users u ON u.id = mr.user_id
+		WHERE u.batch_id = $1
+		GROUP BY mr.stage`, batchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count batch rejections: %w", err)
+	}
+	for rows.Next() {
+		var stage string
+		var count int
+		if err := rows.Scan(&stage, &count); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan batch rejections: %w", err)
+		}
+		funnel.RejectedByStage[models.MatchSource(stage)] = count
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to count batch rejections: %w", err)
+	}
+
+	rows, err = r.db.QueryContext(ctx, `
+		SELECT m.status, COUNT(*)
+		FROM matches m
+		JOIN users u ON u.id = m.user_id
+		WHERE u.batch_id = $1
+		GROUP BY m.status`, batchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count batch matches: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan batch matches: %w", err)
+		}
+		funnel.MatchesByStatus[models.MatchStatus(status)] = count
+		funnel.Matches += count
+	}
+	return funnel, rows.Err()
+}
+
+// scanBatch scans batchColumns, and any extra destinations, into a batch
+// and computes its progress.
+func scanBatch(row pgx.Row, extra ...interface{}) (*models.UploadBatch, error) {
+	var batch models.UploadBatch
+	var source, status string
+	var usersMatched, usersToMatch int
+	dest := append([]interface{}{
+		&batch.ID,
+		&batch.BatchID,
+		&source,
+		&batch.S3Key,
+		&batch.FileName,
+		&status,
+		&batch.TotalRows,
+		&batch.ValidRows,
+		&batch.SavedRows,
+		&batch.FailedRows,
+		&batch.Error,
+		&batch.StartedAt,
+		&batch.CompletedAt,
+		&batch.UpdatedAt,
+		&usersMatched,
+		&usersToMatch,
+	}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	batch.Source = models.BatchSource(source)
+	batch.Status = models.BatchStatus(status)
+	batch.Progress = models.NewBatchProgress(&batch, usersMatched, usersToMatch)
+	return &batch, nil
+}
+
+// encodeRowErrors encodes at most models.MaxBatchRowErrors row errors
+func encodeRowErrors(rowErrors []string) (string, error) {
+	if rowErrors == nil {
+		rowErrors = []string{}
+	}
+	if len(rowErrors) > models.MaxBatchRowErrors {
+		rowErrors = rowErrors[:models.MaxBatchRowErrors]
+	}
+	encoded, err := json.Marshal(rowErrors)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode row errors: %w", err)
+	}
+	return string(encoded), nil
+}
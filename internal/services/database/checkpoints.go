@@ -0,0 +1,92 @@
+// Package database provides database operations for the loan eligibility engine.
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"loan-eligibility-engine/internal/models"
+)
+
+// CheckpointRepository handles matching checkpoint database operations.
+type CheckpointRepository struct {
+	db *DB
+}
+
+// NewCheckpointRepository creates a new checkpoint repository.
+func NewCheckpointRepository(db *DB) *CheckpointRepository {
+	return &CheckpointRepository{db: db}
+}
+
+// Get retrieves the checkpoint of a batch. Returns nil if the batch has never
+// been matched in chunks.
+func (r *CheckpointRepository) Get(ctx context.Context, batchID string) (*models.MatchCheckpoint, error) {
+	query := `
+		SELECT batch_id, status, last_user_id, users_processed, total_users,
+			   matches_saved, llm_calls, started_at, updated_at, completed_at
+		FROM match_checkpoints
+		WHERE batch_id = $1`
+
+	var cp models.MatchCheckpoint
+	var status string
+	err := r.db.QueryRowContext(ctx, query, batchID).Scan(
+		&cp.BatchID,
+		&status,
+		&cp.LastUserID,
+		&cp.UsersProcessed,
+		&cp.TotalUsers,
+		&cp.MatchesSaved,
+		&cp.LLMCalls,
+		&cp.StartedAt,
+		&cp.UpdatedAt,
+		&cp.CompletedAt,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get match checkpoint: %w", err)
+	}
+
+	cp.Status = models.CheckpointStatus(status)
+	return &cp, nil
+}
+
+// Save creates or overwrites the checkpoint of a batch.
+func (r *CheckpointRepository) Save(ctx context.Context, cp *models.MatchCheckpoint) error {
+	cp.UpdatedAt = time.Now().UTC()
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO match_checkpoints (
+			batch_id, status, last_user_id, users_processed, total_users,
+			matches_saved, llm_calls, started_at, updated_at, completed_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (batch_id) DO UPDATE SET
+			status = EXCLUDED.status,
+			last_user_id = EXCLUDED.last_user_id,
+			users_processed = EXCLUDED.users_processed,
+			total_users = EXCLUDED.total_users,
+			matches_saved = EXCLUDED.matches_saved,
+			llm_calls = EXCLUDED.llm_calls,
+			started_at = EXCLUDED.started_at,
+			updated_at = EXCLUDED.updated_at,
+			completed_at = EXCLUDED.completed_at`,
+		cp.BatchID,
+		string(cp.Status),
+		cp.LastUserID,
+		cp.UsersProcessed,
+		cp.TotalUsers,
+		cp.MatchesSaved,
+		cp.LLMCalls,
+		cp.StartedAt,
+		cp.UpdatedAt,
+		cp.CompletedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save match checkpoint: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,279 @@
+// Package database provides database operations for the loan eligibility engine.
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"loan-eligibility-engine/internal/models"
+)
+
+// CrawlerRepository handles crawler source and run database operations.
+type CrawlerRepository struct {
+	db *DB
+}
+
+// NewCrawlerRepository creates a new crawler repository.
+func NewCrawlerRepository(db *DB) *CrawlerRepository {
+	return &CrawlerRepository{db: db}
+}
+
+// sourceColumns are the crawler_sources columns read by scanSource
+const sourceColumns = `
+	id, name, url, provider_name, product_type, currency, rules, is_active,
+	last_crawled_at, COALESCE(last_status, ''), COALESCE(last_error, ''), created_at, updated_at`
+
+// runColumns are the crawler_runs columns read by scanRun
+const runColumns = `
+	id, COALESCE(source_name, ''), status, COALESCE(sources_crawled, 0), sources_failed,
+	COALESCE(products_found, 0), COALESCE(products_added, 0), COALESCE(products_updated, 0),
+	COALESCE(products_failed, 0), COALESCE(error_message, ''), COALESCE(started_at, created_at),
+	completed_at, details`
+
+// ListSources retrieves the registered sources by name, optionally only the
+// active ones.
+func (r *CrawlerRepository) ListSources(ctx context.Context, activeOnly bool) ([]*models.CrawlerSource, error) {
+	query := `SELECT ` + sourceColumns + `
+		FROM crawler_sources
+		WHERE is_active OR NOT $1
+		ORDER BY name`
+
+	rows, err := r.db.QueryContext(ctx, query, activeOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query crawler sources: %w", err)
+	}
+	defer rows.Close()
+
+	var sources []*models.CrawlerSource
+	for rows.Next() {
+		source, err := scanSource(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan crawler source: %w", err)
+		}
+		sources = append(sources, source)
+	}
+	return sources, rows.Err()
+}
+
+// GetSource retrieves a source by name. Returns nil if it does not exist.
+func (r *CrawlerRepository) GetSource(ctx context.Context, name string) (*models.CrawlerSource, error) {
+	query := `SELECT ` + sourceColumns + ` FROM crawler_sources WHERE name = $1`
+
+	source, err := scanSource(r.db.QueryRowContext(ctx, query, name))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get crawler source: %w", err)
+	}
+	return source, nil
+}
+
+// UpsertSource registers a source, or replaces the definition of the source
+// with the same name. A nil IsActive registers the source active and leaves
+// an existing source's flag unchanged.
+func (r *CrawlerRepository) UpsertSource(ctx context.Context, source *models.CrawlerSourceCreate) (*models.CrawlerSource, error) {
+	rules, err := json.Marshal(source.Rules)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode extraction rules: %w", err)
+	}
+
+	query := `
+		INSERT INTO crawler_sources (name, url, provider_name, product_type, currency, rules, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, COALESCE($7, true), $8, $8)
+		ON CONFLICT (name) DO UPDATE SET
+			url = EXCLUDED.url,
+			provider_name = EXCLUDED.provider_name,
+			product_type = EXCLUDED.product_type,
+			currency = EXCLUDED.currency,
+			rules = EXCLUDED.rules,
+			is_active = COALESCE($7, crawler_sources.is_active)
+		RETURNING ` + sourceColumns
+
+	saved, err := scanSource(r.db.QueryRowContext(ctx, query,
+		source.Name,
+		source.URL,
+		source.ProviderName,
+		string(source.ProductType),
+		string(source.Currency.OrDefault()),
+		string(rules),
+		source.IsActive,
+		time.Now().UTC(),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to upsert crawler source: %w", err)
+	}
+	return saved, nil
+}
+
+// RecordSourceCrawl records when a source was last crawled and how it went.
+func (r *CrawlerRepository) RecordSourceCrawl(ctx context.Context, sourceID int64, status models.CrawlerRunStatus, errMsg string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE crawler_sources SET last_crawled_at = $2, last_status = $3, last_error = NULLIF($4, '')
+		WHERE id = $1`,
+		sourceID, time.Now().UTC(), string(status), errMsg,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record crawler source crawl: %w", err)
+	}
+	return nil
+}
+
+// CreateRun records the start of a crawler run and sets its ID.
+func (r *CrawlerRepository) CreateRun(ctx context.Context, run *models.CrawlerRun) error {
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO crawler_runs (source_name, status, started_at, created_at)
+		VALUES (NULLIF($1, ''), $2, $3, $3)
+		RETURNING id`,
+		run.SourceName, string(models.CrawlerRunStatusRunning), run.StartedAt,
+	).Scan(&run.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create crawler run: %w", err)
+	}
+
+	run.Status = models.CrawlerRunStatusRunning
+	return nil
+}
+
+// CompleteRun stores a run's counts, per-source results and final status.
+func (r *CrawlerRepository) CompleteRun(ctx context.Context, run *models.CrawlerRun) error {
+	details, err := json.Marshal(run.Sources)
+	if err != nil {
+		return fmt.Errorf("failed to encode crawler run details: %w", err)
+	}
+	if run.Sources == nil {
+		details = []byte("[]")
+	}
+
+	now := time.Now().UTC()
+	_, err = r.db.ExecContext(ctx, `
+		UPDATE crawler_runs SET
+			status = $2, sources_crawled = $3, sources_failed = $4, products_found = $5,
+			products_added = $6, products_updated = $7, products_failed = $8,
+			error_message = NULLIF($9, ''), details = $10, completed_at = $11
+		WHERE id = $1`,
+		run.ID,
+		string(run.Status),
+		run.SourcesCrawled,
+		run.SourcesFailed,
+		run.ProductsFound,
+		run.ProductsAdded,
+		run.ProductsUpdated,
+		run.ProductsFailed,
+		run.Error,
+		string(details),
+		now,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to complete crawler run: %w", err)
+	}
+
+	run.CompletedAt = &now
+	return nil
+}
+
+// GetRun retrieves a crawler run with its per-source results. Returns nil if
+// the run does not exist.
+func (r *CrawlerRepository) GetRun(ctx context.Context, id int64) (*models.CrawlerRun, error) {
+	query := `SELECT ` + runColumns + ` FROM crawler_runs WHERE id = $1`
+
+	run, err := scanRun(r.db.QueryRowContext(ctx, query, id))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get crawler run: %w", err)
+	}
+	return run, nil
+}
+
+// ListRuns retrieves the most recent crawler runs.
+func (r *CrawlerRepository) ListRuns(ctx context.Context, limit int) ([]*models.CrawlerRun, error) {
+	query := `SELECT ` + runColumns + `
+		FROM crawler_runs
+		ORDER BY started_at DESC, id DESC
+		LIMIT $1`
+
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query crawler runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []*models.CrawlerRun
+	for rows.Next() {
+		run, err := scanRun(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan crawler run: %w", err)
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+// scanSource scans sourceColumns into a source.
+func scanSource(row pgx.Row) (*models.CrawlerSource, error) {
+	var source models.CrawlerSource
+	var productType, currency string
+	var rules []byte
+	err := row.Scan(
+		&source.ID,
+		&source.Name,
+		&source.URL,
+		&source.ProviderName,
+		&productType,
+		&currency,
+		&rules,
+		&source.IsActive,
+		&source.LastCrawledAt,
+		&source.LastStatus,
+		&source.LastError,
+		&source.CreatedAt,
+		&source.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	source.ProductType = models.LoanProductType(productType)
+	source.Currency = models.Currency(currency)
+	if err := json.Unmarshal(rules, &source.Rules); err != nil {
+		return nil, fmt.Errorf("failed to decode extraction rules of %s: %w", source.Name, err)
+	}
+	return &source, nil
+}
+
+// scanRun scans runColumns into a run.
+func scanRun(row pgx.Row) (*models.CrawlerRun, error) {
+	var run models.CrawlerRun
+	var status string
+	var details []byte
+	err := row.Scan(
+		&run.ID,
+		&run.SourceName,
+		&status,
+		&run.SourcesCrawled,
+		&run.SourcesFailed,
+		&run.ProductsFound,
+		&run.ProductsAdded,
+		&run.ProductsUpdated,
+		&run.ProductsFailed,
+		&run.Error,
+		&run.StartedAt,
+		&run.CompletedAt,
+		&details,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	run.Status = models.CrawlerRunStatus(status)
+	if err := json.Unmarshal(details, &run.Sources); err != nil {
+		return nil, fmt.Errorf("failed to decode crawler run details: %w", err)
+	}
+	return &run, nil
+}
@@ -0,0 +1,85 @@
+// Package database provides database operations for the loan eligibility engine.
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"loan-eligibility-engine/internal/models"
+)
+
+// FXRateRepository handles exchange rate database operations.
+type FXRateRepository struct {
+	db *DB
+}
+
+// NewFXRateRepository creates a new exchange rate repository.
+func NewFXRateRepository(db *DB) *FXRateRepository {
+	return &FXRateRepository{db: db}
+}
+
+// GetAll retrieves every configured exchange rate.
+func (r *FXRateRepository) GetAll(ctx context.Context) ([]*models.FXRate, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT currency, rate_to_base, updated_at
+		FROM fx_rates
+		ORDER BY currency`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query exchange rates: %w", err)
+	}
+	defer rows.Close()
+
+	var rates []*models.FXRate
+	for rows.Next() {
+		var rate models.FXRate
+		var currency string
+		if err := rows.Scan(&currency, &rate.RateToBase, &rate.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan exchange rate: %w", err)
+		}
+		rate.Currency = models.Currency(currency)
+		rates = append(rates, &rate)
+	}
+	return rates, rows.Err()
+}
+
+// Upsert stores exchange rates, leaving currencies not listed unchanged. When
+// a rate changes, every active product is flagged for re-matching, since
+// cross-currency matches of any product may depend on it. It reports whether
+// any rate changed.
+func (r *FXRateRepository) Upsert(ctx context.Context, rates []*models.FXRate) (bool, error) {
+	changed := false
+	err := r.db.WithTransaction(ctx, func(tx pgx.Tx) error {
+		now := time.Now().UTC()
+		for _, rate := range rates {
+			tag, err := tx.Exec(ctx, `
+				INSERT INTO fx_rates (currency, rate_to_base, updated_at)
+				VALUES ($1, $2, $3)
+				ON CONFLICT (currency) DO UPDATE SET
+					rate_to_base = EXCLUDED.rate_to_base,
+					updated_at = EXCLUDED.updated_at
+				WHERE fx_rates.rate_to_base <> EXCLUDED.rate_to_base`,
+				string(rate.Currency), rate.RateToBase, now)
+			if err != nil {
+				return fmt.Errorf("failed to save exchange rate for %s: %w", rate.Currency, err)
+			}
+			if tag.RowsAffected() > 0 {
+				changed = true
+			}
+		}
+
+		if !changed {
+			return nil
+		}
+		if _, err := tx.Exec(ctx, "UPDATE loan_products SET terms_changed_at = $1 WHERE is_active = true", now); err != nil {
+			return fmt.Errorf("failed to flag products for rematch: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return changed, nil
+}
@@ -0,0 +1,266 @@
+// Package database provides database operations for the loan eligibility engine.
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"loan-eligibility-engine/internal/models"
+)
+
+var (
+	// ErrJobFinished is returned when cancelling a job that already finished.
+	ErrJobFinished = errors.New("job already finished")
+
+	// ErrJobLeaseLost is returned when a worker updates a job it no longer
+	// holds, because its lease expired and another worker claimed the job.
+	ErrJobLeaseLost = errors.New("job lease lost")
+)
+
+// jobColumns lists the columns scanned by scanJob, without the input data.
+const jobColumns = `
+	id, job_type, status, payload, result, progress_done, progress_total,
+	attempts, max_attempts, COALESCE(last_error, ''), cancel_requested,
+	COALESCE(locked_by, ''), locked_until, run_after, created_at, started_at,
+	finished_at, updated_at`
+
+// JobRepository handles the background job queue. Jobs are claimed with
+// FOR UPDATE SKIP LOCKED, so any number of workers and server instances can
+// poll the same table without claiming a job twice.
+type JobRepository struct {
+	db *DB
+}
+
+// NewJobRepository creates a new job repository.
+func NewJobRepository(db *DB) *JobRepository {
+	return &JobRepository{db: db}
+}
+
+// Enqueue adds a job to the queue.
+func (r *JobRepository) Enqueue(ctx context.Context, job *models.JobCreate) (*models.Job, error) {
+	payload := job.Payload
+	if len(payload) == 0 {
+		payload = json.RawMessage("{}")
+	}
+
+	now := time.Now().UTC()
+	row := r.db.QueryRowContext(ctx, `
+		INSERT INTO jobs (job_type, status, payload, data, max_attempts, run_after, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $6, $6)
+		RETURNING`+jobColumns,
+		string(job.Type), string(models.JobStatusQueued), []byte(payload), job.Data, max(job.MaxAttempts, 1), now,
+	)
+
+	created, err := scanJob(row)
+	if err != nil {
+		return nil, fmt.Errorf("failed to enqueue job: %w", err)
+	}
+	return created, nil
+}
+
+// Get retrieves a job by ID, without its input data. Returns nil if not found.
+func (r *JobRepository) Get(ctx context.Context, id int64) (*models.Job, error) {
+	job, err := scanJob(r.db.QueryRowContext(ctx, "SELECT"+jobColumns+" FROM jobs WHERE id = $1", id))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	return job, nil
+}
+
+// Claim leases the next runnable job of the given types to a worker. A job is
+// runnable when it is queued and due, or running under an expired lease.
+// Expired jobs that are out of attempts or were asked to cancel are finished
+// instead of claimed. Returns nil if no job is runnable.
+func (r *JobRepository) Claim(ctx context.Context, workerID string, types []models.JobType, lease time.Duration) (*models.Job, error) {
+	now := time.Now().UTC()
+
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE jobs SET
+			status = CASE WHEN cancel_requested THEN $2 ELSE $3 END,
+			last_error = CASE WHEN cancel_requested THEN last_error ELSE 'worker lease expired' END,
+			locked_by = NULL, locked_until = NULL, finished_at = $1, updated_at = $1
+		WHERE status = $4 AND locked_until < $1 AND (cancel_requested OR attempts >= max_attempts)`,
+		now, string(models.JobStatusCancelled), string(models.JobStatusFailed), string(models.JobStatusRunning),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expire abandoned jobs: %w", err)
+	}
+
+	jobTypes := make([]string, len(types))
+	for i, t := range types {
+		jobTypes[i] = string(t)
+	}
+
+	row := r.db.QueryRowContext(ctx, `
+		UPDATE jobs SET
+			status = $4, attempts = attempts + 1, locked_by = $2, locked_until = $3,
+			started_at = COALESCE(started_at, $1), updated_at = $1
+		WHERE id = (
+			SELECT id FROM jobs
+			WHERE job_type = ANY($5)
+			  AND ((status = $6 AND run_after <= $1) OR (status = $4 AND locked_until < $1))
+			ORDER BY run_after, id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING data,`+jobColumns,
+		now, workerID, now.Add(lease), string(models.JobStatusRunning), jobTypes, string(models.JobStatusQueued),
+	)
+
+	var data []byte
+	job, err := scanJob(row, &data)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim job: %w", err)
+	}
+	job.Data = data
+	return job, nil
+}
+
+// Heartbeat renews a worker's lease on a job and records its progress.
+// Returns whether cancellation was requested, or ErrJobLeaseLost if the
+// worker no longer holds the job.
+func (r *JobRepository) Heartbeat(ctx context.Context, id int64, workerID string, lease time.Duration, done, total int) (bool, error) {
+	now := time.Now().UTC()
+
+	var cancelRequested bool
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE jobs SET locked_until = $3, progress_done = $4, progress_total = $5, updated_at = $6
+		WHERE id = $1 AND locked_by = $2 AND status = $7
+		RETURNING cancel_requested`,
+		id, workerID, now.Add(lease), done, total, now, string(models.JobStatusRunning),
+	).Scan(&cancelRequested)
+	if err == pgx.ErrNoRows {
+		return false, ErrJobLeaseLost
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to renew job lease: %w", err)
+	}
+	return cancelRequested, nil
+}
+
+// Complete marks a job held by the worker as succeeded with its result.
+func (r *JobRepository) Complete(ctx context.Context, id int64, workerID string, result json.RawMessage, done, total int) error {
+	return r.finish(ctx, id, workerID, `
+		UPDATE jobs SET status = $3, result = $4, progress_done = $5, progress_total = $6,
+			last_error = NULL, locked_by = NULL, locked_until = NULL, finished_at = $7, updated_at = $7
+		WHERE id = $1 AND locked_by = $2 AND status = $8`,
+		string(models.JobStatusSucceeded), []byte(result), done, total, time.Now().UTC(), string(models.JobStatusRunning),
+	)
+}
+
+// Fail records an error on a job held by the worker. The job is queued again
+// to run at retryAt while it has attempts left; a nil retryAt, or running out
+// of attempts, fails it for good. Returns the job's new status.
+func (r *JobRepository) Fail(ctx context.Context, id int64, workerID, errMsg string, retryAt *time.Time) (models.JobStatus, error) {
+	now := time.Now().UTC()
+	retry := retryAt != nil
+	if !retry {
+		retryAt = &now
+	}
+
+	var status string
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE jobs SET
+			status = CASE WHEN $3 AND attempts < max_attempts THEN $4 ELSE $5 END,
+			run_after = $6, last_error = $7, locked_by = NULL, locked_until = NULL,
+			finished_at = CASE WHEN $3 AND attempts < max_attempts THEN NULL ELSE $8 END,
+			updated_at = $8
+		WHERE id = $1 AND locked_by = $2 AND status = $9
+		RETURNING status`,
+		id, workerID, retry, string(models.JobStatusQueued), string(models.JobStatusFailed),
+		*retryAt, errMsg, now, string(models.JobStatusRunning),
+	).Scan(&status)
+	if err == pgx.ErrNoRows {
+		return "", ErrJobLeaseLost
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to record job failure: %w", err)
+	}
+	return models.JobStatus(status), nil
+}
+
+// MarkCancelled finishes a job held by the worker after it stopped on a
+// cancellation request.
+func (r *JobRepository) MarkCancelled(ctx context.Context, id int64, workerID string, done, total int) error {
+	return r.finish(ctx, id, workerID, `
+		UPDATE jobs SET status = $3, progress_done = $4, progress_total = $5,
+			locked_by = NULL, locked_until = NULL, finished_at = $6, updated_at = $6
+		WHERE id = $1 AND locked_by = $2 AND status = $7`,
+		string(models.JobStatusCancelled), done, total, time.Now().UTC(), string(models.JobStatusRunning),
+	)
+}
+
+// Cancel cancels a queued job at once, or asks the worker running a job to
+// stop at its next heartbeat. Returns nil if the job does not exist and
+// ErrJobFinished if it already finished.
+func (r *JobRepository) Cancel(ctx context.Context, id int64) (*models.Job, error) {
+	now := time.Now().UTC()
+	row := r.db.QueryRowContext(ctx, `
+		UPDATE jobs SET
+			status = CASE WHEN status = $2 THEN $3 ELSE status END,
+			finished_at = CASE WHEN status = $2 THEN $5 ELSE finished_at END,
+			cancel_requested = true, updated_at = $5
+		WHERE id = $1 AND status IN ($2, $4)
+		RETURNING`+jobColumns,
+		id, string(models.JobStatusQueued), string(models.JobStatusCancelled), string(models.JobStatusRunning), now,
+	)
+
+	job, err := scanJob(row)
+	if err == pgx.ErrNoRows {
+		existing, err := r.Get(ctx, id)
+		if err != nil || existing == nil {
+			return nil, err
+		}
+		return nil, ErrJobFinished
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel job: %w", err)
+	}
+	return job, nil
+}
+
+// finish runs an update that ends a job held by the worker.
+func (r *JobRepository) finish(ctx context.Context, id int64, workerID, query string, args ...interface{}) error {
+	affected, err := r.db.ExecContext(ctx, query, append([]interface{}{id, workerID}, args...)...)
+	if err != nil {
+		return fmt.Errorf("failed to finish job: %w", err)
+	}
+	if affected == 0 {
+		return ErrJobLeaseLost
+	}
+	return nil
+}
+
+// scanJob scans jobColumns, after any leading destinations, into a job.
+func scanJob(row pgx.Row, leading ...interface{}) (*models.Job, error) {
+	var job models.Job
+	var jobType, status string
+	var payload, result []byte
+
+	dest := append(leading,
+		&job.ID, &jobType, &status, &payload, &result, &job.ProgressDone, &job.ProgressTotal,
+		&job.Attempts, &job.MaxAttempts, &job.LastError, &job.CancelRequested,
+		&job.LockedBy, &job.LockedUntil, &job.RunAfter, &job.CreatedAt, &job.StartedAt,
+		&job.FinishedAt, &job.UpdatedAt,
+	)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	job.Type = models.JobType(jobType)
+	job.Status = models.JobStatus(status)
+	job.Payload = payload
+	job.Result = result
+	return &job, nil
+}
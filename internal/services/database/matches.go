@@ -22,14 +22,21 @@ func NewMatchRepository(db *DB) *MatchRepository {
 	return &MatchRepository{db: db}
 }
 
-// Create inserts a new match into the database.
+// keepManualDecision is the ON CONFLICT condition that leaves a match a
+// reviewer decided on untouched, every field of it, until the match expires
+const keepManualDecision = `(matches.match_source <> 'manual' OR matches.status = 'expired')`
+
+// Create inserts a new match into the database. A reviewer's decision on an
+// existing match is kept until the match expires.
 func (r *MatchRepository) Create(ctx context.Context, match *models.MatchCreate) (int64, error) {
 	query := `
 		INSERT INTO matches (
 			user_id, product_id, match_score, status, match_source,
 			income_eligible, credit_score_eligible, age_eligible, employment_eligible,
-			llm_analysis, llm_confidence, batch_id, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $13)
+			llm_analysis, llm_confidence, rule_results,
+			emi_min, emi_max, foir, max_eligible_amount, estimated_rate, processing_fee_percent,
+			scoring_version, batch_id, created_at, updated_at, product_version
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $21, NULLIF($22, 0))
 		ON CONFLICT (user_id, product_id) DO UPDATE SET
 			match_score = EXCLUDED.match_score,
 			status = EXCLUDED.status,
@@ -40,14 +47,29 @@ func (r *MatchRepository) Create(ctx context.Context, match *models.MatchCreate)
 			employment_eligible = EXCLUDED.employment_eligible,
 			llm_analysis = EXCLUDED.llm_analysis,
 			llm_confidence = EXCLUDED.llm_confidence,
+			rule_results = EXCLUDED.rule_results,
+			emi_min = EXCLUDED.emi_min,
+			emi_max = EXCLUDED.emi_max,
+			foir = EXCLUDED.foir,
+			max_eligible_amount = EXCLUDED.max_eligible_amount,
+			estimated_rate = EXCLUDED.estimated_rate,
+			processing_fee_percent = EXCLUDED.processing_fee_percent,
+			scoring_version = EXCLUDED.scoring_version,
+			product_version = EXCLUDED.product_version,
 			batch_id = EXCLUDED.batch_id,
 			updated_at = EXCLUDED.updated_at
+		WHERE ` + keepManualDecision + `
 		RETURNING id`
 
+	ruleResults, err := marshalRuleResults(match.RuleResults)
+	if err != nil {
+		return 0, err
+	}
+
 	var id int64
 	now := time.Now().UTC()
 
-	err := r.db.QueryRowContext(ctx, query,
+	err = r.db.QueryRowContext(ctx, query,
 		match.UserID,
 		match.ProductID,
 		match.MatchScore,
@@ -59,10 +81,26 @@ func (r *MatchRepository) Create(ctx context.Context, match *models.MatchCreate)
 		match.EmploymentEligible,
 		match.LLMAnalysis,
 		match.LLMConfidence,
+		ruleResults,
+		match.EMIMin,
+		match.EMIMax,
+		match.FOIR,
+		match.MaxEligibleAmount,
+		match.EstimatedRate,
+		match.ProcessingFeePercent,
+		match.ScoringVersion,
 		match.BatchID,
 		now,
+		match.ProductVersion,
 	).Scan(&id)
 
+	if err == pgx.ErrNoRows {
+		// A reviewer's decision was kept as it was
+		err = r.db.QueryRowContext(ctx,
+			"SELECT id FROM matches WHERE user_id = $1 AND product_id = $2",
+			match.UserID, match.ProductID,
+		).Scan(&id)
+	}
 	if err != nil {
 		return 0, fmt.Errorf("failed to create match: %w", err)
 	}
@@ -70,7 +108,8 @@ func (r *MatchRepository) Create(ctx context.Context, match *models.MatchCreate)
 	return id, nil
 }
 
-// BulkInsert inserts multiple matches into the database.
+// BulkInsert inserts multiple matches into the database. A reviewer's
+// decision on an existing match is kept until the match expires.
 func (r *MatchRepository) BulkInsert(ctx context.Context, matches []*models.MatchCreate) (int, int, error) {
 	inserted := 0
 	failed := 0
@@ -79,16 +118,37 @@ func (r *MatchRepository) BulkInsert(ctx context.Context, matches []*models.Matc
 		now := time.Now().UTC()
 
 		for _, match := range matches {
-			_, err := tx.Exec(ctx, `
+			ruleResults, err := marshalRuleResults(match.RuleResults)
+			if err != nil {
+				failed++
+				continue
+			}
+
+			_, err = tx.Exec(ctx, `
 				INSERT INTO matches (
 					user_id, product_id, match_score, status, match_source,
 					income_eligible, credit_score_eligible, age_eligible, employment_eligible,
-					llm_analysis, llm_confidence, batch_id, created_at, updated_at
-				) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $13)
+					llm_analysis, llm_confidence, rule_results,
+					emi_min, emi_max, foir, max_eligible_amount, estimated_rate, processing_fee_percent,
+					scoring_version, batch_id, created_at, updated_at, product_version
+				) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $21, NULLIF($22, 0))
 				ON CONFLICT (user_id, product_id) DO UPDATE SET
 					match_score = EXCLUDED.match_score,
 					status = EXCLUDED.status,
-					updated_at = EXCLUDED.updated_at`,
+					match_source = EXCLUDED.match_source,
+					llm_analysis = EXCLUDED.llm_analysis,
+					llm_confidence = EXCLUDED.llm_confidence,
+					rule_results = EXCLUDED.rule_results,
+					emi_min = EXCLUDED.emi_min,
+					emi_max = EXCLUDED.emi_max,
+					foir = EXCLUDED.foir,
+					max_eligible_amount = EXCLUDED.max_eligible_amount,
+					estimated_rate = EXCLUDED.estimated_rate,
+					processing_fee_percent = EXCLUDED.processing_fee_percent,
+					scoring_version = EXCLUDED.scoring_version,
+					product_version = EXCLUDED.product_version,
+					updated_at = EXCLUDED.updated_at
+				WHERE `+keepManualDecision,
 				match.UserID,
 				match.ProductID,
 				match.MatchScore,
@@ -100,8 +160,17 @@ func (r *MatchRepository) BulkInsert(ctx context.Context, matches []*models.Matc
 				match.EmploymentEligible,
 				match.LLMAnalysis,
 				match.LLMConfidence,
+				ruleResults,
+				match.EMIMin,
+				match.EMIMax,
+				match.FOIR,
+				match.MaxEligibleAmount,
+				match.EstimatedRate,
+				match.ProcessingFeePercent,
+				match.ScoringVersion,
 				match.BatchID,
 				now,
+				match.ProductVersion,
 			)
 
 			if err != nil {
@@ -122,7 +191,10 @@ func (r *MatchRepository) GetPendingNotifications(ctx context.Context, batchID s
 		SELECT 
 			m.id, m.user_id, m.product_id, m.match_score, m.status, m.match_source,
 			m.income_eligible, m.credit_score_eligible, m.age_eligible, m.employment_eligible,
-			m.llm_analysis, m.llm_confidence, m.batch_id, m.created_at, m.updated_at, m.notified_at,
+			m.llm_analysis, m.llm_confidence,
+			COALESCE(m.emi_min, 0), COALESCE(m.emi_max, 0), COALESCE(m.foir, 0), COALESCE(m.max_eligible_amount, 0),
+			COALESCE(m.estimated_rate, 0), m.processing_fee_percent, COALESCE(m.scoring_version, ''), m.product_version,
+			m.batch_id, m.created_at, m.updated_at, m.notified_at,
 			u.email as user_email, u.user_id as user_name,
 			p.product_name, p.provider_name, p.interest_rate_min, p.interest_rate_max,
 			p.loan_amount_min, p.loan_amount_max
@@ -153,7 +225,10 @@ func (r *MatchRepository) GetPendingNotifications(ctx context.Context, batchID s
 		err := rows.Scan(
 			&m.ID, &m.UserID, &m.ProductID, &m.MatchScore, &status, &source,
 			&m.IncomeEligible, &m.CreditScoreEligible, &m.AgeEligible, &m.EmploymentEligible,
-			&m.LLMAnalysis, &m.LLMConfidence, &m.BatchID, &m.CreatedAt, &m.UpdatedAt, &m.NotifiedAt,
+			&m.LLMAnalysis, &m.LLMConfidence,
+			&m.EMIMin, &m.EMIMax, &m.FOIR, &m.MaxEligibleAmount,
+			&m.EstimatedRate, &m.ProcessingFeePercent, &m.ScoringVersion, &m.ProductVersion,
+			&m.BatchID, &m.CreatedAt, &m.UpdatedAt, &m.NotifiedAt,
 			&m.UserEmail, &m.UserName,
 			&m.ProductName, &m.ProviderName, &m.InterestRateMin, &m.InterestRateMax,
 			&m.LoanAmountMin, &m.LoanAmountMax,
@@ -179,21 +254,57 @@ func (r *MatchRepository) MarkAsNotified(ctx context.Context, matchID int64) err
 	return err
 }
 
+// prefilterJoins join the exchange rates of the user's currency (fu) and the
+// product's currency (fp) to every user-product pair
+const prefilterJoins = `
+		LEFT JOIN fx_rates fu ON fu.currency = u.currency
+		LEFT JOIN fx_rates fp ON fp.currency = p.currency`
+
+// userToProductFX converts a user's amounts into the product's currency. It
+// is NULL, failing every comparison, when either currency has no rate.
+const userToProductFX = `(CASE WHEN u.currency = p.currency THEN 1 ELSE fu.rate_to_base / fp.rate_to_base END)`
+
+// prefilterConditions are the stage 1 hard criteria shared by the SQL prefilter
+// queries. Users are aliased u and products p, and the user's amounts are
+// compared in the product's currency.
+const prefilterConditions = `u.is_active = true
+		  AND p.is_active = true
+		  AND u.monthly_income * ` + userToProductFX + ` >= p.min_monthly_income
+		  AND u.credit_score >= p.min_credit_score
+		  AND (p.max_credit_score IS NULL OR u.credit_score <= p.max_credit_score)
+		  AND u.age >= p.min_age
+		  AND u.age <= p.max_age
+		  AND (COALESCE(cardinality(p.accepted_employment_status), 0) = 0
+		       OR u.employment_status = ANY(p.accepted_employment_status))
+		  AND (u.loan_purpose = '' OR u.loan_purpose = p.product_type)
+		  AND (u.requested_amount = 0
+		       OR (u.requested_amount * ` + userToProductFX + ` >= p.loan_amount_min
+		           AND (p.loan_amount_max = 0 OR u.requested_amount * ` + userToProductFX + ` <= p.loan_amount_max)))`
+
 // SQLPrefilterMatches performs fast SQL-based pre-filtering for matching.
-// This is Stage 1 of the optimization pipeline.
+// This is Stage 1 of the optimization pipeline. User amounts are returned in
+// the product's currency.
 func (r *MatchRepository) SQLPrefilterMatches(ctx context.Context, batchID string) ([]*models.MatchCandidate, error) {
 	query := `
 		SELECT 
 			u.id as user_db_id,
 			u.user_id as user_external_id,
 			u.email,
-			u.monthly_income,
+			u.monthly_income * ` + userToProductFX + ` AS monthly_income,
 			u.credit_score,
 			u.employment_status,
 			u.age,
+			u.existing_emi * ` + userToProductFX + ` AS existing_emi,
+			u.credit_card_outstanding * ` + userToProductFX + ` AS credit_card_outstanding,
+			u.active_loans,
+			u.requested_amount * ` + userToProductFX + ` AS requested_amount,
+			u.requested_tenure_months,
+			u.loan_purpose,
 			p.id as product_id,
 			p.product_name,
 			p.provider_name,
+			COALESCE(p.product_type, '') AS product_type,
+			p.currency,
 			p.min_monthly_income,
 			p.min_credit_score,
 			p.max_credit_score,
@@ -201,16 +312,14 @@ func (r *MatchRepository) SQLPrefilterMatches(ctx context.Context, batchID strin
 			p.max_age,
 			p.accepted_employment_status,
 			p.interest_rate_min,
-			p.interest_rate_max
+			p.interest_rate_max,
+			p.loan_amount_min,
+			p.loan_amount_max,
+			p.tenure_min_months,
+			p.tenure_max_months
 		FROM users u
-		CROSS JOIN loan_products p
-		WHERE u.is_active = true
-		  AND p.is_active = true
-		  AND u.monthly_income >= p.min_monthly_income
-		  AND u.credit_score >= p.min_credit_score
-		  AND (p.max_credit_score IS NULL OR u.credit_score <= p.max_credit_score)
-		  AND u.age >= p.min_age
-		  AND u.age <= p.max_age`
+		CROSS JOIN loan_products p` + prefilterJoins + `
+		WHERE ` + prefilterConditions
 
 	args := []interface{}{}
 	if batchID != "" {
@@ -229,7 +338,7 @@ func (r *MatchRepository) SQLPrefilterMatches(ctx context.Context, batchID strin
 	var candidates []*models.MatchCandidate
 	for rows.Next() {
 		var c models.MatchCandidate
-		var empStatus, empStatusJSON string
+		var empStatus, empStatusJSON, loanPurpose, productType, currency string
 
 		err := rows.Scan(
 			&c.UserDBID,
@@ -239,9 +348,17 @@ func (r *MatchRepository) SQLPrefilterMatches(ctx context.Context, batchID strin
 			&c.CreditScore,
 			&empStatus,
 			&c.Age,
+			&c.ExistingEMI,
+			&c.CreditCardOutstanding,
+			&c.ActiveLoans,
+			&c.RequestedAmount,
+			&c.RequestedTenureMonths,
+			&loanPurpose,
 			&c.ProductID,
 			&c.ProductName,
 			&c.ProviderName,
+			&productType,
+			&currency,
 			&c.MinMonthlyIncome,
 			&c.MinCreditScore,
 			&c.MaxCreditScore,
@@ -250,12 +367,19 @@ func (r *MatchRepository) SQLPrefilterMatches(ctx context.Context, batchID strin
 			&empStatusJSON,
 			&c.InterestRateMin,
 			&c.InterestRateMax,
+			&c.LoanAmountMin,
+			&c.LoanAmountMax,
+			&c.TenureMinMonths,
+			&c.TenureMaxMonths,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan candidate: %w", err)
 		}
 
 		c.EmploymentStatus = models.EmploymentStatus(empStatus)
+		c.LoanPurpose = models.LoanProductType(loanPurpose)
+		c.ProductType = models.LoanProductType(productType)
+		c.Currency = models.Currency(currency)
 
 		if empStatusJSON != "" {
 			if err := json.Unmarshal([]byte(empStatusJSON), &c.AcceptedEmploymentStatus); err != nil {
@@ -270,6 +394,34 @@ func (r *MatchRepository) SQLPrefilterMatches(ctx context.Context, batchID strin
 	return candidates, nil
 }
 
+// PrefilterPairs runs stage 1 in Postgres for the given users and returns,
+// for each user, the IDs of the active products that passed.
+func (r *MatchRepository) PrefilterPairs(ctx context.Context, userIDs []int64) (map[int64][]int64, error) {
+	query := `
+		SELECT u.id, p.id
+		FROM users u
+		CROSS JOIN loan_products p` + prefilterJoins + `
+		WHERE u.id = ANY($1) AND ` + prefilterConditions + `
+		ORDER BY u.id, p.id`
+
+	rows, err := r.db.QueryContext(ctx, query, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute SQL prefilter: %w", err)
+	}
+	defer rows.Close()
+
+	pairs := make(map[int64][]int64, len(userIDs))
+	for rows.Next() {
+		var userID, productID int64
+		if err := rows.Scan(&userID, &productID); err != nil {
+			return nil, fmt.Errorf("failed to scan prefilter pair: %w", err)
+		}
+		pairs[userID] = append(pairs[userID], productID)
+	}
+
+	return pairs, rows.Err()
+}
+
 // GetBatchSummary returns summary statistics for a batch.
 func (r *MatchRepository) GetBatchSummary(ctx context.Context, batchID string) (*models.BatchMatchSummary, error) {
 	summary := &models.BatchMatchSummary{
@@ -324,10 +476,13 @@ func (r *MatchRepository) GetByUserID(ctx context.Context, userID int64) ([]mode
 	query := `
 		SELECT id, user_id, product_id, match_score, status, match_source,
 			   income_eligible, credit_score_eligible, age_eligible, employment_eligible,
-			   llm_analysis, llm_confidence, batch_id, created_at, updated_at, notified_at
+			   llm_analysis, llm_confidence, rule_results,
+			   COALESCE(emi_min, 0), COALESCE(emi_max, 0), COALESCE(foir, 0), COALESCE(max_eligible_amount, 0),
+			   COALESCE(estimated_rate, 0), processing_fee_percent, COALESCE(scoring_version, ''), product_version,
+			   batch_id, created_at, updated_at, notified_at
 		FROM matches
 		WHERE user_id = $1
-		ORDER BY match_score DESC`
+		ORDER BY match_score DESC, estimated_rate NULLS LAST`
 
 	rows, err := r.db.QueryContext(ctx, query, userID)
 	if err != nil {
@@ -338,12 +493,73 @@ func (r *MatchRepository) GetByUserID(ctx context.Context, userID int64) ([]mode
 	return scanMatches(rows)
 }
 
+// GetByUserIDs retrieves all matches for the given users.
+func (r *MatchRepository) GetByUserIDs(ctx context.Context, userIDs []int64) ([]models.Match, error) {
+	query := `
+		SELECT id, user_id, product_id, match_score, status, match_source,
+			   income_eligible, credit_score_eligible, age_eligible, employment_eligible,
+			   llm_analysis, llm_confidence, rule_results,
+			   COALESCE(emi_min, 0), COALESCE(emi_max, 0), COALESCE(foir, 0), COALESCE(max_eligible_amount, 0),
+			   COALESCE(estimated_rate, 0), processing_fee_percent, COALESCE(scoring_version, ''), product_version,
+			   batch_id, created_at, updated_at, notified_at
+		FROM matches
+		WHERE user_id = ANY($1)
+		ORDER BY user_id, match_score DESC, estimated_rate NULLS LAST`
+
+	rows, err := r.db.QueryContext(ctx, query, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get matches by users: %w", err)
+	}
+	defer rows.Close()
+
+	return scanMatches(rows)
+}
+
+// GetByProductID retrieves all matches for a specific product.
+func (r *MatchRepository) GetByProductID(ctx context.Context, productID int64) ([]models.Match, error) {
+	query := `
+		SELECT id, user_id, product_id, match_score, status, match_source,
+			   income_eligible, credit_score_eligible, age_eligible, employment_eligible,
+			   llm_analysis, llm_confidence, rule_results,
+			   COALESCE(emi_min, 0), COALESCE(emi_max, 0), COALESCE(foir, 0), COALESCE(max_eligible_amount, 0),
+			   COALESCE(estimated_rate, 0), processing_fee_percent, COALESCE(scoring_version, ''), product_version,
+			   batch_id, created_at, updated_at, notified_at
+		FROM matches
+		WHERE product_id = $1
+		ORDER BY match_score DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get matches by product: %w", err)
+	}
+	defer rows.Close()
+
+	return scanMatches(rows)
+}
+
+// Expire marks matches as expired.
+func (r *MatchRepository) Expire(ctx context.Context, matchIDs []int64) error {
+	if len(matchIDs) == 0 {
+		return nil
+	}
+	_, err := r.db.ExecContext(ctx,
+		"UPDATE matches SET status = 'expired', updated_at = $1 WHERE id = ANY($2)",
+		time.Now().UTC(), matchIDs)
+	if err != nil {
+		return fmt.Errorf("failed to expire matches: %w", err)
+	}
+	return nil
+}
+
 // GetByBatchID retrieves matches for a specific batch with a limit.
 func (r *MatchRepository) GetByBatchID(ctx context.Context, batchID string, limit int) ([]models.Match, error) {
 	query := `
 		SELECT id, user_id, product_id, match_score, status, match_source,
 			   income_eligible, credit_score_eligible, age_eligible, employment_eligible,
-			   llm_analysis, llm_confidence, batch_id, created_at, updated_at, notified_at
+			   llm_analysis, llm_confidence, rule_results,
+			   COALESCE(emi_min, 0), COALESCE(emi_max, 0), COALESCE(foir, 0), COALESCE(max_eligible_amount, 0),
+			   COALESCE(estimated_rate, 0), processing_fee_percent, COALESCE(scoring_version, ''), product_version,
+			   batch_id, created_at, updated_at, notified_at
 		FROM matches
 		WHERE batch_id = $1
 		ORDER BY match_score DESC
@@ -363,9 +579,12 @@ func (r *MatchRepository) GetPending(ctx context.Context, limit int) ([]models.M
 	query := `
 		SELECT id, user_id, product_id, match_score, status, match_source,
 			   income_eligible, credit_score_eligible, age_eligible, employment_eligible,
-			   llm_analysis, llm_confidence, batch_id, created_at, updated_at, notified_at
+			   llm_analysis, llm_confidence, rule_results,
+			   COALESCE(emi_min, 0), COALESCE(emi_max, 0), COALESCE(foir, 0), COALESCE(max_eligible_amount, 0),
+			   COALESCE(estimated_rate, 0), processing_fee_percent, COALESCE(scoring_version, ''), product_version,
+			   batch_id, created_at, updated_at, notified_at
 		FROM matches
-		WHERE status = 'pending' OR notified_at IS NULL
+		WHERE (status = 'pending' OR notified_at IS NULL) AND status NOT IN ('unreviewed', 'pending_review')
 		ORDER BY created_at DESC
 		LIMIT $1`
 
@@ -388,14 +607,23 @@ func scanMatches(rows interface {
 		var m models.Match
 		var status, source string
 		var llmAnalysis, batchID *string
+		var ruleResults []byte
 		err := rows.Scan(
 			&m.ID, &m.UserID, &m.ProductID, &m.MatchScore, &status, &source,
 			&m.IncomeEligible, &m.CreditScoreEligible, &m.AgeEligible, &m.EmploymentEligible,
-			&llmAnalysis, &m.LLMConfidence, &batchID, &m.CreatedAt, &m.UpdatedAt, &m.NotifiedAt,
+			&llmAnalysis, &m.LLMConfidence, &ruleResults,
+			&m.EMIMin, &m.EMIMax, &m.FOIR, &m.MaxEligibleAmount,
+			&m.EstimatedRate, &m.ProcessingFeePercent, &m.ScoringVersion, &m.ProductVersion,
+			&batchID, &m.CreatedAt, &m.UpdatedAt, &m.NotifiedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan match: %w", err)
 		}
+		if len(ruleResults) > 0 {
+			if err := json.Unmarshal(ruleResults, &m.RuleResults); err != nil {
+				return nil, fmt.Errorf("failed to decode rule results: %w", err)
+			}
+		}
 		m.Status = models.MatchStatus(status)
 		m.MatchSource = models.MatchSource(source)
 		if llmAnalysis != nil {
@@ -408,3 +636,15 @@ func scanMatches(rows interface {
 	}
 	return matches, nil
 }
+
+// marshalRuleResults encodes rule results for the JSONB rule_results column.
+func marshalRuleResults(results []models.RuleResult) (interface{}, error) {
+	if len(results) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(results)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal rule results: %w", err)
+	}
+	return string(data), nil
+}
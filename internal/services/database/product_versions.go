@@ -0,0 +1,109 @@
+// Package database provides database operations for the loan eligibility engine.
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"loan-eligibility-engine/internal/models"
+)
+
+// ProductVersionRepository reads loan product version history. Versions are
+// written by database triggers whenever a product's terms change.
+type ProductVersionRepository struct {
+	db *DB
+}
+
+// NewProductVersionRepository creates a new product version repository.
+func NewProductVersionRepository(db *DB) *ProductVersionRepository {
+	return &ProductVersionRepository{db: db}
+}
+
+// versionColumns are the loan_product_versions columns read by scanVersions
+const versionColumns = `
+	v.id, v.product_id, v.version, v.source, v.changed_fields, COALESCE(v.terms->>'product_name', ''),
+	v.provider_name, v.interest_rate_min, v.interest_rate_max, v.processing_fee_percent, v.is_active,
+	v.terms, v.created_at`
+
+// GetByProductID retrieves the versions of a product, newest first, with the
+// number of matches computed against each.
+func (r *ProductVersionRepository) GetByProductID(ctx context.Context, productID int64) ([]*models.LoanProductVersion, error) {
+	query := `
+		SELECT ` + versionColumns + `,
+			(SELECT COUNT(*) FROM matches m WHERE m.product_id = v.product_id AND m.product_version = v.version)
+		FROM loan_product_versions v
+		WHERE v.product_id = $1
+		ORDER BY v.version DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query product versions: %w", err)
+	}
+	defer rows.Close()
+
+	return scanVersions(rows, true)
+}
+
+// GetRateHistory retrieves the versions of every product, or of one lender's
+// products, made up to the given time (all of them if zero), ordered by
+// product and version for models.BuildRateTimelines.
+func (r *ProductVersionRepository) GetRateHistory(ctx context.Context, provider string, to time.Time) ([]*models.LoanProductVersion, error) {
+	var until *time.Time
+	if !to.IsZero() {
+		until = &to
+	}
+
+	query := `
+		SELECT ` + versionColumns + `
+		FROM loan_product_versions v
+		WHERE ($1 = '' OR v.provider_name = $1)
+			AND ($2::timestamp IS NULL OR v.created_at <= $2)
+		ORDER BY v.provider_name, v.product_id, v.version`
+
+	rows, err := r.db.QueryContext(ctx, query, provider, until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query product rate history: %w", err)
+	}
+	defer rows.Close()
+
+	return scanVersions(rows, false)
+}
+
+// scanVersions scans versionColumns, followed by the match count if
+// withMatches is set, into product versions.
+func scanVersions(rows pgx.Rows, withMatches bool) ([]*models.LoanProductVersion, error) {
+	versions := make([]*models.LoanProductVersion, 0)
+	for rows.Next() {
+		var v models.LoanProductVersion
+		var source string
+		dest := []interface{}{
+			&v.ID,
+			&v.ProductID,
+			&v.Version,
+			&source,
+			&v.ChangedFields,
+			&v.ProductName,
+			&v.ProviderName,
+			&v.InterestRateMin,
+			&v.InterestRateMax,
+			&v.ProcessingFeePercent,
+			&v.IsActive,
+			&v.Terms,
+			&v.CreatedAt,
+		}
+		if withMatches {
+			dest = append(dest, &v.Matches)
+		}
+
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to scan product version: %w", err)
+		}
+		v.Source = models.ProductChangeSource(source)
+		versions = append(versions, &v)
+	}
+
+	return versions, rows.Err()
+}
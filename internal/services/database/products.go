@@ -34,9 +34,9 @@ func (r *ProductRepository) Create(ctx context.Context, product *models.LoanProd
 			product_name, provider_name, product_type, interest_rate_min, interest_rate_max,
 			loan_amount_min, loan_amount_max, tenure_min_months, tenure_max_months,
 			min_monthly_income, min_credit_score, max_credit_score, min_age, max_age,
-			accepted_employment_status, processing_fee_percent, source_url,
+			accepted_employment_status, processing_fee_percent, max_foir, source_url, currency,
 			created_at, updated_at, is_active, last_crawled_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $18, true, $18)
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $20, true, $20)
 		RETURNING id`
 
 	var id int64
@@ -59,7 +59,9 @@ func (r *ProductRepository) Create(ctx context.Context, product *models.LoanProd
 		product.MaxAge,
 		string(empStatusJSON),
 		product.ProcessingFeePercent,
+		product.MaxFOIR,
 		product.SourceURL,
+		string(product.Currency.OrDefault()),
 		now,
 	).Scan(&id)
 
@@ -70,14 +72,130 @@ func (r *ProductRepository) Create(ctx context.Context, product *models.LoanProd
 	return id, nil
 }
 
+// Upsert inserts a loan product or updates the existing product with the same
+// provider and name. It reports whether the product is new, and whether it is
+// new or its matching-relevant terms changed, as detected by the terms change
+// trigger. A nil MaxFOIR keeps the product's current limit, since crawled
+// terms rarely include one. A change to the product's terms is recorded as a
+// new product version from the given source.
+func (r *ProductRepository) Upsert(ctx context.Context, product *models.LoanProductCreate, source models.ProductChangeSource) (*models.ProductUpsert, error) {
+	empStatus := make([]string, len(product.AcceptedEmploymentStatus))
+	for i, s := range product.AcceptedEmploymentStatus {
+		empStatus[i] = string(s)
+	}
+
+	query := `
+		INSERT INTO loan_products (
+			product_name, provider_name, product_type, interest_rate_min, interest_rate_max,
+			loan_amount_min, loan_amount_max, tenure_min_months, tenure_max_months,
+			min_monthly_income, min_credit_score, max_credit_score, min_age, max_age,
+			accepted_employment_status, processing_fee_percent, max_foir, source_url, currency,
+			created_at, updated_at, is_active, last_crawled_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $20, true, $20)
+		ON CONFLICT (provider_name, product_name) DO UPDATE SET
+			product_type = EXCLUDED.product_type,
+			interest_rate_min = EXCLUDED.interest_rate_min,
+			interest_rate_max = EXCLUDED.interest_rate_max,
+			loan_amount_min = EXCLUDED.loan_amount_min,
+			loan_amount_max = EXCLUDED.loan_amount_max,
+			tenure_min_months = EXCLUDED.tenure_min_months,
+			tenure_max_months = EXCLUDED.tenure_max_months,
+			min_monthly_income = EXCLUDED.min_monthly_income,
+			min_credit_score = EXCLUDED.min_credit_score,
+			max_credit_score = EXCLUDED.max_credit_score,
+			min_age = EXCLUDED.min_age,
+			max_age = EXCLUDED.max_age,
+			accepted_employment_status = EXCLUDED.accepted_employment_status,
+			processing_fee_percent = EXCLUDED.processing_fee_percent,
+			max_foir = COALESCE(EXCLUDED.max_foir, loan_products.max_foir),
+			source_url = EXCLUDED.source_url,
+			currency = EXCLUDED.currency,
+			is_active = true,
+			last_crawled_at = EXCLUDED.last_crawled_at,
+			updated_at = EXCLUDED.updated_at
+		RETURNING id, xmax = 0, terms_changed_at = (now() AT TIME ZONE 'UTC')`
+
+	var result models.ProductUpsert
+	err := r.db.WithTransaction(ctx, func(tx pgx.Tx) error {
+		// Read by the product version trigger; local to the transaction
+		if _, err := tx.Exec(ctx, "SELECT set_config('loan.product_change_source', $1, true)", string(source)); err != nil {
+			return fmt.Errorf("failed to set product change source: %w", err)
+		}
+
+		return tx.QueryRow(ctx, query,
+			product.ProductName,
+			product.ProviderName,
+			string(product.ProductType),
+			product.InterestRateMin,
+			product.InterestRateMax,
+			product.LoanAmountMin,
+			product.LoanAmountMax,
+			product.TenureMinMonths,
+			product.TenureMaxMonths,
+			product.MinMonthlyIncome,
+			product.MinCreditScore,
+			product.MaxCreditScore,
+			product.MinAge,
+			product.MaxAge,
+			empStatus,
+			product.ProcessingFeePercent,
+			product.MaxFOIR,
+			product.SourceURL,
+			string(product.Currency.OrDefault()),
+			time.Now().UTC(),
+		).Scan(&result.ID, &result.Inserted, &result.Changed)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to upsert loan product: %w", err)
+	}
+
+	return &result, nil
+}
+
+// GetPendingRematch returns the IDs of products, active or not, whose terms or
+// active flag changed since they were last re-matched.
+func (r *ProductRepository) GetPendingRematch(ctx context.Context) ([]int64, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id FROM loan_products
+		WHERE rematched_at IS NULL OR terms_changed_at > rematched_at
+		ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query products pending rematch: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan product id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// MarkRematched records that products were re-matched as of the given time.
+// Changes made after that time will be picked up by the next run.
+func (r *ProductRepository) MarkRematched(ctx context.Context, ids []int64, at time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		"UPDATE loan_products SET rematched_at = $1 WHERE id = ANY($2)",
+		at, ids)
+	if err != nil {
+		return fmt.Errorf("failed to mark products rematched: %w", err)
+	}
+	return nil
+}
+
 // GetByID retrieves a loan product by its ID.
 func (r *ProductRepository) GetByID(ctx context.Context, id int64) (*models.LoanProduct, error) {
 	query := `
 		SELECT id, product_name, provider_name, product_type, interest_rate_min, interest_rate_max,
 			loan_amount_min, loan_amount_max, tenure_min_months, tenure_max_months,
 			min_monthly_income, min_credit_score, max_credit_score, min_age, max_age,
-			accepted_employment_status, processing_fee_percent, source_url,
-			created_at, updated_at, is_active, last_crawled_at
+			accepted_employment_status, processing_fee_percent, max_foir, source_url, currency,
+			created_at, updated_at, is_active, last_crawled_at, version
 		FROM loan_products
 		WHERE id = $1`
 
@@ -98,8 +216,8 @@ func (r *ProductRepository) GetAllActive(ctx context.Context) ([]*models.LoanPro
 		SELECT id, product_name, provider_name, product_type, interest_rate_min, interest_rate_max,
 			loan_amount_min, loan_amount_max, tenure_min_months, tenure_max_months,
 			min_monthly_income, min_credit_score, max_credit_score, min_age, max_age,
-			accepted_employment_status, processing_fee_percent, source_url,
-			created_at, updated_at, is_active, last_crawled_at
+			accepted_employment_status, processing_fee_percent, max_foir, source_url, currency,
+			created_at, updated_at, is_active, last_crawled_at, version
 		FROM loan_products
 		WHERE is_active = true
 		ORDER BY id`
@@ -141,7 +259,7 @@ func (r *ProductRepository) Deactivate(ctx context.Context, id int64) error {
 // scanProduct scans a single row into a LoanProduct.
 func (r *ProductRepository) scanProduct(row pgx.Row) (*models.LoanProduct, error) {
 	var product models.LoanProduct
-	var productType string
+	var productType, currency string
 	var empStatus []string
 
 	err := row.Scan(
@@ -162,11 +280,14 @@ func (r *ProductRepository) scanProduct(row pgx.Row) (*models.LoanProduct, error
 		&product.MaxAge,
 		&empStatus,
 		&product.ProcessingFeePercent,
+		&product.MaxFOIR,
 		&product.SourceURL,
+		&currency,
 		&product.CreatedAt,
 		&product.UpdatedAt,
 		&product.IsActive,
 		&product.LastCrawledAt,
+		&product.Version,
 	)
 
 	if err != nil {
@@ -174,6 +295,7 @@ func (r *ProductRepository) scanProduct(row pgx.Row) (*models.LoanProduct, error
 	}
 
 	product.ProductType = models.LoanProductType(productType)
+	product.Currency = models.Currency(currency)
 
 	// Convert string slice to EmploymentStatus slice
 	for _, s := range empStatus {
@@ -186,7 +308,7 @@ func (r *ProductRepository) scanProduct(row pgx.Row) (*models.LoanProduct, error
 // scanProductRow scans a row from pgx.Rows into a LoanProduct.
 func (r *ProductRepository) scanProductRow(rows pgx.Rows) (*models.LoanProduct, error) {
 	var product models.LoanProduct
-	var productType string
+	var productType, currency string
 	var empStatus []string
 
 	err := rows.Scan(
@@ -207,11 +329,14 @@ func (r *ProductRepository) scanProductRow(rows pgx.Rows) (*models.LoanProduct,
 		&product.MaxAge,
 		&empStatus,
 		&product.ProcessingFeePercent,
+		&product.MaxFOIR,
 		&product.SourceURL,
+		&currency,
 		&product.CreatedAt,
 		&product.UpdatedAt,
 		&product.IsActive,
 		&product.LastCrawledAt,
+		&product.Version,
 	)
 
 	if err != nil {
@@ -219,6 +344,7 @@ func (r *ProductRepository) scanProductRow(rows pgx.Rows) (*models.LoanProduct,
 	}
 
 	product.ProductType = models.LoanProductType(productType)
+	product.Currency = models.Currency(currency)
 
 	// Convert string slice to EmploymentStatus slice
 	for _, s := range empStatus {
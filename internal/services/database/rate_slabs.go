@@ -0,0 +1,133 @@
+// Package database provides database operations for the loan eligibility engine.
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"loan-eligibility-engine/internal/models"
+)
+
+// RateSlabRepository handles product rate slab database operations.
+type RateSlabRepository struct {
+	db *DB
+}
+
+// NewRateSlabRepository creates a new rate slab repository.
+func NewRateSlabRepository(db *DB) *RateSlabRepository {
+	return &RateSlabRepository{db: db}
+}
+
+// GetByProductID retrieves the rate slabs of a product.
+func (r *RateSlabRepository) GetByProductID(ctx context.Context, productID int64) ([]*models.RateSlab, error) {
+	query := `
+		SELECT id, product_id, min_credit_score, max_credit_score, min_monthly_income, max_monthly_income,
+			interest_rate, processing_fee_percent, created_at, updated_at
+		FROM product_rate_slabs
+		WHERE product_id = $1
+		ORDER BY min_credit_score, min_monthly_income, id`
+
+	rows, err := r.db.QueryContext(ctx, query, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rate slabs: %w", err)
+	}
+	defer rows.Close()
+
+	return scanRateSlabs(rows)
+}
+
+// GetByProductIDs retrieves rate slabs grouped by product ID.
+func (r *RateSlabRepository) GetByProductIDs(ctx context.Context, productIDs []int64) (map[int64][]*models.RateSlab, error) {
+	result := make(map[int64][]*models.RateSlab)
+	if len(productIDs) == 0 {
+		return result, nil
+	}
+
+	query := `
+		SELECT id, product_id, min_credit_score, max_credit_score, min_monthly_income, max_monthly_income,
+			interest_rate, processing_fee_percent, created_at, updated_at
+		FROM product_rate_slabs
+		WHERE product_id = ANY($1)
+		ORDER BY product_id, min_credit_score, min_monthly_income, id`
+
+	rows, err := r.db.QueryContext(ctx, query, productIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rate slabs: %w", err)
+	}
+	defer rows.Close()
+
+	slabs, err := scanRateSlabs(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, slab := range slabs {
+		result[slab.ProductID] = append(result[slab.ProductID], slab)
+	}
+
+	return result, nil
+}
+
+// ReplaceForProduct atomically replaces the rate slabs of a product and flags
+// the product for re-matching, so stored matches get the new rates.
+func (r *RateSlabRepository) ReplaceForProduct(ctx context.Context, productID int64, slabs []*models.RateSlabCreate) error {
+	return r.db.WithTransaction(ctx, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, "DELETE FROM product_rate_slabs WHERE product_id = $1", productID); err != nil {
+			return fmt.Errorf("failed to delete rate slabs: %w", err)
+		}
+
+		now := time.Now().UTC()
+		for _, slab := range slabs {
+			_, err := tx.Exec(ctx, `
+				INSERT INTO product_rate_slabs (
+					product_id, min_credit_score, max_credit_score, min_monthly_income, max_monthly_income,
+					interest_rate, processing_fee_percent, created_at, updated_at
+				) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8)`,
+				productID,
+				slab.MinCreditScore,
+				slab.MaxCreditScore,
+				slab.MinMonthlyIncome,
+				slab.MaxMonthlyIncome,
+				slab.InterestRate,
+				slab.ProcessingFeePercent,
+				now,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to insert rate slab: %w", err)
+			}
+		}
+
+		if _, err := tx.Exec(ctx, "UPDATE loan_products SET terms_changed_at = $1 WHERE id = $2", now, productID); err != nil {
+			return fmt.Errorf("failed to flag product for rematch: %w", err)
+		}
+		return nil
+	})
+}
+
+// scanRateSlabs scans rate slab rows into a slice.
+func scanRateSlabs(rows pgx.Rows) ([]*models.RateSlab, error) {
+	var slabs []*models.RateSlab
+	for rows.Next() {
+		var slab models.RateSlab
+		err := rows.Scan(
+			&slab.ID,
+			&slab.ProductID,
+			&slab.MinCreditScore,
+			&slab.MaxCreditScore,
+			&slab.MinMonthlyIncome,
+			&slab.MaxMonthlyIncome,
+			&slab.InterestRate,
+			&slab.ProcessingFeePercent,
+			&slab.CreatedAt,
+			&slab.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan rate slab: %w", err)
+		}
+		slabs = append(slabs, &slab)
+	}
+	return slabs, rows.Err()
+}
@@ -0,0 +1,151 @@
+// Package database provides database operations for the loan eligibility engine.
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"loan-eligibility-engine/internal/models"
+)
+
+// RejectionRepository handles match rejection database operations.
+type RejectionRepository struct {
+	db *DB
+}
+
+// NewRejectionRepository creates a new rejection repository.
+func NewRejectionRepository(db *DB) *RejectionRepository {
+	return &RejectionRepository{db: db}
+}
+
+// ReplaceForUsers replaces the stored rejections of the given users in a single
+// transaction, so a re-run never leaves stale reasons behind.
+func (r *RejectionRepository) ReplaceForUsers(ctx context.Context, userIDs []int64, rejections []*models.MatchRejection) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	return r.db.WithTransaction(ctx, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, "DELETE FROM match_rejections WHERE user_id = ANY($1)", userIDs); err != nil {
+			return fmt.Errorf("failed to clear rejections: %w", err)
+		}
+
+		return insertRejections(ctx, tx, rejections)
+	})
+}
+
+// ReplaceForProductUsers replaces the stored rejections of a product for the
+// users with IDs in (afterUserID, throughUserID] in a single transaction, so
+// a product can be re-matched one page of users at a time.
+func (r *RejectionRepository) ReplaceForProductUsers(ctx context.Context, productID, afterUserID, throughUserID int64, rejections []*models.MatchRejection) error {
+	return r.db.WithTransaction(ctx, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `
+			DELETE FROM match_rejections
+			WHERE product_id = $1 AND user_id > $2 AND user_id <= $3`,
+			productID, afterUserID, throughUserID)
+		if err != nil {
+			return fmt.Errorf("failed to clear rejections: %w", err)
+		}
+		return insertRejections(ctx, tx, rejections)
+	})
+}
+
+// insertRejections upserts rejections within a transaction.
+func insertRejections(ctx context.Context, tx pgx.Tx, rejections []*models.MatchRejection) error {
+	now := time.Now().UTC()
+	for _, rej := range rejections {
+		reasons, err := json.Marshal(rej.Reasons)
+		if err != nil {
+			return fmt.Errorf("failed to marshal rejection reasons: %w", err)
+		}
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO match_rejections (user_id, product_id, stage, reasons, batch_id, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (user_id, product_id) DO UPDATE SET
+				stage = EXCLUDED.stage,
+				reasons = EXCLUDED.reasons,
+				batch_id = EXCLUDED.batch_id,
+				created_at = EXCLUDED.created_at`,
+			rej.UserID,
+			rej.ProductID,
+			string(rej.Stage),
+			string(reasons),
+			rej.BatchID,
+			now,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert rejection: %w", err)
+		}
+	}
+	return nil
+}
+
+// GetByUserID retrieves a user's rejections keyed by product ID.
+func (r *RejectionRepository) GetByUserID(ctx context.Context, userID int64) (map[int64]*models.MatchRejection, error) {
+	query := `
+		SELECT id, user_id, product_id, stage, reasons, COALESCE(batch_id, ''), created_at
+		FROM match_rejections
+		WHERE user_id = $1`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rejections: %w", err)
+	}
+	defer rows.Close()
+
+	rejections, err := scanRejections(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[int64]*models.MatchRejection, len(rejections))
+	for _, rej := range rejections {
+		result[rej.ProductID] = rej
+	}
+	return result, nil
+}
+
+// GetByStage retrieves the rejections made at a stage for the given users.
+func (r *RejectionRepository) GetByStage(ctx context.Context, userIDs []int64, stage models.MatchSource) ([]*models.MatchRejection, error) {
+	query := `
+		SELECT id, user_id, product_id, stage, reasons, COALESCE(batch_id, ''), created_at
+		FROM match_rejections
+		WHERE user_id = ANY($1) AND stage = $2`
+
+	rows, err := r.db.QueryContext(ctx, query, userIDs, string(stage))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rejections: %w", err)
+	}
+	defer rows.Close()
+
+	return scanRejections(rows)
+}
+
+// scanRejections scans rejection rows and decodes their reasons.
+func scanRejections(rows pgx.Rows) ([]*models.MatchRejection, error) {
+	var rejections []*models.MatchRejection
+	for rows.Next() {
+		var rej models.MatchRejection
+		var stage string
+		var reasons []byte
+
+		if err := rows.Scan(&rej.ID, &rej.UserID, &rej.ProductID, &stage, &reasons, &rej.BatchID, &rej.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan rejection: %w", err)
+		}
+
+		rej.Stage = models.MatchSource(stage)
+		if len(reasons) > 0 {
+			if err := json.Unmarshal(reasons, &rej.Reasons); err != nil {
+				return nil, fmt.Errorf("failed to decode rejection reasons: %w", err)
+			}
+		}
+		rejections = append(rejections, &rej)
+	}
+
+	return rejections, rows.Err()
+}
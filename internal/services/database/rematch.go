@@ -0,0 +1,141 @@
+// Package database provides database operations for the loan eligibility engine.
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"loan-eligibility-engine/internal/models"
+)
+
+// RematchRepository handles re-matching run database operations.
+type RematchRepository struct {
+	db *DB
+}
+
+// NewRematchRepository creates a new rematch repository.
+func NewRematchRepository(db *DB) *RematchRepository {
+	return &RematchRepository{db: db}
+}
+
+// CreateRun records the start of a re-matching run and sets its ID.
+func (r *RematchRepository) CreateRun(ctx context.Context, run *models.RematchRun) error {
+	query := `
+		INSERT INTO rematch_runs (trigger_source, product_ids, status, started_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id`
+
+	err := r.db.QueryRowContext(ctx, query,
+		string(run.Trigger),
+		run.ProductIDs,
+		string(models.RematchRunStatusRunning),
+		run.StartedAt,
+	).Scan(&run.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create rematch run: %w", err)
+	}
+
+	run.Status = models.RematchRunStatusRunning
+	return nil
+}
+
+// CompleteRun stores the run's changes and final status in a single transaction.
+func (r *RematchRepository) CompleteRun(ctx context.Context, run *models.RematchRun) error {
+	return r.db.WithTransaction(ctx, func(tx pgx.Tx) error {
+		now := time.Now().UTC()
+
+		for _, c := range run.Changes {
+			_, err := tx.Exec(ctx, `
+				INSERT INTO rematch_changes (run_id, user_id, product_id, change_type, old_score, new_score, created_at)
+				VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+				run.ID,
+				c.UserID,
+				c.ProductID,
+				string(c.ChangeType),
+				c.OldScore,
+				c.NewScore,
+				now,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to insert rematch change: %w", err)
+			}
+		}
+
+		_, err := tx.Exec(ctx, `
+			UPDATE rematch_runs SET
+				status = $1, matches_added = $2, matches_removed = $3, matches_rescored = $4,
+				error_message = NULLIF($5, ''), completed_at = $6
+			WHERE id = $7`,
+			string(run.Status),
+			run.Added,
+			run.Removed,
+			run.Rescored,
+			run.Error,
+			now,
+			run.ID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to complete rematch run: %w", err)
+		}
+
+		run.CompletedAt = &now
+		return nil
+	})
+}
+
+// GetRun retrieves a re-matching run with its changes. Returns nil if not found.
+func (r *RematchRepository) GetRun(ctx context.Context, id int64) (*models.RematchRun, error) {
+	query := `
+		SELECT id, trigger_source, product_ids, status, matches_added, matches_removed, matches_rescored,
+			COALESCE(error_message, ''), started_at, completed_at
+		FROM rematch_runs
+		WHERE id = $1`
+
+	var run models.RematchRun
+	var trigger, status string
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&run.ID,
+		&trigger,
+		&run.ProductIDs,
+		&status,
+		&run.Added,
+		&run.Removed,
+		&run.Rescored,
+		&run.Error,
+		&run.StartedAt,
+		&run.CompletedAt,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rematch run: %w", err)
+	}
+	run.Trigger = models.RematchTrigger(trigger)
+	run.Status = models.RematchRunStatus(status)
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, run_id, user_id, product_id, change_type, old_score, new_score, created_at
+		FROM rematch_changes
+		WHERE run_id = $1
+		ORDER BY product_id, change_type, user_id`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rematch changes: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c models.RematchChange
+		var changeType string
+		if err := rows.Scan(&c.ID, &c.RunID, &c.UserID, &c.ProductID, &changeType, &c.OldScore, &c.NewScore, &c.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan rematch change: %w", err)
+		}
+		c.ChangeType = models.RematchChangeType(changeType)
+		run.Changes = append(run.Changes, c)
+	}
+
+	return &run, rows.Err()
+}
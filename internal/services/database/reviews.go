@@ -0,0 +1,135 @@
+// Package database provides database operations for the loan eligibility engine.
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"loan-eligibility-engine/internal/models"
+)
+
+// ErrNotPendingReview is returned when deciding on a match that is not
+// awaiting review, for example because it was already decided.
+var ErrNotPendingReview = errors.New("match is not awaiting review")
+
+// ReviewRepository handles the human review queue.
+type ReviewRepository struct {
+	db *DB
+}
+
+// NewReviewRepository creates a new review repository.
+func NewReviewRepository(db *DB) *ReviewRepository {
+	return &ReviewRepository{db: db}
+}
+
+// ListPending retrieves matches awaiting review, oldest first.
+func (r *ReviewRepository) ListPending(ctx context.Context, limit, offset int) ([]*models.MatchWithDetails, error) {
+	query := `
+		SELECT
+			m.id, m.user_id, m.product_id, m.match_score, m.status, m.match_source,
+			m.income_eligible, m.credit_score_eligible, m.age_eligible, m.employment_eligible,
+			COALESCE(m.llm_analysis, ''), m.llm_confidence,
+			COALESCE(m.emi_min, 0), COALESCE(m.emi_max, 0), COALESCE(m.foir, 0), COALESCE(m.max_eligible_amount, 0),
+			COALESCE(m.estimated_rate, 0), m.processing_fee_percent, COALESCE(m.scoring_version, ''), m.product_version,
+			COALESCE(m.batch_id, ''), m.created_at, m.updated_at,
+			u.email as user_email, u.user_id as user_name,
+			p.product_name, p.provider_name, p.interest_rate_min, p.interest_rate_max,
+			p.loan_amount_min, p.loan_amount_max
+		FROM matches m
+		JOIN users u ON m.user_id = u.id
+		JOIN loan_products p ON m.product_id = p.id
+		WHERE m.status = 'pending_review'
+		ORDER BY m.updated_at, m.id
+		LIMIT $1 OFFSET $2`
+
+	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query review queue: %w", err)
+	}
+	defer rows.Close()
+
+	results := make([]*models.MatchWithDetails, 0)
+	for rows.Next() {
+		var m models.MatchWithDetails
+		var status, source string
+
+		err := rows.Scan(
+			&m.ID, &m.UserID, &m.ProductID, &m.MatchScore, &status, &source,
+			&m.IncomeEligible, &m.CreditScoreEligible, &m.AgeEligible, &m.EmploymentEligible,
+			&m.LLMAnalysis, &m.LLMConfidence,
+			&m.EMIMin, &m.EMIMax, &m.FOIR, &m.MaxEligibleAmount,
+			&m.EstimatedRate, &m.ProcessingFeePercent, &m.ScoringVersion, &m.ProductVersion,
+			&m.BatchID, &m.CreatedAt, &m.UpdatedAt,
+			&m.UserEmail, &m.UserName,
+			&m.ProductName, &m.ProviderName, &m.InterestRateMin, &m.InterestRateMax,
+			&m.LoanAmountMin, &m.LoanAmountMax,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan match: %w", err)
+		}
+
+		m.Status = models.MatchStatus(status)
+		m.MatchSource = models.MatchSource(source)
+		results = append(results, &m)
+	}
+
+	return results, rows.Err()
+}
+
+// Decide records a reviewer's decision on a match awaiting review and moves
+// the match to the resulting status with the manual source. Returns nil if
+// the match does not exist and ErrNotPendingReview if it is not awaiting
+// review.
+func (r *ReviewRepository) Decide(ctx context.Context, matchID int64, decision models.ReviewDecision, reviewer, comment string) (*models.MatchReview, error) {
+	var review *models.MatchReview
+
+	err := r.db.WithTransaction(ctx, func(tx pgx.Tx) error {
+		var status string
+		err := tx.QueryRow(ctx, "SELECT status FROM matches WHERE id = $1 FOR UPDATE", matchID).Scan(&status)
+		if err == pgx.ErrNoRows {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get match: %w", err)
+		}
+		if models.MatchStatus(status) != models.MatchStatusPendingReview {
+			return ErrNotPendingReview
+		}
+
+		now := time.Now().UTC()
+		_, err = tx.Exec(ctx,
+			"UPDATE matches SET status = $1, match_source = $2, updated_at = $3 WHERE id = $4",
+			string(decision.Status()), string(models.MatchSourceManual), now, matchID)
+		if err != nil {
+			return fmt.Errorf("failed to update match: %w", err)
+		}
+
+		review = &models.MatchReview{
+			MatchID:        matchID,
+			Decision:       decision,
+			Reviewer:       reviewer,
+			Comment:        comment,
+			PreviousStatus: models.MatchStatus(status),
+			CreatedAt:      now,
+		}
+		err = tx.QueryRow(ctx, `
+			INSERT INTO match_reviews (match_id, decision, reviewer, comment, previous_status, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id`,
+			matchID, string(decision), reviewer, comment, status, now,
+		).Scan(&review.ID)
+		if err != nil {
+			return fmt.Errorf("failed to record review: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return review, nil
+}
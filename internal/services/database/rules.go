@@ -0,0 +1,128 @@
+// Package database provides database operations for the loan eligibility engine.
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"loan-eligibility-engine/internal/models"
+)
+
+// RuleRepository handles eligibility rule database operations.
+type RuleRepository struct {
+	db *DB
+}
+
+// NewRuleRepository creates a new rule repository.
+func NewRuleRepository(db *DB) *RuleRepository {
+	return &RuleRepository{db: db}
+}
+
+// GetByProductID retrieves all rules for a product, including inactive ones.
+func (r *RuleRepository) GetByProductID(ctx context.Context, productID int64) ([]*models.EligibilityRule, error) {
+	query := `
+		SELECT id, product_id, name, expression, COALESCE(description, ''), priority, is_active, created_at, updated_at
+		FROM product_eligibility_rules
+		WHERE product_id = $1
+		ORDER BY priority, id`
+
+	rows, err := r.db.QueryContext(ctx, query, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rules: %w", err)
+	}
+	defer rows.Close()
+
+	return scanRules(rows)
+}
+
+// GetActiveByProductIDs retrieves active rules grouped by product ID.
+func (r *RuleRepository) GetActiveByProductIDs(ctx context.Context, productIDs []int64) (map[int64][]*models.EligibilityRule, error) {
+	result := make(map[int64][]*models.EligibilityRule)
+	if len(productIDs) == 0 {
+		return result, nil
+	}
+
+	query := `
+		SELECT id, product_id, name, expression, COALESCE(description, ''), priority, is_active, created_at, updated_at
+		FROM product_eligibility_rules
+		WHERE product_id = ANY($1) AND is_active = true
+		ORDER BY product_id, priority, id`
+
+	rows, err := r.db.QueryContext(ctx, query, productIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules, err := scanRules(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rule := range rules {
+		result[rule.ProductID] = append(result[rule.ProductID], rule)
+	}
+
+	return result, nil
+}
+
+// ReplaceForProduct atomically replaces the rule set of a product and flags
+// the product for re-matching.
+func (r *RuleRepository) ReplaceForProduct(ctx context.Context, productID int64, rules []*models.EligibilityRuleCreate) error {
+	return r.db.WithTransaction(ctx, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, "DELETE FROM product_eligibility_rules WHERE product_id = $1", productID); err != nil {
+			return fmt.Errorf("failed to delete rules: %w", err)
+		}
+
+		now := time.Now().UTC()
+		for _, rule := range rules {
+			_, err := tx.Exec(ctx, `
+				INSERT INTO product_eligibility_rules (
+					product_id, name, expression, description, priority, is_active, created_at, updated_at
+				) VALUES ($1, $2, $3, $4, $5, true, $6, $6)`,
+				productID,
+				rule.Name,
+				rule.Expression,
+				rule.Description,
+				rule.Priority,
+				now,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to insert rule %s: %w", rule.Name, err)
+			}
+		}
+
+		// New rules change who matches, so the product needs re-matching
+		if _, err := tx.Exec(ctx, "UPDATE loan_products SET terms_changed_at = $1 WHERE id = $2", now, productID); err != nil {
+			return fmt.Errorf("failed to flag product for rematch: %w", err)
+		}
+		return nil
+	})
+}
+
+// scanRules scans rule rows into a slice.
+func scanRules(rows pgx.Rows) ([]*models.EligibilityRule, error) {
+	var rules []*models.EligibilityRule
+	for rows.Next() {
+		var rule models.EligibilityRule
+		err := rows.Scan(
+			&rule.ID,
+			&rule.ProductID,
+			&rule.Name,
+			&rule.Expression,
+			&rule.Description,
+			&rule.Priority,
+			&rule.IsActive,
+			&rule.CreatedAt,
+			&rule.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan rule: %w", err)
+		}
+		rules = append(rules, &rule)
+	}
+	return rules, rows.Err()
+}
@@ -0,0 +1,47 @@
+// Package database provides database operations for the loan eligibility engine.
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ScoringModelRepository records the scoring model versions used for matches.
+type ScoringModelRepository struct {
+	db *DB
+}
+
+// NewScoringModelRepository creates a new scoring model repository.
+func NewScoringModelRepository(db *DB) *ScoringModelRepository {
+	return &ScoringModelRepository{db: db}
+}
+
+// Register records a scoring model version and its JSON definition. It
+// reports false if the version is already registered with a different
+// definition, since a version must always mean the same weights.
+func (r *ScoringModelRepository) Register(ctx context.Context, version string, definition []byte) (bool, error) {
+	var same bool
+	err := r.db.WithTransaction(ctx, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO scoring_models (version, definition, created_at)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (version) DO NOTHING`,
+			version, string(definition), time.Now().UTC())
+		if err != nil {
+			return fmt.Errorf("failed to register scoring model: %w", err)
+		}
+
+		err = tx.QueryRow(ctx,
+			"SELECT definition = $2::jsonb FROM scoring_models WHERE version = $1",
+			version, string(definition)).Scan(&same)
+		if err != nil {
+			return fmt.Errorf("failed to check scoring model: %w", err)
+		}
+		return nil
+	})
+
+	return same, err
+}
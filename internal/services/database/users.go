@@ -25,8 +25,10 @@ func NewUserRepository(db *DB) *UserRepository {
 // Create inserts a new user into the database.
 func (r *UserRepository) Create(ctx context.Context, user *models.UserCreate) (int64, error) {
 	query := `
-		INSERT INTO users (user_id, email, monthly_income, credit_score, employment_status, age, batch_id, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8)
+		INSERT INTO users (user_id, email, monthly_income, credit_score, employment_status, age, batch_id,
+			existing_emi, credit_card_outstanding, active_loans,
+			requested_amount, requested_tenure_months, loan_purpose, currency, locale, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $16)
 		ON CONFLICT (user_id) DO UPDATE SET
 			email = EXCLUDED.email,
 			monthly_income = EXCLUDED.monthly_income,
@@ -34,6 +36,14 @@ func (r *UserRepository) Create(ctx context.Context, user *models.UserCreate) (i
 			employment_status = EXCLUDED.employment_status,
 			age = EXCLUDED.age,
 			batch_id = EXCLUDED.batch_id,
+			existing_emi = EXCLUDED.existing_emi,
+			credit_card_outstanding = EXCLUDED.credit_card_outstanding,
+			active_loans = EXCLUDED.active_loans,
+			requested_amount = EXCLUDED.requested_amount,
+			requested_tenure_months = EXCLUDED.requested_tenure_months,
+			loan_purpose = EXCLUDED.loan_purpose,
+			currency = EXCLUDED.currency,
+			locale = EXCLUDED.locale,
 			updated_at = EXCLUDED.updated_at
 		RETURNING id`
 
@@ -46,6 +56,14 @@ func (r *UserRepository) Create(ctx context.Context, user *models.UserCreate) (i
 		string(user.EmploymentStatus),
 		user.Age,
 		user.BatchID,
+		user.ExistingEMI,
+		user.CreditCardOutstanding,
+		user.ActiveLoans,
+		user.RequestedAmount,
+		user.RequestedTenureMonths,
+		string(user.LoanPurpose),
+		string(user.Currency.OrDefault()),
+		user.Locale,
 		time.Now().UTC(),
 	).Scan(&id)
 
@@ -64,12 +82,19 @@ func (r *UserRepository) BulkInsert(ctx context.Context, users []*models.UserCre
 		Errors:        []string{},
 	}
 
-	// Use a transaction for bulk insert
+	// Use a transaction for bulk insert, with a savepoint per row so that a
+	// row Postgres rejects does not abort the rows after it
 	err := r.db.WithTransaction(ctx, func(tx pgx.Tx) error {
 		for _, user := range users {
-			_, err := tx.Exec(ctx, `
-				INSERT INTO users (user_id, email, monthly_income, credit_score, employment_status, age, batch_id, created_at, updated_at, is_active)
-				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8, true)
+			row, err := tx.Begin(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to create savepoint: %w", err)
+			}
+			_, err = row.Exec(ctx, `
+				INSERT INTO users (user_id, email, monthly_income, credit_score, employment_status, age, batch_id,
+					existing_emi, credit_card_outstanding, active_loans,
+					requested_amount, requested_tenure_months, loan_purpose, currency, locale, created_at, updated_at, is_active)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $16, true)
 				ON CONFLICT (user_id) DO UPDATE SET
 					email = EXCLUDED.email,
 					monthly_income = EXCLUDED.monthly_income,
@@ -77,6 +102,14 @@ func (r *UserRepository) BulkInsert(ctx context.Context, users []*models.UserCre
 					employment_status = EXCLUDED.employment_status,
 					age = EXCLUDED.age,
 					batch_id = EXCLUDED.batch_id,
+					existing_emi = EXCLUDED.existing_emi,
+					credit_card_outstanding = EXCLUDED.credit_card_outstanding,
+					active_loans = EXCLUDED.active_loans,
+					requested_amount = EXCLUDED.requested_amount,
+					requested_tenure_months = EXCLUDED.requested_tenure_months,
+					loan_purpose = EXCLUDED.loan_purpose,
+					currency = EXCLUDED.currency,
+					locale = EXCLUDED.locale,
 					updated_at = EXCLUDED.updated_at`,
 				user.UserID,
 				user.Email,
@@ -85,15 +118,29 @@ func (r *UserRepository) BulkInsert(ctx context.Context, users []*models.UserCre
 				string(user.EmploymentStatus),
 				user.Age,
 				user.BatchID,
+				user.ExistingEMI,
+				user.CreditCardOutstanding,
+				user.ActiveLoans,
+				user.RequestedAmount,
+				user.RequestedTenureMonths,
+				string(user.LoanPurpose),
+				string(user.Currency.OrDefault()),
+				user.Locale,
 				time.Now().UTC(),
 			)
 
 			if err != nil {
+				if rbErr := row.Rollback(ctx); rbErr != nil {
+					return fmt.Errorf("failed to roll back to savepoint: %w", rbErr)
+				}
 				result.FailedCount++
 				result.Errors = append(result.Errors, fmt.Sprintf("user %s: %v", user.UserID, err))
-			} else {
-				result.InsertedCount++
+				continue
+			}
+			if err := row.Commit(ctx); err != nil {
+				return fmt.Errorf("failed to release savepoint: %w", err)
 			}
+			result.InsertedCount++
 		}
 		return nil
 	})
@@ -108,12 +155,14 @@ func (r *UserRepository) BulkInsert(ctx context.Context, users []*models.UserCre
 // GetByID retrieves a user by their database ID.
 func (r *UserRepository) GetByID(ctx context.Context, id int64) (*models.User, error) {
 	query := `
-		SELECT id, user_id, email, monthly_income, credit_score, employment_status, age, batch_id, created_at, updated_at, is_active
+		SELECT id, user_id, email, monthly_income, credit_score, employment_status, age, batch_id, created_at, updated_at, is_active,
+			existing_emi, credit_card_outstanding, active_loans,
+			requested_amount, requested_tenure_months, loan_purpose, currency, locale
 		FROM users
 		WHERE id = $1`
 
 	var user models.User
-	var empStatus string
+	var empStatus, loanPurpose, currency string
 
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&user.ID,
@@ -127,6 +176,14 @@ func (r *UserRepository) GetByID(ctx context.Context, id int64) (*models.User, e
 		&user.CreatedAt,
 		&user.UpdatedAt,
 		&user.IsActive,
+		&user.ExistingEMI,
+		&user.CreditCardOutstanding,
+		&user.ActiveLoans,
+		&user.RequestedAmount,
+		&user.RequestedTenureMonths,
+		&loanPurpose,
+		&currency,
+		&user.Locale,
 	)
 
 	if err == pgx.ErrNoRows {
@@ -137,6 +194,8 @@ func (r *UserRepository) GetByID(ctx context.Context, id int64) (*models.User, e
 	}
 
 	user.EmploymentStatus = models.EmploymentStatus(empStatus)
+	user.LoanPurpose = models.LoanProductType(loanPurpose)
+	user.Currency = models.Currency(currency)
 	return &user, nil
 }
 
@@ -155,7 +214,9 @@ func (r *UserRepository) GetByIDs(ctx context.Context, ids []int64) ([]*models.U
 	}
 
 	query := fmt.Sprintf(`
-		SELECT id, user_id, email, monthly_income, credit_score, employment_status, age, batch_id, created_at, updated_at, is_active
+		SELECT id, user_id, email, monthly_income, credit_score, employment_status, age, batch_id, created_at, updated_at, is_active,
+			existing_emi, credit_card_outstanding, active_loans,
+			requested_amount, requested_tenure_months, loan_purpose, currency, locale
 		FROM users
 		WHERE id IN (%s) AND is_active = true
 		ORDER BY id`, strings.Join(placeholders, ","))
@@ -169,7 +230,7 @@ func (r *UserRepository) GetByIDs(ctx context.Context, ids []int64) ([]*models.U
 	var users []*models.User
 	for rows.Next() {
 		var user models.User
-		var empStatus string
+		var empStatus, loanPurpose, currency string
 
 		err := rows.Scan(
 			&user.ID,
@@ -183,12 +244,22 @@ func (r *UserRepository) GetByIDs(ctx context.Context, ids []int64) ([]*models.U
 			&user.CreatedAt,
 			&user.UpdatedAt,
 			&user.IsActive,
+			&user.ExistingEMI,
+			&user.CreditCardOutstanding,
+			&user.ActiveLoans,
+			&user.RequestedAmount,
+			&user.RequestedTenureMonths,
+			&loanPurpose,
+			&currency,
+			&user.Locale,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan user: %w", err)
 		}
 
 		user.EmploymentStatus = models.EmploymentStatus(empStatus)
+		user.LoanPurpose = models.LoanProductType(loanPurpose)
+		user.Currency = models.Currency(currency)
 		users = append(users, &user)
 	}
 
@@ -198,12 +269,14 @@ func (r *UserRepository) GetByIDs(ctx context.Context, ids []int64) ([]*models.U
 // GetByUserID retrieves a user by their external user ID.
 func (r *UserRepository) GetByUserID(ctx context.Context, userID string) (*models.User, error) {
 	query := `
-		SELECT id, user_id, email, monthly_income, credit_score, employment_status, age, batch_id, created_at, updated_at, is_active
+		SELECT id, user_id, email, monthly_income, credit_score, employment_status, age, batch_id, created_at, updated_at, is_active,
+			existing_emi, credit_card_outstanding, active_loans,
+			requested_amount, requested_tenure_months, loan_purpose, currency, locale
 		FROM users
 		WHERE user_id = $1 AND is_active = true`
 
 	var user models.User
-	var empStatus string
+	var empStatus, loanPurpose, currency string
 
 	err := r.db.QueryRowContext(ctx, query, userID).Scan(
 		&user.ID,
@@ -217,6 +290,14 @@ func (r *UserRepository) GetByUserID(ctx context.Context, userID string) (*model
 		&user.CreatedAt,
 		&user.UpdatedAt,
 		&user.IsActive,
+		&user.ExistingEMI,
+		&user.CreditCardOutstanding,
+		&user.ActiveLoans,
+		&user.RequestedAmount,
+		&user.RequestedTenureMonths,
+		&loanPurpose,
+		&currency,
+		&user.Locale,
 	)
 
 	if err == pgx.ErrNoRows {
@@ -227,13 +308,17 @@ func (r *UserRepository) GetByUserID(ctx context.Context, userID string) (*model
 	}
 
 	user.EmploymentStatus = models.EmploymentStatus(empStatus)
+	user.LoanPurpose = models.LoanProductType(loanPurpose)
+	user.Currency = models.Currency(currency)
 	return &user, nil
 }
 
 // GetByBatchID retrieves all users from a specific batch.
 func (r *UserRepository) GetByBatchID(ctx context.Context, batchID string) ([]*models.User, error) {
 	query := `
-		SELECT id, user_id, email, monthly_income, credit_score, employment_status, age, batch_id, created_at, updated_at, is_active
+		SELECT id, user_id, email, monthly_income, credit_score, employment_status, age, batch_id, created_at, updated_at, is_active,
+			existing_emi, credit_card_outstanding, active_loans,
+			requested_amount, requested_tenure_months, loan_purpose, currency, locale
 		FROM users
 		WHERE batch_id = $1 AND is_active = true
 		ORDER BY id`
@@ -247,7 +332,7 @@ func (r *UserRepository) GetByBatchID(ctx context.Context, batchID string) ([]*m
 	var users []*models.User
 	for rows.Next() {
 		var user models.User
-		var empStatus string
+		var empStatus, loanPurpose, currency string
 
 		err := rows.Scan(
 			&user.ID,
@@ -261,12 +346,22 @@ func (r *UserRepository) GetByBatchID(ctx context.Context, batchID string) ([]*m
 			&user.CreatedAt,
 			&user.UpdatedAt,
 			&user.IsActive,
+			&user.ExistingEMI,
+			&user.CreditCardOutstanding,
+			&user.ActiveLoans,
+			&user.RequestedAmount,
+			&user.RequestedTenureMonths,
+			&loanPurpose,
+			&currency,
+			&user.Locale,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan user: %w", err)
 		}
 
 		user.EmploymentStatus = models.EmploymentStatus(empStatus)
+		user.LoanPurpose = models.LoanProductType(loanPurpose)
+		user.Currency = models.Currency(currency)
 		users = append(users, &user)
 	}
 
@@ -276,7 +371,9 @@ func (r *UserRepository) GetByBatchID(ctx context.Context, batchID string) ([]*m
 // GetAllActive retrieves all active users.
 func (r *UserRepository) GetAllActive(ctx context.Context) ([]*models.User, error) {
 	query := `
-		SELECT id, user_id, email, monthly_income, credit_score, employment_status, age, batch_id, created_at, updated_at, is_active
+		SELECT id, user_id, email, monthly_income, credit_score, employment_status, age, batch_id, created_at, updated_at, is_active,
+			existing_emi, credit_card_outstanding, active_loans,
+			requested_amount, requested_tenure_months, loan_purpose, currency, locale
 		FROM users
 		WHERE is_active = true
 		ORDER BY id`
@@ -290,7 +387,62 @@ func (r *UserRepository) GetAllActive(ctx context.Context) ([]*models.User, erro
 	var users []*models.User
 	for rows.Next() {
 		var user models.User
-		var empStatus string
+		var empStatus, loanPurpose, currency string
+
+		err := rows.Scan(
+			&user.ID,
+			&user.UserID,
+			&user.Email,
+			&user.MonthlyIncome,
+			&user.CreditScore,
+			&empStatus,
+			&user.Age,
+			&user.BatchID,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+			&user.IsActive,
+			&user.ExistingEMI,
+			&user.CreditCardOutstanding,
+			&user.ActiveLoans,
+			&user.RequestedAmount,
+			&user.RequestedTenureMonths,
+			&loanPurpose,
+			&currency,
+			&user.Locale,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+
+		user.EmploymentStatus = models.EmploymentStatus(empStatus)
+		user.LoanPurpose = models.LoanProductType(loanPurpose)
+		user.Currency = models.Currency(currency)
+		users = append(users, &user)
+	}
+
+	return users, nil
+}
+
+// GetCreatedBetween retrieves active users created in [from, to).
+func (r *UserRepository) GetCreatedBetween(ctx context.Context, from, to time.Time) ([]*models.User, error) {
+	query := `
+		SELECT id, user_id, email, monthly_income, credit_score, employment_status, age, batch_id, created_at, updated_at, is_active,
+			existing_emi, credit_card_outstanding, active_loans,
+			requested_amount, requested_tenure_months, loan_purpose, currency, locale
+		FROM users
+		WHERE created_at >= $1 AND created_at < $2 AND is_active = true
+		ORDER BY id`
+
+	rows, err := r.db.QueryContext(ctx, query, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []*models.User
+	for rows.Next() {
+		var user models.User
+		var empStatus, loanPurpose, currency string
 
 		err := rows.Scan(
 			&user.ID,
@@ -304,12 +456,22 @@ func (r *UserRepository) GetAllActive(ctx context.Context) ([]*models.User, erro
 			&user.CreatedAt,
 			&user.UpdatedAt,
 			&user.IsActive,
+			&user.ExistingEMI,
+			&user.CreditCardOutstanding,
+			&user.ActiveLoans,
+			&user.RequestedAmount,
+			&user.RequestedTenureMonths,
+			&loanPurpose,
+			&currency,
+			&user.Locale,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan user: %w", err)
 		}
 
 		user.EmploymentStatus = models.EmploymentStatus(empStatus)
+		user.LoanPurpose = models.LoanProductType(loanPurpose)
+		user.Currency = models.Currency(currency)
 		users = append(users, &user)
 	}
 
@@ -325,3 +487,96 @@ func (r *UserRepository) CountByBatchID(ctx context.Context, batchID string) (in
 	}
 	return count, nil
 }
+
+// CountActive returns the number of active users.
+func (r *UserRepository) CountActive(ctx context.Context) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM users WHERE is_active = true").Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count users: %w", err)
+	}
+	return count, nil
+}
+
+// GetIDsByBatchID returns up to limit active user IDs from a batch that are
+// greater than afterID, in ascending order, for keyset pagination.
+func (r *UserRepository) GetIDsByBatchID(ctx context.Context, batchID string, afterID int64, limit int) ([]int64, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id FROM users
+		WHERE batch_id = $1 AND is_active = true AND id > $2
+		ORDER BY id
+		LIMIT $3`,
+		batchID, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user ids: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan user id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// GetActiveAfter returns up to limit active users with IDs greater than
+// afterID, in ascending order, for keyset pagination.
+func (r *UserRepository) GetActiveAfter(ctx context.Context, afterID int64, limit int) ([]*models.User, error) {
+	query := `
+		SELECT id, user_id, email, monthly_income, credit_score, employment_status, age, batch_id, created_at, updated_at, is_active,
+			existing_emi, credit_card_outstanding, active_loans,
+			requested_amount, requested_tenure_months, loan_purpose, currency, locale
+		FROM users
+		WHERE is_active = true AND id > $1
+		ORDER BY id
+		LIMIT $2`
+
+	rows, err := r.db.QueryContext(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []*models.User
+	for rows.Next() {
+		var user models.User
+		var empStatus, loanPurpose, currency string
+
+		err := rows.Scan(
+			&user.ID,
+			&user.UserID,
+			&user.Email,
+			&user.MonthlyIncome,
+			&user.CreditScore,
+			&empStatus,
+			&user.Age,
+			&user.BatchID,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+			&user.IsActive,
+			&user.ExistingEMI,
+			&user.CreditCardOutstanding,
+			&user.ActiveLoans,
+			&user.RequestedAmount,
+			&user.RequestedTenureMonths,
+			&loanPurpose,
+			&currency,
+			&user.Locale,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+
+		user.EmploymentStatus = models.EmploymentStatus(empStatus)
+		user.LoanPurpose = models.LoanProductType(loanPurpose)
+		user.Currency = models.Currency(currency)
+		users = append(users, &user)
+	}
+
+	return users, rows.Err()
+}
@@ -0,0 +1,161 @@
+// Package database provides database operations for the loan eligibility engine.
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"loan-eligibility-engine/internal/models"
+)
+
+// VerdictCacheRepository handles cached LLM verdict database operations.
+type VerdictCacheRepository struct {
+	db *DB
+}
+
+// NewVerdictCacheRepository creates a new verdict cache repository.
+func NewVerdictCacheRepository(db *DB) *VerdictCacheRepository {
+	return &VerdictCacheRepository{db: db}
+}
+
+// Get retrieves an unexpired verdict by cache key. Returns nil on a miss.
+func (r *VerdictCacheRepository) Get(ctx context.Context, cacheKey string) (*models.LLMVerdict, error) {
+	query := `
+		SELECT cache_key, product_id, provider, prompt_version, qualified,
+			COALESCE(confidence, 0), COALESCE(reasoning, ''), risk_factors, created_at, expires_at
+		FROM llm_verdict_cache
+		WHERE cache_key = $1 AND expires_at > $2`
+
+	var v models.LLMVerdict
+	var riskFactors []byte
+	err := r.db.QueryRowContext(ctx, query, cacheKey, time.Now().UTC()).Scan(
+		&v.CacheKey,
+		&v.ProductID,
+		&v.Provider,
+		&v.PromptVersion,
+		&v.Qualified,
+		&v.Confidence,
+		&v.Reasoning,
+		&riskFactors,
+		&v.CreatedAt,
+		&v.ExpiresAt,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cached verdict: %w", err)
+	}
+
+	if len(riskFactors) > 0 {
+		if err := json.Unmarshal(riskFactors, &v.RiskFactors); err != nil {
+			return nil, fmt.Errorf("failed to decode risk factors: %w", err)
+		}
+	}
+
+	return &v, nil
+}
+
+// GetMany retrieves the unexpired verdicts for the given keys, keyed by cache key.
+func (r *VerdictCacheRepository) GetMany(ctx context.Context, cacheKeys []string) (map[string]*models.LLMVerdict, error) {
+	result := make(map[string]*models.LLMVerdict)
+	if len(cacheKeys) == 0 {
+		return result, nil
+	}
+
+	query := `
+		SELECT cache_key, product_id, provider, prompt_version, qualified,
+			COALESCE(confidence, 0), COALESCE(reasoning, ''), risk_factors, created_at, expires_at
+		FROM llm_verdict_cache
+		WHERE cache_key = ANY($1) AND expires_at > $2`
+
+	rows, err := r.db.QueryContext(ctx, query, cacheKeys, time.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cached verdicts: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var v models.LLMVerdict
+		var riskFactors []byte
+		if err := rows.Scan(
+			&v.CacheKey,
+			&v.ProductID,
+			&v.Provider,
+			&v.PromptVersion,
+			&v.Qualified,
+			&v.Confidence,
+			&v.Reasoning,
+			&riskFactors,
+			&v.CreatedAt,
+			&v.ExpiresAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan cached verdict: %w", err)
+		}
+
+		if len(riskFactors) > 0 {
+			if err := json.Unmarshal(riskFactors, &v.RiskFactors); err != nil {
+				return nil, fmt.Errorf("failed to decode risk factors: %w", err)
+			}
+		}
+		result[v.CacheKey] = &v
+	}
+
+	return result, rows.Err()
+}
+
+// Put stores a verdict, replacing any existing entry for the same key.
+func (r *VerdictCacheRepository) Put(ctx context.Context, v *models.LLMVerdict) error {
+	riskFactors := v.RiskFactors
+	if riskFactors == nil {
+		riskFactors = []string{}
+	}
+	riskJSON, err := json.Marshal(riskFactors)
+	if err != nil {
+		return fmt.Errorf("failed to marshal risk factors: %w", err)
+	}
+
+	query := `
+		INSERT INTO llm_verdict_cache (
+			cache_key, product_id, provider, prompt_version, qualified,
+			confidence, reasoning, risk_factors, created_at, expires_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (cache_key) DO UPDATE SET
+			qualified = EXCLUDED.qualified,
+			confidence = EXCLUDED.confidence,
+			reasoning = EXCLUDED.reasoning,
+			risk_factors = EXCLUDED.risk_factors,
+			created_at = EXCLUDED.created_at,
+			expires_at = EXCLUDED.expires_at`
+
+	_, err = r.db.ExecContext(ctx, query,
+		v.CacheKey,
+		v.ProductID,
+		v.Provider,
+		v.PromptVersion,
+		v.Qualified,
+		v.Confidence,
+		v.Reasoning,
+		string(riskJSON),
+		v.CreatedAt,
+		v.ExpiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to store verdict: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteExpired removes expired verdicts and returns how many were deleted.
+func (r *VerdictCacheRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	deleted, err := r.db.ExecContext(ctx, "DELETE FROM llm_verdict_cache WHERE expires_at <= $1", time.Now().UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired verdicts: %w", err)
+	}
+	return deleted, nil
+}
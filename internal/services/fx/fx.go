@@ -0,0 +1,103 @@
+// Package fx converts amounts between currencies with the configured exchange
+// rates, so that users and loan products in different currencies can be
+// compared.
+package fx
+
+import (
+	"math"
+
+	"loan-eligibility-engine/internal/models"
+)
+
+// Table holds the value of each currency in a common base currency. A nil
+// Table only converts between equal currencies.
+type Table struct {
+	rates map[models.Currency]float64
+}
+
+// NewTable builds a table from stored exchange rates. Non-positive rates are
+// ignored.
+func NewTable(rates []*models.FXRate) *Table {
+	t := &Table{rates: make(map[models.Currency]float64, len(rates))}
+	for _, r := range rates {
+		if r.RateToBase > 0 {
+			t.rates[r.Currency] = r.RateToBase
+		}
+	}
+	return t
+}
+
+// Rate returns the factor that converts amounts from one currency to another.
+// Empty currencies are DefaultCurrency. It reports false when either currency
+// has no rate.
+func (t *Table) Rate(from, to models.Currency) (float64, bool) {
+	from, to = from.OrDefault(), to.OrDefault()
+	if from == to {
+		return 1, true
+	}
+	if t == nil {
+		return 0, false
+	}
+
+	fromRate, ok := t.rates[from]
+	if !ok {
+		return 0, false
+	}
+	toRate, ok := t.rates[to]
+	if !ok {
+		return 0, false
+	}
+	return fromRate / toRate, true
+}
+
+// Convert converts an amount from one currency to another
+func (t *Table) Convert(amount float64, from, to models.Currency) (float64, bool) {
+	rate, ok := t.Rate(from, to)
+	if !ok {
+		return 0, false
+	}
+	return amount * rate, true
+}
+
+// UserIn returns the user as seen in another currency: income, obligations
+// and requested amount converted, and Currency set to the target. A user
+// already in that currency is returned as is; the user itself is never
+// modified.
+func (t *Table) UserIn(user *models.User, currency models.Currency) (*models.User, bool) {
+	if user.Currency.OrDefault() == currency.OrDefault() {
+		return user, true
+	}
+
+	rate, ok := t.Rate(user.Currency, currency)
+	if !ok {
+		return nil, false
+	}
+
+	converted := *user
+	converted.MonthlyIncome *= rate
+	converted.ExistingEMI *= rate
+	converted.CreditCardOutstanding *= rate
+	converted.RequestedAmount *= rate
+	converted.Currency = currency
+	return &converted, true
+}
+
+// CostIn converts the amounts of a borrowing cost to another currency,
+// rounded to two decimals. Rates, tenure and APR are unchanged.
+func (t *Table) CostIn(cost models.BorrowingCost, from, to models.Currency) (models.BorrowingCost, bool) {
+	rate, ok := t.Rate(from, to)
+	if !ok {
+		return cost, false
+	}
+	if rate == 1 {
+		return cost, true
+	}
+
+	convert := func(v float64) float64 { return math.Round(v*rate*100) / 100 }
+	cost.Amount = convert(cost.Amount)
+	cost.EMI = convert(cost.EMI)
+	cost.TotalInterest = convert(cost.TotalInterest)
+	cost.TotalFees = convert(cost.TotalFees)
+	cost.TotalCost = convert(cost.TotalCost)
+	return cost, true
+}
@@ -0,0 +1,306 @@
+// Package jobs runs background work from the Postgres-backed job queue. Each
+// worker claims one job at a time and renews its lease while the job runs, so
+// jobs survive restarts and several server instances can share one queue.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"loan-eligibility-engine/internal/models"
+	"loan-eligibility-engine/internal/services/database"
+	"loan-eligibility-engine/internal/utils"
+)
+
+// Defaults used for zero Options fields
+const (
+	DefaultWorkers      = 2
+	DefaultPollInterval = 2 * time.Second
+	DefaultLease        = time.Minute
+	DefaultRetryBackoff = 5 * time.Second
+)
+
+// maxRetryBackoff caps the delay before a failed job is retried
+const maxRetryBackoff = 10 * time.Minute
+
+// Store persists jobs. *database.JobRepository implements it.
+type Store interface {
+	Claim(ctx context.Context, workerID string, types []models.JobType, lease time.Duration) (*models.Job, error)
+	Heartbeat(ctx context.Context, id int64, workerID string, lease time.Duration, done, total int) (bool, error)
+	Complete(ctx context.Context, id int64, workerID string, result json.RawMessage, done, total int) error
+	Fail(ctx context.Context, id int64, workerID, errMsg string, retryAt *time.Time) (models.JobStatus, error)
+	MarkCancelled(ctx context.Context, id int64, workerID string, done, total int) error
+}
+
+// ProgressFunc reports how many of a job's units of work are done
+type ProgressFunc func(done, total int)
+
+// Handler performs a job and returns its result, which is stored as JSON.
+// The context is cancelled when the job is cancelled or the worker loses its
+// lease; handlers should stop at the next safe point.
+type Handler func(ctx context.Context, job *models.Job, progress ProgressFunc) (interface{}, error)
+
+// permanentError marks a failure that retrying cannot fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps an error so the job fails without being retried, for
+// example when its payload is invalid
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether an error was marked with Permanent
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
+
+// Options configures a Runner. Zero values select the defaults.
+type Options struct {
+	Workers      int
+	PollInterval time.Duration
+	Lease        time.Duration
+	RetryBackoff time.Duration
+}
+
+// Runner polls the queue and runs claimed jobs with the registered handlers
+type Runner struct {
+	store    Store
+	workerID string
+	opts     Options
+	handlers map[models.JobType]Handler
+}
+
+// NewRunner creates a runner. workerID must be unique across server
+// instances; each worker goroutine appends its own index to it.
+func NewRunner(store Store, workerID string, opts Options) *Runner {
+	if opts.Workers <= 0 {
+		opts.Workers = DefaultWorkers
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultPollInterval
+	}
+	if opts.Lease <= 0 {
+		opts.Lease = DefaultLease
+	}
+	if opts.RetryBackoff <= 0 {
+		opts.RetryBackoff = DefaultRetryBackoff
+	}
+	return &Runner{
+		store:    store,
+		workerID: workerID,
+		opts:     opts,
+		handlers: make(map[models.JobType]Handler),
+	}
+}
+
+// Handle registers the handler for a job type. Only job types with a handler
+// are claimed. Handlers must be registered before Run is called.
+func (r *Runner) Handle(jobType models.JobType, h Handler) {
+	r.handlers[jobType] = h
+}
+
+// Run starts the workers and blocks until ctx is cancelled and every worker
+// has stopped. A job interrupted by shutdown is queued again.
+func (r *Runner) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < r.opts.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.work(ctx, fmt.Sprintf("%s/%d", r.workerID, i))
+		}()
+	}
+	wg.Wait()
+}
+
+// RunOnce claims and runs a single job. Returns false if no job was runnable.
+func (r *Runner) RunOnce(ctx context.Context) (bool, error) {
+	return r.runOnce(ctx, r.workerID)
+}
+
+// work polls for jobs until ctx is cancelled
+func (r *Runner) work(ctx context.Context, workerID string) {
+	for ctx.Err() == nil {
+		ran, err := r.runOnce(ctx, workerID)
+		if err != nil && ctx.Err() == nil {
+			utils.Logger.Error("Job worker poll failed", zap.String("worker", workerID), zap.Error(err))
+		}
+		if ran {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(r.opts.PollInterval):
+		}
+	}
+}
+
+// runOnce claims a job for the worker and runs it
+func (r *Runner) runOnce(ctx context.Context, workerID string) (bool, error) {
+	types := make([]models.JobType, 0, len(r.handlers))
+	for t := range r.handlers {
+		types = append(types, t)
+	}
+
+	job, err := r.store.Claim(ctx, workerID, types, r.opts.Lease)
+	if err != nil || job == nil {
+		return false, err
+	}
+
+	r.execute(ctx, workerID, job)
+	return true, nil
+}
+
+// execute runs a claimed job while a heartbeat renews its lease, then records
+// the outcome
+func (r *Runner) execute(ctx context.Context, workerID string, job *models.Job) {
+	log := utils.Logger.With(
+		zap.Int64("job_id", job.ID),
+		zap.String("job_type", string(job.Type)),
+		zap.Int("attempt", job.Attempts),
+		zap.String("worker", workerID),
+	)
+	log.Info("Job started")
+
+	var mu sync.Mutex
+	done, total := job.ProgressDone, job.ProgressTotal
+	progress := func(d, t int) {
+		mu.Lock()
+		done, total = d, t
+		mu.Unlock()
+	}
+	snapshot := func() (int, int) {
+		mu.Lock()
+		defer mu.Unlock()
+		return done, total
+	}
+
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Renew the lease and pick up cancellation requests until the handler returns
+	var cancelled, lost bool
+	stop := make(chan struct{})
+	var hb sync.WaitGroup
+	hb.Add(1)
+	go func() {
+		defer hb.Done()
+		ticker := time.NewTicker(r.opts.Lease / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-jobCtx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			d, t := snapshot()
+			cancelRequested, err := r.store.Heartbeat(jobCtx, job.ID, workerID, r.opts.Lease, d, t)
+			switch {
+			case errors.Is(err, database.ErrJobLeaseLost):
+				mu.Lock()
+				lost = true
+				mu.Unlock()
+				cancel()
+				return
+			case err != nil:
+				log.Warn("Job heartbeat failed", zap.Error(err))
+			case cancelRequested:
+				mu.Lock()
+				cancelled = true
+				mu.Unlock()
+				cancel()
+				return
+			}
+		}
+	}()
+
+	result, err := runHandler(jobCtx, r.handlers[job.Type], job, progress)
+	close(stop)
+	hb.Wait()
+
+	// Record the outcome even if the runner is shutting down
+	finishCtx := context.WithoutCancel(ctx)
+	d, t := snapshot()
+	mu.Lock()
+	wasCancelled, wasLost := cancelled, lost
+	mu.Unlock()
+
+	switch {
+	case wasLost:
+		log.Warn("Job lease lost, leaving the job to its new worker")
+		return
+
+	case wasCancelled && err != nil:
+		err = r.store.MarkCancelled(finishCtx, job.ID, workerID, d, t)
+		log.Info("Job cancelled", zap.Int("progress_done", d), zap.Int("progress_total", t))
+
+	case err != nil:
+		var retryAt *time.Time
+		var permanent *permanentError
+		if ctx.Err() != nil {
+			// Interrupted by shutdown: let the next worker pick it up at once
+			now := time.Now().UTC()
+			retryAt = &now
+		} else if !errors.As(err, &permanent) {
+			next := time.Now().UTC().Add(r.backoff(job.Attempts))
+			retryAt = &next
+		}
+
+		errMsg := err.Error()
+		var status models.JobStatus
+		status, err = r.store.Fail(finishCtx, job.ID, workerID, errMsg, retryAt)
+		if err == nil {
+			log.Warn("Job failed", zap.String("status", string(status)), zap.String("error", errMsg))
+		}
+
+	default:
+		var encoded []byte
+		encoded, err = json.Marshal(result)
+		if err != nil {
+			_, err = r.store.Fail(finishCtx, job.ID, workerID, fmt.Sprintf("failed to encode result: %v", err), nil)
+			break
+		}
+		err = r.store.Complete(finishCtx, job.ID, workerID, encoded, d, t)
+		log.Info("Job succeeded")
+	}
+
+	if err != nil {
+		log.Error("Failed to record job outcome", zap.Error(err))
+	}
+}
+
+// backoff returns the delay before retrying after the given attempt, doubling
+// with each attempt
+func (r *Runner) backoff(attempt int) time.Duration {
+	delay := r.opts.RetryBackoff
+	for i := 1; i < attempt && delay < maxRetryBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryBackoff)
+}
+
+// runHandler calls the handler, turning a panic into an error
+func runHandler(ctx context.Context, h Handler, job *models.Job, progress ProgressFunc) (result interface{}, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("job handler panicked: %v", p)
+		}
+	}()
+	return h(ctx, job, progress)
+}
@@ -0,0 +1,100 @@
+package llm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"loan-eligibility-engine/internal/models"
+)
+
+// PromptVersion identifies the prompt built by BuildPrompt. Bump it whenever
+// the prompt changes so that cached verdicts from the old prompt are not reused.
+const PromptVersion = 5
+
+// cacheInputs are the prompt inputs that determine a verdict: every field
+// BuildPrompt shows. The external user ID is deliberately left out so that
+// identical financial profiles, for example from a re-upload, share a verdict.
+// The product fields must match those compared by the
+// invalidate_llm_verdicts_on_product_change trigger.
+type cacheInputs struct {
+	PromptVersion    int      `json:"prompt_version"`
+	Provider         string   `json:"provider"`
+	Age              int      `json:"age"`
+	MonthlyIncome    float64  `json:"monthly_income"`
+	CreditScore      int      `json:"credit_score"`
+	EmploymentStatus string   `json:"employment_status"`
+	ExistingEMI      float64  `json:"existing_emi"`
+	CardOutstanding  float64  `json:"credit_card_outstanding"`
+	ActiveLoans      int      `json:"active_loans"`
+	RequestedAmount  float64  `json:"requested_amount"`
+	RequestedTenure  int      `json:"requested_tenure_months"`
+	LoanPurpose      string   `json:"loan_purpose"`
+	Currency         string   `json:"currency"`
+	Locale           string   `json:"locale"`
+	ProductID        int64    `json:"product_id"`
+	ProductName      string   `json:"product_name"`
+	ProviderName     string   `json:"provider_name"`
+	ProductType      string   `json:"product_type"`
+	InterestRateMin  float64  `json:"interest_rate_min"`
+	InterestRateMax  float64  `json:"interest_rate_max"`
+	LoanAmountMin    float64  `json:"loan_amount_min"`
+	LoanAmountMax    float64  `json:"loan_amount_max"`
+	TenureMinMonths  int      `json:"tenure_min_months"`
+	TenureMaxMonths  int      `json:"tenure_max_months"`
+	ProcessingFeePct *float64 `json:"processing_fee_percent"`
+	MinCreditScore   int      `json:"min_credit_score"`
+	MinMonthlyIncome float64  `json:"min_monthly_income"`
+	MinAge           int      `json:"min_age"`
+	MaxAge           int      `json:"max_age"`
+	AcceptedStatuses []string `json:"accepted_employment_status"`
+}
+
+// CacheKey returns the verdict cache key for a user-product pair evaluated by
+// the named backend: a hex SHA-256 of the prompt version and prompt inputs.
+func CacheKey(provider string, user *models.User, product *models.LoanProduct) string {
+	data, _ := json.Marshal(cacheInputs{
+		PromptVersion:    PromptVersion,
+		Provider:         provider,
+		Age:              user.Age,
+		MonthlyIncome:    user.MonthlyIncome,
+		CreditScore:      user.CreditScore,
+		EmploymentStatus: string(user.EmploymentStatus),
+		ExistingEMI:      user.ExistingEMI,
+		CardOutstanding:  user.CreditCardOutstanding,
+		ActiveLoans:      user.ActiveLoans,
+		RequestedAmount:  user.RequestedAmount,
+		RequestedTenure:  user.RequestedTenureMonths,
+		LoanPurpose:      string(user.LoanPurpose),
+		Currency:         string(product.Currency.OrDefault()),
+		Locale:           user.Locale,
+		ProductID:        product.ID,
+		ProductName:      product.ProductName,
+		ProviderName:     product.ProviderName,
+		ProductType:      string(product.ProductType),
+		InterestRateMin:  product.InterestRateMin,
+		InterestRateMax:  product.InterestRateMax,
+		LoanAmountMin:    product.LoanAmountMin,
+		LoanAmountMax:    product.LoanAmountMax,
+		TenureMinMonths:  product.TenureMinMonths,
+		TenureMaxMonths:  product.TenureMaxMonths,
+		ProcessingFeePct: product.ProcessingFeePercent,
+		MinCreditScore:   product.MinCreditScore,
+		MinMonthlyIncome: product.MinMonthlyIncome,
+		MinAge:           product.MinAge,
+		MaxAge:           product.MaxAge,
+		AcceptedStatuses: employmentStatuses(product.AcceptedEmploymentStatus),
+	})
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// employmentStatuses returns the statuses as strings
+func employmentStatuses(statuses []models.EmploymentStatus) []string {
+	out := make([]string, len(statuses))
+	for i, s := range statuses {
+		out[i] = string(s)
+	}
+	return out
+}
@@ -0,0 +1,172 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"loan-eligibility-engine/internal/models"
+)
+
+// Gemini defaults
+const (
+	DefaultGeminiBaseURL = "https://generativelanguage.googleapis.com/v1beta"
+	DefaultGeminiModel   = "gemini-pro"
+)
+
+// GeminiEvaluator calls the Gemini generateContent API
+type GeminiEvaluator struct {
+	apiKey  string
+	baseURL string
+	model   string
+	client  *http.Client
+}
+
+// NewGemini creates a Gemini evaluator. Empty baseURL and model use the defaults.
+func NewGemini(apiKey, baseURL, model string, client *http.Client) *GeminiEvaluator {
+	if baseURL == "" {
+		baseURL = DefaultGeminiBaseURL
+	}
+	if model == "" {
+		model = DefaultGeminiModel
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &GeminiEvaluator{
+		apiKey:  apiKey,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		model:   model,
+		client:  client,
+	}
+}
+
+// Name identifies the backend and model
+func (g *GeminiEvaluator) Name() string {
+	return ProviderGemini + "/" + g.model
+}
+
+// EvaluateMatch calls the Gemini API to evaluate a user-product match
+func (g *GeminiEvaluator) EvaluateMatch(ctx context.Context, user *models.User, product *models.LoanProduct) (*Response, error) {
+	return converse(ctx, BuildPrompt(user, product), g.send)
+}
+
+// send posts a conversation to generateContent and returns the reply text
+func (g *GeminiEvaluator) send(ctx context.Context, turns []turn) (string, error) {
+	request := geminiRequest{
+		GenerationConfig: geminiGenerationConfig{
+			Temperature:     0.1,
+			TopK:            1,
+			TopP:            1,
+			MaxOutputTokens: 500,
+		},
+	}
+	for _, t := range turns {
+		role := "user"
+		if t.fromModel {
+			role = "model"
+		}
+		request.Contents = append(request.Contents, geminiContent{
+			Role:  role,
+			Parts: []geminiPart{{Text: t.text}},
+		})
+	}
+
+	jsonBody, err := json.Marshal(request)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/models/%s:generateContent", g.baseURL, g.model)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(jsonBody))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	// Send the key as a header so it never appears in logged URLs
+	req.Header.Set("x-goog-api-key", g.apiKey)
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("API request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", newAPIError(resp)
+	}
+
+	var result geminiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return result.text()
+}
+
+// geminiPart is one part of a message; only text parts are used
+type geminiPart struct {
+	Text string `json:"text"`
+}
+
+// geminiContent is one message of a generateContent conversation
+type geminiContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []geminiPart `json:"parts"`
+}
+
+// geminiGenerationConfig holds the sampling settings
+type geminiGenerationConfig struct {
+	Temperature     float64 `json:"temperature"`
+	TopK            int     `json:"topK"`
+	TopP            float64 `json:"topP"`
+	MaxOutputTokens int     `json:"maxOutputTokens"`
+}
+
+// geminiRequest is the generateContent request body
+type geminiRequest struct {
+	Contents         []geminiContent        `json:"contents"`
+	GenerationConfig geminiGenerationConfig `json:"generationConfig"`
+}
+
+// geminiResponse is the subset of the generateContent response we read
+type geminiResponse struct {
+	Candidates []struct {
+		Content      geminiContent `json:"content"`
+		FinishReason string        `json:"finishReason"`
+	} `json:"candidates"`
+	PromptFeedback struct {
+		BlockReason string `json:"blockReason"`
+	} `json:"promptFeedback"`
+}
+
+// text returns the reply text of the first candidate. Blocked prompts and
+// candidates stopped by a safety or other content filter are ErrBlocked; a
+// reply cut off at the token limit is returned so it can be re-prompted.
+func (r *geminiResponse) text() (string, error) {
+	if r.PromptFeedback.BlockReason != "" {
+		return "", fmt.Errorf("%w: prompt blocked (%s)", ErrBlocked, r.PromptFeedback.BlockReason)
+	}
+	if len(r.Candidates) == 0 {
+		return "", fmt.Errorf("no candidates in response")
+	}
+
+	candidate := r.Candidates[0]
+	switch candidate.FinishReason {
+	case "", "STOP", "MAX_TOKENS", "FINISH_REASON_UNSPECIFIED":
+	default:
+		return "", fmt.Errorf("%w: finish reason %s", ErrBlocked, candidate.FinishReason)
+	}
+
+	var text strings.Builder
+	for _, part := range candidate.Content.Parts {
+		text.WriteString(part.Text)
+	}
+	if text.Len() == 0 {
+		return "", fmt.Errorf("no text in response (finish reason %q)", candidate.FinishReason)
+	}
+	return text.String(), nil
+}
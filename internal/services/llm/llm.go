@@ -0,0 +1,203 @@
+// Package llm provides the pluggable LLM backends used by the qualitative
+// check in stage 3 of the matching pipeline.
+//
+// Every backend implements Evaluator. The backend is chosen from config:
+// LLM_PROVIDER selects gemini, openai, local (any OpenAI-compatible server)
+// or stub. When unset, Gemini is used if GEMINI_API_KEY is present, then
+// OpenAI if OPENAI_API_KEY is present, and the deterministic stub otherwise.
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"loan-eligibility-engine/internal/config"
+	"loan-eligibility-engine/internal/models"
+	"loan-eligibility-engine/internal/services/affordability"
+	"loan-eligibility-engine/internal/utils"
+)
+
+// Provider names accepted in LLM_PROVIDER
+const (
+	ProviderGemini = "gemini"
+	ProviderOpenAI = "openai"
+	ProviderLocal  = "local"
+	ProviderStub   = "stub"
+)
+
+// Evaluator assesses whether a user is a good candidate for a loan product
+type Evaluator interface {
+	// EvaluateMatch returns the qualitative verdict for a user-product pair
+	EvaluateMatch(ctx context.Context, user *models.User, product *models.LoanProduct) (*Response, error)
+	// Name identifies the backend and model, e.g. "gemini/gemini-pro"
+	Name() string
+}
+
+// Response is the verdict returned by an Evaluator
+type Response struct {
+	Qualified   bool     `json:"qualified"`
+	Confidence  float64  `json:"confidence"`
+	Reasoning   string   `json:"reasoning"`
+	RiskFactors []string `json:"risk_factors,omitempty"`
+}
+
+// APIError is returned when an LLM API responds with a non-200 status
+type APIError struct {
+	StatusCode int
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API returned status %d", e.StatusCode)
+}
+
+// Retryable reports whether the request may succeed if retried
+func (e *APIError) Retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// newAPIError builds an APIError from a failed response
+func newAPIError(resp *http.Response) *APIError {
+	apiErr := &APIError{StatusCode: resp.StatusCode}
+	if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs > 0 {
+		apiErr.RetryAfter = time.Duration(secs) * time.Second
+	}
+	return apiErr
+}
+
+// New creates the Evaluator selected by the configuration
+func New(cfg *config.Config) (Evaluator, error) {
+	client := &http.Client{Timeout: time.Duration(cfg.LLMTimeoutSeconds) * time.Second}
+
+	provider := strings.ToLower(cfg.LLMProvider)
+	if provider == "" {
+		switch {
+		case cfg.GeminiAPIKey != "":
+			provider = ProviderGemini
+		case cfg.OpenAIAPIKey != "":
+			provider = ProviderOpenAI
+		default:
+			provider = ProviderStub
+		}
+	}
+
+	switch provider {
+	case ProviderGemini:
+		if cfg.GeminiAPIKey == "" {
+			return nil, fmt.Errorf("LLM provider %q requires GEMINI_API_KEY", provider)
+		}
+		return NewGemini(cfg.GeminiAPIKey, cfg.LLMBaseURL, cfg.LLMModel, client), nil
+	case ProviderOpenAI:
+		if cfg.OpenAIAPIKey == "" {
+			return nil, fmt.Errorf("LLM provider %q requires OPENAI_API_KEY", provider)
+		}
+		return NewOpenAI(ProviderOpenAI, cfg.OpenAIAPIKey, cfg.LLMBaseURL, cfg.LLMModel, client), nil
+	case ProviderLocal:
+		baseURL := cfg.LLMBaseURL
+		if baseURL == "" {
+			baseURL = DefaultLocalBaseURL
+		}
+		model := cfg.LLMModel
+		if model == "" {
+			model = DefaultLocalModel
+		}
+		return NewOpenAI(ProviderLocal, cfg.OpenAIAPIKey, baseURL, model, client), nil
+	case ProviderStub:
+		return NewStub(), nil
+	default:
+		return nil, fmt.Errorf("unknown LLM provider %q", cfg.LLMProvider)
+	}
+}
+
+// BuildPrompt creates the evaluation prompt shared by all remote backends.
+// The user's amounts are expected in the product's currency; amounts are
+// written in the user's locale.
+func BuildPrompt(user *models.User, product *models.LoanProduct) string {
+	money := func(amount float64) string {
+		return utils.FormatMoney(amount, product.Currency.OrDefault(), user.Locale)
+	}
+	minTenure, maxTenure := affordability.Tenures(product)
+
+	return fmt.Sprintf(`You are a loan eligibility expert. Evaluate if this user is a good candidate for this loan product.
+
+USER PROFILE:
+- User ID: %s
+- Age: %d years
+- Monthly Income: %s
+- Credit Score: %d
+- Employment Status: %s
+- Existing EMIs: %s per month
+- Credit Card Outstanding: %s
+- Active Loans: %d
+- Requested Loan: %s
+
+LOAN PRODUCT:
+- Name: %s
+- Provider: %s
+- Type: %s
+- Interest Rate: %.2f%% - %.2f%%
+- Loan Amount Range: %s - %s
+- Tenure: %d - %d months
+- Processing Fee: %s
+- Min Credit Score: %d
+- Min Monthly Income: %s
+- Age Range: %d - %d years
+- Accepted Employment: %s
+
+Respond ONLY with valid JSON in this exact format:
+%s
+
+Consider:
+1. Does the user meet all hard requirements?
+2. Is their income sufficient for loan EMI on top of existing obligations?
+3. Does the product suit the loan the user requested?
+4. Are there any red flags or risk factors?
+5. Overall likelihood of loan approval`,
+		user.UserID, user.Age, money(user.MonthlyIncome), user.CreditScore,
+		user.EmploymentStatus, money(user.ExistingEMI), money(user.CreditCardOutstanding), user.ActiveLoans,
+		requestedTerms(user, money),
+		product.ProductName, product.ProviderName, product.ProductType, product.InterestRateMin, product.InterestRateMax,
+		money(product.LoanAmountMin), money(product.LoanAmountMax), minTenure, maxTenure,
+		processingFee(product), product.MinCreditScore,
+		money(product.MinMonthlyIncome), product.MinAge, product.MaxAge, acceptedEmployment(product),
+		verdictFormat,
+	)
+}
+
+// processingFee describes the product's processing fee in the prompt
+func processingFee(product *models.LoanProduct) string {
+	if product.ProcessingFeePercent == nil {
+		return "not stated"
+	}
+	return fmt.Sprintf("%.2f%%", *product.ProcessingFeePercent)
+}
+
+// acceptedEmployment lists the employment statuses the product accepts
+func acceptedEmployment(product *models.LoanProduct) string {
+	if len(product.AcceptedEmploymentStatus) == 0 {
+		return "any"
+	}
+	return strings.Join(employmentStatuses(product.AcceptedEmploymentStatus), ", ")
+}
+
+// requestedTerms describes the loan the user asked for in the prompt
+func requestedTerms(user *models.User, money func(float64) string) string {
+	var parts []string
+	if user.RequestedAmount > 0 {
+		parts = append(parts, money(user.RequestedAmount))
+	}
+	if user.RequestedTenureMonths > 0 {
+		parts = append(parts, fmt.Sprintf("over %d months", user.RequestedTenureMonths))
+	}
+	if user.LoanPurpose != "" {
+		parts = append(parts, fmt.Sprintf("for a %s loan", user.LoanPurpose))
+	}
+	if len(parts) == 0 {
+		return "not stated"
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,146 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"loan-eligibility-engine/internal/models"
+)
+
+// OpenAI and local endpoint defaults
+const (
+	DefaultOpenAIBaseURL = "https://api.openai.com/v1"
+	DefaultOpenAIModel   = "gpt-4o-mini"
+	DefaultLocalBaseURL  = "http://localhost:11434/v1"
+	DefaultLocalModel    = "llama3"
+)
+
+// OpenAIEvaluator calls an OpenAI-compatible chat completions API. It is used
+// both for OpenAI itself and for local servers that expose the same API.
+type OpenAIEvaluator struct {
+	provider string
+	apiKey   string
+	baseURL  string
+	model    string
+	client   *http.Client
+}
+
+// chatMessage is a single chat completions message. Refusal is only set on
+// replies.
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+	Refusal string `json:"refusal,omitempty"`
+}
+
+// chatRequest is the chat completions request body
+type chatRequest struct {
+	Model       string        `json:"model"`
+	Messages    []chatMessage `json:"messages"`
+	Temperature float64       `json:"temperature"`
+	MaxTokens   int           `json:"max_tokens"`
+}
+
+// chatResponse is the subset of the chat completions response we read
+type chatResponse struct {
+	Choices []struct {
+		Message      chatMessage `json:"message"`
+		FinishReason string      `json:"finish_reason"`
+	} `json:"choices"`
+}
+
+// NewOpenAI creates an OpenAI-compatible evaluator. provider is used in Name;
+// empty baseURL and model use the OpenAI defaults. An empty apiKey sends no
+// Authorization header, which most local servers accept.
+func NewOpenAI(provider, apiKey, baseURL, model string, client *http.Client) *OpenAIEvaluator {
+	if provider == "" {
+		provider = ProviderOpenAI
+	}
+	if baseURL == "" {
+		baseURL = DefaultOpenAIBaseURL
+	}
+	if model == "" {
+		model = DefaultOpenAIModel
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &OpenAIEvaluator{
+		provider: provider,
+		apiKey:   apiKey,
+		baseURL:  strings.TrimRight(baseURL, "/"),
+		model:    model,
+		client:   client,
+	}
+}
+
+// Name identifies the backend and model
+func (o *OpenAIEvaluator) Name() string {
+	return o.provider + "/" + o.model
+}
+
+// EvaluateMatch calls the chat completions API to evaluate a user-product match
+func (o *OpenAIEvaluator) EvaluateMatch(ctx context.Context, user *models.User, product *models.LoanProduct) (*Response, error) {
+	return converse(ctx, BuildPrompt(user, product), o.send)
+}
+
+// send posts a conversation to chat completions and returns the reply text
+func (o *OpenAIEvaluator) send(ctx context.Context, turns []turn) (string, error) {
+	messages := make([]chatMessage, len(turns))
+	for i, t := range turns {
+		messages[i] = chatMessage{Role: "user", Content: t.text}
+		if t.fromModel {
+			messages[i].Role = "assistant"
+		}
+	}
+
+	jsonBody, err := json.Marshal(chatRequest{
+		Model:       o.model,
+		Messages:    messages,
+		Temperature: 0.1,
+		MaxTokens:   500,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.baseURL+"/chat/completions", bytes.NewReader(jsonBody))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if o.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+o.apiKey)
+	}
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("API request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", newAPIError(resp)
+	}
+
+	var result chatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(result.Choices) == 0 {
+		return "", fmt.Errorf("no choices in response")
+	}
+
+	choice := result.Choices[0]
+	if choice.FinishReason == "content_filter" {
+		return "", fmt.Errorf("%w: finish reason %s", ErrBlocked, choice.FinishReason)
+	}
+	if choice.Message.Refusal != "" {
+		return "", fmt.Errorf("%w: %s", ErrBlocked, choice.Message.Refusal)
+	}
+	return choice.Message.Content, nil
+}
@@ -0,0 +1,78 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"loan-eligibility-engine/internal/models"
+	"loan-eligibility-engine/internal/services/affordability"
+)
+
+// StubEvaluator is a deterministic, rules-based evaluator that makes no
+// network calls. It is used for tests and when no API key is configured.
+type StubEvaluator struct{}
+
+// NewStub creates a stub evaluator
+func NewStub() *StubEvaluator {
+	return &StubEvaluator{}
+}
+
+// Name identifies the backend
+func (s *StubEvaluator) Name() string {
+	return ProviderStub
+}
+
+// EvaluateMatch qualifies a pair when the hard criteria hold and the EMI on the
+// minimum loan amount at the maximum rate stays within the default FOIR limit.
+// Confidence grows with the credit score headroom over the product minimum.
+// The send hook runs once, as for a single request, so the stub is rate
+// limited and budgeted like the remote backends it stands in for.
+func (s *StubEvaluator) EvaluateMatch(ctx context.Context, user *models.User, product *models.LoanProduct) (*Response, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := beforeSend(ctx); err != nil {
+		return nil, err
+	}
+
+	var risks []string
+	if user.MonthlyIncome < product.MinMonthlyIncome {
+		risks = append(risks, "income below product minimum")
+	}
+	if user.CreditScore < product.MinCreditScore {
+		risks = append(risks, "credit score below product minimum")
+	}
+	if user.Age < product.MinAge || user.Age > product.MaxAge {
+		risks = append(risks, "age outside product range")
+	}
+
+	foir := affordability.FOIR(user, product)
+	switch {
+	case math.IsInf(foir, 1):
+		risks = append(risks, "no monthly income")
+	case foir > affordability.MaxFOIR(product, 0):
+		risks = append(risks, fmt.Sprintf("EMIs would take %.0f%% of monthly income", foir*100))
+	}
+
+	confidence := 0.5
+	if span := 900 - product.MinCreditScore; span > 0 {
+		confidence += 0.5 * float64(user.CreditScore-product.MinCreditScore) / float64(span)
+	}
+	confidence = math.Max(0, math.Min(1, confidence))
+
+	if len(risks) > 0 {
+		return &Response{
+			Qualified:   false,
+			Confidence:  confidence,
+			Reasoning:   "stub evaluation: profile does not fit the product",
+			RiskFactors: risks,
+		}, nil
+	}
+
+	return &Response{
+		Qualified:  true,
+		Confidence: confidence,
+		Reasoning:  fmt.Sprintf("stub evaluation: EMI at %.0f%% of monthly income", foir*100),
+	}, nil
+}
@@ -0,0 +1,175 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// verdictFormat is the JSON shape every backend is asked to reply with
+const verdictFormat = `{
+  "qualified": true/false,
+  "confidence": 0.0-1.0,
+  "reasoning": "Brief explanation",
+  "risk_factors": ["factor1", "factor2"]
+}`
+
+var (
+	// ErrBlocked is returned when the provider refused to answer, for example
+	// because a safety filter blocked the prompt or the reply
+	ErrBlocked = errors.New("response blocked by provider")
+
+	// ErrInvalidVerdict is returned when the reply has no usable verdict: no
+	// JSON object, missing fields or values out of range
+	ErrInvalidVerdict = errors.New("invalid verdict")
+)
+
+// rawVerdict mirrors Response with pointers so missing fields can be told
+// apart from zero values
+type rawVerdict struct {
+	Qualified   *bool    `json:"qualified"`
+	Confidence  *float64 `json:"confidence"`
+	Reasoning   string   `json:"reasoning"`
+	RiskFactors []string `json:"risk_factors"`
+}
+
+// turn is one message of a conversation with the model
+type turn struct {
+	fromModel bool
+	text      string
+}
+
+// sendFunc sends a conversation to a backend and returns the model's reply
+type sendFunc func(ctx context.Context, turns []turn) (string, error)
+
+// SendHook is called before every request an evaluator sends to the model,
+// corrective re-prompts included, so callers can rate-limit and count each
+// one. An error cancels the request and is returned by EvaluateMatch.
+type SendHook func(ctx context.Context) error
+
+type sendHookKey struct{}
+
+// WithSendHook returns a context whose evaluations call hook before each
+// request to the model
+func WithSendHook(ctx context.Context, hook SendHook) context.Context {
+	return context.WithValue(ctx, sendHookKey{}, hook)
+}
+
+// beforeSend runs the context's send hook, if any
+func beforeSend(ctx context.Context) error {
+	if hook, ok := ctx.Value(sendHookKey{}).(SendHook); ok && hook != nil {
+		return hook(ctx)
+	}
+	return nil
+}
+
+// hooked wraps send to run the context's send hook before every request
+func (send sendFunc) hooked() sendFunc {
+	return func(ctx context.Context, turns []turn) (string, error) {
+		if err := beforeSend(ctx); err != nil {
+			return "", err
+		}
+		return send(ctx, turns)
+	}
+}
+
+// converse sends the prompt and parses the verdict. A reply without a valid
+// verdict gets one corrective re-prompt that quotes the problem back to the
+// model; other errors are returned as is. Both requests go through the
+// context's send hook.
+func converse(ctx context.Context, prompt string, send sendFunc) (*Response, error) {
+	send = send.hooked()
+	turns := []turn{{text: prompt}}
+
+	reply, err := send(ctx, turns)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := parseVerdict(reply)
+	if !errors.Is(err, ErrInvalidVerdict) {
+		return response, err
+	}
+
+	turns = append(turns, turn{fromModel: true, text: reply}, turn{text: correctionPrompt(err)})
+	reply, err = send(ctx, turns)
+	if err != nil {
+		return nil, fmt.Errorf("corrective re-prompt failed: %w", err)
+	}
+
+	response, err = parseVerdict(reply)
+	if err != nil {
+		return nil, fmt.Errorf("after corrective re-prompt: %w", err)
+	}
+	return response, nil
+}
+
+// correctionPrompt asks the model to fix a reply that failed validation
+func correctionPrompt(err error) string {
+	return fmt.Sprintf(`Your previous reply could not be used: %v.
+
+Respond again with ONLY a JSON object in this exact format, with "confidence" between 0 and 1 and a non-empty "reasoning":
+%s`, err, verdictFormat)
+}
+
+// parseVerdict extracts and validates the JSON verdict in the model's text
+// output. The first JSON object that decodes is used, so surrounding prose or
+// code fences are ignored.
+func parseVerdict(text string) (*Response, error) {
+	var decodeErr error
+	for i := strings.IndexByte(text, '{'); i >= 0; {
+		var raw rawVerdict
+		err := json.NewDecoder(strings.NewReader(text[i:])).Decode(&raw)
+		if err == nil {
+			return raw.validate()
+		}
+		if decodeErr == nil {
+			decodeErr = err
+		}
+
+		next := strings.IndexByte(text[i+1:], '{')
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+
+	if decodeErr != nil {
+		return nil, fmt.Errorf("%w: malformed JSON: %v", ErrInvalidVerdict, decodeErr)
+	}
+	return nil, fmt.Errorf("%w: no JSON object in response", ErrInvalidVerdict)
+}
+
+// validate checks that every field is present and in range
+func (v *rawVerdict) validate() (*Response, error) {
+	if v.Qualified == nil {
+		return nil, fmt.Errorf("%w: missing \"qualified\"", ErrInvalidVerdict)
+	}
+	if v.Confidence == nil {
+		return nil, fmt.Errorf("%w: missing \"confidence\"", ErrInvalidVerdict)
+	}
+	if *v.Confidence < 0 || *v.Confidence > 1 {
+		return nil, fmt.Errorf("%w: confidence %g is outside [0, 1]", ErrInvalidVerdict, *v.Confidence)
+	}
+
+	reasoning := strings.TrimSpace(v.Reasoning)
+	if reasoning == "" {
+		return nil, fmt.Errorf("%w: empty \"reasoning\"", ErrInvalidVerdict)
+	}
+
+	var risks []string
+	for _, r := range v.RiskFactors {
+		if r = strings.TrimSpace(r); r != "" {
+			risks = append(risks, r)
+		}
+	}
+
+	return &Response{
+		Qualified:   *v.Qualified,
+		Confidence:  *v.Confidence,
+		Reasoning:   reasoning,
+		RiskFactors: risks,
+	}, nil
+}
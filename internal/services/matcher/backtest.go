@@ -0,0 +1,334 @@
+package matcher
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+
+	"loan-eligibility-engine/internal/models"
+	"loan-eligibility-engine/internal/services/rules"
+	"loan-eligibility-engine/internal/services/scoring"
+	"loan-eligibility-engine/internal/utils"
+)
+
+// BacktestOptions selects the historical users to replay and the alternate
+// configuration to replay them under
+type BacktestOptions struct {
+	// BatchID replays one upload; otherwise users created in [From, To) are replayed
+	BatchID string
+	From    time.Time
+	To      time.Time
+
+	// Model replaces the current scoring model when set
+	Model *scoring.Model
+
+	// Rules replaces the stored eligibility rules of the listed products
+	Rules map[int64][]*models.EligibilityRuleCreate
+}
+
+// pairKey identifies a user-product pair
+type pairKey struct {
+	userID    int64
+	productID int64
+}
+
+// Backtest replays historical users through the prefilter, the logic filter
+// and scoring under an alternate configuration and compares the result with
+// their stored matches. Nothing is saved and the LLM is never called: each
+// pair reuses its stored verdict, and pairs without one count as unreviewed.
+// Users are matched against the current active products.
+func (m *MatcherService) Backtest(ctx context.Context, opts BacktestOptions) (*models.BacktestReport, error) {
+	users, err := m.backtestUsers(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	products, err := m.productRepo.GetAllActive(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get products: %w", err)
+	}
+
+	in, err := m.backtestInputs(ctx, products, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]int64, len(users))
+	for i, u := range users {
+		userIDs[i] = u.ID
+	}
+
+	stored, err := m.matchRepo.GetByUserIDs(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	llmRejected, err := m.rejectRepo.GetByStage(ctx, userIDs, models.MatchSourceLLMCheck)
+	if err != nil {
+		return nil, err
+	}
+
+	verdicts := storedVerdicts(stored, llmRejected)
+
+	var replayed []*models.MatchCreate
+	unreviewed := 0
+	for start := 0; start < len(users); start += m.chunkSize() {
+		chunk := users[start:min(start+m.chunkSize(), len(users))]
+
+		candidates, _, err := m.sqlPrefilter(ctx, chunk, in)
+		if err != nil {
+			return nil, err
+		}
+		candidates, _ = m.logicFilter(candidates, chunk, in)
+
+		// Stage 3: replay the stored LLM verdicts
+		var passed, pending []*MatchCandidate
+		for _, c := range candidates {
+			verdict, ok := verdicts[pairKey{c.UserID, c.ProductID}]
+			if !ok {
+				pending = append(pending, c)
+				continue
+			}
+			if verdict != nil {
+				c.LLMCheckPassed = true
+				c.LLMReasoning = verdict.LLMAnalysis
+				if verdict.LLMConfidence != nil {
+					c.LLMConfidence = *verdict.LLMConfidence
+				}
+				passed = append(passed, c)
+			}
+		}
+		unreviewed += len(pending)
+
+		replayed = append(replayed, m.createMatches(passed, models.MatchStatusEligible)...)
+		replayed = append(replayed, m.createMatches(pending, models.MatchStatusUnreviewed)...)
+	}
+
+	report := DiffBacktest(stored, replayed)
+	report.BatchID = opts.BatchID
+	if opts.BatchID == "" {
+		report.From, report.To = &opts.From, &opts.To
+	}
+	report.ScoringVersion = in.model.Version
+	report.UsersEvaluated = len(users)
+	report.Unreviewed = unreviewed
+
+	utils.Logger.Info("Backtest complete",
+		zap.String("batch_id", opts.BatchID),
+		zap.String("scoring_version", report.ScoringVersion),
+		zap.Int("users", report.UsersEvaluated),
+		zap.Int("added", report.Added),
+		zap.Int("dropped", report.Dropped),
+		zap.Int("rank_changed", report.RankChanged),
+	)
+
+	return report, nil
+}
+
+// backtestUsers loads the users of the batch or date range being replayed
+func (m *MatcherService) backtestUsers(ctx context.Context, opts BacktestOptions) ([]*models.User, error) {
+	if opts.BatchID != "" {
+		return m.userRepo.GetByBatchID(ctx, opts.BatchID)
+	}
+	if opts.From.IsZero() || !opts.To.After(opts.From) {
+		return nil, fmt.Errorf("backtest needs a batch ID or a date range")
+	}
+	return m.userRepo.GetCreatedBetween(ctx, opts.From, opts.To)
+}
+
+// backtestInputs loads the stored rules and rate slabs and applies the
+// alternate rules and scoring model. The model is not registered since nothing
+// is saved.
+func (m *MatcherService) backtestInputs(ctx context.Context, products []*models.LoanProduct, opts BacktestOptions) (*matchInputs, error) {
+	ruleSets, err := m.loadRuleSets(ctx, products)
+	if err != nil {
+		return nil, err
+	}
+
+	for productID, creates := range opts.Rules {
+		stored := make([]*models.EligibilityRule, len(creates))
+		for i, r := range creates {
+			stored[i] = &models.EligibilityRule{
+				ProductID:  productID,
+				Name:       r.Name,
+				Expression: r.Expression,
+				Priority:   r.Priority,
+				IsActive:   true,
+			}
+		}
+		set, errs := rules.FromModels(stored)
+		if len(errs) > 0 {
+			return nil, fmt.Errorf("invalid rules for product %d: %w", productID, errs[0])
+		}
+		if len(set) == 0 {
+			set = rules.DefaultRuleSet()
+		}
+		ruleSets[productID] = set
+	}
+
+	model := opts.Model
+	if model == nil {
+		model, err = m.scoring.Model()
+		if err != nil {
+			utils.Logger.Warn("Could not load scoring model, using previous one",
+				zap.String("version", model.Version),
+				zap.Error(err),
+			)
+		}
+	}
+
+	rateSlabs, err := m.loadRateSlabs(ctx, products)
+	if err != nil {
+		return nil, err
+	}
+
+	rates, err := m.loadFXRates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &matchInputs{products: products, ruleSets: ruleSets, rateSlabs: rateSlabs, rates: rates, model: model}, nil
+}
+
+// storedVerdicts maps every pair with a stored LLM or reviewer verdict to the
+// match that passed it, or to nil if the LLM or a reviewer rejected the pair.
+// Pairs still awaiting review have no verdict.
+func storedVerdicts(stored []models.Match, llmRejected []*models.MatchRejection) map[pairKey]*models.Match {
+	verdicts := make(map[pairKey]*models.Match, len(stored)+len(llmRejected))
+	for _, rej := range llmRejected {
+		verdicts[pairKey{rej.UserID, rej.ProductID}] = nil
+	}
+	for i := range stored {
+		match := &stored[i]
+		key := pairKey{match.UserID, match.ProductID}
+		switch {
+		case match.MatchSource == models.MatchSourceManual && match.Status == models.MatchStatusNotEligible:
+			verdicts[key] = nil
+		case match.MatchSource == models.MatchSourceManual,
+			match.MatchSource == models.MatchSourceLLMCheck && match.Status != models.MatchStatusPendingReview:
+			verdicts[key] = match
+		}
+	}
+	return verdicts
+}
+
+// DiffBacktest compares the current stored matches of a set of users with the
+// matches a backtest produced for them. Pairs in both sets are reported when
+// their rank among the user's matches changes.
+func DiffBacktest(stored []models.Match, replayed []*models.MatchCreate) *models.BacktestReport {
+	oldScores := make(map[pairKey]float64, len(stored))
+	for _, match := range stored {
+		if isCurrentMatch(match.Status) {
+			oldScores[pairKey{match.UserID, match.ProductID}] = match.MatchScore
+		}
+	}
+	newScores := make(map[pairKey]float64, len(replayed))
+	for _, match := range replayed {
+		newScores[pairKey{match.UserID, match.ProductID}] = match.MatchScore
+	}
+
+	oldRanks := rankByUser(oldScores)
+	newRanks := rankByUser(newScores)
+
+	report := &models.BacktestReport{
+		StoredMatches:   len(oldScores),
+		ReplayedMatches: len(newScores),
+		Before:          models.NewScoreDistribution(scoreValues(oldScores)),
+		After:           models.NewScoreDistribution(scoreValues(newScores)),
+		Changes:         []models.BacktestChange{},
+	}
+
+	var deltaSum float64
+	common := 0
+	for key, newScore := range newScores {
+		oldScore, ok := oldScores[key]
+		if !ok {
+			report.Added++
+			report.Changes = append(report.Changes, models.BacktestChange{
+				UserID:     key.userID,
+				ProductID:  key.productID,
+				ChangeType: models.BacktestChangeAdded,
+				NewScore:   &newScore,
+				NewRank:    newRanks[key],
+			})
+			continue
+		}
+
+		deltaSum += newScore - oldScore
+		common++
+		if oldRanks[key] != newRanks[key] {
+			report.RankChanged++
+			report.Changes = append(report.Changes, models.BacktestChange{
+				UserID:     key.userID,
+				ProductID:  key.productID,
+				ChangeType: models.BacktestChangeRank,
+				OldScore:   &oldScore,
+				NewScore:   &newScore,
+				OldRank:    oldRanks[key],
+				NewRank:    newRanks[key],
+			})
+		}
+	}
+
+	for key, oldScore := range oldScores {
+		if _, ok := newScores[key]; ok {
+			continue
+		}
+		report.Dropped++
+		report.Changes = append(report.Changes, models.BacktestChange{
+			UserID:     key.userID,
+			ProductID:  key.productID,
+			ChangeType: models.BacktestChangeDropped,
+			OldScore:   &oldScore,
+			OldRank:    oldRanks[key],
+		})
+	}
+
+	if common > 0 {
+		report.AvgScoreDelta = deltaSum / float64(common)
+	}
+
+	sort.Slice(report.Changes, func(i, j int) bool {
+		a, b := report.Changes[i], report.Changes[j]
+		if a.UserID != b.UserID {
+			return a.UserID < b.UserID
+		}
+		return a.ProductID < b.ProductID
+	})
+
+	return report
+}
+
+// rankByUser ranks each user's products by score, best first, breaking ties
+// by product ID
+func rankByUser(scores map[pairKey]float64) map[pairKey]int {
+	byUser := make(map[int64][]pairKey)
+	for key := range scores {
+		byUser[key.userID] = append(byUser[key.userID], key)
+	}
+
+	ranks := make(map[pairKey]int, len(scores))
+	for _, keys := range byUser {
+		sort.Slice(keys, func(i, j int) bool {
+			if scores[keys[i]] != scores[keys[j]] {
+				return scores[keys[i]] > scores[keys[j]]
+			}
+			return keys[i].productID < keys[j].productID
+		})
+		for i, key := range keys {
+			ranks[key] = i + 1
+		}
+	}
+	return ranks
+}
+
+// scoreValues returns the scores of a pair map
+func scoreValues(scores map[pairKey]float64) []float64 {
+	values := make([]float64, 0, len(scores))
+	for _, s := range scores {
+		values = append(values, s)
+	}
+	return values
+}
@@ -0,0 +1,144 @@
+package matcher
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+// errLLMBudgetSpent is returned by the send hook once a chunk's LLM budget is
+// spent; the candidate it was for is left unreviewed
+var errLLMBudgetSpent = errors.New("LLM budget spent")
+
+// AllocateLLMBudget chooses which candidates are sent to the LLM.
+//
+// Every user's top topK candidates by score are guaranteed a slot first, so no
+// user is starved by higher-scoring users elsewhere in the batch. If the budget
+// cannot cover every guaranteed slot, each user's best candidate is served
+// before anyone's second, and so on, with ties broken by score. Any budget left
+// after the guarantees is spent on the remaining candidates by score.
+//
+// A non-positive budget means no limit. Candidates that do not fit the budget
+// are returned as unreviewed. Both slices are ordered by descending score.
+func AllocateLLMBudget(candidates []*MatchCandidate, topK, budget int) (selected, unreviewed []*MatchCandidate) {
+	if budget <= 0 || len(candidates) <= budget {
+		selected = append([]*MatchCandidate(nil), candidates...)
+		sortByScore(selected)
+		return selected, nil
+	}
+
+	// Rank each user's candidates by score
+	byUser := make(map[int64][]*MatchCandidate)
+	for _, c := range candidates {
+		byUser[c.UserID] = append(byUser[c.UserID], c)
+	}
+	rank := make(map[*MatchCandidate]int, len(candidates))
+	for _, userCandidates := range byUser {
+		sortByScore(userCandidates)
+		for i, c := range userCandidates {
+			rank[c] = i
+		}
+	}
+
+	// Guaranteed slots first, ordered by per-user rank then score; the rest by score
+	ordered := append([]*MatchCandidate(nil), candidates...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		a, b := ordered[i], ordered[j]
+		aGuaranteed, bGuaranteed := rank[a] < topK, rank[b] < topK
+		if aGuaranteed != bGuaranteed {
+			return aGuaranteed
+		}
+		if aGuaranteed && rank[a] != rank[b] {
+			return rank[a] < rank[b]
+		}
+		return a.EligibilityScore > b.EligibilityScore
+	})
+
+	selected, unreviewed = ordered[:budget], ordered[budget:]
+	sortByScore(selected)
+	sortByScore(unreviewed)
+	return selected, unreviewed
+}
+
+// ChunkLLMBudget returns the share of the LLM budget a chunk of chunkUsers may
+// spend when usersLeft users, including the chunk, are still to be matched and
+// used calls were already spent. Splitting what is left in proportion to the
+// users keeps early chunks from starving later ones; unspent calls roll over.
+//
+// A negative result means no limit (total is not positive) and zero means the
+// budget is exhausted.
+func ChunkLLMBudget(total, used, chunkUsers, usersLeft int) int {
+	if total <= 0 {
+		return -1
+	}
+
+	remaining := total - used
+	if remaining <= 0 {
+		return 0
+	}
+	if usersLeft <= chunkUsers {
+		return remaining
+	}
+
+	// Round up so small chunks still get a call
+	return (remaining*chunkUsers + usersLeft - 1) / usersLeft
+}
+
+// sendBudget counts the requests sent to the LLM during a chunk. Retries and
+// corrective re-prompts draw on the same budget as first requests, so a chunk
+// never sends more than its share. A non-positive budget means no limit.
+type sendBudget struct {
+	mu     sync.Mutex
+	budget int
+	sent   int
+}
+
+// take reserves one request, reporting false once the budget is spent
+func (b *sendBudget) take() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.budget > 0 && b.sent >= b.budget {
+		return false
+	}
+	b.sent++
+	return true
+}
+
+// spent returns the number of requests sent
+func (b *sendBudget) spent() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.sent
+}
+
+// withoutCandidates returns candidates minus those in drop, keeping the order
+func withoutCandidates(candidates, drop []*MatchCandidate) []*MatchCandidate {
+	if len(drop) == 0 {
+		return candidates
+	}
+
+	dropped := make(map[*MatchCandidate]bool, len(drop))
+	for _, c := range drop {
+		dropped[c] = true
+	}
+
+	kept := make([]*MatchCandidate, 0, len(candidates)-len(drop))
+	for _, c := range candidates {
+		if !dropped[c] {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+// sortByScore sorts candidates by descending eligibility score, then by
+// ascending estimated rate, keeping the input order for remaining ties
+func sortByScore(candidates []*MatchCandidate) {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].EligibilityScore != candidates[j].EligibilityScore {
+			return candidates[i].EligibilityScore > candidates[j].EligibilityScore
+		}
+		return candidates[i].RateEstimate.EstimatedRate < candidates[j].RateEstimate.EstimatedRate
+	})
+}
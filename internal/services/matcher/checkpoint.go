@@ -0,0 +1,126 @@
+package matcher
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"loan-eligibility-engine/internal/models"
+	"loan-eligibility-engine/internal/utils"
+)
+
+// ProcessBatch matches every active user of an upload batch in chunks,
+// reading user IDs from the database a chunk at a time. Progress is
+// checkpointed after each chunk is saved, so calling ProcessBatch again after
+// a crash resumes with the first unsaved chunk. A completed batch is matched
+// again from the start.
+func (m *MatcherService) ProcessBatch(ctx context.Context, batchID string) (*MatchingResult, error) {
+	return m.ProcessBatchWithProgress(ctx, batchID, nil)
+}
+
+// ProcessBatchWithProgress is ProcessBatch with a callback that receives the
+// number of users processed and the batch size after every saved chunk. A
+// cancelled context stops matching between chunks; the checkpoint lets a
+// later call resume.
+func (m *MatcherService) ProcessBatchWithProgress(ctx context.Context, batchID string, progress func(done, total int)) (*MatchingResult, error) {
+	startTime := time.Now()
+	result := &MatchingResult{}
+
+	cp, err := m.checkpoints.Get(ctx, batchID)
+	if err != nil {
+		return nil, err
+	}
+	if cp != nil && cp.Status == models.CheckpointStatusRunning {
+		result.ResumedUsers = cp.UsersProcessed
+		utils.Logger.Info("Resuming batch matching from checkpoint",
+			zap.String("batch_id", batchID),
+			zap.Int64("last_user_id", cp.LastUserID),
+			zap.Int("users_processed", cp.UsersProcessed),
+		)
+	} else {
+		total, err := m.userRepo.CountByBatchID(ctx, batchID)
+		if err != nil {
+			return nil, err
+		}
+		cp = &models.MatchCheckpoint{
+			BatchID:    batchID,
+			Status:     models.CheckpointStatusRunning,
+			TotalUsers: total,
+			StartedAt:  time.Now().UTC(),
+		}
+		if err := m.checkpoints.Save(ctx, cp); err != nil {
+			return nil, err
+		}
+	}
+
+	in, err := m.loadInputs(ctx, result)
+	if err != nil {
+		return nil, err
+	}
+
+	utils.Logger.Info("Starting batch matching pipeline",
+		zap.String("batch_id", batchID),
+		zap.Int("users", cp.TotalUsers-cp.UsersProcessed),
+		zap.Int("products", len(in.products)),
+		zap.String("scoring_version", in.model.Version),
+		zap.Int("chunk_size", m.chunkSize()),
+	)
+
+	for {
+		userIDs, err := m.userRepo.GetIDsByBatchID(ctx, batchID, cp.LastUserID, m.chunkSize())
+		if err != nil {
+			return nil, err
+		}
+		if len(userIDs) == 0 {
+			break
+		}
+
+		// A short chunk is the last one, whatever the initial count said
+		usersLeft := max(cp.TotalUsers-cp.UsersProcessed, len(userIDs))
+		if len(userIDs) < m.chunkSize() {
+			usersLeft = len(userIDs)
+		}
+		budget := ChunkLLMBudget(m.config.LLMBatchBudget, cp.LLMCalls, len(userIDs), usersLeft)
+
+		finalBefore := result.FinalMatches + result.LLMUnreviewed + result.PendingReview
+		calls, err := m.processChunk(ctx, userIDs, in, budget, result)
+		if err != nil {
+			return nil, fmt.Errorf("batch %s stopped after user %d: %w", batchID, cp.LastUserID, err)
+		}
+
+		cp.LastUserID = userIDs[len(userIDs)-1]
+		cp.UsersProcessed += len(userIDs)
+		cp.MatchesSaved += result.FinalMatches + result.LLMUnreviewed + result.PendingReview - finalBefore
+		cp.LLMCalls += calls
+		if err := m.checkpoints.Save(ctx, cp); err != nil {
+			return nil, err
+		}
+		if progress != nil {
+			progress(cp.UsersProcessed, max(cp.TotalUsers, cp.UsersProcessed))
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("batch %s stopped after user %d: %w", batchID, cp.LastUserID, err)
+		}
+	}
+
+	now := time.Now().UTC()
+	cp.Status = models.CheckpointStatusCompleted
+	cp.CompletedAt = &now
+	if err := m.checkpoints.Save(ctx, cp); err != nil {
+		return nil, err
+	}
+
+	result.ProcessingTime = time.Since(startTime)
+
+	utils.Logger.Info("Batch matching pipeline complete",
+		zap.String("batch_id", batchID),
+		zap.Int("final_matches", result.FinalMatches),
+		zap.Int("chunks", result.Chunks),
+		zap.Int("resumed_users", result.ResumedUsers),
+		zap.Duration("processing_time", result.ProcessingTime),
+	)
+
+	return result, nil
+}
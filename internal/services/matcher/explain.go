@@ -0,0 +1,283 @@
+package matcher
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"loan-eligibility-engine/internal/models"
+	"loan-eligibility-engine/internal/services/fx"
+	"loan-eligibility-engine/internal/services/rules"
+	"loan-eligibility-engine/internal/utils"
+)
+
+// currencyChecks evaluates the hard stage 1 criteria with the user's amounts
+// converted into the product's currency. Without an exchange rate between the
+// two, the pair fails a single currency check.
+func currencyChecks(user *models.User, product *models.LoanProduct, rates *fx.Table) []models.EligibilityCheck {
+	converted, ok := rates.UserIn(user, product.Currency)
+	if !ok {
+		return []models.EligibilityCheck{{
+			Criterion: "currency",
+			Passed:    false,
+			Message:   fmt.Sprintf("no exchange rate from %s to %s", user.Currency.OrDefault(), product.Currency.OrDefault()),
+		}}
+	}
+	return prefilterChecks(converted, product)
+}
+
+// prefilterChecks evaluates the hard stage 1 criteria for a user-product pair
+// whose amounts are in the same currency
+func prefilterChecks(user *models.User, product *models.LoanProduct) []models.EligibilityCheck {
+	checks := make([]models.EligibilityCheck, 0, 6)
+	money := func(amount float64) string {
+		return utils.FormatMoney(amount, product.Currency.OrDefault(), user.Locale)
+	}
+
+	// Income
+	income := models.EligibilityCheck{Criterion: "income", Passed: user.MonthlyIncome >= product.MinMonthlyIncome}
+	if income.Passed {
+		income.Message = fmt.Sprintf("monthly income %s meets minimum %s",
+			money(user.MonthlyIncome), money(product.MinMonthlyIncome))
+	} else {
+		income.Message = fmt.Sprintf("monthly income %s below minimum %s",
+			money(user.MonthlyIncome), money(product.MinMonthlyIncome))
+	}
+	checks = append(checks, income)
+
+	// Credit score
+	credit := models.EligibilityCheck{Criterion: "credit_score", Passed: true}
+	switch {
+	case user.CreditScore < product.MinCreditScore:
+		credit.Passed = false
+		credit.Message = fmt.Sprintf("credit score %d below minimum %d", user.CreditScore, product.MinCreditScore)
+	case product.MaxCreditScore != nil && user.CreditScore > *product.MaxCreditScore:
+		credit.Passed = false
+		credit.Message = fmt.Sprintf("credit score %d above maximum %d", user.CreditScore, *product.MaxCreditScore)
+	default:
+		credit.Message = fmt.Sprintf("credit score %d meets minimum %d", user.CreditScore, product.MinCreditScore)
+	}
+	checks = append(checks, credit)
+
+	// Age
+	age := models.EligibilityCheck{Criterion: "age", Passed: true}
+	switch {
+	case user.Age < product.MinAge:
+		age.Passed = false
+		age.Message = fmt.Sprintf("age %d below min %d", user.Age, product.MinAge)
+	case user.Age > product.MaxAge:
+		age.Passed = false
+		age.Message = fmt.Sprintf("age %d above max %d", user.Age, product.MaxAge)
+	default:
+		age.Message = fmt.Sprintf("age %d within %d-%d", user.Age, product.MinAge, product.MaxAge)
+	}
+	checks = append(checks, age)
+
+	// Employment status; no restrictions means all are accepted
+	employment := models.EligibilityCheck{Criterion: "employment", Passed: len(product.AcceptedEmploymentStatus) == 0}
+	accepted := make([]string, len(product.AcceptedEmploymentStatus))
+	for i, status := range product.AcceptedEmploymentStatus {
+		accepted[i] = string(status)
+		if status == user.EmploymentStatus {
+			employment.Passed = true
+		}
+	}
+	if employment.Passed {
+		employment.Message = fmt.Sprintf("employment status %s accepted", user.EmploymentStatus)
+	} else {
+		employment.Message = fmt.Sprintf("employment status %s not accepted (accepted: %s)",
+			user.EmploymentStatus, strings.Join(accepted, ", "))
+	}
+	checks = append(checks, employment)
+
+	// Loan purpose, when the user stated one
+	if user.LoanPurpose != "" {
+		purpose := models.EligibilityCheck{Criterion: "loan_purpose", Passed: user.LoanPurpose == product.ProductType}
+		if purpose.Passed {
+			purpose.Message = fmt.Sprintf("%s loan matches requested purpose", product.ProductType)
+		} else {
+			purpose.Message = fmt.Sprintf("%s loan does not serve requested purpose %s", product.ProductType, user.LoanPurpose)
+		}
+		checks = append(checks, purpose)
+	}
+
+	// Requested amount, when the user stated one; a zero maximum is unbounded
+	if user.RequestedAmount > 0 {
+		amount := models.EligibilityCheck{Criterion: "requested_amount", Passed: true}
+		switch {
+		case user.RequestedAmount < product.LoanAmountMin:
+			amount.Passed = false
+			amount.Message = fmt.Sprintf("requested amount %s below product minimum %s",
+				money(user.RequestedAmount), money(product.LoanAmountMin))
+		case product.LoanAmountMax > 0 && user.RequestedAmount > product.LoanAmountMax:
+			amount.Passed = false
+			amount.Message = fmt.Sprintf("requested amount %s above product maximum %s",
+				money(user.RequestedAmount), money(product.LoanAmountMax))
+		default:
+			amount.Message = fmt.Sprintf("requested amount %s within product range", money(user.RequestedAmount))
+		}
+		checks = append(checks, amount)
+	}
+
+	return checks
+}
+
+// failedChecks returns only the checks that did not pass
+func failedChecks(checks []models.EligibilityCheck) []models.EligibilityCheck {
+	var failed []models.EligibilityCheck
+	for _, c := range checks {
+		if !c.Passed {
+			failed = append(failed, c)
+		}
+	}
+	return failed
+}
+
+// ruleChecks converts rule results into eligibility checks. When includePassed
+// is false only failing rules are returned.
+func ruleChecks(results []models.RuleResult, includePassed bool) []models.EligibilityCheck {
+	var checks []models.EligibilityCheck
+	for _, r := range results {
+		if r.Passed && !includePassed {
+			continue
+		}
+
+		check := models.EligibilityCheck{Criterion: "rule:" + r.Rule, Passed: r.Passed}
+		switch {
+		case r.Passed:
+			check.Message = fmt.Sprintf("rule %s passed: %s", r.Rule, r.Expression)
+		case r.Error != "":
+			check.Message = fmt.Sprintf("rule %s could not be evaluated: %s", r.Rule, r.Error)
+		default:
+			check.Message = fmt.Sprintf("rule %s failed: %s", r.Rule, r.Expression)
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// llmRejections builds rejections for candidates the LLM stage did not approve
+func llmRejections(candidates []*MatchCandidate, users []*models.User) []*models.MatchRejection {
+	batchIDs := make(map[int64]string, len(users))
+	for _, u := range users {
+		batchIDs[u.ID] = u.BatchID
+	}
+
+	var rejections []*models.MatchRejection
+	for _, c := range candidates {
+		if c.LLMCheckPassed || c.NeedsReview {
+			continue
+		}
+
+		message := c.LLMReasoning
+		if message == "" {
+			message = "not approved by the LLM qualitative check"
+		}
+		rejections = append(rejections, &models.MatchRejection{
+			UserID:    c.UserID,
+			ProductID: c.ProductID,
+			Stage:     models.MatchSourceLLMCheck,
+			Reasons:   []models.EligibilityCheck{{Criterion: "llm_check", Passed: false, Message: message}},
+			BatchID:   batchIDs[c.UserID],
+		})
+	}
+	return rejections
+}
+
+// ExplainUser returns the full pass/fail breakdown of a user against every
+// active product. Stage 1 and 2 are evaluated live against the current
+// product terms and rules; the LLM outcome comes from the stored match or
+// rejection. Returns nil if the user does not exist.
+func (m *MatcherService) ExplainUser(ctx context.Context, userID int64) (*models.UserEligibilityReport, error) {
+	user, err := m.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, nil
+	}
+
+	products, err := m.productRepo.GetAllActive(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get products: %w", err)
+	}
+
+	ruleSets, err := m.loadRuleSets(ctx, products)
+	if err != nil {
+		return nil, err
+	}
+
+	rates, err := m.loadFXRates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	storedMatches, err := m.matchRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	matchByProduct := make(map[int64]models.Match, len(storedMatches))
+	for _, match := range storedMatches {
+		matchByProduct[match.ProductID] = match
+	}
+
+	rejections, err := m.rejectRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.UserEligibilityReport{
+		UserID:     user.ID,
+		ExternalID: user.UserID,
+		Products:   make([]*models.ProductEligibility, 0, len(products)),
+	}
+
+	for _, product := range products {
+		pe := &models.ProductEligibility{
+			ProductID:    product.ID,
+			ProductName:  product.ProductName,
+			ProviderName: product.ProviderName,
+			Checks:       currencyChecks(user, product, rates),
+		}
+		report.Products = append(report.Products, pe)
+
+		if match, ok := matchByProduct[product.ID]; ok {
+			score := match.MatchScore
+			pe.MatchStatus = match.Status
+			pe.MatchScore = &score
+			pe.LLMAnalysis = match.LLMAnalysis
+		}
+
+		if len(failedChecks(pe.Checks)) > 0 {
+			pe.Outcome = models.EligibilityOutcomeRejectedSQL
+			continue
+		}
+
+		// The currency check passed, so the rate exists
+		converted, _ := rates.UserIn(user, product.Currency)
+		results, passed := ruleSets[product.ID].WithFOIRLimit().Evaluate(rules.NewEnv(converted, product, m.config.MaxFOIR))
+		pe.RuleResults = results
+		pe.Checks = append(pe.Checks, ruleChecks(results, true)...)
+		if !passed {
+			pe.Outcome = models.EligibilityOutcomeRejectedRules
+			continue
+		}
+
+		switch {
+		case pe.MatchStatus == models.MatchStatusEligible || pe.MatchStatus == models.MatchStatusNotified:
+			pe.Outcome = models.EligibilityOutcomeMatched
+			report.EligibleCount++
+		case pe.MatchStatus == models.MatchStatusPendingReview:
+			pe.Outcome = models.EligibilityOutcomePendingReview
+		case pe.MatchStatus == models.MatchStatusNotEligible && matchByProduct[product.ID].MatchSource == models.MatchSourceManual:
+			pe.Outcome = models.EligibilityOutcomeRejectedReview
+		case rejections[product.ID] != nil && rejections[product.ID].Stage == models.MatchSourceLLMCheck:
+			pe.Outcome = models.EligibilityOutcomeRejectedLLM
+			pe.Checks = append(pe.Checks, rejections[product.ID].Reasons...)
+		default:
+			pe.Outcome = models.EligibilityOutcomeNotYetEvaluated
+		}
+	}
+
+	return report, nil
+}
@@ -0,0 +1,195 @@
+package matcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"loan-eligibility-engine/internal/models"
+	"loan-eligibility-engine/internal/services/llm"
+	"loan-eligibility-engine/internal/utils"
+)
+
+const (
+	// retryBaseDelay is the first backoff delay; it doubles on every attempt
+	retryBaseDelay = 500 * time.Millisecond
+	// retryMaxDelay caps a single backoff delay
+	retryMaxDelay = 20 * time.Second
+)
+
+// llmCheck evaluates candidates with the LLM using a bounded worker pool.
+// Every request, retries and corrective re-prompts included, waits on the
+// shared rate limiter and draws on budget (see ChunkLLMBudget). It returns
+// the approved candidates, those held for human review and those left
+// unreviewed because the budget ran out, all in input order, with the number
+// of requests sent. Per-candidate failures are returned as errors.
+func (m *MatcherService) llmCheck(ctx context.Context, candidates []*MatchCandidate, users []*models.User, in *matchInputs, budget int) (passed, review, unreviewed []*MatchCandidate, sent int, errs []error) {
+	userMap := make(map[int64]*models.User)
+	for _, u := range users {
+		userMap[u.ID] = u
+	}
+
+	productMap := make(map[int64]*models.LoanProduct)
+	for _, p := range in.products {
+		productMap[p.ID] = p
+	}
+
+	workers := m.config.LLMConcurrency
+	if workers < 1 {
+		workers = 1
+	}
+
+	sends := &sendBudget{budget: budget}
+	ctx = llm.WithSendHook(ctx, func(ctx context.Context) error {
+		if !sends.take() {
+			return errLLMBudgetSpent
+		}
+		return m.llmLimiter.Wait(ctx)
+	})
+
+	candidateErrs := make([]error, len(candidates))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				c := candidates[i]
+				user, product := in.userFor(userMap[c.UserID], productMap[c.ProductID])
+				if user == nil || product == nil {
+					continue
+				}
+				candidateErrs[i] = m.evaluateCandidate(ctx, c, user, product)
+			}
+		}()
+	}
+
+dispatch:
+	for i := range candidates {
+		select {
+		case <-ctx.Done():
+			break dispatch
+		case jobs <- i:
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	passed = make([]*MatchCandidate, 0)
+	for i, c := range candidates {
+		if errors.Is(candidateErrs[i], errLLMBudgetSpent) {
+			unreviewed = append(unreviewed, c)
+			continue
+		}
+		if candidateErrs[i] != nil {
+			errs = append(errs, fmt.Errorf("user %d product %d: %w", c.UserID, c.ProductID, candidateErrs[i]))
+		}
+		switch {
+		case c.NeedsReview:
+			review = append(review, c)
+		case c.LLMCheckPassed:
+			passed = append(passed, c)
+		}
+	}
+
+	return passed, review, unreviewed, sends.spent(), errs
+}
+
+// evaluateCandidate runs the LLM check for a single candidate and records the
+// verdict on it. A candidate whose check fails is held for human review
+// rather than approved or rejected automatically, unless the budget ran out
+// before it got a verdict.
+func (m *MatcherService) evaluateCandidate(ctx context.Context, c *MatchCandidate, user *models.User, product *models.LoanProduct) error {
+	response, err := m.evaluateWithRetry(ctx, user, product)
+	if err != nil {
+		if ctx.Err() != nil || errors.Is(err, errLLMBudgetSpent) {
+			return err
+		}
+
+		utils.Logger.Warn("LLM check failed for candidate",
+			zap.Int64("user_id", c.UserID),
+			zap.Int64("product_id", c.ProductID),
+			zap.Error(err),
+		)
+		c.NeedsReview = true
+		c.LLMReasoning = "LLM check could not be completed: " + err.Error()
+		return err
+	}
+
+	m.storeVerdict(ctx, llm.CacheKey(m.evaluator.Name(), user, product), product.ID, response)
+	m.applyVerdict(c, response.Qualified, response.Confidence, response.Reasoning)
+
+	return nil
+}
+
+// applyVerdict records an LLM verdict on a candidate. Verdicts, approving or
+// not, with a confidence below LLMReviewConfidence are held for human review.
+func (m *MatcherService) applyVerdict(c *MatchCandidate, qualified bool, confidence float64, reasoning string) {
+	c.LLMCheckPassed = qualified
+	c.LLMReasoning = reasoning
+	c.LLMConfidence = confidence
+	c.NeedsReview = confidence < m.config.LLMReviewConfidence
+}
+
+// evaluateWithRetry calls the LLM, retrying 429 and 5xx responses with
+// exponential backoff and full jitter. Rate limiting is left to the send
+// hook, which the evaluator calls before each request.
+func (m *MatcherService) evaluateWithRetry(ctx context.Context, user *models.User, product *models.LoanProduct) (*llm.Response, error) {
+	var lastErr error
+
+	for attempt := 0; attempt <= m.config.LLMMaxRetries; attempt++ {
+		if attempt > 0 {
+			if err := sleepContext(ctx, backoffDelay(attempt, lastErr)); err != nil {
+				return nil, err
+			}
+		}
+
+		response, err := m.evaluator.EvaluateMatch(ctx, user, product)
+		if err == nil {
+			return response, nil
+		}
+		lastErr = err
+
+		var apiErr *llm.APIError
+		if !errors.As(err, &apiErr) || !apiErr.Retryable() {
+			return nil, err
+		}
+	}
+
+	return nil, fmt.Errorf("giving up after %d retries: %w", m.config.LLMMaxRetries, lastErr)
+}
+
+// backoffDelay returns the delay before the given retry attempt. A server
+// supplied Retry-After takes precedence over the computed backoff.
+func backoffDelay(attempt int, lastErr error) time.Duration {
+	var apiErr *llm.APIError
+	if errors.As(lastErr, &apiErr) && apiErr.RetryAfter > 0 {
+		return apiErr.RetryAfter
+	}
+
+	ceiling := retryBaseDelay << (attempt - 1)
+	if ceiling <= 0 || ceiling > retryMaxDelay {
+		ceiling = retryMaxDelay
+	}
+	return time.Duration(rand.Int63n(int64(ceiling)) + 1)
+}
+
+// sleepContext sleeps for d or until the context is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -5,15 +5,20 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"net/http"
-	"strings"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
 
 	"loan-eligibility-engine/internal/config"
 	"loan-eligibility-engine/internal/models"
+	"loan-eligibility-engine/internal/services/affordability"
 	"loan-eligibility-engine/internal/services/database"
+	"loan-eligibility-engine/internal/services/fx"
+	"loan-eligibility-engine/internal/services/llm"
+	"loan-eligibility-engine/internal/services/pricing"
+	"loan-eligibility-engine/internal/services/rules"
+	"loan-eligibility-engine/internal/services/scoring"
 	"loan-eligibility-engine/internal/utils"
 )
 
@@ -23,26 +28,20 @@ type MatcherService struct {
 	userRepo    *database.UserRepository
 	productRepo *database.ProductRepository
 	matchRepo   *database.MatchRepository
-	llmClient   *LLMClient
+	ruleRepo    *database.RuleRepository
+	slabRepo    *database.RateSlabRepository
+	fxRepo      *database.FXRateRepository
+	rejectRepo  *database.RejectionRepository
+	rematchRepo *database.RematchRepository
+	checkpoints *database.CheckpointRepository
+	scoringRepo *database.ScoringModelRepository
+	verdicts    *database.VerdictCacheRepository
+	evaluator   llm.Evaluator
+	llmLimiter  *utils.TokenBucket
+	scoring     *scoring.Source
 	config      *config.Config
 }
 
-// LLMClient handles calls to Gemini/GPT API
-type LLMClient struct {
-	apiKey string
-	apiURL string
-	model  string
-	client *http.Client
-}
-
-// LLMResponse represents the LLM API response
-type LLMResponse struct {
-	Qualified   bool     `json:"qualified"`
-	Confidence  float64  `json:"confidence"`
-	Reasoning   string   `json:"reasoning"`
-	RiskFactors []string `json:"risk_factors,omitempty"`
-}
-
 // MatchingResult contains the complete result of matching a batch of users
 type MatchingResult struct {
 	TotalUsers         int
@@ -53,6 +52,14 @@ type MatchingResult struct {
 	LLMCheckPassed     int
 	FinalMatches       int
 	ProcessingTime     time.Duration
+	Rejections         int
+	LLMCacheHits       int
+	LLMCacheMisses     int
+	LLMUnreviewed      int
+	PendingReview      int
+	Chunks             int
+	ResumedUsers       int
+	ScoringVersion     string
 	Errors             []error
 }
 
@@ -68,6 +75,13 @@ type MatchCandidate struct {
 	LLMCheckPassed      bool
 	LLMReasoning        string
 	LLMConfidence       float64
+	LLMCached           bool
+	NeedsReview         bool
+	RuleResults         []models.RuleResult
+	Affordability       models.Affordability
+	RateEstimate        models.RateEstimate
+	ScoringVersion      string
+	ProductVersion      int // version of the product terms the match was computed against
 }
 
 // NewMatcherService creates a new matcher service
@@ -77,299 +91,497 @@ func NewMatcherService(db *database.DB) (*MatcherService, error) {
 		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
-	llmClient := &LLMClient{
-		apiKey: cfg.GeminiAPIKey,
-		apiURL: "https://generativelanguage.googleapis.com/v1beta/models/gemini-pro:generateContent",
-		model:  "gemini-pro",
-		client: &http.Client{Timeout: 30 * time.Second},
+	evaluator, err := llm.New(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create LLM evaluator: %w", err)
 	}
 
+	return NewMatcherServiceWithEvaluator(db, cfg, evaluator), nil
+}
+
+// NewMatcherServiceWithEvaluator creates a matcher service that uses the given
+// configuration and LLM evaluator
+func NewMatcherServiceWithEvaluator(db *database.DB, cfg *config.Config, evaluator llm.Evaluator) *MatcherService {
 	return &MatcherService{
 		db:          db,
 		userRepo:    database.NewUserRepository(db),
 		productRepo: database.NewProductRepository(db),
 		matchRepo:   database.NewMatchRepository(db),
-		llmClient:   llmClient,
+		ruleRepo:    database.NewRuleRepository(db),
+		slabRepo:    database.NewRateSlabRepository(db),
+		fxRepo:      database.NewFXRateRepository(db),
+		rejectRepo:  database.NewRejectionRepository(db),
+		rematchRepo: database.NewRematchRepository(db),
+		checkpoints: database.NewCheckpointRepository(db),
+		scoringRepo: database.NewScoringModelRepository(db),
+		verdicts:    database.NewVerdictCacheRepository(db),
+		evaluator:   evaluator,
+		llmLimiter:  utils.NewTokenBucket(cfg.LLMRequestsPerMinute, cfg.LLMConcurrency),
+		scoring:     scoring.NewSource(cfg.ScoringModelPath),
 		config:      cfg,
-	}, nil
+	}
 }
 
-// ProcessNewUsers runs the matching pipeline for newly uploaded users
+// ProcessNewUsers runs the matching pipeline for newly uploaded users. Users
+// are matched in chunks of MatchChunkSize and each chunk is saved before the
+// next one starts, so memory stays bounded for large uploads.
 func (m *MatcherService) ProcessNewUsers(ctx context.Context, userIDs []int64) (*MatchingResult, error) {
 	startTime := time.Now()
 	result := &MatchingResult{}
 
-	// Get users
-	users, err := m.userRepo.GetByIDs(ctx, userIDs)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get users: %w", err)
-	}
-	result.TotalUsers = len(users)
-
-	// Get active products
-	products, err := m.productRepo.GetAllActive(ctx)
+	in, err := m.loadInputs(ctx, result)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get products: %w", err)
+		return nil, err
 	}
-	result.TotalProducts = len(products)
-	result.TotalPairs = len(users) * len(products)
 
 	utils.Logger.Info("Starting matching pipeline",
-		zap.Int("users", len(users)),
-		zap.Int("products", len(products)),
-		zap.Int("total_pairs", result.TotalPairs),
-	)
-
-	// Stage 1: SQL Prefilter - basic eligibility checks
-	candidates := m.sqlPrefilter(users, products)
-	result.SQLPrefilterPassed = len(candidates)
-
-	utils.Logger.Info("Stage 1 complete: SQL prefilter",
-		zap.Int("passed", len(candidates)),
-		zap.Int("filtered_out", result.TotalPairs-len(candidates)),
-	)
-
-	// Stage 2: Logic Filter
-	candidates = m.logicFilter(candidates, users, products)
-	result.LogicFilterPassed = len(candidates)
-
-	utils.Logger.Info("Stage 2 complete: Logic filter",
-		zap.Int("passed", len(candidates)),
-		zap.Int("filtered_out", result.SQLPrefilterPassed-len(candidates)),
+		zap.Int("users", len(userIDs)),
+		zap.Int("products", len(in.products)),
+		zap.String("scoring_version", in.model.Version),
+		zap.Int("chunk_size", m.chunkSize()),
 	)
 
-	// Stage 3: LLM Check (for top candidates only)
-	// Limit to top 100 candidates per batch to control API costs
-	topCandidates := m.selectTopCandidates(candidates, 100)
-	finalCandidates, err := m.llmCheck(ctx, topCandidates, users, products)
-	if err != nil {
-		utils.Logger.Warn("LLM check had errors", zap.Error(err))
-		result.Errors = append(result.Errors, err)
-	}
-	result.LLMCheckPassed = len(finalCandidates)
+	llmCalls := 0
+	for start := 0; start < len(userIDs); start += m.chunkSize() {
+		end := min(start+m.chunkSize(), len(userIDs))
+		budget := ChunkLLMBudget(m.config.LLMBatchBudget, llmCalls, end-start, len(userIDs)-start)
 
-	utils.Logger.Info("Stage 3 complete: LLM check",
-		zap.Int("passed", len(finalCandidates)),
-		zap.Int("filtered_out", len(topCandidates)-len(finalCandidates)),
-	)
-
-	// Save matches to database
-	matches := m.createMatches(finalCandidates)
-	if _, _, err := m.matchRepo.BulkInsert(ctx, matches); err != nil {
-		return nil, fmt.Errorf("failed to save matches: %w", err)
+		calls, err := m.processChunk(ctx, userIDs[start:end], in, budget, result)
+		if err != nil {
+			return nil, err
+		}
+		llmCalls += calls
 	}
-	result.FinalMatches = len(matches)
 
 	result.ProcessingTime = time.Since(startTime)
 
 	utils.Logger.Info("Matching pipeline complete",
 		zap.Int("final_matches", result.FinalMatches),
+		zap.Int("chunks", result.Chunks),
 		zap.Duration("processing_time", result.ProcessingTime),
 	)
 
 	return result, nil
 }
 
-// sqlPrefilter performs basic eligibility checks
-func (m *MatcherService) sqlPrefilter(users []*models.User, products []*models.LoanProduct) []*MatchCandidate {
-	candidates := make([]*MatchCandidate, 0)
+// matchInputs are loaded once per run and shared by every chunk
+type matchInputs struct {
+	products  []*models.LoanProduct
+	ruleSets  map[int64]rules.RuleSet
+	rateSlabs map[int64][]*models.RateSlab
+	rates     *fx.Table
+	model     *scoring.Model
+}
 
-	for _, user := range users {
-		for _, product := range products {
-			// Check basic eligibility criteria
-			incomeEligible := user.MonthlyIncome >= product.MinMonthlyIncome
-			creditScoreEligible := user.CreditScore >= product.MinCreditScore
-			ageEligible := user.Age >= product.MinAge && user.Age <= product.MaxAge
-
-			// Check employment status
-			employmentEligible := false
-			for _, empStatus := range product.AcceptedEmploymentStatus {
-				if empStatus == user.EmploymentStatus {
-					employmentEligible = true
-					break
-				}
-			}
-			// If no employment restrictions, all are eligible
-			if len(product.AcceptedEmploymentStatus) == 0 {
-				employmentEligible = true
-			}
+// userFor returns the user converted into the product's currency, or a nil
+// user when either is missing or there is no exchange rate between them
+func (in *matchInputs) userFor(user *models.User, product *models.LoanProduct) (*models.User, *models.LoanProduct) {
+	if user == nil || product == nil {
+		return nil, nil
+	}
+	converted, ok := in.rates.UserIn(user, product.Currency)
+	if !ok {
+		return nil, nil
+	}
+	return converted, product
+}
 
-			// Only include if basic criteria pass
-			if incomeEligible && creditScoreEligible && ageEligible && employmentEligible {
-				candidates = append(candidates, &MatchCandidate{
-					UserID:              user.ID,
-					ProductID:           product.ID,
-					IncomeEligible:      incomeEligible,
-					CreditScoreEligible: creditScoreEligible,
-					AgeEligible:         ageEligible,
-					EmploymentEligible:  employmentEligible,
-				})
-			}
-		}
+// loadInputs loads the active products, their compiled rules and the scoring
+// model for a run
+func (m *MatcherService) loadInputs(ctx context.Context, result *MatchingResult) (*matchInputs, error) {
+	products, err := m.productRepo.GetAllActive(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get products: %w", err)
 	}
+	result.TotalProducts = len(products)
 
-	return candidates
+	in, err := m.inputsFor(ctx, products)
+	if err != nil {
+		return nil, err
+	}
+	result.ScoringVersion = in.model.Version
+
+	return in, nil
 }
 
-// logicFilter applies business logic rules
-func (m *MatcherService) logicFilter(candidates []*MatchCandidate, users []*models.User, products []*models.LoanProduct) []*MatchCandidate {
-	userMap := make(map[int64]*models.User)
-	for _, u := range users {
-		userMap[u.ID] = u
+// inputsFor loads the rules, rate slabs, exchange rates and scoring model for
+// matching the given products
+func (m *MatcherService) inputsFor(ctx context.Context, products []*models.LoanProduct) (*matchInputs, error) {
+	ruleSets, err := m.loadRuleSets(ctx, products)
+	if err != nil {
+		return nil, err
 	}
 
-	productMap := make(map[int64]*models.LoanProduct)
-	for _, p := range products {
-		productMap[p.ID] = p
+	rateSlabs, err := m.loadRateSlabs(ctx, products)
+	if err != nil {
+		return nil, err
 	}
 
-	filtered := make([]*MatchCandidate, 0, len(candidates)/2)
+	rates, err := m.loadFXRates(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	for _, c := range candidates {
-		user := userMap[c.UserID]
-		product := productMap[c.ProductID]
+	model, err := m.scoringModel(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-		if user == nil || product == nil {
-			continue
-		}
+	return &matchInputs{products: products, ruleSets: ruleSets, rateSlabs: rateSlabs, rates: rates, model: model}, nil
+}
 
-		// Apply detailed business rules
-		if !m.passesBusinessRules(user, product) {
-			continue
-		}
+// scoringModel returns the current scoring model and registers its version,
+// so every stored score can be traced back to the weights that produced it
+func (m *MatcherService) scoringModel(ctx context.Context) (*scoring.Model, error) {
+	model, err := m.scoring.Model()
+	if err != nil {
+		utils.Logger.Warn("Could not load scoring model, using previous one",
+			zap.String("version", model.Version),
+			zap.Error(err),
+		)
+	}
 
-		// Calculate eligibility score
-		c.EligibilityScore = m.calculateEligibilityScore(user, product)
+	definition, err := json.Marshal(model)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode scoring model: %w", err)
+	}
+	same, err := m.scoringRepo.Register(ctx, model.Version, definition)
+	if err != nil {
+		return nil, err
+	}
+	if !same {
+		return nil, fmt.Errorf("scoring model version %s is already registered with different weights; give the new weights a new version", model.Version)
+	}
 
-		filtered = append(filtered, c)
+	return model, nil
+}
+
+// chunkSize returns the number of users matched per chunk
+func (m *MatcherService) chunkSize() int {
+	if m.config.MatchChunkSize < 1 {
+		return 1000
+	}
+	return m.config.MatchChunkSize
+}
+
+// processChunk matches one chunk of users against the products, then saves
+// the chunk's matches and rejections. It returns the number of LLM calls the
+// chunk spent.
+func (m *MatcherService) processChunk(ctx context.Context, userIDs []int64, in *matchInputs, budget int, result *MatchingResult) (int, error) {
+	users, err := m.userRepo.GetByIDs(ctx, userIDs)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get users: %w", err)
 	}
+	result.TotalUsers += len(users)
+	result.TotalPairs += len(users) * len(in.products)
+	result.Chunks++
+
+	matches, rejections, llmCalls, err := m.evaluatePairs(ctx, users, in, budget, result)
+	if err != nil {
+		return 0, err
+	}
+
+	// Save matches to database; candidates beyond the LLM budget are kept as unreviewed
+	if _, _, err := m.matchRepo.BulkInsert(ctx, matches); err != nil {
+		return 0, fmt.Errorf("failed to save matches: %w", err)
+	}
+
+	// Record why every other evaluated pair was rejected
+	if err := m.rejectRepo.ReplaceForUsers(ctx, userIDs, rejections); err != nil {
+		utils.Logger.Warn("Failed to save match rejections", zap.Error(err))
+		result.Errors = append(result.Errors, err)
+	}
+	result.Rejections += len(rejections)
+
+	utils.Logger.Info("Matched chunk",
+		zap.Int("chunk", result.Chunks),
+		zap.Int("users", len(users)),
+		zap.Int("matches", len(matches)),
+		zap.Int("llm_calls", llmCalls),
+	)
 
-	return filtered
+	return llmCalls, nil
 }
 
-// passesBusinessRules checks detailed eligibility criteria
-func (m *MatcherService) passesBusinessRules(user *models.User, product *models.LoanProduct) bool {
-	// Debt-to-income ratio check (assuming max 50% of income can go to loan)
-	maxEMI := user.MonthlyIncome * 0.5
+// evaluatePairs runs the three matching stages over every user-product pair
+// and adds the stage statistics to result. budget caps the LLM calls, see
+// ChunkLLMBudget. It returns the matches to save, including unreviewed ones,
+// the reasons every other pair was rejected and the LLM requests sent,
+// retries and corrective re-prompts included.
+func (m *MatcherService) evaluatePairs(ctx context.Context, users []*models.User, in *matchInputs, budget int, result *MatchingResult) ([]*models.MatchCreate, []*models.MatchRejection, int, error) {
+	products := in.products
 
-	// Rough EMI calculation at max interest rate
-	monthlyRate := product.InterestRateMax / 100 / 12
-	tenure := float64(product.TenureMaxMonths)
-	if tenure == 0 {
-		tenure = 60
+	// Stage 1: SQL Prefilter - basic eligibility checks
+	candidates, rejections, err := m.sqlPrefilter(ctx, users, in)
+	if err != nil {
+		return nil, nil, 0, err
 	}
+	result.SQLPrefilterPassed += len(candidates)
 
-	// EMI = P * r * (1+r)^n / ((1+r)^n - 1)
-	if monthlyRate > 0 {
-		emiForMinAmount := product.LoanAmountMin * monthlyRate *
-			pow(1+monthlyRate, tenure) / (pow(1+monthlyRate, tenure) - 1)
+	utils.Logger.Debug("Stage 1 complete: SQL prefilter",
+		zap.Int("passed", len(candidates)),
+		zap.Int("filtered_out", len(users)*len(products)-len(candidates)),
+	)
 
-		if emiForMinAmount > maxEMI*2 { // Some buffer
-			return false
+	// Stage 2: Logic Filter
+	prefiltered := len(candidates)
+	candidates, ruleRejections := m.logicFilter(candidates, users, in)
+	rejections = append(rejections, ruleRejections...)
+	result.LogicFilterPassed += len(candidates)
+
+	utils.Logger.Debug("Stage 2 complete: Logic filter",
+		zap.Int("passed", len(candidates)),
+		zap.Int("filtered_out", prefiltered-len(candidates)),
+	)
+
+	// Stage 3: LLM Check
+	// Cached verdicts are free; the remaining candidates share the LLM budget,
+	// with every user's top-K guaranteed a slot
+	cached, uncached := m.applyCachedVerdicts(ctx, candidates, users, in)
+	var selected, unreviewed []*MatchCandidate
+	if budget == 0 {
+		unreviewed = append(unreviewed, uncached...)
+		sortByScore(unreviewed)
+	} else {
+		selected, unreviewed = AllocateLLMBudget(uncached, m.config.LLMTopKPerUser, budget)
+	}
+	evaluated, review, starved, llmCalls, llmErrors := m.llmCheck(ctx, selected, users, in, budget)
+	if len(starved) > 0 {
+		// Retries and re-prompts spent the budget before these got a verdict
+		selected = withoutCandidates(selected, starved)
+		unreviewed = append(unreviewed, starved...)
+		sortByScore(unreviewed)
+	}
+	if len(llmErrors) > 0 {
+		utils.Logger.Warn("LLM check had errors", zap.Int("errors", len(llmErrors)))
+		result.Errors = append(result.Errors, llmErrors...)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, nil, 0, fmt.Errorf("matching cancelled during LLM check: %w", err)
+	}
+
+	// Low-confidence and failed verdicts wait for a reviewer
+	finalCandidates := make([]*MatchCandidate, 0, len(cached)+len(evaluated))
+	var cachedReview []*MatchCandidate
+	for _, c := range cached {
+		switch {
+		case c.NeedsReview:
+			cachedReview = append(cachedReview, c)
+		case c.LLMCheckPassed:
+			finalCandidates = append(finalCandidates, c)
 		}
 	}
+	finalCandidates = append(finalCandidates, evaluated...)
+	review = append(cachedReview, review...)
+
+	result.LLMCheckPassed += len(finalCandidates)
+	result.LLMCacheHits += len(cached)
+	if m.cacheEnabled() {
+		result.LLMCacheMisses += len(selected)
+	}
+	result.LLMUnreviewed += len(unreviewed)
+	result.PendingReview += len(review)
+	rejections = append(rejections, llmRejections(cached, users)...)
+	rejections = append(rejections, llmRejections(selected, users)...)
+
+	utils.Logger.Debug("Stage 3 complete: LLM check",
+		zap.Int("passed", len(finalCandidates)),
+		zap.Int("filtered_out", len(cached)+len(selected)-len(finalCandidates)-len(review)),
+		zap.Int("pending_review", len(review)),
+		zap.Int("cache_hits", len(cached)),
+		zap.Int("unreviewed", len(unreviewed)),
+	)
+
+	result.FinalMatches += len(finalCandidates)
 
-	return true
+	// Candidates beyond the LLM budget are kept as unreviewed
+	matches := m.createMatches(finalCandidates, models.MatchStatusEligible)
+	matches = append(matches, m.createMatches(review, models.MatchStatusPendingReview)...)
+	matches = append(matches, m.createMatches(unreviewed, models.MatchStatusUnreviewed)...)
+
+	return matches, rejections, llmCalls, nil
 }
 
-// pow calculates power for float64
-func pow(base, exp float64) float64 {
-	result := 1.0
-	for i := 0; i < int(exp); i++ {
-		result *= base
+// sqlPrefilter runs the basic eligibility checks in Postgres and records why
+// failing pairs were dropped
+func (m *MatcherService) sqlPrefilter(ctx context.Context, users []*models.User, in *matchInputs) ([]*MatchCandidate, []*models.MatchRejection, error) {
+	userIDs := make([]int64, len(users))
+	for i, u := range users {
+		userIDs[i] = u.ID
 	}
-	return result
+
+	pairs, err := m.matchRepo.PrefilterPairs(ctx, userIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	candidates, rejections := prefilter(users, in.products, pairs, in.rates)
+	return candidates, rejections, nil
 }
 
-// calculateEligibilityScore computes a 0-100 score
-func (m *MatcherService) calculateEligibilityScore(user *models.User, product *models.LoanProduct) float64 {
-	var score float64 = 0
-
-	// Credit score component (40% weight)
-	creditScoreExcess := float64(user.CreditScore - product.MinCreditScore)
-	creditScoreRange := float64(900 - product.MinCreditScore)
-	if creditScoreRange > 0 {
-		creditComponent := (creditScoreExcess / creditScoreRange) * 40
-		if creditComponent > 40 {
-			creditComponent = 40
+// prefilter splits every user-product pair into stage 1 candidates and
+// rejections. sqlPassed holds the product IDs each user passed in Postgres;
+// when nil, as for simulated product terms, the checks are evaluated in Go.
+// Reasons are only computed for pairs that did not pass. Users are checked in
+// the product's currency; pairs without an exchange rate are rejected.
+func prefilter(users []*models.User, products []*models.LoanProduct, sqlPassed map[int64][]int64, rates *fx.Table) ([]*MatchCandidate, []*models.MatchRejection) {
+	candidates := make([]*MatchCandidate, 0)
+	rejections := make([]*models.MatchRejection, 0)
+
+	for _, user := range users {
+		var passed map[int64]bool
+		if sqlPassed != nil {
+			passed = make(map[int64]bool, len(sqlPassed[user.ID]))
+			for _, productID := range sqlPassed[user.ID] {
+				passed[productID] = true
+			}
 		}
-		if creditComponent < 0 {
-			creditComponent = 0
+
+		for _, product := range products {
+			if !passed[product.ID] {
+				failed := failedChecks(currencyChecks(user, product, rates))
+				if len(failed) > 0 {
+					rejections = append(rejections, &models.MatchRejection{
+						UserID:    user.ID,
+						ProductID: product.ID,
+						Stage:     models.MatchSourceSQLFilter,
+						Reasons:   failed,
+						BatchID:   user.BatchID,
+					})
+					continue
+				}
+				// Pairs that pass in Go but not in Postgres were excluded for
+				// another reason, such as an inactive user or a product
+				// deactivated mid-run
+				if sqlPassed != nil {
+					continue
+				}
+			}
+
+			candidates = append(candidates, &MatchCandidate{
+				UserID:              user.ID,
+				ProductID:           product.ID,
+				ProductVersion:      product.Version,
+				IncomeEligible:      true,
+				CreditScoreEligible: true,
+				AgeEligible:         true,
+				EmploymentEligible:  true,
+			})
 		}
-		score += creditComponent
 	}
 
-	// Income component (30% weight)
-	incomeExcess := user.MonthlyIncome - product.MinMonthlyIncome
-	incomeRange := product.MinMonthlyIncome * 2 // 2x min income is considered excellent
-	if incomeRange > 0 {
-		incomeComponent := (incomeExcess / incomeRange) * 30
-		if incomeComponent > 30 {
-			incomeComponent = 30
-		}
-		if incomeComponent < 0 {
-			incomeComponent = 0
-		}
-		score += incomeComponent
+	return candidates, rejections
+}
+
+// loadRuleSets compiles the stored eligibility rules for each product.
+// Products without stored rules get the default rule set; a rule that does
+// not compile fails every pair of its product.
+func (m *MatcherService) loadRuleSets(ctx context.Context, products []*models.LoanProduct) (map[int64]rules.RuleSet, error) {
+	productIDs := make([]int64, len(products))
+	for i, p := range products {
+		productIDs[i] = p.ID
+	}
+
+	stored, err := m.ruleRepo.GetActiveByProductIDs(ctx, productIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load eligibility rules: %w", err)
 	}
 
-	// Loan amount fit (20% weight) - base score for eligible candidates
-	score += 20
+	ruleSets := make(map[int64]rules.RuleSet, len(products))
+	for _, p := range products {
+		productRules, ok := stored[p.ID]
+		if !ok || len(productRules) == 0 {
+			ruleSets[p.ID] = rules.DefaultRuleSet()
+			continue
+		}
 
-	// Age component (10% weight)
-	ageRange := float64(product.MaxAge - product.MinAge)
-	if ageRange > 0 {
-		ageMidpoint := float64(product.MinAge+product.MaxAge) / 2
-		ageDiff := abs(float64(user.Age) - ageMidpoint)
-		ageComponent := (1 - ageDiff/(ageRange/2)) * 10
-		if ageComponent < 0 {
-			ageComponent = 0
+		set, errs := rules.FromModels(productRules)
+		for _, e := range errs {
+			utils.Logger.Warn("Invalid eligibility rule fails every pair",
+				zap.Int64("product_id", p.ID),
+				zap.Error(e),
+			)
 		}
-		score += ageComponent
+		ruleSets[p.ID] = set
 	}
 
-	return score
+	return ruleSets, nil
 }
 
-func abs(x float64) float64 {
-	if x < 0 {
-		return -x
+// loadRateSlabs loads the rate slabs of each product
+func (m *MatcherService) loadRateSlabs(ctx context.Context, products []*models.LoanProduct) (map[int64][]*models.RateSlab, error) {
+	productIDs := make([]int64, len(products))
+	for i, p := range products {
+		productIDs[i] = p.ID
 	}
-	return x
-}
 
-// selectTopCandidates selects the top N candidates by score
-func (m *MatcherService) selectTopCandidates(candidates []*MatchCandidate, limit int) []*MatchCandidate {
-	if len(candidates) <= limit {
-		return candidates
+	slabs, err := m.slabRepo.GetByProductIDs(ctx, productIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load rate slabs: %w", err)
 	}
+	return slabs, nil
+}
 
-	// Sort by eligibility score (simple bubble sort for small arrays)
-	for i := 0; i < len(candidates)-1; i++ {
-		for j := 0; j < len(candidates)-i-1; j++ {
-			if candidates[j].EligibilityScore < candidates[j+1].EligibilityScore {
-				candidates[j], candidates[j+1] = candidates[j+1], candidates[j]
-			}
-		}
+// loadFXRates loads the exchange rates used to compare users and products in
+// different currencies
+func (m *MatcherService) loadFXRates(ctx context.Context) (*fx.Table, error) {
+	rates, err := m.fxRepo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load exchange rates: %w", err)
 	}
-
-	return candidates[:limit]
+	return fx.NewTable(rates), nil
 }
 
-// llmCheck uses LLM for qualitative assessment
-func (m *MatcherService) llmCheck(ctx context.Context, candidates []*MatchCandidate, users []*models.User, products []*models.LoanProduct) ([]*MatchCandidate, error) {
+// logicFilter applies each product's eligibility rules and scores the
+// survivors. Candidates are split across MatchWorkers goroutines; the output
+// keeps the input order.
+func (m *MatcherService) logicFilter(candidates []*MatchCandidate, users []*models.User, in *matchInputs) ([]*MatchCandidate, []*models.MatchRejection) {
 	userMap := make(map[int64]*models.User)
 	for _, u := range users {
 		userMap[u.ID] = u
 	}
 
 	productMap := make(map[int64]*models.LoanProduct)
-	for _, p := range products {
+	for _, p := range in.products {
 		productMap[p.ID] = p
 	}
 
-	passed := make([]*MatchCandidate, 0)
-	var lastErr error
+	workers := max(1, min(m.config.MatchWorkers, len(candidates)))
+	shardSize := (len(candidates) + workers - 1) / workers
+
+	type shardResult struct {
+		filtered   []*MatchCandidate
+		rejections []*models.MatchRejection
+	}
+	shards := make([]shardResult, workers)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		lo := min(i*shardSize, len(candidates))
+		hi := min(lo+shardSize, len(candidates))
+
+		wg.Add(1)
+		go func(i int, shard []*MatchCandidate) {
+			defer wg.Done()
+			shards[i].filtered, shards[i].rejections = m.logicFilterShard(shard, userMap, productMap, in)
+		}(i, candidates[lo:hi])
+	}
+	wg.Wait()
+
+	filtered := make([]*MatchCandidate, 0, len(candidates)/2)
+	rejections := make([]*models.MatchRejection, 0)
+	for _, s := range shards {
+		filtered = append(filtered, s.filtered...)
+		rejections = append(rejections, s.rejections...)
+	}
+
+	return filtered, rejections
+}
+
+// logicFilterShard runs the logic filter over one shard of candidates
+func (m *MatcherService) logicFilterShard(candidates []*MatchCandidate, userMap map[int64]*models.User, productMap map[int64]*models.LoanProduct, in *matchInputs) ([]*MatchCandidate, []*models.MatchRejection) {
+	filtered := make([]*MatchCandidate, 0, len(candidates)/2)
+	rejections := make([]*models.MatchRejection, 0)
 
 	for _, c := range candidates {
 		user := userMap[c.UserID]
@@ -379,37 +591,53 @@ func (m *MatcherService) llmCheck(ctx context.Context, candidates []*MatchCandid
 			continue
 		}
 
-		response, err := m.llmClient.EvaluateMatch(ctx, user, product)
-		if err != nil {
-			utils.Logger.Warn("LLM check failed for candidate",
-				zap.Int64("user_id", c.UserID),
-				zap.Int64("product_id", c.ProductID),
-				zap.Error(err),
-			)
-			lastErr = err
-			// On LLM failure, keep candidates that passed logic filter with high scores
-			if c.EligibilityScore >= 60 {
-				c.LLMCheckPassed = true
-				c.LLMReasoning = "LLM check skipped due to API error, high score approved"
-				passed = append(passed, c)
-			}
+		// Evaluate the user in the product's currency
+		user, ok := in.rates.UserIn(user, product.Currency)
+		if !ok {
 			continue
 		}
 
-		c.LLMCheckPassed = response.Qualified
-		c.LLMReasoning = response.Reasoning
-		c.LLMConfidence = response.Confidence
+		ruleSet, ok := in.ruleSets[product.ID]
+		if !ok {
+			ruleSet = rules.DefaultRuleSet()
+		}
 
-		if response.Qualified {
-			passed = append(passed, c)
+		// Apply the product's eligibility rules and the FOIR limit
+		results, passed := ruleSet.WithFOIRLimit().Evaluate(rules.NewEnv(user, product, m.config.MaxFOIR))
+		c.RuleResults = results
+		if !passed {
+			rejections = append(rejections, &models.MatchRejection{
+				UserID:    user.ID,
+				ProductID: product.ID,
+				Stage:     models.MatchSourceLogicFilter,
+				Reasons:   ruleChecks(results, false),
+				BatchID:   user.BatchID,
+			})
+			continue
 		}
+
+		// Calculate eligibility score, what the user can afford and the rate
+		// they can expect
+		c.EligibilityScore = in.model.Score(user, product)
+		c.ScoringVersion = in.model.Version
+		c.Affordability = affordability.Assess(user, product, m.config.MaxFOIR)
+		c.RateEstimate = pricing.Estimate(user, product, in.rateSlabs[product.ID])
+
+		filtered = append(filtered, c)
 	}
 
-	return passed, lastErr
+	return filtered, rejections
 }
 
-// createMatches converts candidates to MatchCreate models
-func (m *MatcherService) createMatches(candidates []*MatchCandidate) []*models.MatchCreate {
+// createMatches converts candidates to MatchCreate models with the given status.
+// Unreviewed candidates never reached the LLM, so they are attributed to the
+// logic filter.
+func (m *MatcherService) createMatches(candidates []*MatchCandidate, status models.MatchStatus) []*models.MatchCreate {
+	source := models.MatchSourceLLMCheck
+	if status == models.MatchStatusUnreviewed {
+		source = models.MatchSourceLogicFilter
+	}
+
 	matches := make([]*models.MatchCreate, len(candidates))
 
 	for i, c := range candidates {
@@ -422,148 +650,21 @@ func (m *MatcherService) createMatches(candidates []*MatchCandidate) []*models.M
 			UserID:              c.UserID,
 			ProductID:           c.ProductID,
 			MatchScore:          c.EligibilityScore,
-			Status:              models.MatchStatusEligible,
-			MatchSource:         models.MatchSourceLLMCheck,
+			Status:              status,
+			MatchSource:         source,
 			IncomeEligible:      c.IncomeEligible,
 			CreditScoreEligible: c.CreditScoreEligible,
 			AgeEligible:         c.AgeEligible,
 			EmploymentEligible:  c.EmploymentEligible,
 			LLMAnalysis:         c.LLMReasoning,
 			LLMConfidence:       llmConfidence,
+			RuleResults:         c.RuleResults,
+			Affordability:       c.Affordability,
+			RateEstimate:        c.RateEstimate,
+			ScoringVersion:      c.ScoringVersion,
+			ProductVersion:      c.ProductVersion,
 		}
 	}
 
 	return matches
 }
-
-// EvaluateMatch calls the LLM API to evaluate a user-product match
-func (c *LLMClient) EvaluateMatch(ctx context.Context, user *models.User, product *models.LoanProduct) (*LLMResponse, error) {
-	if c.apiKey == "" {
-		return &LLMResponse{
-			Qualified:  true,
-			Confidence: 0.7,
-			Reasoning:  "LLM check skipped - no API key configured",
-		}, nil
-	}
-
-	prompt := c.buildPrompt(user, product)
-
-	requestBody := map[string]interface{}{
-		"contents": []map[string]interface{}{
-			{
-				"parts": []map[string]string{
-					{"text": prompt},
-				},
-			},
-		},
-		"generationConfig": map[string]interface{}{
-			"temperature":     0.1,
-			"topK":            1,
-			"topP":            1,
-			"maxOutputTokens": 500,
-		},
-	}
-
-	jsonBody, err := json.Marshal(requestBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	url := fmt.Sprintf("%s?key=%s", c.apiURL, c.apiKey)
-	req, err := http.NewRequestWithContext(ctx, "POST", url, strings.NewReader(string(jsonBody)))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("API request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API returned status %d", resp.StatusCode)
-	}
-
-	var result map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
-
-	return c.parseResponse(result)
-}
-
-// buildPrompt creates the LLM prompt
-func (c *LLMClient) buildPrompt(user *models.User, product *models.LoanProduct) string {
-	return fmt.Sprintf(`You are a loan eligibility expert. Evaluate if this user is a good candidate for this loan product.
-
-USER PROFILE:
-- User ID: %s
-- Age: %d years
-- Monthly Income: ₹%.0f
-- Credit Score: %d
-- Employment Status: %s
-
-LOAN PRODUCT:
-- Name: %s
-- Provider: %s
-- Interest Rate: %.2f%% - %.2f%%
-- Loan Amount Range: ₹%.0f - ₹%.0f
-- Min Credit Score: %d
-- Min Monthly Income: ₹%.0f
-- Age Range: %d - %d years
-
-Respond ONLY with valid JSON in this exact format:
-{
-  "qualified": true/false,
-  "confidence": 0.0-1.0,
-  "reasoning": "Brief explanation",
-  "risk_factors": ["factor1", "factor2"]
-}
-
-Consider:
-1. Does the user meet all hard requirements?
-2. Is their income sufficient for loan EMI?
-3. Are there any red flags or risk factors?
-4. Overall likelihood of loan approval`,
-		user.UserID, user.Age, user.MonthlyIncome, user.CreditScore,
-		user.EmploymentStatus,
-		product.ProductName, product.ProviderName, product.InterestRateMin, product.InterestRateMax,
-		product.LoanAmountMin, product.LoanAmountMax, product.MinCreditScore,
-		product.MinMonthlyIncome, product.MinAge, product.MaxAge,
-	)
-}
-
-// parseResponse extracts LLMResponse from API response
-func (c *LLMClient) parseResponse(result map[string]interface{}) (*LLMResponse, error) {
-	candidates, ok := result["candidates"].([]interface{})
-	if !ok || len(candidates) == 0 {
-		return nil, fmt.Errorf("no candidates in response")
-	}
-
-	candidate := candidates[0].(map[string]interface{})
-	content := candidate["content"].(map[string]interface{})
-	parts := content["parts"].([]interface{})
-	if len(parts) == 0 {
-		return nil, fmt.Errorf("no parts in response")
-	}
-
-	text := parts[0].(map[string]interface{})["text"].(string)
-
-	// Extract JSON from response
-	start := strings.Index(text, "{")
-	end := strings.LastIndex(text, "}")
-	if start == -1 || end == -1 {
-		return nil, fmt.Errorf("no JSON found in response")
-	}
-
-	jsonStr := text[start : end+1]
-
-	var response LLMResponse
-	if err := json.Unmarshal([]byte(jsonStr), &response); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON: %w", err)
-	}
-
-	return &response, nil
-}
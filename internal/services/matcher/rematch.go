@@ -0,0 +1,282 @@
+package matcher
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"go.uber.org/zap"
+
+	"loan-eligibility-engine/internal/models"
+	"loan-eligibility-engine/internal/utils"
+)
+
+// rescoreThreshold is the smallest score change recorded as a rescore
+const rescoreThreshold = 0.005
+
+// isCurrentMatch reports whether a stored match is still live, i.e. it has
+// not been expired or ruled out
+func isCurrentMatch(status models.MatchStatus) bool {
+	switch status {
+	case models.MatchStatusEligible, models.MatchStatusNotified,
+		models.MatchStatusUnreviewed, models.MatchStatusPending, models.MatchStatusPendingReview:
+		return true
+	}
+	return false
+}
+
+// DiffProductMatches compares the stored matches of a product with the
+// matches produced by re-running the pipeline for it. It returns the changes
+// to record and the IDs of stored matches that should be expired. Fresh
+// matches for users who were already notified keep the notified status so
+// they are not notified again. Pairs a reviewer decided on are left out, as
+// saving the fresh match keeps the decision (see MatchRepository.BulkInsert).
+func DiffProductMatches(existing []models.Match, fresh []*models.MatchCreate) ([]models.RematchChange, []int64) {
+	current := make(map[int64]models.Match, len(existing))
+	reviewed := make(map[int64]bool)
+	for _, match := range existing {
+		if isCurrentMatch(match.Status) {
+			current[match.UserID] = match
+		}
+		if match.MatchSource == models.MatchSourceManual && match.Status != models.MatchStatusExpired {
+			reviewed[match.UserID] = true
+		}
+	}
+
+	var changes []models.RematchChange
+	seen := make(map[int64]bool, len(fresh))
+	for _, match := range fresh {
+		seen[match.UserID] = true
+		if reviewed[match.UserID] {
+			continue
+		}
+		newScore := match.MatchScore
+
+		old, ok := current[match.UserID]
+		if !ok {
+			changes = append(changes, models.RematchChange{
+				UserID:     match.UserID,
+				ProductID:  match.ProductID,
+				ChangeType: models.RematchChangeAdded,
+				NewScore:   &newScore,
+			})
+			continue
+		}
+
+		if old.Status == models.MatchStatusNotified {
+			match.Status = models.MatchStatusNotified
+		}
+		if math.Abs(old.MatchScore-newScore) > rescoreThreshold || old.Status != match.Status {
+			oldScore := old.MatchScore
+			changes = append(changes, models.RematchChange{
+				UserID:     match.UserID,
+				ProductID:  match.ProductID,
+				ChangeType: models.RematchChangeRescored,
+				OldScore:   &oldScore,
+				NewScore:   &newScore,
+			})
+		}
+	}
+
+	var expired []int64
+	for _, match := range existing {
+		if !isCurrentMatch(match.Status) || seen[match.UserID] {
+			continue
+		}
+		oldScore := match.MatchScore
+		changes = append(changes, models.RematchChange{
+			UserID:     match.UserID,
+			ProductID:  match.ProductID,
+			ChangeType: models.RematchChangeRemoved,
+			OldScore:   &oldScore,
+		})
+		expired = append(expired, match.ID)
+	}
+
+	return changes, expired
+}
+
+// RematchChangedProducts re-matches every product whose terms or active flag
+// changed since it was last re-matched. Returns nil if nothing changed.
+func (m *MatcherService) RematchChangedProducts(ctx context.Context) (*models.RematchRun, error) {
+	productIDs, err := m.productRepo.GetPendingRematch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(productIDs) == 0 {
+		return nil, nil
+	}
+
+	return m.RematchProducts(ctx, productIDs, models.RematchTriggerProductChange)
+}
+
+// RematchProducts re-runs the pipeline for the given products against all
+// active users, a chunk of users at a time. Matches of deactivated products
+// are expired. The run and every added, removed or rescored match are
+// recorded, including on failure.
+func (m *MatcherService) RematchProducts(ctx context.Context, productIDs []int64, trigger models.RematchTrigger) (*models.RematchRun, error) {
+	run := &models.RematchRun{
+		Trigger:    trigger,
+		ProductIDs: productIDs,
+		StartedAt:  time.Now().UTC(),
+	}
+	if err := m.rematchRepo.CreateRun(ctx, run); err != nil {
+		return nil, err
+	}
+
+	utils.Logger.Info("Starting product re-match",
+		zap.Int64("run_id", run.ID),
+		zap.String("trigger", string(run.Trigger)),
+		zap.Int64s("products", productIDs),
+	)
+
+	var runErr error
+	var done []int64
+	for _, productID := range productIDs {
+		changes, err := m.rematchProduct(ctx, productID)
+		if err != nil {
+			runErr = fmt.Errorf("product %d: %w", productID, err)
+			break
+		}
+		run.Changes = append(run.Changes, changes...)
+		done = append(done, productID)
+	}
+
+	for _, change := range run.Changes {
+		switch change.ChangeType {
+		case models.RematchChangeAdded:
+			run.Added++
+		case models.RematchChangeRemoved:
+			run.Removed++
+		case models.RematchChangeRescored:
+			run.Rescored++
+		}
+	}
+
+	run.Status = models.RematchRunStatusCompleted
+	if runErr != nil {
+		run.Status = models.RematchRunStatusFailed
+		run.Error = runErr.Error()
+	}
+
+	// Record the run even if the request was cancelled part-way
+	saveCtx := context.WithoutCancel(ctx)
+	if err := m.rematchRepo.CompleteRun(saveCtx, run); err != nil {
+		return run, err
+	}
+	if len(done) > 0 {
+		if err := m.productRepo.MarkRematched(saveCtx, done, run.StartedAt); err != nil {
+			return run, err
+		}
+	}
+
+	utils.Logger.Info("Product re-match complete",
+		zap.Int64("run_id", run.ID),
+		zap.String("status", string(run.Status)),
+		zap.Int("added", run.Added),
+		zap.Int("removed", run.Removed),
+		zap.Int("rescored", run.Rescored),
+	)
+
+	return run, runErr
+}
+
+// rematchProduct re-evaluates one product against the active users, one
+// chunk at a time, saving each chunk's matches and rejections before loading
+// the next. The LLM budget is shared across the chunks with ChunkLLMBudget,
+// as in ProcessBatch and ProcessNewUsers. A deleted or inactive product has
+// all of its current matches expired.
+func (m *MatcherService) rematchProduct(ctx context.Context, productID int64) ([]models.RematchChange, error) {
+	product, err := m.productRepo.GetByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := m.matchRepo.GetByProductID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []models.RematchChange
+	var afterID int64
+	if product != nil && product.IsActive {
+		in, err := m.inputsFor(ctx, []*models.LoanProduct{product})
+		if err != nil {
+			return nil, err
+		}
+		total, err := m.userRepo.CountActive(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		llmCalls, evaluated := 0, 0
+		for {
+			users, err := m.userRepo.GetActiveAfter(ctx, afterID, m.chunkSize())
+			if err != nil {
+				return nil, fmt.Errorf("failed to get users: %w", err)
+			}
+			if len(users) == 0 {
+				break
+			}
+			lastID := users[len(users)-1].ID
+
+			result := &MatchingResult{
+				TotalUsers:    len(users),
+				TotalProducts: 1,
+				TotalPairs:    len(users),
+			}
+			// Users activated since the count was taken still get a share
+			budget := ChunkLLMBudget(m.config.LLMBatchBudget, llmCalls, len(users), max(total-evaluated, len(users)))
+			fresh, rejections, calls, err := m.evaluatePairs(ctx, users, in, budget, result)
+			if err != nil {
+				return nil, err
+			}
+			llmCalls += calls
+			evaluated += len(users)
+
+			chunkChanges, err := m.saveRematch(ctx, productID, existing, afterID, lastID, fresh, rejections)
+			if err != nil {
+				return nil, err
+			}
+			changes = append(changes, chunkChanges...)
+			afterID = lastID
+		}
+	}
+
+	// Past the last chunk are users no longer active, or every user if the
+	// product is gone: their matches are expired and rejections cleared
+	tailChanges, err := m.saveRematch(ctx, productID, existing, afterID, math.MaxInt64, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return append(changes, tailChanges...), nil
+}
+
+// saveRematch diffs and saves the re-matched pairs of a product for the users
+// with IDs in (afterID, throughID], given the product's stored matches
+func (m *MatcherService) saveRematch(ctx context.Context, productID int64, existing []models.Match, afterID, throughID int64, fresh []*models.MatchCreate, rejections []*models.MatchRejection) ([]models.RematchChange, error) {
+	var inRange []models.Match
+	for _, match := range existing {
+		if match.UserID > afterID && match.UserID <= throughID {
+			inRange = append(inRange, match)
+		}
+	}
+
+	changes, expired := DiffProductMatches(inRange, fresh)
+
+	if _, _, err := m.matchRepo.BulkInsert(ctx, fresh); err != nil {
+		return nil, fmt.Errorf("failed to save matches: %w", err)
+	}
+	if err := m.matchRepo.Expire(ctx, expired); err != nil {
+		return nil, err
+	}
+	if err := m.rejectRepo.ReplaceForProductUsers(ctx, productID, afterID, throughID, rejections); err != nil {
+		utils.Logger.Warn("Failed to save match rejections",
+			zap.Int64("product_id", productID),
+			zap.Error(err),
+		)
+	}
+
+	return changes, nil
+}
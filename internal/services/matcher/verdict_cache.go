@@ -0,0 +1,91 @@
+package matcher
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"loan-eligibility-engine/internal/models"
+	"loan-eligibility-engine/internal/services/llm"
+	"loan-eligibility-engine/internal/utils"
+)
+
+// cacheEnabled reports whether LLM verdicts are cached. A non-positive
+// LLM_CACHE_TTL_HOURS disables the cache.
+func (m *MatcherService) cacheEnabled() bool {
+	return m.verdicts != nil && m.config.LLMCacheTTLHours > 0
+}
+
+// applyCachedVerdicts records cached verdicts on the candidates that have
+// one and returns the cached and uncached candidates separately. Cached
+// verdicts cost nothing, so they are resolved before the LLM budget is spent.
+// The cache is best effort: a lookup failure treats every candidate as a miss.
+func (m *MatcherService) applyCachedVerdicts(ctx context.Context, candidates []*MatchCandidate, users []*models.User, in *matchInputs) (cached, uncached []*MatchCandidate) {
+	if !m.cacheEnabled() || len(candidates) == 0 {
+		return nil, candidates
+	}
+
+	userMap := make(map[int64]*models.User)
+	for _, u := range users {
+		userMap[u.ID] = u
+	}
+
+	productMap := make(map[int64]*models.LoanProduct)
+	for _, p := range in.products {
+		productMap[p.ID] = p
+	}
+
+	provider := m.evaluator.Name()
+	keys := make([]string, len(candidates))
+	for i, c := range candidates {
+		user, product := in.userFor(userMap[c.UserID], productMap[c.ProductID])
+		if user != nil && product != nil {
+			keys[i] = llm.CacheKey(provider, user, product)
+		}
+	}
+
+	verdicts, err := m.verdicts.GetMany(ctx, keys)
+	if err != nil {
+		utils.Logger.Warn("LLM verdict cache lookup failed", zap.Error(err))
+		return nil, candidates
+	}
+
+	for i, c := range candidates {
+		verdict := verdicts[keys[i]]
+		if verdict == nil {
+			uncached = append(uncached, c)
+			continue
+		}
+
+		c.LLMCached = true
+		m.applyVerdict(c, verdict.Qualified, verdict.Confidence, verdict.Reasoning)
+		cached = append(cached, c)
+	}
+
+	return cached, uncached
+}
+
+// storeVerdict caches a fresh LLM verdict. Failures are logged and ignored.
+func (m *MatcherService) storeVerdict(ctx context.Context, cacheKey string, productID int64, response *llm.Response) {
+	if !m.cacheEnabled() {
+		return
+	}
+
+	now := time.Now().UTC()
+	err := m.verdicts.Put(ctx, &models.LLMVerdict{
+		CacheKey:      cacheKey,
+		ProductID:     productID,
+		Provider:      m.evaluator.Name(),
+		PromptVersion: llm.PromptVersion,
+		Qualified:     response.Qualified,
+		Confidence:    response.Confidence,
+		Reasoning:     response.Reasoning,
+		RiskFactors:   response.RiskFactors,
+		CreatedAt:     now,
+		ExpiresAt:     now.Add(time.Duration(m.config.LLMCacheTTLHours) * time.Hour),
+	})
+	if err != nil {
+		utils.Logger.Warn("Failed to cache LLM verdict", zap.Error(err))
+	}
+}
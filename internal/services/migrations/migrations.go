@@ -0,0 +1,190 @@
+// Package migrations evolves the database schema through ordered, versioned
+// SQL migrations embedded in the binary. Each migration is a pair of files,
+// NNNN_name.up.sql and NNNN_name.down.sql; applied versions are recorded in
+// the schema_migrations table with a checksum of their up file, so an edit to
+// a migration that already ran is caught rather than silently diverging.
+package migrations
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+//go:embed sql/*.sql
+var embedded embed.FS
+
+var (
+	// ErrSchemaBehind is returned by Check when migrations are pending
+	ErrSchemaBehind = errors.New("database schema is behind")
+	// ErrChecksumMismatch is returned when an applied migration's file changed
+	ErrChecksumMismatch = errors.New("applied migration does not match its file")
+)
+
+// fileName matches migration files, e.g. 0002_add_batches.up.sql
+var fileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration is one schema change with its rollback
+type Migration struct {
+	Version  int
+	Name     string
+	Up       string
+	Down     string
+	Checksum string // SHA-256 of Up
+}
+
+// Applied is a row of schema_migrations
+type Applied struct {
+	Version   int
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+// State is the status of one migration: known to the binary, applied to the
+// database, or both
+type State struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+	// Modified is set when the applied checksum differs from the file's
+	Modified bool `json:"modified,omitempty"`
+	// Unknown is set for an applied version this binary has no file for,
+	// i.e. the database was migrated by a newer release
+	Unknown bool `json:"unknown,omitempty"`
+}
+
+// Load returns the embedded migrations in version order
+func Load() ([]*Migration, error) {
+	sub, err := fs.Sub(embedded, "sql")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open embedded migrations: %w", err)
+	}
+	return LoadFS(sub)
+}
+
+// LoadFS reads migrations from the root of fsys in version order. Every
+// version must have both an up and a down file, and versions must be unique.
+func LoadFS(fsys fs.FS) ([]*Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list migrations: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		parts := fileName.FindStringSubmatch(entry.Name())
+		if parts == nil {
+			return nil, fmt.Errorf("invalid migration file name %q, want NNNN_name.up.sql or NNNN_name.down.sql", entry.Name())
+		}
+		version, _ := strconv.Atoi(parts[1])
+		if version <= 0 {
+			return nil, fmt.Errorf("migration %s: version must be positive", entry.Name())
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: parts[2]}
+			byVersion[version] = migration
+		} else if migration.Name != parts[2] {
+			return nil, fmt.Errorf("migration version %d is used by both %q and %q", version, migration.Name, parts[2])
+		}
+
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+		if parts[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration %04d_%s has no up file", migration.Version, migration.Name)
+		}
+		if migration.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s has no down file", migration.Version, migration.Name)
+		}
+		migration.Checksum = Checksum(migration.Up)
+		migrations = append(migrations, migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Checksum is the hex SHA-256 of a migration's up SQL
+func Checksum(sql string) string {
+	sum := sha256.Sum256([]byte(sql))
+	return hex.EncodeToString(sum[:])
+}
+
+// Pending verifies the applied migrations against the known ones and returns
+// those not yet applied, in version order. An applied migration whose file
+// changed is ErrChecksumMismatch; applied versions the binary does not know
+// are left alone.
+func Pending(migrations []*Migration, applied []Applied) ([]*Migration, error) {
+	done := make(map[int]Applied, len(applied))
+	for _, a := range applied {
+		done[a.Version] = a
+	}
+
+	var pending []*Migration
+	for _, migration := range migrations {
+		a, ok := done[migration.Version]
+		if !ok {
+			pending = append(pending, migration)
+			continue
+		}
+		if a.Checksum != migration.Checksum {
+			return nil, fmt.Errorf("%w: %04d_%s", ErrChecksumMismatch, migration.Version, migration.Name)
+		}
+	}
+	return pending, nil
+}
+
+// States merges known and applied migrations into one list in version order
+func States(migrations []*Migration, applied []Applied) []State {
+	done := make(map[int]Applied, len(applied))
+	for _, a := range applied {
+		done[a.Version] = a
+	}
+
+	states := make([]State, 0, len(migrations)+len(applied))
+	known := make(map[int]bool, len(migrations))
+	for _, migration := range migrations {
+		known[migration.Version] = true
+		state := State{Version: migration.Version, Name: migration.Name}
+		if a, ok := done[migration.Version]; ok {
+			appliedAt := a.AppliedAt
+			state.Applied = true
+			state.AppliedAt = &appliedAt
+			state.Modified = a.Checksum != migration.Checksum
+		}
+		states = append(states, state)
+	}
+	for _, a := range applied {
+		if known[a.Version] {
+			continue
+		}
+		appliedAt := a.AppliedAt
+		states = append(states, State{Version: a.Version, Name: a.Name, Applied: true, AppliedAt: &appliedAt, Unknown: true})
+	}
+
+	sort.Slice(states, func(i, j int) bool { return states[i].Version < states[j].Version })
+	return states
+}
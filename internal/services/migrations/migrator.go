@@ -0,0 +1,213 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"loan-eligibility-engine/internal/services/database"
+)
+
+// lockKey is the advisory lock held while migrating, so two processes never
+// apply the same migration
+const lockKey = 7238100422
+
+const createTable = `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		checksum VARCHAR(64) NOT NULL,
+		applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`
+
+// Migrator applies migrations to a database
+type Migrator struct {
+	db         *database.DB
+	migrations []*Migration
+}
+
+// NewMigrator creates a migrator for the embedded migrations
+func NewMigrator(db *database.DB) (*Migrator, error) {
+	migrations, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Migrations returns the known migrations in version order
+func (m *Migrator) Migrations() []*Migration {
+	return m.migrations
+}
+
+// Up applies every pending migration in version order, each in its own
+// transaction, and returns those applied. It stops at the first failure,
+// leaving earlier migrations applied.
+func (m *Migrator) Up(ctx context.Context) ([]*Migration, error) {
+	if _, err := m.db.ExecContext(ctx, createTable); err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pending, err := Pending(m.migrations, applied)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []*Migration
+	for _, migration := range pending {
+		ran := false
+		err := m.db.WithTransaction(ctx, func(tx pgx.Tx) error {
+			if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", lockKey); err != nil {
+				return fmt.Errorf("failed to take migration lock: %w", err)
+			}
+
+			// Another process may have applied it while we waited for the lock
+			var exists bool
+			if err := tx.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM schema_migrations WHERE version = $1)", migration.Version).Scan(&exists); err != nil {
+				return fmt.Errorf("failed to check migration %04d: %w", migration.Version, err)
+			}
+			if exists {
+				return nil
+			}
+
+			if _, err := tx.Exec(ctx, migration.Up); err != nil {
+				return fmt.Errorf("failed to apply migration %04d_%s: %w", migration.Version, migration.Name, err)
+			}
+			_, err := tx.Exec(ctx, "INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)",
+				migration.Version, migration.Name, migration.Checksum)
+			if err != nil {
+				return fmt.Errorf("failed to record migration %04d: %w", migration.Version, err)
+			}
+			ran = true
+			return nil
+		})
+		if err != nil {
+			return done, err
+		}
+		if ran {
+			done = append(done, migration)
+		}
+	}
+	return done, nil
+}
+
+// Down rolls back the latest steps applied migrations, newest first, and
+// returns those rolled back. Every migration rolled back must be known to
+// this binary and unchanged since it was applied.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]*Migration, error) {
+	if steps <= 0 {
+		return nil, fmt.Errorf("steps must be positive, got %d", steps)
+	}
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[int]*Migration, len(m.migrations))
+	for _, migration := range m.migrations {
+		known[migration.Version] = migration
+	}
+
+	// Check the whole rollback before running any of it
+	var rollback []*Migration
+	for i := len(applied) - 1; i >= 0 && len(rollback) < steps; i-- {
+		migration, ok := known[applied[i].Version]
+		if !ok {
+			return nil, fmt.Errorf("applied migration %04d_%s is unknown to this release", applied[i].Version, applied[i].Name)
+		}
+		if applied[i].Checksum != migration.Checksum {
+			return nil, fmt.Errorf("%w: %04d_%s", ErrChecksumMismatch, migration.Version, migration.Name)
+		}
+		rollback = append(rollback, migration)
+	}
+
+	var done []*Migration
+	for _, migration := range rollback {
+		err := m.db.WithTransaction(ctx, func(tx pgx.Tx) error {
+			if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", lockKey); err != nil {
+				return fmt.Errorf("failed to take migration lock: %w", err)
+			}
+			if _, err := tx.Exec(ctx, migration.Down); err != nil {
+				return fmt.Errorf("failed to roll back migration %04d_%s: %w", migration.Version, migration.Name, err)
+			}
+			if _, err := tx.Exec(ctx, "DELETE FROM schema_migrations WHERE version = $1", migration.Version); err != nil {
+				return fmt.Errorf("failed to unrecord migration %04d: %w", migration.Version, err)
+			}
+			return nil
+		})
+		if err != nil {
+			return done, err
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// Status lists every known or applied migration
+func (m *Migrator) Status(ctx context.Context) ([]State, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return States(m.migrations, applied), nil
+}
+
+// Check returns ErrSchemaBehind when migrations are pending and
+// ErrChecksumMismatch when an applied migration changed. It only reads.
+func (m *Migrator) Check(ctx context.Context) error {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return err
+	}
+	pending, err := Pending(m.migrations, applied)
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: %d pending migration(s), starting with %04d_%s; run migrate up",
+			ErrSchemaBehind, len(pending), pending[0].Version, pending[0].Name)
+	}
+	return nil
+}
+
+// Check verifies that a database has every embedded migration applied
+func Check(ctx context.Context, db *database.DB) error {
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		return err
+	}
+	return migrator.Check(ctx)
+}
+
+// applied reads schema_migrations in version order; a database that was
+// never migrated has none
+func (m *Migrator) applied(ctx context.Context) ([]Applied, error) {
+	var exists bool
+	if err := m.db.QueryRowContext(ctx, "SELECT to_regclass('schema_migrations') IS NOT NULL").Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to look up schema_migrations: %w", err)
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	rows, err := m.db.QueryContext(ctx, "SELECT version, name, checksum, applied_at FROM schema_migrations ORDER BY version")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	var applied []Applied
+	for rows.Next() {
+		var a Applied
+		if err := rows.Scan(&a.Version, &a.Name, &a.Checksum, &a.AppliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan schema migration: %w", err)
+		}
+		applied = append(applied, a)
+	}
+	return applied, rows.Err()
+}
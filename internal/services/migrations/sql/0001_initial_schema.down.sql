@@ -0,0 +1,25 @@
+-- Removes the initial schema, and with it every row in the database.
+-- migrate refuses to run this in production without -allow-data-loss.
+
+DROP TABLE IF EXISTS jobs;
+DROP TABLE IF EXISTS notification_logs;
+DROP TABLE IF EXISTS match_checkpoints;
+DROP TABLE IF EXISTS scoring_models;
+DROP TABLE IF EXISTS rematch_changes;
+DROP TABLE IF EXISTS rematch_runs;
+DROP TABLE IF EXISTS crawler_runs;
+DROP TABLE IF EXISTS upload_batches;
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS llm_verdict_cache;
+DROP TABLE IF EXISTS match_reviews;
+DROP TABLE IF EXISTS match_rejections;
+DROP TABLE IF EXISTS matches;
+DROP TABLE IF EXISTS product_rate_slabs;
+DROP TABLE IF EXISTS product_eligibility_rules;
+DROP TABLE IF EXISTS fx_rates;
+DROP TABLE IF EXISTS loan_products;
+DROP TABLE IF EXISTS users;
+
+DROP FUNCTION IF EXISTS invalidate_llm_verdicts();
+DROP FUNCTION IF EXISTS track_product_terms_change();
+DROP FUNCTION IF EXISTS update_updated_at_column();
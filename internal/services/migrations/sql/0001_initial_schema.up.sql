@@ -0,0 +1,515 @@
+-- Initial schema: every table, index and trigger of the engine.
+--
+-- Written to be safe on a database created by the former
+-- scripts/init_database.sql, which it adopts without touching data: tables
+-- that script created get the columns added since through
+-- ADD COLUMN IF NOT EXISTS, before any index or trigger uses them.
+
+-- Users Table
+CREATE TABLE IF NOT EXISTS users (
+    id SERIAL PRIMARY KEY,
+    user_id VARCHAR(50) UNIQUE NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    monthly_income DECIMAL(12,2) NOT NULL,
+    credit_score INTEGER NOT NULL CHECK (credit_score >= 300 AND credit_score <= 900),
+    employment_status VARCHAR(50) NOT NULL,
+    age INTEGER NOT NULL CHECK (age >= 18 AND age <= 120),
+    existing_emi DECIMAL(12,2) NOT NULL DEFAULT 0 CHECK (existing_emi >= 0),
+    credit_card_outstanding DECIMAL(12,2) NOT NULL DEFAULT 0 CHECK (credit_card_outstanding >= 0),
+    active_loans INTEGER NOT NULL DEFAULT 0 CHECK (active_loans >= 0),
+    requested_amount DECIMAL(15,2) NOT NULL DEFAULT 0 CHECK (requested_amount >= 0),
+    requested_tenure_months INTEGER NOT NULL DEFAULT 0 CHECK (requested_tenure_months >= 0),
+    loan_purpose VARCHAR(50) NOT NULL DEFAULT '',
+    currency VARCHAR(3) NOT NULL DEFAULT 'INR',
+    locale VARCHAR(10) NOT NULL DEFAULT '',
+    batch_id VARCHAR(50),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    is_active BOOLEAN DEFAULT TRUE
+);
+
+-- Columns added since scripts/init_database.sql
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS existing_emi DECIMAL(12,2) NOT NULL DEFAULT 0 CHECK (existing_emi >= 0),
+    ADD COLUMN IF NOT EXISTS credit_card_outstanding DECIMAL(12,2) NOT NULL DEFAULT 0 CHECK (credit_card_outstanding >= 0),
+    ADD COLUMN IF NOT EXISTS active_loans INTEGER NOT NULL DEFAULT 0 CHECK (active_loans >= 0),
+    ADD COLUMN IF NOT EXISTS requested_amount DECIMAL(15,2) NOT NULL DEFAULT 0 CHECK (requested_amount >= 0),
+    ADD COLUMN IF NOT EXISTS requested_tenure_months INTEGER NOT NULL DEFAULT 0 CHECK (requested_tenure_months >= 0),
+    ADD COLUMN IF NOT EXISTS loan_purpose VARCHAR(50) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'INR',
+    ADD COLUMN IF NOT EXISTS locale VARCHAR(10) NOT NULL DEFAULT '';
+
+-- Indexes for users
+CREATE INDEX IF NOT EXISTS idx_users_user_id ON users(user_id);
+CREATE INDEX IF NOT EXISTS idx_users_email ON users(email);
+CREATE INDEX IF NOT EXISTS idx_users_credit_score ON users(credit_score);
+CREATE INDEX IF NOT EXISTS idx_users_monthly_income ON users(monthly_income);
+CREATE INDEX IF NOT EXISTS idx_users_batch_id ON users(batch_id);
+CREATE INDEX IF NOT EXISTS idx_users_employment_status ON users(employment_status);
+CREATE INDEX IF NOT EXISTS idx_users_created_at ON users(created_at);
+
+-- Loan Products Table
+CREATE TABLE IF NOT EXISTS loan_products (
+    id SERIAL PRIMARY KEY,
+    product_name VARCHAR(200) NOT NULL,
+    provider_name VARCHAR(200) NOT NULL,
+    product_type VARCHAR(50) DEFAULT 'personal',
+    interest_rate_min DECIMAL(5,2) NOT NULL CHECK (interest_rate_min >= 0),
+    interest_rate_max DECIMAL(5,2) NOT NULL CHECK (interest_rate_max >= 0),
+    loan_amount_min DECIMAL(15,2) NOT NULL CHECK (loan_amount_min >= 0),
+    loan_amount_max DECIMAL(15,2) NOT NULL CHECK (loan_amount_max >= 0),
+    tenure_min_months INTEGER NOT NULL DEFAULT 12,
+    tenure_max_months INTEGER NOT NULL DEFAULT 60,
+    min_monthly_income DECIMAL(12,2) NOT NULL,
+    min_credit_score INTEGER NOT NULL CHECK (min_credit_score >= 300 AND min_credit_score <= 900),
+    max_credit_score INTEGER,
+    min_age INTEGER DEFAULT 21 CHECK (min_age >= 18),
+    max_age INTEGER DEFAULT 65 CHECK (max_age <= 120),
+    accepted_employment_status TEXT[],
+    processing_fee_percent DECIMAL(5,2),
+    max_foir DECIMAL(4,3) CHECK (max_foir > 0 AND max_foir <= 1),
+    currency VARCHAR(3) NOT NULL DEFAULT 'INR',
+    source_url VARCHAR(500),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    is_active BOOLEAN DEFAULT TRUE,
+    last_crawled_at TIMESTAMP,
+    terms_changed_at TIMESTAMP DEFAULT (now() AT TIME ZONE 'UTC'),
+    rematched_at TIMESTAMP,
+
+    CONSTRAINT valid_loan_amount_range CHECK (loan_amount_max >= loan_amount_min),
+    CONSTRAINT valid_interest_rate_range CHECK (interest_rate_max >= interest_rate_min),
+    CONSTRAINT valid_age_range CHECK (max_age >= min_age),
+    CONSTRAINT valid_tenure_range CHECK (tenure_max_months >= tenure_min_months),
+    CONSTRAINT unique_provider_product UNIQUE (provider_name, product_name)
+);
+
+-- Columns added since scripts/init_database.sql. Existing products start as
+-- changed, so the first re-match run picks them all up.
+ALTER TABLE loan_products
+    ADD COLUMN IF NOT EXISTS max_foir DECIMAL(4,3) CHECK (max_foir > 0 AND max_foir <= 1),
+    ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'INR',
+    ADD COLUMN IF NOT EXISTS terms_changed_at TIMESTAMP DEFAULT (now() AT TIME ZONE 'UTC'),
+    ADD COLUMN IF NOT EXISTS rematched_at TIMESTAMP;
+
+-- Indexes for loan_products
+CREATE INDEX IF NOT EXISTS idx_products_provider ON loan_products(provider_name);
+CREATE INDEX IF NOT EXISTS idx_products_min_income ON loan_products(min_monthly_income);
+CREATE INDEX IF NOT EXISTS idx_products_min_credit ON loan_products(min_credit_score);
+CREATE INDEX IF NOT EXISTS idx_products_is_active ON loan_products(is_active);
+
+-- FX Rates Table (value of one unit of each currency in the base currency,
+-- INR; used to compare users and products in different currencies)
+CREATE TABLE IF NOT EXISTS fx_rates (
+    currency VARCHAR(3) PRIMARY KEY,
+    rate_to_base DECIMAL(20,8) NOT NULL CHECK (rate_to_base > 0),
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Product Eligibility Rules Table
+CREATE TABLE IF NOT EXISTS product_eligibility_rules (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES loan_products(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    expression TEXT NOT NULL,
+    description TEXT,
+    priority INTEGER NOT NULL DEFAULT 0,
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(product_id, name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_rules_product_id ON product_eligibility_rules(product_id);
+
+-- Product Rate Slabs Table (interest rate by credit score and income band;
+-- both bands are inclusive and a NULL max_monthly_income is open-ended)
+CREATE TABLE IF NOT EXISTS product_rate_slabs (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES loan_products(id) ON DELETE CASCADE,
+    min_credit_score INTEGER NOT NULL DEFAULT 300 CHECK (min_credit_score >= 300 AND min_credit_score <= 900),
+    max_credit_score INTEGER NOT NULL DEFAULT 900 CHECK (max_credit_score >= 300 AND max_credit_score <= 900),
+    min_monthly_income DECIMAL(12,2) NOT NULL DEFAULT 0 CHECK (min_monthly_income >= 0),
+    max_monthly_income DECIMAL(12,2),
+    interest_rate DECIMAL(5,2) NOT NULL CHECK (interest_rate > 0 AND interest_rate <= 100),
+    processing_fee_percent DECIMAL(5,2) CHECK (processing_fee_percent >= 0 AND processing_fee_percent <= 100),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT valid_slab_credit_range CHECK (max_credit_score >= min_credit_score),
+    CONSTRAINT valid_slab_income_range CHECK (max_monthly_income IS NULL OR max_monthly_income >= min_monthly_income)
+);
+
+CREATE INDEX IF NOT EXISTS idx_rate_slabs_product_id ON product_rate_slabs(product_id);
+
+-- Matches Table
+CREATE TABLE IF NOT EXISTS matches (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    product_id INTEGER REFERENCES loan_products(id) ON DELETE CASCADE,
+    match_score DECIMAL(5,2) DEFAULT 0,
+    status VARCHAR(50) DEFAULT 'pending',
+    match_source VARCHAR(50) DEFAULT 'sql_filter',
+    income_eligible BOOLEAN DEFAULT FALSE,
+    credit_score_eligible BOOLEAN DEFAULT FALSE,
+    age_eligible BOOLEAN DEFAULT FALSE,
+    employment_eligible BOOLEAN DEFAULT FALSE,
+    llm_analysis TEXT,
+    llm_confidence DECIMAL(3,2),
+    rule_results JSONB,
+    emi_min DECIMAL(12,2),
+    emi_max DECIMAL(12,2),
+    foir DECIMAL(6,4),
+    max_eligible_amount DECIMAL(15,2),
+    estimated_rate DECIMAL(5,2),
+    processing_fee_percent DECIMAL(5,2),
+    scoring_version VARCHAR(50),
+    batch_id VARCHAR(50),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    notified_at TIMESTAMP,
+    UNIQUE(user_id, product_id)
+);
+
+-- Columns added since scripts/init_database.sql
+ALTER TABLE matches
+    ADD COLUMN IF NOT EXISTS rule_results JSONB,
+    ADD COLUMN IF NOT EXISTS emi_min DECIMAL(12,2),
+    ADD COLUMN IF NOT EXISTS emi_max DECIMAL(12,2),
+    ADD COLUMN IF NOT EXISTS foir DECIMAL(6,4),
+    ADD COLUMN IF NOT EXISTS max_eligible_amount DECIMAL(15,2),
+    ADD COLUMN IF NOT EXISTS estimated_rate DECIMAL(5,2),
+    ADD COLUMN IF NOT EXISTS processing_fee_percent DECIMAL(5,2),
+    ADD COLUMN IF NOT EXISTS scoring_version VARCHAR(50);
+
+-- Indexes for matches
+CREATE INDEX IF NOT EXISTS idx_matches_user_id ON matches(user_id);
+CREATE INDEX IF NOT EXISTS idx_matches_product_id ON matches(product_id);
+CREATE INDEX IF NOT EXISTS idx_matches_status ON matches(status);
+CREATE INDEX IF NOT EXISTS idx_matches_batch_id ON matches(batch_id);
+CREATE INDEX IF NOT EXISTS idx_matches_score ON matches(match_score DESC);
+CREATE INDEX IF NOT EXISTS idx_matches_scoring_version ON matches(scoring_version);
+
+-- Match Rejections Table (why a user-product pair did not match)
+CREATE TABLE IF NOT EXISTS match_rejections (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL REFERENCES loan_products(id) ON DELETE CASCADE,
+    stage VARCHAR(50) NOT NULL,
+    reasons JSONB NOT NULL DEFAULT '[]',
+    batch_id VARCHAR(50),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(user_id, product_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_rejections_user_id ON match_rejections(user_id);
+CREATE INDEX IF NOT EXISTS idx_rejections_stage ON match_rejections(stage);
+
+-- Match Reviews Table (reviewer decisions on low-confidence or failed LLM verdicts)
+CREATE TABLE IF NOT EXISTS match_reviews (
+    id SERIAL PRIMARY KEY,
+    match_id INTEGER NOT NULL REFERENCES matches(id) ON DELETE CASCADE,
+    decision VARCHAR(20) NOT NULL,
+    reviewer VARCHAR(255) NOT NULL,
+    comment TEXT NOT NULL,
+    previous_status VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_reviews_match_id ON match_reviews(match_id);
+
+-- LLM Verdict Cache (keyed by a hash of prompt version, provider, user profile and product terms)
+CREATE TABLE IF NOT EXISTS llm_verdict_cache (
+    cache_key VARCHAR(64) PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES loan_products(id) ON DELETE CASCADE,
+    provider VARCHAR(100) NOT NULL,
+    prompt_version INTEGER NOT NULL,
+    qualified BOOLEAN NOT NULL,
+    confidence DECIMAL(5,4),
+    reasoning TEXT,
+    risk_factors JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_verdict_cache_product_id ON llm_verdict_cache(product_id);
+CREATE INDEX IF NOT EXISTS idx_verdict_cache_expires_at ON llm_verdict_cache(expires_at);
+
+-- Notifications Table
+CREATE TABLE IF NOT EXISTS notifications (
+    id SERIAL PRIMARY KEY,
+    match_id INTEGER REFERENCES matches(id) ON DELETE SET NULL,
+    user_db_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    sent_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    status VARCHAR(50) DEFAULT 'pending',
+    message_id VARCHAR(255),
+    error_message TEXT
+);
+
+-- Indexes for notifications
+CREATE INDEX IF NOT EXISTS idx_notifications_user ON notifications(user_db_id);
+CREATE INDEX IF NOT EXISTS idx_notifications_status ON notifications(status);
+
+-- Upload Batches Table
+CREATE TABLE IF NOT EXISTS upload_batches (
+    id SERIAL PRIMARY KEY,
+    batch_id VARCHAR(50) UNIQUE NOT NULL,
+    s3_key VARCHAR(512) NOT NULL,
+    file_name VARCHAR(255),
+    total_rows INTEGER NOT NULL DEFAULT 0,
+    successful_rows INTEGER NOT NULL DEFAULT 0,
+    failed_rows INTEGER NOT NULL DEFAULT 0,
+    status VARCHAR(50) NOT NULL DEFAULT 'processing',
+    error_details TEXT,
+    started_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_batches_batch_id ON upload_batches(batch_id);
+CREATE INDEX IF NOT EXISTS idx_batches_status ON upload_batches(status);
+
+-- Crawler Runs Table
+CREATE TABLE IF NOT EXISTS crawler_runs (
+    id SERIAL PRIMARY KEY,
+    source_name VARCHAR(255),
+    source_url TEXT,
+    status VARCHAR(50) NOT NULL DEFAULT 'running',
+    products_found INTEGER DEFAULT 0,
+    products_added INTEGER DEFAULT 0,
+    products_updated INTEGER DEFAULT 0,
+    products_failed INTEGER DEFAULT 0,
+    sources_crawled INTEGER DEFAULT 0,
+    error_message TEXT,
+    started_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_crawler_source ON crawler_runs(source_name);
+CREATE INDEX IF NOT EXISTS idx_crawler_status ON crawler_runs(status);
+
+-- Rematch Runs Table (product-triggered re-matching)
+CREATE TABLE IF NOT EXISTS rematch_runs (
+    id SERIAL PRIMARY KEY,
+    trigger_source VARCHAR(50) NOT NULL,
+    product_ids INTEGER[] NOT NULL DEFAULT '{}',
+    status VARCHAR(50) NOT NULL DEFAULT 'running',
+    matches_added INTEGER DEFAULT 0,
+    matches_removed INTEGER DEFAULT 0,
+    matches_rescored INTEGER DEFAULT 0,
+    error_message TEXT,
+    started_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP
+);
+
+-- Rematch Changes Table (matches added, removed or rescored by a run)
+CREATE TABLE IF NOT EXISTS rematch_changes (
+    id SERIAL PRIMARY KEY,
+    run_id INTEGER NOT NULL REFERENCES rematch_runs(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL REFERENCES loan_products(id) ON DELETE CASCADE,
+    change_type VARCHAR(20) NOT NULL,
+    old_score DECIMAL(5,2),
+    new_score DECIMAL(5,2),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_rematch_changes_run_id ON rematch_changes(run_id);
+CREATE INDEX IF NOT EXISTS idx_rematch_changes_product_id ON rematch_changes(product_id);
+
+-- Scoring Models Table (every scoring model version that produced a match score)
+CREATE TABLE IF NOT EXISTS scoring_models (
+    version VARCHAR(50) PRIMARY KEY,
+    definition JSONB NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Match Checkpoints Table (resumable chunked matching per upload batch)
+CREATE TABLE IF NOT EXISTS match_checkpoints (
+    batch_id VARCHAR(50) PRIMARY KEY,
+    status VARCHAR(50) NOT NULL DEFAULT 'running',
+    last_user_id INTEGER NOT NULL DEFAULT 0,
+    users_processed INTEGER NOT NULL DEFAULT 0,
+    total_users INTEGER NOT NULL DEFAULT 0,
+    matches_saved INTEGER NOT NULL DEFAULT 0,
+    llm_calls INTEGER NOT NULL DEFAULT 0,
+    started_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP
+);
+
+-- Notification Logs Table (for n8n workflow tracking)
+CREATE TABLE IF NOT EXISTS notification_logs (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    email VARCHAR(255) NOT NULL,
+    notification_type VARCHAR(50) NOT NULL DEFAULT 'loan_match',
+    status VARCHAR(50) NOT NULL DEFAULT 'pending',
+    subject VARCHAR(500),
+    message_id VARCHAR(255),
+    error_message TEXT,
+    match_count INTEGER DEFAULT 0,
+    sent_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_logs_user ON notification_logs(user_id);
+CREATE INDEX IF NOT EXISTS idx_notification_logs_status ON notification_logs(status);
+CREATE INDEX IF NOT EXISTS idx_notification_logs_email ON notification_logs(email);
+
+-- Jobs Table (durable background work: CSV ingest, matching, notifications, re-matching)
+CREATE TABLE IF NOT EXISTS jobs (
+    id SERIAL PRIMARY KEY,
+    job_type VARCHAR(50) NOT NULL,
+    status VARCHAR(50) NOT NULL DEFAULT 'queued',
+    payload JSONB NOT NULL DEFAULT '{}',
+    data BYTEA,
+    result JSONB,
+    progress_done INTEGER NOT NULL DEFAULT 0,
+    progress_total INTEGER NOT NULL DEFAULT 0,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 3,
+    last_error TEXT,
+    cancel_requested BOOLEAN NOT NULL DEFAULT false,
+    locked_by VARCHAR(255),
+    locked_until TIMESTAMP,
+    run_after TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMP,
+    finished_at TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_jobs_runnable ON jobs(status, run_after) WHERE status IN ('queued', 'running');
+CREATE INDEX IF NOT EXISTS idx_jobs_type ON jobs(job_type);
+
+-- Function to update updated_at timestamp
+CREATE OR REPLACE FUNCTION update_updated_at_column()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at = CURRENT_TIMESTAMP;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+-- Triggers for updated_at
+DROP TRIGGER IF EXISTS update_users_updated_at ON users;
+CREATE TRIGGER update_users_updated_at
+    BEFORE UPDATE ON users
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+DROP TRIGGER IF EXISTS update_loan_products_updated_at ON loan_products;
+CREATE TRIGGER update_loan_products_updated_at
+    BEFORE UPDATE ON loan_products
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+DROP TRIGGER IF EXISTS update_fx_rates_updated_at ON fx_rates;
+CREATE TRIGGER update_fx_rates_updated_at
+    BEFORE UPDATE ON fx_rates
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+DROP TRIGGER IF EXISTS update_rules_updated_at ON product_eligibility_rules;
+CREATE TRIGGER update_rules_updated_at
+    BEFORE UPDATE ON product_eligibility_rules
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+DROP TRIGGER IF EXISTS update_rate_slabs_updated_at ON product_rate_slabs;
+CREATE TRIGGER update_rate_slabs_updated_at
+    BEFORE UPDATE ON product_rate_slabs
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+DROP TRIGGER IF EXISTS update_matches_updated_at ON matches;
+CREATE TRIGGER update_matches_updated_at
+    BEFORE UPDATE ON matches
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Record when a product's matching-relevant terms or active flag change,
+-- so that incremental re-matching can pick it up (UTC, like the application)
+CREATE OR REPLACE FUNCTION track_product_terms_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF (OLD.product_type, OLD.interest_rate_min, OLD.interest_rate_max,
+        OLD.loan_amount_min, OLD.loan_amount_max, OLD.tenure_min_months, OLD.tenure_max_months,
+        OLD.min_monthly_income, OLD.min_credit_score, OLD.max_credit_score,
+        OLD.min_age, OLD.max_age, OLD.accepted_employment_status, OLD.processing_fee_percent,
+        OLD.max_foir, OLD.currency, OLD.is_active)
+       IS DISTINCT FROM
+       (NEW.product_type, NEW.interest_rate_min, NEW.interest_rate_max,
+        NEW.loan_amount_min, NEW.loan_amount_max, NEW.tenure_min_months, NEW.tenure_max_months,
+        NEW.min_monthly_income, NEW.min_credit_score, NEW.max_credit_score,
+        NEW.min_age, NEW.max_age, NEW.accepted_employment_status, NEW.processing_fee_percent,
+        NEW.max_foir, NEW.currency, NEW.is_active)
+    THEN
+        NEW.terms_changed_at = now() AT TIME ZONE 'UTC';
+    END IF;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS track_loan_products_terms_change ON loan_products;
+CREATE TRIGGER track_loan_products_terms_change
+    BEFORE UPDATE ON loan_products
+    FOR EACH ROW
+    EXECUTE FUNCTION track_product_terms_change();
+
+-- Drop cached LLM verdicts when a product's terms change. The columns compared
+-- are those the LLM prompt shows, as in llm.CacheKey.
+CREATE OR REPLACE FUNCTION invalidate_llm_verdicts()
+RETURNS TRIGGER AS $$
+BEGIN
+    DELETE FROM llm_verdict_cache WHERE product_id = NEW.id;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS invalidate_llm_verdicts_on_product_change ON loan_products;
+CREATE TRIGGER invalidate_llm_verdicts_on_product_change
+    AFTER UPDATE ON loan_products
+    FOR EACH ROW
+    WHEN ((OLD.product_name, OLD.provider_name, OLD.product_type, OLD.interest_rate_min, OLD.interest_rate_max,
+           OLD.loan_amount_min, OLD.loan_amount_max, OLD.tenure_min_months, OLD.tenure_max_months,
+           OLD.processing_fee_percent, OLD.min_monthly_income, OLD.min_credit_score,
+           OLD.min_age, OLD.max_age, OLD.accepted_employment_status, OLD.currency)
+          IS DISTINCT FROM
+          (NEW.product_name, NEW.provider_name, NEW.product_type, NEW.interest_rate_min, NEW.interest_rate_max,
+           NEW.loan_amount_min, NEW.loan_amount_max, NEW.tenure_min_months, NEW.tenure_max_months,
+           NEW.processing_fee_percent, NEW.min_monthly_income, NEW.min_credit_score,
+           NEW.min_age, NEW.max_age, NEW.accepted_employment_status, NEW.currency))
+    EXECUTE FUNCTION invalidate_llm_verdicts();
+
+-- Starting exchange rates; update them through PUT /api/fx-rates
+INSERT INTO fx_rates (currency, rate_to_base) VALUES
+    ('INR', 1),
+    ('USD', 83.00),
+    ('EUR', 90.00),
+    ('GBP', 105.00),
+    ('AED', 22.60),
+    ('SGD', 62.00)
+ON CONFLICT (currency) DO NOTHING;
+
+-- Table comments
+COMMENT ON TABLE users IS 'User profiles with financial information for loan eligibility';
+COMMENT ON TABLE loan_products IS 'Loan products from various banks and financial institutions';
+COMMENT ON TABLE fx_rates IS 'Exchange rates to the base currency for cross-currency matching';
+COMMENT ON TABLE product_eligibility_rules IS 'Declarative per-product eligibility rules evaluated by the logic filter';
+COMMENT ON TABLE product_rate_slabs IS 'Per-product interest rate and processing fee by credit score and income band';
+COMMENT ON TABLE matches IS 'User-to-loan product matching results with eligibility scores';
+COMMENT ON TABLE match_rejections IS 'Structured reasons for user-product pairs that did not match';
+COMMENT ON TABLE match_reviews IS 'Audit trail of reviewer decisions on matches held for human review';
+COMMENT ON TABLE llm_verdict_cache IS 'Cached LLM verdicts, invalidated when product terms change';
+COMMENT ON TABLE notifications IS 'Email notification delivery tracking';
+COMMENT ON TABLE upload_batches IS 'Tracking table for CSV upload processing';
+COMMENT ON TABLE crawler_runs IS 'Execution history of the loan product web crawler';
+COMMENT ON TABLE rematch_runs IS 'Product-triggered incremental re-matching runs';
+COMMENT ON TABLE rematch_changes IS 'Matches added, removed or rescored by each re-matching run';
+COMMENT ON TABLE scoring_models IS 'Weights and curves of each scoring model version, referenced by matches.scoring_version';
+COMMENT ON TABLE match_checkpoints IS 'Progress of chunked batch matching, used to resume after a crash';
+COMMENT ON TABLE jobs IS 'Background job queue polled by server workers with FOR UPDATE SKIP LOCKED';
@@ -0,0 +1,14 @@
+DROP TRIGGER IF EXISTS update_upload_batches_updated_at ON upload_batches;
+DROP INDEX IF EXISTS idx_batches_created_at;
+
+ALTER TABLE upload_batches
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS row_errors,
+    DROP COLUMN IF EXISTS valid_rows,
+    DROP COLUMN IF EXISTS source;
+
+UPDATE upload_batches SET s3_key = '' WHERE s3_key IS NULL;
+ALTER TABLE upload_batches ALTER COLUMN s3_key SET NOT NULL;
+ALTER TABLE upload_batches ALTER COLUMN status SET DEFAULT 'processing';
+
+COMMENT ON TABLE upload_batches IS 'Tracking table for CSV upload processing';
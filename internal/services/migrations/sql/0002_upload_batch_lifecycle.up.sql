@@ -0,0 +1,22 @@
+-- Upload batch lifecycle: received -> parsing -> inserting -> matching ->
+-- completed or failed, recorded by the server's ingest jobs and the CSV
+-- processor Lambda.
+
+ALTER TABLE upload_batches ALTER COLUMN s3_key DROP NOT NULL;
+ALTER TABLE upload_batches ALTER COLUMN status SET DEFAULT 'received';
+
+ALTER TABLE upload_batches
+    ADD COLUMN IF NOT EXISTS source VARCHAR(20) NOT NULL DEFAULT 'upload',
+    ADD COLUMN IF NOT EXISTS valid_rows INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS row_errors JSONB NOT NULL DEFAULT '[]',
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_batches_created_at ON upload_batches(created_at DESC);
+
+DROP TRIGGER IF EXISTS update_upload_batches_updated_at ON upload_batches;
+CREATE TRIGGER update_upload_batches_updated_at
+    BEFORE UPDATE ON upload_batches
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE upload_batches IS 'Lifecycle, row counts and row errors of each CSV upload';
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_crawler_started_at;
+
+ALTER TABLE crawler_runs
+    DROP COLUMN IF EXISTS details,
+    DROP COLUMN IF EXISTS sources_failed;
+
+DROP TABLE IF EXISTS crawler_sources;
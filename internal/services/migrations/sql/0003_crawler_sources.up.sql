@@ -0,0 +1,37 @@
+-- Crawler source registry: the pages the native crawler reads loan products
+-- from and the rules for extracting them. Crawler runs record the outcome of
+-- each source they crawl.
+
+CREATE TABLE IF NOT EXISTS crawler_sources (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    url TEXT NOT NULL,
+    provider_name VARCHAR(200) NOT NULL,
+    product_type VARCHAR(50) NOT NULL DEFAULT 'personal',
+    currency VARCHAR(3) NOT NULL DEFAULT 'INR',
+    rules JSONB NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    last_crawled_at TIMESTAMP,
+    last_status VARCHAR(50),
+    last_error TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_crawler_sources_active ON crawler_sources(is_active);
+
+DROP TRIGGER IF EXISTS update_crawler_sources_updated_at ON crawler_sources;
+CREATE TRIGGER update_crawler_sources_updated_at
+    BEFORE UPDATE ON crawler_sources
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+ALTER TABLE crawler_runs
+    ADD COLUMN IF NOT EXISTS sources_failed INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS details JSONB NOT NULL DEFAULT '[]';
+
+CREATE INDEX IF NOT EXISTS idx_crawler_started_at ON crawler_runs(started_at DESC);
+
+COMMENT ON TABLE crawler_sources IS 'Pages the loan product crawler reads and their extraction rules';
+COMMENT ON COLUMN crawler_sources.rules IS 'Selectors and regexes mapping page content to loan product fields';
+COMMENT ON COLUMN crawler_runs.details IS 'Per-source products found, added, updated and failed, with errors';
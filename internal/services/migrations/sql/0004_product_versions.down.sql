@@ -0,0 +1,21 @@
+DROP TRIGGER IF EXISTS set_matches_product_version ON matches;
+DROP FUNCTION IF EXISTS set_match_product_version();
+
+DROP INDEX IF EXISTS idx_matches_product_version;
+
+ALTER TABLE matches
+    DROP CONSTRAINT IF EXISTS matches_product_version_fkey,
+    DROP COLUMN IF EXISTS product_version;
+
+DROP TRIGGER IF EXISTS record_loan_products_version ON loan_products;
+DROP FUNCTION IF EXISTS record_product_version();
+
+DROP TRIGGER IF EXISTS bump_loan_products_version ON loan_products;
+DROP FUNCTION IF EXISTS bump_product_version();
+
+DROP FUNCTION IF EXISTS product_version_terms(loan_products);
+
+DROP TABLE IF EXISTS loan_product_versions;
+
+ALTER TABLE loan_products
+    DROP COLUMN IF EXISTS version;
@@ -0,0 +1,143 @@
+-- Loan product version history: every insert of a loan product, and every
+-- update that changes its terms, records the product as it then stood in
+-- loan_product_versions, with the fields that changed and where the change
+-- came from. Matches record the product version they were computed against.
+
+ALTER TABLE loan_products
+    ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+
+CREATE TABLE IF NOT EXISTS loan_product_versions (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES loan_products(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    source VARCHAR(20) NOT NULL CHECK (source IN ('crawler', 'admin', 'import')),
+    changed_fields TEXT[] NOT NULL DEFAULT '{}',
+    provider_name VARCHAR(200) NOT NULL,
+    interest_rate_min DECIMAL(5, 2) NOT NULL,
+    interest_rate_max DECIMAL(5, 2) NOT NULL,
+    processing_fee_percent DECIMAL(5, 2),
+    is_active BOOLEAN NOT NULL,
+    terms JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT (now() AT TIME ZONE 'UTC'),
+    UNIQUE (product_id, version)
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_versions_provider ON loan_product_versions(provider_name, created_at);
+CREATE INDEX IF NOT EXISTS idx_product_versions_created_at ON loan_product_versions(created_at);
+
+-- The versioned fields of a product: everything but its ID, version and
+-- bookkeeping timestamps
+CREATE OR REPLACE FUNCTION product_version_terms(p loan_products)
+RETURNS JSONB AS $$
+    SELECT to_jsonb(p) - ARRAY['id', 'version', 'created_at', 'updated_at', 'last_crawled_at',
+                               'terms_changed_at', 'rematched_at'];
+$$ LANGUAGE sql STABLE;
+
+-- Bump the version when any versioned field changes. The version cannot be
+-- set directly.
+CREATE OR REPLACE FUNCTION bump_product_version()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF product_version_terms(OLD) IS DISTINCT FROM product_version_terms(NEW) THEN
+        NEW.version = OLD.version + 1;
+    ELSE
+        NEW.version = OLD.version;
+    END IF;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS bump_loan_products_version ON loan_products;
+CREATE TRIGGER bump_loan_products_version
+    BEFORE UPDATE ON loan_products
+    FOR EACH ROW
+    EXECUTE FUNCTION bump_product_version();
+
+-- Record a new product version. The source is taken from the
+-- loan.product_change_source setting, which the crawler and the sample data
+-- set; changes made without it are taken to be admin edits.
+CREATE OR REPLACE FUNCTION record_product_version()
+RETURNS TRIGGER AS $$
+DECLARE
+    new_terms JSONB := product_version_terms(NEW);
+    changed TEXT[] := '{}';
+BEGIN
+    IF TG_OP = 'UPDATE' THEN
+        IF NEW.version = OLD.version THEN
+            RETURN NULL;
+        END IF;
+
+        SELECT COALESCE(array_agg(n.key ORDER BY n.key), '{}') INTO changed
+        FROM jsonb_each(new_terms) n
+        WHERE n.value IS DISTINCT FROM product_version_terms(OLD) -> n.key;
+    END IF;
+
+    INSERT INTO loan_product_versions (
+        product_id, version, source, changed_fields, provider_name,
+        interest_rate_min, interest_rate_max, processing_fee_percent, is_active, terms
+    ) VALUES (
+        NEW.id, NEW.version,
+        COALESCE(NULLIF(current_setting('loan.product_change_source', true), ''), 'admin'),
+        changed, NEW.provider_name,
+        NEW.interest_rate_min, NEW.interest_rate_max, NEW.processing_fee_percent, NEW.is_active, new_terms
+    );
+    RETURN NULL;
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS record_loan_products_version ON loan_products;
+CREATE TRIGGER record_loan_products_version
+    AFTER INSERT OR UPDATE ON loan_products
+    FOR EACH ROW
+    EXECUTE FUNCTION record_product_version();
+
+-- Existing products start their history at version 1, as imported
+INSERT INTO loan_product_versions (
+    product_id, version, source, provider_name,
+    interest_rate_min, interest_rate_max, processing_fee_percent, is_active, terms, created_at
+)
+SELECT p.id, p.version, 'import', p.provider_name,
+       p.interest_rate_min, p.interest_rate_max, p.processing_fee_percent, p.is_active,
+       product_version_terms(p), COALESCE(p.terms_changed_at, p.created_at, now() AT TIME ZONE 'UTC')
+FROM loan_products p
+ON CONFLICT (product_id, version) DO NOTHING;
+
+-- Matches computed before versions were tracked keep a NULL product version
+ALTER TABLE matches
+    ADD COLUMN IF NOT EXISTS product_version INTEGER;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'matches_product_version_fkey') THEN
+        ALTER TABLE matches
+            ADD CONSTRAINT matches_product_version_fkey
+            FOREIGN KEY (product_id, product_version)
+            REFERENCES loan_product_versions(product_id, version) ON DELETE CASCADE;
+    END IF;
+END;
+$$;
+
+CREATE INDEX IF NOT EXISTS idx_matches_product_version ON matches(product_id, product_version);
+
+-- Matches saved without a product version were computed against the current
+-- one
+CREATE OR REPLACE FUNCTION set_match_product_version()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.product_version IS NULL THEN
+        SELECT version INTO NEW.product_version FROM loan_products WHERE id = NEW.product_id;
+    END IF;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS set_matches_product_version ON matches;
+CREATE TRIGGER set_matches_product_version
+    BEFORE INSERT ON matches
+    FOR EACH ROW
+    EXECUTE FUNCTION set_match_product_version();
+
+COMMENT ON TABLE loan_product_versions IS 'Each version of a loan product''s terms, with the fields that changed and the source of the change';
+COMMENT ON COLUMN loan_product_versions.changed_fields IS 'Fields that differ from the previous version; empty for version 1';
+COMMENT ON COLUMN loan_product_versions.terms IS 'The product''s versioned fields as of this version';
+COMMENT ON COLUMN matches.product_version IS 'Version of the loan product the match was computed against';
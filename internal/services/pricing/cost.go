@@ -0,0 +1,122 @@
+package pricing
+
+import (
+	"math"
+	"sort"
+
+	"loan-eligibility-engine/internal/models"
+	"loan-eligibility-engine/internal/services/affordability"
+)
+
+// aprIterations bounds the bisection that solves for the APR; 100 halvings
+// of the search range are well past float64 precision
+const aprIterations = 100
+
+// Cost computes what borrowing amount over months at an annual rate (in
+// percent) costs, with a processing fee charged as a percentage of the
+// amount. The APR is the annual rate at which the EMIs repay the amount net
+// of the fee, so it equals the interest rate when there is no fee.
+func Cost(amount, annualRate float64, months int, feePercent *float64) models.BorrowingCost {
+	emi := affordability.EMI(amount, annualRate, months)
+
+	var fees float64
+	if feePercent != nil {
+		fees = amount * *feePercent / 100
+	}
+	interest := emi*float64(months) - amount
+
+	return models.BorrowingCost{
+		Amount:        amount,
+		TenureMonths:  months,
+		InterestRate:  annualRate,
+		EMI:           round2(emi),
+		TotalInterest: round2(interest),
+		TotalFees:     round2(fees),
+		TotalCost:     round2(interest + fees),
+		APR:           round2(apr(amount-fees, emi, months, annualRate)),
+	}
+}
+
+// Price prices a match for amount over months, at the match's estimated rate
+// and fee or, for matches stored without an estimate, the product's maximum
+// rate and its fee. The amount and the offer are in the product's currency.
+// It returns false when the product does not lend that amount or tenure; a
+// zero maximum amount means no upper limit.
+func Price(match *models.Match, product *models.LoanProduct, amount float64, months int) (*models.Offer, bool) {
+	if amount < product.LoanAmountMin || (product.LoanAmountMax > 0 && amount > product.LoanAmountMax) {
+		return nil, false
+	}
+	minTenure, maxTenure := affordability.Tenures(product)
+	if months < minTenure || months > maxTenure {
+		return nil, false
+	}
+
+	rate, fee := match.EstimatedRate, match.ProcessingFeePercent
+	if rate <= 0 {
+		rate, fee = product.InterestRateMax, product.ProcessingFeePercent
+	}
+	if fee == nil {
+		fee = product.ProcessingFeePercent
+	}
+
+	return &models.Offer{
+		MatchID:         match.ID,
+		ProductID:       product.ID,
+		ProductName:     product.ProductName,
+		ProviderName:    product.ProviderName,
+		ProductType:     product.ProductType,
+		ProductCurrency: product.Currency.OrDefault(),
+		MatchScore:      match.MatchScore,
+		Status:          match.Status,
+		Currency:        product.Currency.OrDefault(),
+		BorrowingCost:   Cost(amount, rate, months, fee),
+	}, true
+}
+
+// Rank orders offers in place. By cost, the lowest total cost comes first,
+// then the lowest APR and the highest score; by score, the highest score
+// comes first and the lowest total cost breaks ties.
+func Rank(offers []*models.Offer, by models.OfferRanking) {
+	sort.SliceStable(offers, func(i, j int) bool {
+		a, b := offers[i], offers[j]
+		if by == models.OfferRankingCost {
+			if a.TotalCost != b.TotalCost {
+				return a.TotalCost < b.TotalCost
+			}
+			if a.APR != b.APR {
+				return a.APR < b.APR
+			}
+			return a.MatchScore > b.MatchScore
+		}
+		if a.MatchScore != b.MatchScore {
+			return a.MatchScore > b.MatchScore
+		}
+		return a.TotalCost < b.TotalCost
+	})
+}
+
+// apr solves for the annual rate (in percent) at which months payments of
+// emi repay disbursed, by bisection on the monthly rate. The payments never
+// fall short of the amount they were computed on, so disbursed below it
+// always has a non-negative rate.
+func apr(disbursed, emi float64, months int, annualRate float64) float64 {
+	if months <= 0 || disbursed <= 0 || emi <= 0 {
+		return annualRate
+	}
+
+	lo, hi := 0.0, 1.0
+	for i := 0; i < aprIterations; i++ {
+		mid := (lo + hi) / 2
+		if affordability.Principal(emi, mid*1200, months) > disbursed {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2 * 1200
+}
+
+// round2 rounds to two decimal places
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
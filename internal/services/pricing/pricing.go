@@ -0,0 +1,58 @@
+// Package pricing estimates the interest rate and processing fee a user can
+// expect on a loan product, from the product's credit and income rate slabs.
+package pricing
+
+import (
+	"math"
+
+	"loan-eligibility-engine/internal/models"
+)
+
+// CreditScoreCeiling is the credit score that earns a product's minimum rate
+// when the product has no rate slab for the user
+const CreditScoreCeiling = 900
+
+// Estimate returns the rate a user can expect on a product. Of the slabs
+// covering the user's credit score and income, the one with the lowest rate
+// applies, with its processing fee or else the product's.
+//
+// Without a covering slab the rate is interpolated by credit score, from the
+// product's maximum rate at its minimum credit score to its minimum rate at
+// CreditScoreCeiling, and the product's processing fee applies.
+func Estimate(user *models.User, product *models.LoanProduct, slabs []*models.RateSlab) models.RateEstimate {
+	var best *models.RateSlab
+	for _, slab := range slabs {
+		if slab.ProductID != product.ID || !slab.Covers(user.CreditScore, user.MonthlyIncome) {
+			continue
+		}
+		if best == nil || slab.InterestRate < best.InterestRate {
+			best = slab
+		}
+	}
+
+	if best != nil {
+		fee := best.ProcessingFeePercent
+		if fee == nil {
+			fee = product.ProcessingFeePercent
+		}
+		return models.RateEstimate{EstimatedRate: best.InterestRate, ProcessingFeePercent: fee}
+	}
+
+	return models.RateEstimate{
+		EstimatedRate:        interpolate(user.CreditScore, product),
+		ProcessingFeePercent: product.ProcessingFeePercent,
+	}
+}
+
+// interpolate places the rate within the product's range by how far the
+// credit score sits between the product minimum and CreditScoreCeiling
+func interpolate(creditScore int, product *models.LoanProduct) float64 {
+	creditRange := float64(CreditScoreCeiling - product.MinCreditScore)
+	if creditRange <= 0 {
+		return product.InterestRateMin
+	}
+
+	headroom := math.Max(0, math.Min(1, float64(creditScore-product.MinCreditScore)/creditRange))
+	rate := product.InterestRateMax - headroom*(product.InterestRateMax-product.InterestRateMin)
+	return round2(rate)
+}
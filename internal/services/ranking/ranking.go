@@ -0,0 +1,159 @@
+// Package ranking turns a user's matches, listed best first, into the offers
+// shown to the user. Near-identical products of one provider are collapsed
+// into the best of them, each provider is capped to a number of offers and
+// product types are interleaved, so that one lender cannot fill the list.
+package ranking
+
+import (
+	"strings"
+
+	"loan-eligibility-engine/internal/models"
+)
+
+// Options configure Diversify. The zero value keeps every entry in order.
+type Options struct {
+	// MaxPerProvider caps the offers of one provider; zero is no cap
+	MaxPerProvider int
+	// DiversifyTypes interleaves product types, best of each type first
+	DiversifyTypes bool
+	// CollapseDuplicates folds near-duplicate products into the best one
+	CollapseDuplicates bool
+	// Limit caps the number of offers; zero is no limit
+	Limit int
+}
+
+// Entry describes the product behind a ranked match
+type Entry struct {
+	Provider    string
+	ProductType models.LoanProductType
+	RateMin     float64
+	RateMax     float64
+	AmountMin   float64
+	AmountMax   float64
+}
+
+// EntryFor describes a loan product
+func EntryFor(product *models.LoanProduct) Entry {
+	return Entry{
+		Provider:    product.ProviderName,
+		ProductType: product.ProductType,
+		RateMin:     product.InterestRateMin,
+		RateMax:     product.InterestRateMax,
+		AmountMin:   product.LoanAmountMin,
+		AmountMax:   product.LoanAmountMax,
+	}
+}
+
+// IsDuplicate reports whether two products are near-identical: the same type
+// from the same provider, with overlapping interest rate and amount ranges
+func IsDuplicate(a, b Entry) bool {
+	if !sameProvider(a.Provider, b.Provider) || a.ProductType != b.ProductType {
+		return false
+	}
+	return a.RateMin <= b.RateMax && b.RateMin <= a.RateMax &&
+		a.AmountMin <= b.AmountMax && b.AmountMin <= a.AmountMax
+}
+
+// Pick is an entry kept in the ranking, by index into the input, with the
+// indexes of the near-duplicates collapsed into it
+type Pick struct {
+	Index      int
+	Duplicates []int
+}
+
+// Result is the diversified ranking. Collapsed counts entries folded into a
+// near-duplicate and Capped those dropped by the provider cap; entries beyond
+// the limit are not counted.
+type Result struct {
+	Picks     []Pick
+	Collapsed int
+	Capped    int
+}
+
+// Diversify ranks entries, given best first. Duplicates are collapsed first,
+// so a provider's cap is spent on distinct products, then the cap applies in
+// rank order, then types are interleaved and the limit applied.
+func Diversify(entries []Entry, opts Options) Result {
+	var result Result
+
+	// Collapse near-duplicates into the best-ranked of them
+	kept := make([]*Pick, 0, len(entries))
+	for i, entry := range entries {
+		var into *Pick
+		if opts.CollapseDuplicates {
+			for _, pick := range kept {
+				if IsDuplicate(entries[pick.Index], entry) {
+					into = pick
+					break
+				}
+			}
+		}
+		if into != nil {
+			into.Duplicates = append(into.Duplicates, i)
+			result.Collapsed++
+			continue
+		}
+		kept = append(kept, &Pick{Index: i})
+	}
+
+	// Cap each provider
+	perProvider := make(map[string]int)
+	capped := kept[:0]
+	for _, pick := range kept {
+		provider := providerKey(entries[pick.Index].Provider)
+		if opts.MaxPerProvider > 0 && perProvider[provider] >= opts.MaxPerProvider {
+			result.Capped++
+			continue
+		}
+		perProvider[provider]++
+		capped = append(capped, pick)
+	}
+
+	if opts.DiversifyTypes {
+		capped = interleaveTypes(capped, entries)
+	}
+	if opts.Limit > 0 && len(capped) > opts.Limit {
+		capped = capped[:opts.Limit]
+	}
+
+	result.Picks = make([]Pick, len(capped))
+	for i, pick := range capped {
+		result.Picks[i] = *pick
+	}
+	return result
+}
+
+// interleaveTypes takes the best remaining pick of each product type in
+// turn, types ordered by their best pick, keeping the order within a type
+func interleaveTypes(picks []*Pick, entries []Entry) []*Pick {
+	var types []models.LoanProductType
+	byType := make(map[models.LoanProductType][]*Pick)
+	for _, pick := range picks {
+		productType := entries[pick.Index].ProductType
+		if _, ok := byType[productType]; !ok {
+			types = append(types, productType)
+		}
+		byType[productType] = append(byType[productType], pick)
+	}
+
+	interleaved := make([]*Pick, 0, len(picks))
+	for len(interleaved) < len(picks) {
+		for _, productType := range types {
+			if queue := byType[productType]; len(queue) > 0 {
+				interleaved = append(interleaved, queue[0])
+				byType[productType] = queue[1:]
+			}
+		}
+	}
+	return interleaved
+}
+
+// sameProvider compares provider names ignoring case and outer spaces
+func sameProvider(a, b string) bool {
+	return providerKey(a) == providerKey(b)
+}
+
+// providerKey is the provider name compared by the cap and duplicate check
+func providerKey(provider string) string {
+	return strings.ToLower(strings.TrimSpace(provider))
+}
@@ -0,0 +1,611 @@
+// Package rules implements the declarative eligibility rule language
+package rules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// tokenKind identifies the lexical class of a token
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokString
+	tokIdent
+	tokOp
+)
+
+// token is a single lexical element of an expression
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// keywords are identifiers with special meaning in the grammar
+var keywords = map[string]bool{
+	"and":   true,
+	"or":    true,
+	"not":   true,
+	"in":    true,
+	"true":  true,
+	"false": true,
+}
+
+// tokenize splits an expression into tokens
+func tokenize(src string) ([]token, error) {
+	var tokens []token
+	i := 0
+
+	for i < len(src) {
+		ch := src[i]
+
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r':
+			i++
+
+		case isDigit(ch) || (ch == '.' && i+1 < len(src) && isDigit(src[i+1])):
+			start := i
+			for i < len(src) && (isDigit(src[i]) || src[i] == '.' || src[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: strings.ReplaceAll(src[start:i], "_", ""), pos: start})
+
+		case isIdentStart(ch):
+			start := i
+			for i < len(src) && (isIdentStart(src[i]) || isDigit(src[i])) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: src[start:i], pos: start})
+
+		case ch == '"' || ch == '\'':
+			start := i
+			i++
+			var sb strings.Builder
+			for i < len(src) && src[i] != ch {
+				sb.WriteByte(src[i])
+				i++
+			}
+			if i >= len(src) {
+				return nil, fmt.Errorf("unterminated string at position %d", start)
+			}
+			i++ // closing quote
+			tokens = append(tokens, token{kind: tokString, text: sb.String(), pos: start})
+
+		default:
+			start := i
+			two := ""
+			if i+1 < len(src) {
+				two = src[i : i+2]
+			}
+			switch two {
+			case "<=", ">=", "==", "!=", "&&", "||":
+				tokens = append(tokens, token{kind: tokOp, text: two, pos: start})
+				i += 2
+				continue
+			}
+			switch ch {
+			case '+', '-', '*', '/', '(', ')', '[', ']', ',', '<', '>', '!':
+				tokens = append(tokens, token{kind: tokOp, text: string(rune(ch)), pos: start})
+			case '=':
+				tokens = append(tokens, token{kind: tokOp, text: "==", pos: start})
+			default:
+				return nil, fmt.Errorf("unexpected character %q at position %d", rune(ch), start)
+			}
+			i++
+		}
+	}
+
+	tokens = append(tokens, token{kind: tokEOF, pos: len(src)})
+	return tokens, nil
+}
+
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+func isIdentStart(b byte) bool {
+	return b == '_' || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
+
+// node is a parsed expression tree element
+type node interface {
+	eval(env Env) (interface{}, error)
+}
+
+type literalNode struct{ value interface{} }
+
+type identNode struct{ name string }
+
+type listNode struct{ items []node }
+
+type unaryNode struct {
+	op      string
+	operand node
+}
+
+type binaryNode struct {
+	op          string
+	left, right node
+}
+
+type inNode struct {
+	negate bool
+	left   node
+	list   node
+}
+
+// parser is a recursive-descent parser over a token stream
+type parser struct {
+	tokens []token
+	pos    int
+	vars   map[string]bool
+}
+
+// parse builds an expression tree from source text
+func parse(src string) (node, []string, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p := &parser{tokens: tokens, vars: make(map[string]bool)}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, nil, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos)
+	}
+
+	vars := make([]string, 0, len(p.vars))
+	for v := range p.vars {
+		vars = append(vars, v)
+	}
+	return root, vars, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// isOp reports whether the current token is one of the given operators or keywords
+func (p *parser) isOp(ops ...string) bool {
+	tok := p.peek()
+	if tok.kind != tokOp && !(tok.kind == tokIdent && keywords[strings.ToLower(tok.text)]) {
+		return false
+	}
+	for _, op := range ops {
+		if strings.EqualFold(tok.text, op) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) expect(op string) error {
+	if !p.isOp(op) {
+		tok := p.peek()
+		if tok.kind == tokEOF {
+			return fmt.Errorf("expected %q at end of expression", op)
+		}
+		return fmt.Errorf("expected %q at position %d, found %q", op, tok.pos, tok.text)
+	}
+	p.next()
+	return nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("or", "||") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: "or", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("and", "&&") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: "and", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (node, error) {
+	if p.isOp("not", "!") {
+		p.next()
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{op: "not", operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case p.isOp("<", "<=", ">", ">=", "==", "!="):
+		op := p.next().text
+		right, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		return &binaryNode{op: op, left: left, right: right}, nil
+
+	case p.isOp("in"):
+		p.next()
+		list, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		return &inNode{left: left, list: list}, nil
+
+	case p.isOp("not") && p.pos+1 < len(p.tokens) && strings.EqualFold(p.tokens[p.pos+1].text, "in"):
+		p.next()
+		p.next()
+		list, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		return &inNode{negate: true, left: left, list: list}, nil
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseAdditive() (node, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("+", "-") {
+		op := p.next().text
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseMultiplicative() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("*", "/") {
+		op := p.next().text
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.isOp("-") {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{op: "-", operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+
+	switch tok.kind {
+	case tokNumber:
+		v, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", tok.text, tok.pos)
+		}
+		return &literalNode{value: v}, nil
+
+	case tokString:
+		return &literalNode{value: tok.text}, nil
+
+	case tokIdent:
+		switch strings.ToLower(tok.text) {
+		case "true":
+			return &literalNode{value: true}, nil
+		case "false":
+			return &literalNode{value: false}, nil
+		}
+		if keywords[strings.ToLower(tok.text)] {
+			return nil, fmt.Errorf("unexpected keyword %q at position %d", tok.text, tok.pos)
+		}
+		p.vars[tok.text] = true
+		return &identNode{name: tok.text}, nil
+
+	case tokOp:
+		switch tok.text {
+		case "(":
+			inner, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return inner, nil
+		case "[":
+			return p.parseList()
+		}
+	}
+
+	if tok.kind == tokEOF {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	return nil, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos)
+}
+
+// parseList parses a list literal. Bare words inside a list are treated as
+// string literals so that "employment in [employed, self_employed]" works
+// without quoting.
+func (p *parser) parseList() (node, error) {
+	list := &listNode{}
+	if p.isOp("]") {
+		p.next()
+		return list, nil
+	}
+
+	for {
+		tok := p.peek()
+		if tok.kind == tokIdent && !keywords[strings.ToLower(tok.text)] {
+			p.next()
+			list.items = append(list.items, &literalNode{value: tok.text})
+		} else {
+			item, err := p.parseAdditive()
+			if err != nil {
+				return nil, err
+			}
+			list.items = append(list.items, item)
+		}
+
+		if p.isOp(",") {
+			p.next()
+			continue
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		return list, nil
+	}
+}
+
+func (n *literalNode) eval(env Env) (interface{}, error) {
+	return n.value, nil
+}
+
+func (n *identNode) eval(env Env) (interface{}, error) {
+	v, ok := env[n.name]
+	if !ok {
+		return nil, fmt.Errorf("unknown variable %q", n.name)
+	}
+	return normalize(v), nil
+}
+
+func (n *listNode) eval(env Env) (interface{}, error) {
+	values := make([]interface{}, len(n.items))
+	for i, item := range n.items {
+		v, err := item.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+func (n *unaryNode) eval(env Env) (interface{}, error) {
+	v, err := n.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "not":
+		b, ok := v.(bool)
+		if !ok {
+			return nil, fmt.Errorf("'not' requires a boolean, got %s", typeName(v))
+		}
+		return !b, nil
+	case "-":
+		f, ok := v.(float64)
+		if !ok {
+			return nil, fmt.Errorf("unary '-' requires a number, got %s", typeName(v))
+		}
+		return -f, nil
+	}
+	return nil, fmt.Errorf("unknown operator %q", n.op)
+}
+
+func (n *binaryNode) eval(env Env) (interface{}, error) {
+	left, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	// Logical operators short-circuit
+	if n.op == "and" || n.op == "or" {
+		lb, ok := left.(bool)
+		if !ok {
+			return nil, fmt.Errorf("'%s' requires booleans, got %s", n.op, typeName(left))
+		}
+		if n.op == "and" && !lb {
+			return false, nil
+		}
+		if n.op == "or" && lb {
+			return true, nil
+		}
+		right, err := n.right.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		rb, ok := right.(bool)
+		if !ok {
+			return nil, fmt.Errorf("'%s' requires booleans, got %s", n.op, typeName(right))
+		}
+		return rb, nil
+	}
+
+	right, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	}
+
+	lf, lok := left.(float64)
+	rf, rok := right.(float64)
+	if !lok || !rok {
+		return nil, fmt.Errorf("'%s' requires numbers, got %s and %s", n.op, typeName(left), typeName(right))
+	}
+
+	switch n.op {
+	case "+":
+		return lf + rf, nil
+	case "-":
+		return lf - rf, nil
+	case "*":
+		return lf * rf, nil
+	case "/":
+		if rf == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return lf / rf, nil
+	case "<":
+		return lf < rf, nil
+	case "<=":
+		return lf <= rf, nil
+	case ">":
+		return lf > rf, nil
+	case ">=":
+		return lf >= rf, nil
+	}
+	return nil, fmt.Errorf("unknown operator %q", n.op)
+}
+
+func (n *inNode) eval(env Env) (interface{}, error) {
+	left, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	listVal, err := n.list.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	items, ok := listVal.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("'in' requires a list, got %s", typeName(listVal))
+	}
+
+	found := false
+	for _, item := range items {
+		if equal(left, item) {
+			found = true
+			break
+		}
+	}
+	return found != n.negate, nil
+}
+
+// normalize converts environment values to the evaluator's value types:
+// float64, string, bool and []interface{}
+func normalize(v interface{}) interface{} {
+	switch t := v.(type) {
+	case int:
+		return float64(t)
+	case int64:
+		return float64(t)
+	case float32:
+		return float64(t)
+	case []string:
+		out := make([]interface{}, len(t))
+		for i, s := range t {
+			out[i] = s
+		}
+		return out
+	case fmt.Stringer:
+		return t.String()
+	}
+	return v
+}
+
+// equal compares two values; strings compare case-insensitively
+func equal(a, b interface{}) bool {
+	switch av := a.(type) {
+	case string:
+		bv, ok := b.(string)
+		return ok && strings.EqualFold(av, bv)
+	case float64:
+		bv, ok := b.(float64)
+		return ok && av == bv
+	case bool:
+		bv, ok := b.(bool)
+		return ok && av == bv
+	}
+	return false
+}
+
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case []interface{}:
+		return "list"
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("%T", v)
+}
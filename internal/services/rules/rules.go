@@ -0,0 +1,247 @@
+// Package rules implements the declarative eligibility rule language
+//
+// Rules are small boolean expressions stored per loan product and evaluated
+// by the logic filter, for example:
+//
+//	foir <= 0.55
+//	age + tenure_years <= 60
+//	employment in [employed, self_employed]
+//	credit_score >= 750 or monthly_income >= 100000
+//
+// Supported operators are + - * / < <= > >= == != and/&& or/|| not/! in and
+// "not in". Bare words inside a list literal are read as strings, and string
+// comparisons are case-insensitive.
+package rules
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"loan-eligibility-engine/internal/models"
+	"loan-eligibility-engine/internal/services/affordability"
+)
+
+// Env holds the variables available to rule expressions
+type Env map[string]interface{}
+
+// Variables documents every variable exposed by NewEnv
+var Variables = map[string]string{
+	"age":                     "User age in years",
+	"monthly_income":          "User monthly income",
+	"annual_income":           "User annual income (monthly_income * 12)",
+	"credit_score":            "User credit score",
+	"existing_emi":            "User EMIs on existing loans",
+	"credit_card_outstanding": "User credit card outstanding",
+	"active_loans":            "User number of active loans",
+	"obligations":             "existing_emi plus the counted share of credit_card_outstanding",
+	"existing_foir":           "obligations divided by monthly_income",
+	"requested_amount":        "Loan amount the user asked for, 0 if not stated",
+	"requested_tenure_months": "Tenure in months the user asked for, 0 if not stated",
+	"loan_purpose":            "Product type the user asked for (personal, home, ...), empty if not stated",
+	"employment":              "User employment status (employed, self_employed, ...)",
+	"product_type":            "Product type (personal, home, auto, ...)",
+	"provider":                "Product provider name",
+	"interest_rate_min":       "Product minimum annual interest rate (%)",
+	"interest_rate_max":       "Product maximum annual interest rate (%)",
+	"loan_amount_min":         "Product minimum loan amount",
+	"loan_amount_max":         "Product maximum loan amount",
+	"tenure_min_months":       "Product minimum tenure in months",
+	"tenure_max_months":       "Product maximum tenure in months",
+	"tenure_years":            "Product maximum tenure in years",
+	"min_monthly_income":      "Product minimum monthly income",
+	"min_credit_score":        "Product minimum credit score",
+	"min_age":                 "Product minimum age",
+	"max_age":                 "Product maximum age",
+	"emi":                     "EMI for loan_amount_min at interest_rate_max over the maximum tenure",
+	"foir":                    "obligations plus emi, divided by monthly_income",
+	"max_foir":                "Product FOIR limit, or the configured default",
+}
+
+// Rule is a compiled eligibility rule
+type Rule struct {
+	Name       string
+	Expression string
+	root       node
+	vars       []string
+	err        error // compile error of a stored rule that could not be compiled
+}
+
+// Compile parses an expression into a rule
+func Compile(name, expression string) (*Rule, error) {
+	if strings.TrimSpace(expression) == "" {
+		return nil, fmt.Errorf("rule %q: expression is empty", name)
+	}
+
+	root, vars, err := parse(expression)
+	if err != nil {
+		return nil, fmt.Errorf("rule %q: %w", name, err)
+	}
+
+	sort.Strings(vars)
+	return &Rule{Name: name, Expression: expression, root: root, vars: vars}, nil
+}
+
+// Validate compiles an expression and checks that it only references known variables
+func Validate(name, expression string) error {
+	rule, err := Compile(name, expression)
+	if err != nil {
+		return err
+	}
+
+	var unknown []string
+	for _, v := range rule.vars {
+		if _, ok := Variables[v]; !ok {
+			unknown = append(unknown, v)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("rule %q: unknown variables: %s", name, strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// Variables returns the variable names referenced by the rule
+func (r *Rule) Variables() []string {
+	return r.vars
+}
+
+// Eval evaluates the rule and reports whether it passed. A rule that failed
+// to compile never passes.
+func (r *Rule) Eval(env Env) (bool, error) {
+	if r.err != nil {
+		return false, r.err
+	}
+
+	v, err := r.root.eval(env)
+	if err != nil {
+		return false, err
+	}
+
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("expression must evaluate to a boolean, got %s", typeName(v))
+	}
+	return b, nil
+}
+
+// RuleSet is an ordered collection of rules that must all pass
+type RuleSet []*Rule
+
+// Evaluate runs every rule and returns the per-rule results. A rule that fails
+// to evaluate counts as failed so that broken policies never approve a match.
+func (s RuleSet) Evaluate(env Env) ([]models.RuleResult, bool) {
+	results := make([]models.RuleResult, 0, len(s))
+	allPassed := true
+
+	for _, rule := range s {
+		passed, err := rule.Eval(env)
+		result := models.RuleResult{
+			Rule:       rule.Name,
+			Expression: rule.Expression,
+			Passed:     passed && err == nil,
+		}
+		if err != nil {
+			result.Error = err.Error()
+		}
+		if !result.Passed {
+			allPassed = false
+		}
+		results = append(results, result)
+	}
+
+	return results, allPassed
+}
+
+// FromModels compiles stored rules into a rule set ordered by priority.
+// Rules that fail to compile are returned as errors and kept in the set as
+// rules that always fail, so a product with broken rules matches no one until
+// they are fixed rather than everyone.
+func FromModels(stored []*models.EligibilityRule) (RuleSet, []error) {
+	sorted := make([]*models.EligibilityRule, 0, len(stored))
+	for _, r := range stored {
+		if r.IsActive {
+			sorted = append(sorted, r)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Priority < sorted[j].Priority
+	})
+
+	set := make(RuleSet, 0, len(sorted))
+	var errs []error
+	for _, r := range sorted {
+		rule, err := Compile(r.Name, r.Expression)
+		if err != nil {
+			errs = append(errs, err)
+			rule = &Rule{Name: r.Name, Expression: r.Expression, err: fmt.Errorf("invalid rule: %w", err)}
+		}
+		set = append(set, rule)
+	}
+	return set, errs
+}
+
+// DefaultRuleSet is applied to products that have no stored rules. It keeps
+// the historical affordability check: the EMI on the minimum loan amount at
+// the maximum rate must not exceed the user's monthly income.
+func DefaultRuleSet() RuleSet {
+	return RuleSet{mustCompile("emi_affordability", "emi <= monthly_income")}
+}
+
+// foirLimit is checked after every product's own rules
+var foirLimit = mustCompile("foir_within_limit", "foir <= max_foir")
+
+// WithFOIRLimit returns the rule set followed by the FOIR limit check, which
+// applies to every product whatever its stored rules. The set itself is not
+// modified.
+func (s RuleSet) WithFOIRLimit() RuleSet {
+	return append(s[:len(s):len(s)], foirLimit)
+}
+
+// mustCompile compiles a built-in rule
+func mustCompile(name, expression string) *Rule {
+	rule, err := Compile(name, expression)
+	if err != nil {
+		panic(err)
+	}
+	return rule
+}
+
+// NewEnv builds the rule environment for a user-product pair. maxFOIR is the
+// FOIR limit for products that do not set their own.
+func NewEnv(user *models.User, product *models.LoanProduct, maxFOIR float64) Env {
+	_, tenure := affordability.Tenures(product)
+	emi := affordability.EMI(product.LoanAmountMin, product.InterestRateMax, tenure)
+
+	return Env{
+		"age":                     user.Age,
+		"monthly_income":          user.MonthlyIncome,
+		"annual_income":           user.MonthlyIncome * 12,
+		"credit_score":            user.CreditScore,
+		"existing_emi":            user.ExistingEMI,
+		"credit_card_outstanding": user.CreditCardOutstanding,
+		"active_loans":            user.ActiveLoans,
+		"obligations":             affordability.Obligations(user),
+		"existing_foir":           affordability.ExistingFOIR(user),
+		"requested_amount":        user.RequestedAmount,
+		"requested_tenure_months": user.RequestedTenureMonths,
+		"loan_purpose":            string(user.LoanPurpose),
+		"employment":              string(user.EmploymentStatus),
+		"product_type":            string(product.ProductType),
+		"provider":                product.ProviderName,
+		"interest_rate_min":       product.InterestRateMin,
+		"interest_rate_max":       product.InterestRateMax,
+		"loan_amount_min":         product.LoanAmountMin,
+		"loan_amount_max":         product.LoanAmountMax,
+		"tenure_min_months":       product.TenureMinMonths,
+		"tenure_max_months":       tenure,
+		"tenure_years":            float64(tenure) / 12,
+		"min_monthly_income":      product.MinMonthlyIncome,
+		"min_credit_score":        product.MinCreditScore,
+		"min_age":                 product.MinAge,
+		"max_age":                 product.MaxAge,
+		"emi":                     emi,
+		"foir":                    affordability.FOIR(user, product),
+		"max_foir":                affordability.MaxFOIR(product, maxFOIR),
+	}
+}
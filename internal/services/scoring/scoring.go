@@ -0,0 +1,286 @@
+// Package scoring computes the 0-100 eligibility score of a user-product pair.
+// The weights and curves come from a versioned model, normally loaded from a
+// JSON file, and the version is stored on every match it scored.
+package scoring
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+
+	"loan-eligibility-engine/internal/models"
+	"loan-eligibility-engine/internal/services/affordability"
+)
+
+// MaxScore is the highest score a model may produce
+const MaxScore = 100
+
+// maxVersionLength matches the width of matches.scoring_version
+const maxVersionLength = 50
+
+// Curve shapes how a component's 0-1 fraction turns into points
+type Curve string
+
+const (
+	CurveLinear Curve = "linear" // points grow evenly
+	CurveSqrt   Curve = "sqrt"   // early gains count most
+	CurveSquare Curve = "square" // only strong profiles score well
+)
+
+// Apply maps x, clamped to 0-1, through the curve
+func (c Curve) Apply(x float64) float64 {
+	x = math.Max(0, math.Min(1, x))
+	switch c {
+	case CurveSqrt:
+		return math.Sqrt(x)
+	case CurveSquare:
+		return x * x
+	default:
+		return x
+	}
+}
+
+// Component is one weighted part of the score
+type Component struct {
+	Weight float64 `json:"weight"`
+	Curve  Curve   `json:"curve,omitempty"`
+}
+
+// points returns the component's contribution for a 0-1 fraction
+func (c Component) points(x float64) float64 {
+	return c.Weight * c.Curve.Apply(x)
+}
+
+// Model is a versioned scoring model. Every pair that passes the hard
+// criteria gets Base points plus:
+//   - Credit: credit score headroom above the product minimum, as a share of
+//     the way to CreditCeiling
+//   - Income: income above the product minimum, as a share of IncomeMultiple
+//     times the minimum
+//   - Age: closeness to the middle of the product's age band
+//
+// Penalties are then taken off, and scores never drop below zero:
+//   - Leverage: the share of income already committed to existing
+//     obligations, as a share of LeverageCeiling
+//   - Terms: how poorly the product fits the requested amount and tenure. A
+//     product whose maximum is AmountSpread times the requested amount or more
+//     fits the amount not at all; a tenure outside the product's range fits
+//     less the further off it is. Users who requested neither are not
+//     penalised.
+type Model struct {
+	Version         string    `json:"version"`
+	Base            float64   `json:"base"`
+	Credit          Component `json:"credit"`
+	Income          Component `json:"income"`
+	Age             Component `json:"age"`
+	Leverage        Component `json:"leverage"`
+	Terms           Component `json:"terms"`
+	CreditCeiling   int       `json:"credit_ceiling"`
+	IncomeMultiple  float64   `json:"income_multiple"`
+	LeverageCeiling float64   `json:"leverage_ceiling"`
+	AmountSpread    float64   `json:"amount_spread"`
+}
+
+// Default returns the built-in model used when no model file is configured
+func Default() *Model {
+	return &Model{
+		Version:         "v3",
+		Base:            20,
+		Credit:          Component{Weight: 40, Curve: CurveLinear},
+		Income:          Component{Weight: 30, Curve: CurveLinear},
+		Age:             Component{Weight: 10, Curve: CurveLinear},
+		Leverage:        Component{Weight: 20, Curve: CurveLinear},
+		Terms:           Component{Weight: 15, Curve: CurveLinear},
+		CreditCeiling:   900,
+		IncomeMultiple:  2,
+		LeverageCeiling: 0.5,
+		AmountSpread:    100,
+	}
+}
+
+// Parse decodes and validates a JSON model. Omitted curves are linear and
+// omitted scales take the default model's values. Omitted penalties are not
+// applied.
+func Parse(data []byte) (*Model, error) {
+	var m Model
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("invalid scoring model: %w", err)
+	}
+
+	defaults := Default()
+	if m.CreditCeiling == 0 {
+		m.CreditCeiling = defaults.CreditCeiling
+	}
+	if m.IncomeMultiple == 0 {
+		m.IncomeMultiple = defaults.IncomeMultiple
+	}
+	if m.LeverageCeiling == 0 {
+		m.LeverageCeiling = defaults.LeverageCeiling
+	}
+	if m.AmountSpread == 0 {
+		m.AmountSpread = defaults.AmountSpread
+	}
+	for _, c := range []*Component{&m.Credit, &m.Income, &m.Age, &m.Leverage, &m.Terms} {
+		if c.Curve == "" {
+			c.Curve = CurveLinear
+		}
+	}
+
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Load reads and parses a model file
+func Load(path string) (*Model, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scoring model: %w", err)
+	}
+	return Parse(data)
+}
+
+// Validate checks that the model is versioned and can only produce scores
+// between 0 and MaxScore
+func (m *Model) Validate() error {
+	if m.Version == "" {
+		return fmt.Errorf("scoring model has no version")
+	}
+	if len(m.Version) > maxVersionLength {
+		return fmt.Errorf("scoring model version longer than %d characters", maxVersionLength)
+	}
+
+	// Penalties only take points off, so they do not count toward the total
+	penalties := map[string]bool{"leverage": true, "terms": true}
+	total := m.Base
+	for name, c := range map[string]Component{
+		"credit": m.Credit, "income": m.Income, "age": m.Age, "leverage": m.Leverage, "terms": m.Terms,
+	} {
+		if c.Weight < 0 {
+			return fmt.Errorf("scoring model %s weight is negative", name)
+		}
+		switch c.Curve {
+		case CurveLinear, CurveSqrt, CurveSquare:
+		default:
+			return fmt.Errorf("scoring model %s has unknown curve %q", name, c.Curve)
+		}
+		if !penalties[name] {
+			total += c.Weight
+		}
+	}
+	if m.Base < 0 || total > MaxScore {
+		return fmt.Errorf("scoring model weights must add up to between 0 and %d, got %.2f", MaxScore, total)
+	}
+	if m.CreditCeiling <= 300 || m.CreditCeiling > 900 {
+		return fmt.Errorf("scoring model credit_ceiling must be between 301 and 900")
+	}
+	if m.IncomeMultiple <= 0 {
+		return fmt.Errorf("scoring model income_multiple must be positive")
+	}
+	if m.LeverageCeiling <= 0 {
+		return fmt.Errorf("scoring model leverage_ceiling must be positive")
+	}
+	if m.AmountSpread <= 1 {
+		return fmt.Errorf("scoring model amount_spread must be greater than 1")
+	}
+	return nil
+}
+
+// Score computes the 0-100 score of a user for a product. It assumes the pair
+// already passed the hard eligibility criteria.
+func (m *Model) Score(user *models.User, product *models.LoanProduct) float64 {
+	score := m.Base
+
+	// Credit score headroom above the product minimum
+	creditRange := float64(m.CreditCeiling - product.MinCreditScore)
+	if creditRange > 0 {
+		score += m.Credit.points(float64(user.CreditScore-product.MinCreditScore) / creditRange)
+	}
+
+	// Income above the product minimum
+	incomeRange := product.MinMonthlyIncome * m.IncomeMultiple
+	if incomeRange > 0 {
+		score += m.Income.points((user.MonthlyIncome - product.MinMonthlyIncome) / incomeRange)
+	}
+
+	// Closeness to the middle of the age band
+	ageRange := float64(product.MaxAge - product.MinAge)
+	if ageRange > 0 {
+		ageMidpoint := float64(product.MinAge+product.MaxAge) / 2
+		score += m.Age.points(1 - math.Abs(float64(user.Age)-ageMidpoint)/(ageRange/2))
+	}
+
+	// Income already committed to existing obligations
+	score -= m.Leverage.points(affordability.ExistingFOIR(user) / m.LeverageCeiling)
+
+	// Distance from the requested amount and tenure
+	score -= m.Terms.points(1 - m.termsFit(user, product))
+
+	return math.Max(0, score)
+}
+
+// termsFit returns how well the product fits the user's requested amount and
+// tenure, from 0 to 1, averaging the terms the user stated
+func (m *Model) termsFit(user *models.User, product *models.LoanProduct) float64 {
+	var fit float64
+	var stated int
+
+	if user.RequestedAmount > 0 {
+		stated++
+		if product.LoanAmountMax <= user.RequestedAmount {
+			fit++
+		} else {
+			fit += math.Max(0, 1-math.Log(product.LoanAmountMax/user.RequestedAmount)/math.Log(m.AmountSpread))
+		}
+	}
+
+	if user.RequestedTenureMonths > 0 {
+		stated++
+		minTenure, maxTenure := affordability.Tenures(product)
+		requested := float64(user.RequestedTenureMonths)
+		switch {
+		case user.RequestedTenureMonths < minTenure:
+			fit += math.Max(0, 1-float64(minTenure-user.RequestedTenureMonths)/requested)
+		case user.RequestedTenureMonths > maxTenure:
+			fit += math.Max(0, 1-float64(user.RequestedTenureMonths-maxTenure)/requested)
+		default:
+			fit++
+		}
+	}
+
+	if stated == 0 {
+		return 1
+	}
+	return fit / float64(stated)
+}
+
+// ScoreCandidate scores a candidate from the SQL prefilter
+func (m *Model) ScoreCandidate(c *models.MatchCandidate) float64 {
+	user := &models.User{
+		MonthlyIncome:    c.MonthlyIncome,
+		CreditScore:      c.CreditScore,
+		EmploymentStatus: c.EmploymentStatus,
+		Age:              c.Age,
+
+		ExistingEMI:           c.ExistingEMI,
+		CreditCardOutstanding: c.CreditCardOutstanding,
+		ActiveLoans:           c.ActiveLoans,
+		RequestedAmount:       c.RequestedAmount,
+		RequestedTenureMonths: c.RequestedTenureMonths,
+		LoanPurpose:           c.LoanPurpose,
+	}
+	product := &models.LoanProduct{
+		ProductType:      c.ProductType,
+		LoanAmountMin:    c.LoanAmountMin,
+		LoanAmountMax:    c.LoanAmountMax,
+		TenureMinMonths:  c.TenureMinMonths,
+		TenureMaxMonths:  c.TenureMaxMonths,
+		MinMonthlyIncome: c.MinMonthlyIncome,
+		MinCreditScore:   c.MinCreditScore,
+		MinAge:           c.MinAge,
+		MaxAge:           c.MaxAge,
+	}
+	return m.Score(user, product)
+}
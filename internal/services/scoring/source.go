@@ -0,0 +1,51 @@
+package scoring
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+// Source serves the current model from a file, reloading it whenever the
+// file changes so weights can be updated without a redeploy. It is safe for
+// concurrent use.
+type Source struct {
+	path string
+
+	mu      sync.Mutex
+	model   *Model
+	modTime time.Time
+}
+
+// NewSource creates a source for the model file at path. An empty path always
+// serves the default model.
+func NewSource(path string) *Source {
+	return &Source{path: path, model: Default()}
+}
+
+// Model returns the current model. If the file is missing or invalid the last
+// good model, initially the default one, is returned together with the error.
+func (s *Source) Model() (*Model, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.path == "" {
+		return s.model, nil
+	}
+
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return s.model, err
+	}
+	if info.ModTime().Equal(s.modTime) {
+		return s.model, nil
+	}
+
+	model, err := Load(s.path)
+	if err != nil {
+		return s.model, err
+	}
+	s.model = model
+	s.modTime = info.ModTime()
+	return s.model, nil
+}
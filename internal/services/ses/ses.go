@@ -55,6 +55,20 @@ type MatchInfo struct {
 	InterestRateMax  float64
 	MaxLoanAmount    float64
 	EligibilityScore float64
+
+	// Affordability for this user; MaxEligibleAmount is zero when unknown
+	MaxEligibleAmount float64
+	EMIMin            float64
+	EMIMax            float64
+
+	// Amounts are in the product's currency, written in the user's locale
+	Currency models.Currency
+	Locale   string
+}
+
+// Money formats an amount of the match's currency for the user
+func (m MatchInfo) Money(amount float64) string {
+	return utils.FormatMoney(amount, m.Currency.OrDefault(), m.Locale)
 }
 
 // SendEmailResult contains the result of sending an email
@@ -216,6 +230,13 @@ func BuildMatchNotificationParams(user *models.User, matches []models.Match, pro
 			InterestRateMax:  product.InterestRateMax,
 			MaxLoanAmount:    product.LoanAmountMax,
 			EligibilityScore: match.MatchScore,
+
+			MaxEligibleAmount: match.MaxEligibleAmount,
+			EMIMin:            match.EMIMin,
+			EMIMax:            match.EMIMax,
+
+			Currency: product.Currency,
+			Locale:   user.Locale,
 		})
 	}
 
@@ -273,8 +294,18 @@ func (s *Service) renderMatchNotificationHTML(params MatchNotificationParams) (s
                 </div>
                 <div class="detail-item">
                     <div class="detail-label">Max Amount</div>
-                    <div class="detail-value">₹{{printf "%.0f" .MaxLoanAmount}}</div>
+                    <div class="detail-value">{{.Money .MaxLoanAmount}}</div>
                 </div>
+                {{if gt .MaxEligibleAmount 0.0}}
+                <div class="detail-item">
+                    <div class="detail-label">You Can Borrow Up To</div>
+                    <div class="detail-value">{{.Money .MaxEligibleAmount}}</div>
+                </div>
+                <div class="detail-item">
+                    <div class="detail-label">Estimated EMI</div>
+                    <div class="detail-value">{{.Money .EMIMin}} - {{.Money .EMIMax}}</div>
+                </div>
+                {{end}}
                 <div class="detail-item">
                     <div class="detail-label">Eligibility Score</div>
                     <div class="detail-value"><span class="score-badge">{{printf "%.0f" .EligibilityScore}}%</span></div>
@@ -320,7 +351,11 @@ func (s *Service) renderMatchNotificationText(params MatchNotificationParams) st
 	for i, match := range params.TopMatches {
 		buf.WriteString(fmt.Sprintf("%d. %s by %s\n", i+1, match.ProductName, match.Provider))
 		buf.WriteString(fmt.Sprintf("   Interest Rate: %.2f%% - %.2f%%\n", match.InterestRateMin, match.InterestRateMax))
-		buf.WriteString(fmt.Sprintf("   Max Amount: ₹%.0f\n", match.MaxLoanAmount))
+		buf.WriteString(fmt.Sprintf("   Max Amount: %s\n", match.Money(match.MaxLoanAmount)))
+		if match.MaxEligibleAmount > 0 {
+			buf.WriteString(fmt.Sprintf("   You Can Borrow Up To: %s\n", match.Money(match.MaxEligibleAmount)))
+			buf.WriteString(fmt.Sprintf("   Estimated EMI: %s - %s\n", match.Money(match.EMIMin), match.Money(match.EMIMax)))
+		}
 		buf.WriteString(fmt.Sprintf("   Eligibility Score: %.0f%%\n\n", match.EligibilityScore))
 	}
 
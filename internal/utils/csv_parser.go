@@ -18,6 +18,7 @@ var (
 	ErrMissingColumns = errors.New("missing required columns")
 	ErrNoDataRows     = errors.New("CSV file contains no data rows")
 	ErrInvalidRowData = errors.New("invalid row data")
+	ErrMixedCurrency  = errors.New("amounts in more than one currency")
 )
 
 // RequiredColumns defines the columns that must be present in the CSV.
@@ -75,6 +76,71 @@ var ColumnAliases = map[string]string{
 	"job_status":        "employment_status",
 	"jobstatus":         "employment_status",
 	"occupation":        "employment_status",
+
+	// existing_emi aliases
+	"existingemi":       "existing_emi",
+	"existing emi":      "existing_emi",
+	"current_emi":       "existing_emi",
+	"emi":               "existing_emi",
+	"emis":              "existing_emi",
+	"monthly_emi":       "existing_emi",
+	"monthly_emis":      "existing_emi",
+	"total_emi":         "existing_emi",
+	"existing_emis":     "existing_emi",
+	"monthly_debt":      "existing_emi",
+	"monthly_debt_paid": "existing_emi",
+
+	// credit_card_outstanding aliases
+	"creditcardoutstanding":   "credit_card_outstanding",
+	"credit card outstanding": "credit_card_outstanding",
+	"card_outstanding":        "credit_card_outstanding",
+	"cc_outstanding":          "credit_card_outstanding",
+	"credit_card_balance":     "credit_card_outstanding",
+	"card_balance":            "credit_card_outstanding",
+
+	// active_loans aliases
+	"activeloans":     "active_loans",
+	"active loans":    "active_loans",
+	"num_loans":       "active_loans",
+	"number_of_loans": "active_loans",
+	"open_loans":      "active_loans",
+	"existing_loans":  "active_loans",
+	"loan_count":      "active_loans",
+
+	// requested_amount aliases
+	"requestedamount":       "requested_amount",
+	"requested amount":      "requested_amount",
+	"loan_amount":           "requested_amount",
+	"loanamount":            "requested_amount",
+	"loan amount":           "requested_amount",
+	"amount":                "requested_amount",
+	"desired_amount":        "requested_amount",
+	"requested_loan_amount": "requested_amount",
+
+	// requested_tenure_months aliases
+	"requested_tenure": "requested_tenure_months",
+	"requestedtenure":  "requested_tenure_months",
+	"tenure":           "requested_tenure_months",
+	"tenure_months":    "requested_tenure_months",
+	"loan_tenure":      "requested_tenure_months",
+	"term_months":      "requested_tenure_months",
+	"repayment_months": "requested_tenure_months",
+
+	// loan_purpose aliases
+	"loanpurpose":  "loan_purpose",
+	"loan purpose": "loan_purpose",
+	"purpose":      "loan_purpose",
+	"loan_type":    "loan_purpose",
+	"loantype":     "loan_purpose",
+
+	// currency aliases
+	"currency_code": "currency",
+	"currencycode":  "currency",
+	"ccy":           "currency",
+
+	// locale aliases
+	"user_locale": "locale",
+	"language":    "locale",
 }
 
 // CSVParser handles parsing of user CSV files.
@@ -214,9 +280,50 @@ func (p *CSVParser) parseRow(record []string, batchID string) (*models.UserCreat
 	if err != nil {
 		return nil, err
 	}
-	income, err := parseFloat(incomeStr)
+	// Every amount in a row must be in one currency: the currency column if
+	// present, otherwise whatever code or symbol the amounts carry
+	var currency models.Currency
+	setCurrency := func(column string, c models.Currency) error {
+		if c == "" {
+			return nil
+		}
+		if currency != "" && c != currency {
+			return fmt.Errorf("%w: %s is in %s, expected %s", ErrMixedCurrency, column, c, currency)
+		}
+		currency = c
+		return nil
+	}
+	parseAmount := func(column, s string) (float64, error) {
+		amount, c, err := parseMoney(s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s: %w", column, err)
+		}
+		return amount, setCurrency(column, c)
+	}
+
+	// Parse optional cells; a missing column or empty cell is empty
+	getOptional := func(column string) string {
+		if _, ok := p.columnMapping[column]; !ok {
+			return ""
+		}
+		value, err := getValue(column)
+		if err != nil {
+			return ""
+		}
+		return value
+	}
+
+	if s := getOptional("currency"); s != "" {
+		c := models.NormalizeCurrency(s)
+		if !c.IsValid() {
+			return nil, fmt.Errorf("invalid currency: %q", s)
+		}
+		currency = c
+	}
+
+	income, err := parseAmount("monthly_income", incomeStr)
 	if err != nil {
-		return nil, fmt.Errorf("invalid monthly_income: %w", err)
+		return nil, err
 	}
 
 	// Check if the original column was annual income - if so, divide by 12
@@ -253,30 +360,107 @@ func (p *CSVParser) parseRow(record []string, batchID string) (*models.UserCreat
 		return nil, fmt.Errorf("invalid age: %w", err)
 	}
 
+	// Parse optional obligations; a missing column or empty cell counts as zero
+	var existingEMI, cardOutstanding float64
+	var activeLoans int
+	if s := getOptional("existing_emi"); s != "" {
+		if existingEMI, err = parseAmount("existing_emi", s); err != nil {
+			return nil, err
+		}
+	}
+	if s := getOptional("credit_card_outstanding"); s != "" {
+		if cardOutstanding, err = parseAmount("credit_card_outstanding", s); err != nil {
+			return nil, err
+		}
+	}
+	if s := getOptional("active_loans"); s != "" {
+		if activeLoans, err = parseInt(s); err != nil {
+			return nil, fmt.Errorf("invalid active_loans: %w", err)
+		}
+	}
+
+	// Parse optional requested terms
+	var requestedAmount float64
+	var requestedTenure int
+	if s := getOptional("requested_amount"); s != "" {
+		if requestedAmount, err = parseAmount("requested_amount", s); err != nil {
+			return nil, err
+		}
+	}
+	if s := getOptional("requested_tenure_months"); s != "" {
+		if requestedTenure, err = parseInt(s); err != nil {
+			return nil, fmt.Errorf("invalid requested_tenure_months: %w", err)
+		}
+	}
+	var loanPurpose models.LoanProductType
+	if s := getOptional("loan_purpose"); s != "" {
+		loanPurpose = models.NormalizeLoanPurpose(s)
+	}
+
+	var locale string
+	if s := getOptional("locale"); s != "" {
+		normalized, ok := NormalizeLocale(s)
+		if !ok {
+			return nil, fmt.Errorf("unsupported locale: %q", s)
+		}
+		locale = normalized
+	}
+
 	return &models.UserCreate{
-		UserID:           userID,
-		Email:            email,
-		MonthlyIncome:    income,
-		CreditScore:      creditScore,
-		EmploymentStatus: employmentStatus,
-		Age:              age,
-		BatchID:          batchID,
+		UserID:                userID,
+		Email:                 email,
+		MonthlyIncome:         income,
+		CreditScore:           creditScore,
+		EmploymentStatus:      employmentStatus,
+		Age:                   age,
+		BatchID:               batchID,
+		ExistingEMI:           existingEMI,
+		CreditCardOutstanding: cardOutstanding,
+		ActiveLoans:           activeLoans,
+		RequestedAmount:       requestedAmount,
+		RequestedTenureMonths: requestedTenure,
+		LoanPurpose:           loanPurpose,
+		Currency:              currency,
+		Locale:                locale,
 	}, nil
 }
 
-// parseFloat parses a string to float64, handling common formats.
-func parseFloat(s string) (float64, error) {
+// currencyMarkers are the symbols and codes recognised around an amount,
+// longest first so that "S$" is not read as "$"
+var currencyMarkers = []string{
+	"SGD", "USD", "EUR", "GBP", "AED", "INR", "US$", "RS.", "DHS",
+	"S$", "RS", "₹", "$", "€", "£",
+}
+
+// parseMoney parses an amount such as "₹45,000", "USD 3,200" or "2500 EUR"
+// and returns the currency it is written in, or "" when it carries none.
+func parseMoney(s string) (float64, models.Currency, error) {
+	s = strings.TrimSpace(s)
 	if s == "" {
-		return 0, errors.New("empty value")
+		return 0, "", errors.New("empty value")
+	}
+
+	var currency models.Currency
+	upper := strings.ToUpper(s)
+	for _, marker := range currencyMarkers {
+		switch {
+		case strings.HasPrefix(upper, marker):
+			s = s[len(marker):]
+		case strings.HasSuffix(upper, marker):
+			s = s[:len(s)-len(marker)]
+		default:
+			continue
+		}
+		currency = models.NormalizeCurrency(marker)
+		break
 	}
 
-	// Remove commas and currency symbols
+	// Remove thousands separators
 	s = strings.ReplaceAll(s, ",", "")
-	s = strings.TrimPrefix(s, "$")
-	s = strings.TrimPrefix(s, "₹")
 	s = strings.TrimSpace(s)
 
-	return strconv.ParseFloat(s, 64)
+	amount, err := strconv.ParseFloat(s, 64)
+	return amount, currency, err
 }
 
 // parseInt parses a string to int, handling common formats.
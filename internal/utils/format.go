@@ -0,0 +1,96 @@
+// Package utils provides utility functions for the loan eligibility engine.
+package utils
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"loan-eligibility-engine/internal/models"
+)
+
+// numberFormat describes how a locale writes whole currency amounts
+type numberFormat struct {
+	indianGrouping bool   // group by thousand, then by hundred (12,34,567)
+	separator      string // between digit groups
+	symbolAfter    bool   // "1.234 €" rather than "€1,234"
+}
+
+// localeFormats are the supported locales
+var localeFormats = map[string]numberFormat{
+	"en-IN": {indianGrouping: true, separator: ","},
+	"hi-IN": {indianGrouping: true, separator: ","},
+	"en-US": {separator: ","},
+	"en-GB": {separator: ","},
+	"en-IE": {separator: ","},
+	"en-AE": {separator: ","},
+	"en-SG": {separator: ","},
+	"de-DE": {separator: ".", symbolAfter: true},
+	"fr-FR": {separator: "\u202f", symbolAfter: true},
+}
+
+// NormalizeLocale converts a locale such as "en_in" to its canonical form,
+// "en-IN", and reports whether it is supported.
+func NormalizeLocale(locale string) (string, bool) {
+	parts := strings.FieldsFunc(strings.TrimSpace(locale), func(r rune) bool { return r == '-' || r == '_' })
+	if len(parts) != 2 {
+		return locale, false
+	}
+	normalized := strings.ToLower(parts[0]) + "-" + strings.ToUpper(parts[1])
+	_, ok := localeFormats[normalized]
+	return normalized, ok
+}
+
+// FormatMoney formats a whole amount in a currency for a locale, e.g.
+// 2500000 INR in en-IN becomes "₹25,00,000" and 1234 EUR in de-DE
+// "1.234 €". An unsupported or empty locale uses the currency's default.
+func FormatMoney(amount float64, currency models.Currency, locale string) string {
+	format, ok := localeFormats[locale]
+	if !ok {
+		format = localeFormats[currency.DefaultLocale()]
+	}
+
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	digits := groupDigits(strconv.FormatFloat(math.Round(amount), 'f', 0, 64), format)
+	symbol := currency.Symbol()
+	if format.symbolAfter {
+		return sign + digits + " " + strings.TrimSpace(symbol)
+	}
+	return sign + symbol + digits
+}
+
+// FormatINR formats an amount in rupees using Indian digit grouping,
+// e.g. 2500000 becomes "₹25,00,000".
+func FormatINR(amount float64) string {
+	return FormatMoney(amount, models.CurrencyINR, "en-IN")
+}
+
+// groupDigits inserts the locale's group separators into a string of digits
+func groupDigits(digits string, format numberFormat) string {
+	if len(digits) <= 3 {
+		return digits
+	}
+
+	// Last three digits form the first group, then groups of two in Indian
+	// grouping and of three otherwise
+	size := 3
+	if format.indianGrouping {
+		size = 2
+	}
+	head, tail := digits[:len(digits)-3], digits[len(digits)-3:]
+	var groups []string
+	for len(head) > size {
+		groups = append([]string{head[len(head)-size:]}, groups...)
+		head = head[:len(head)-size]
+	}
+	if head != "" {
+		groups = append([]string{head}, groups...)
+	}
+
+	return strings.Join(groups, format.separator) + format.separator + tail
+}
@@ -9,6 +9,9 @@ import (
 
 	"github.com/jackc/pgx/v5"
 	"github.com/joho/godotenv"
+
+	"loan-eligibility-engine/internal/services/database"
+	"loan-eligibility-engine/internal/services/migrations"
 )
 
 func main() {
@@ -75,26 +78,46 @@ func main() {
 	fmt.Println("✅ Connected to database successfully!")
 	fmt.Println()
 
-	// Read SQL file
-	fmt.Println("📖 Reading SQL schema file...")
-	sqlBytes, err := os.ReadFile("scripts/init_database.sql")
+	// Apply schema migrations
+	fmt.Println("🚀 Applying schema migrations...")
+	db, err := database.NewFromURL(databaseURL)
 	if err != nil {
-		fmt.Printf("❌ Failed to read SQL file: %v\n", err)
+		fmt.Printf("❌ Failed to connect to database: %v\n", err)
 		os.Exit(1)
 	}
+	defer db.Close()
 
-	fmt.Println("✅ SQL file loaded successfully!")
+	migrator, err := migrations.NewMigrator(db)
+	if err != nil {
+		fmt.Printf("❌ Failed to load migrations: %v\n", err)
+		os.Exit(1)
+	}
+	applied, err := migrator.Up(ctx)
+	for _, migration := range applied {
+		fmt.Printf("   ✅ %04d_%s\n", migration.Version, migration.Name)
+	}
+	if err != nil {
+		fmt.Printf("❌ Migration failed: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println("✅ Database schema is up to date!")
 	fmt.Println()
 
-	// Execute SQL
-	fmt.Println("🚀 Executing database schema...")
+	// Load sample data
+	fmt.Println("📖 Loading sample data...")
+	sqlBytes, err := os.ReadFile("scripts/sample_data.sql")
+	if err != nil {
+		fmt.Printf("❌ Failed to read SQL file: %v\n", err)
+		os.Exit(1)
+	}
+
 	_, err = conn.Exec(ctx, string(sqlBytes))
 	if err != nil {
 		fmt.Printf("❌ Failed to execute SQL: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Println("✅ Database schema executed successfully!")
+	fmt.Println("✅ Sample data loaded successfully!")
 	fmt.Println()
 
 	// Verify by counting tables and products
@@ -0,0 +1,146 @@
+-- Loan Eligibility Engine sample data for local development
+-- Run after the schema migrations (go run ./cmd/migrate up); safe to re-run.
+
+-- Record product versions made by this script as imported
+SET loan.product_change_source = 'import';
+
+-- Insert sample loan products
+INSERT INTO loan_products (
+    product_name, provider_name, product_type,
+    interest_rate_min, interest_rate_max,
+    loan_amount_min, loan_amount_max,
+    tenure_min_months, tenure_max_months,
+    min_monthly_income, min_credit_score,
+    min_age, max_age,
+    accepted_employment_status,
+    processing_fee_percent,
+    source_url
+) VALUES
+(
+    'HDFC Personal Loan',
+    'HDFC Bank',
+    'personal',
+    10.50, 21.00,
+    50000, 4000000,
+    12, 60,
+    25000, 700,
+    21, 60,
+    ARRAY['employed', 'self_employed'],
+    2.50,
+    'https://www.hdfcbank.com/personal/borrow/popular-loans/personal-loan'
+),
+(
+    'ICICI Instant Personal Loan',
+    'ICICI Bank',
+    'personal',
+    10.75, 19.00,
+    50000, 2500000,
+    12, 72,
+    20833, 680,
+    23, 58,
+    ARRAY['employed'],
+    1.99,
+    'https://www.icicibank.com/personal-banking/loans/personal-loan'
+),
+(
+    'SBI Express Personal Loan',
+    'State Bank of India',
+    'personal',
+    11.00, 14.50,
+    100000, 3500000,
+    12, 84,
+    16666, 650,
+    21, 65,
+    ARRAY['employed', 'self_employed', 'retired'],
+    1.00,
+    'https://sbi.co.in/web/personal-banking/loans/personal-loans'
+),
+(
+    'Bajaj Finserv Flexi Loan',
+    'Bajaj Finserv',
+    'personal',
+    12.00, 24.00,
+    25000, 2500000,
+    12, 60,
+    29166, 720,
+    25, 55,
+    ARRAY['employed'],
+    2.00,
+    'https://www.bajajfinserv.in/personal-loan'
+),
+(
+    'Axis Bank Personal Loan',
+    'Axis Bank',
+    'personal',
+    10.49, 22.00,
+    50000, 1500000,
+    12, 60,
+    15000, 700,
+    21, 60,
+    ARRAY['employed', 'self_employed'],
+    1.50,
+    'https://www.axisbank.com/retail/loans/personal-loan'
+)
+ON CONFLICT (provider_name, product_name) DO NOTHING;
+
+-- Sample eligibility rules (products without rules use the default EMI affordability rule)
+INSERT INTO product_eligibility_rules (product_id, name, expression, description, priority)
+SELECT id, 'foir_limit', 'foir <= 0.55', 'EMI on the minimum amount must stay within 55% of income', 10
+FROM loan_products WHERE product_name = 'HDFC Personal Loan'
+ON CONFLICT (product_id, name) DO NOTHING;
+
+INSERT INTO product_eligibility_rules (product_id, name, expression, description, priority)
+SELECT id, 'age_at_maturity', 'age + tenure_years <= 70', 'Loan must mature before the borrower turns 70', 10
+FROM loan_products WHERE product_name = 'SBI Express Personal Loan'
+ON CONFLICT (product_id, name) DO NOTHING;
+
+-- Sample rate slabs (products without slabs interpolate between their minimum and maximum rate)
+INSERT INTO product_rate_slabs (product_id, min_credit_score, max_credit_score, min_monthly_income, max_monthly_income, interest_rate, processing_fee_percent)
+SELECT id, s.min_credit_score, s.max_credit_score, s.min_monthly_income, s.max_monthly_income, s.interest_rate, s.processing_fee_percent
+FROM loan_products,
+     (VALUES (700, 749, 0, NULL, 16.50, 2.50),
+             (750, 799, 0, NULL, 13.25, 2.00),
+             (800, 900, 0, 99999.99, 11.50, 1.50),
+             (800, 900, 100000, NULL, 10.50, 1.00))
+     AS s(min_credit_score, max_credit_score, min_monthly_income, max_monthly_income, interest_rate, processing_fee_percent)
+WHERE product_name = 'HDFC Personal Loan'
+  AND NOT EXISTS (SELECT 1 FROM product_rate_slabs WHERE product_id = loan_products.id);
+
+-- Sample crawler sources for the sample products' pages. Rates, the maximum
+-- amount and the processing fee are read from the page; the terms these pages
+-- rarely state fall back to defaults. Crawl one with
+-- go run ./cmd/crawl -source <name> -dry-run to check its rules.
+INSERT INTO crawler_sources (name, url, provider_name, product_type, rules)
+SELECT s.name, s.url, s.provider_name, 'personal',
+       jsonb_build_object('fields', jsonb_build_object(
+           'product_name', jsonb_build_object('default', s.product_name),
+           'interest_rate_min', jsonb_build_object('regex', '(?i)interest rates?[^%]{0,80}?(\d+(?:\.\d+)?)\s*%'),
+           'interest_rate_max', jsonb_build_object('regex', '(?i)interest rates?[^%]{0,80}?\d+(?:\.\d+)?\s*%?\s*(?:-|to)\s*(\d+(?:\.\d+)?)\s*%'),
+           'loan_amount_min', jsonb_build_object('default', s.loan_amount_min),
+           'loan_amount_max', jsonb_build_object('regex', '(?i)up\s*to\s*(?:rs\.?|₹|inr)?\s*(\d[\d,.]*\s*(?:lakhs?|lacs?|crores?|cr)?)'),
+           'tenure_min_months', jsonb_build_object('default', '12'),
+           'tenure_max_months', jsonb_build_object('default', s.tenure_max_months),
+           'min_monthly_income', jsonb_build_object('default', s.min_monthly_income),
+           'min_credit_score', jsonb_build_object('default', s.min_credit_score),
+           'min_age', jsonb_build_object('default', s.min_age),
+           'max_age', jsonb_build_object('default', s.max_age),
+           'accepted_employment_status', jsonb_build_object('default', s.employment),
+           'processing_fee_percent', jsonb_build_object('regex', '(?i)processing fees?[^%]{0,80}?(\d+(?:\.\d+)?)\s*%', 'default', s.processing_fee)
+       ))
+FROM (VALUES
+    ('hdfc-personal', 'https://www.hdfcbank.com/personal/borrow/popular-loans/personal-loan', 'HDFC Bank', 'HDFC Personal Loan',
+     '50000', '60', '25000', '700', '21', '60', 'employed, self_employed', '2.50'),
+    ('icici-personal', 'https://www.icicibank.com/personal-banking/loans/personal-loan', 'ICICI Bank', 'ICICI Instant Personal Loan',
+     '50000', '72', '20833', '680', '23', '58', 'employed', '1.99'),
+    ('sbi-personal', 'https://sbi.co.in/web/personal-banking/loans/personal-loans', 'State Bank of India', 'SBI Express Personal Loan',
+     '100000', '84', '16666', '650', '21', '65', 'employed, self_employed, retired', '1.00'),
+    ('bajaj-personal', 'https://www.bajajfinserv.in/personal-loan', 'Bajaj Finserv', 'Bajaj Finserv Flexi Loan',
+     '25000', '60', '29166', '720', '25', '55', 'employed', '2.00'),
+    ('axis-personal', 'https://www.axisbank.com/retail/loans/personal-loan', 'Axis Bank', 'Axis Bank Personal Loan',
+     '50000', '60', '15000', '700', '21', '60', 'employed, self_employed', '1.50')
+) AS s(name, url, provider_name, product_name, loan_amount_min, tenure_max_months, min_monthly_income,
+       min_credit_score, min_age, max_age, employment, processing_fee)
+ON CONFLICT (name) DO NOTHING;
+
+-- Verify setup
+SELECT 'Sample loan products: ' || COUNT(*)::text FROM loan_products;
@@ -0,0 +1,113 @@
+package unit_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"loan-eligibility-engine/internal/services/affordability"
+)
+
+func TestAffordability_EMI(t *testing.T) {
+	// 1,00,000 at 12% for 12 months is a well-known 8,884.88 EMI
+	assert.InDelta(t, 8884.88, affordability.EMI(100000, 12, 12), 0.01)
+	assert.InDelta(t, 1000.0, affordability.EMI(12000, 0, 12), 0.0001)
+	assert.Equal(t, 0.0, affordability.EMI(12000, 10, 0))
+}
+
+func TestAffordability_PrincipalInvertsEMI(t *testing.T) {
+	for _, rate := range []float64{0, 10.5, 24} {
+		emi := affordability.EMI(500000, rate, 48)
+		assert.InDelta(t, 500000, affordability.Principal(emi, rate, 48), 0.01)
+	}
+	assert.Equal(t, 0.0, affordability.Principal(0, 12, 12))
+}
+
+func TestAffordability_Tenures(t *testing.T) {
+	minT, maxT := affordability.Tenures(mockProduct(nil))
+	assert.Equal(t, 12, minT)
+	assert.Equal(t, 60, maxT)
+
+	product := mockProduct(nil)
+	product.TenureMinMonths, product.TenureMaxMonths = 0, 0
+	minT, maxT = affordability.Tenures(product)
+	assert.Equal(t, affordability.DefaultTenureMonths, minT)
+	assert.Equal(t, affordability.DefaultTenureMonths, maxT)
+}
+
+func TestAffordability_AssessCapsAtProductMaximum(t *testing.T) {
+	user := mockUser(map[string]interface{}{"monthly_income": float64(500000)})
+	product := mockProduct(nil)
+
+	a := affordability.Assess(user, product, 0.5)
+
+	assert.Equal(t, product.LoanAmountMax, a.MaxEligibleAmount)
+	assert.Less(t, a.EMIMin, a.EMIMax)
+	assert.InDelta(t, affordability.EMI(product.LoanAmountMax, product.InterestRateMin, 60), a.EMIMin, 0.01)
+	assert.InDelta(t, affordability.EMI(product.LoanAmountMax, product.InterestRateMax, 12), a.EMIMax, 0.01)
+}
+
+func TestAffordability_AssessIncomeLimited(t *testing.T) {
+	user := mockUser(map[string]interface{}{"monthly_income": float64(50000)})
+	product := mockProduct(nil)
+
+	a := affordability.Assess(user, product, 0.5)
+
+	// EMI at the max rate over the longest tenure must fit in half the income
+	assert.Greater(t, a.MaxEligibleAmount, product.LoanAmountMin)
+	assert.Less(t, a.MaxEligibleAmount, product.LoanAmountMax)
+	assert.LessOrEqual(t, affordability.EMI(a.MaxEligibleAmount, product.InterestRateMax, 60), 25000.0)
+	assert.InDelta(t, affordability.FOIR(user, product), a.FOIR, 1e-9)
+
+	// A tighter FOIR limit lowers the amount
+	tighter := affordability.Assess(user, product, 0.3)
+	assert.Less(t, tighter.MaxEligibleAmount, a.MaxEligibleAmount)
+}
+
+func TestAffordability_AssessUnaffordable(t *testing.T) {
+	user := mockUser(map[string]interface{}{"monthly_income": float64(1000)})
+	product := mockProduct(nil)
+
+	a := affordability.Assess(user, product, 0.5)
+
+	assert.Equal(t, 0.0, a.MaxEligibleAmount)
+	// EMIs are quoted on the minimum amount instead
+	assert.InDelta(t, affordability.EMI(product.LoanAmountMin, product.InterestRateMin, 60), a.EMIMin, 0.01)
+	assert.Greater(t, a.FOIR, 0.5)
+
+	none := affordability.Assess(mockUser(map[string]interface{}{"monthly_income": float64(0)}), product, 0.5)
+	assert.Equal(t, 0.0, none.MaxEligibleAmount)
+	assert.False(t, none.FOIR > 100, "FOIR must stay finite for storage")
+}
+
+func TestAffordability_ObligationsReduceHeadroom(t *testing.T) {
+	product := mockProduct(nil)
+	free := mockUser(map[string]interface{}{"monthly_income": float64(50000)})
+	indebted := mockUser(map[string]interface{}{
+		"monthly_income":          float64(50000),
+		"existing_emi":            float64(10000),
+		"credit_card_outstanding": float64(40000),
+	})
+
+	// 10,000 of EMIs plus 5% of the card outstanding
+	assert.Equal(t, 12000.0, affordability.Obligations(indebted))
+	assert.InDelta(t, 0.24, affordability.ExistingFOIR(indebted), 1e-9)
+	assert.InDelta(t, affordability.FOIR(free, product)+0.24, affordability.FOIR(indebted, product), 1e-9)
+
+	a := affordability.Assess(indebted, product, 0.5)
+	assert.Less(t, a.MaxEligibleAmount, affordability.Assess(free, product, 0.5).MaxEligibleAmount)
+	assert.LessOrEqual(t, affordability.EMI(a.MaxEligibleAmount, product.InterestRateMax, 60), 25000.0-12000.0)
+}
+
+func TestAffordability_ProductMaxFOIR(t *testing.T) {
+	product := mockProduct(nil)
+	assert.Equal(t, 0.6, affordability.MaxFOIR(product, 0.6))
+	assert.Equal(t, affordability.DefaultMaxFOIR, affordability.MaxFOIR(product, 0))
+
+	limit := 0.3
+	product.MaxFOIR = &limit
+	assert.Equal(t, 0.3, affordability.MaxFOIR(product, 0.6))
+
+	user := mockUser(map[string]interface{}{"monthly_income": float64(50000)})
+	assert.Equal(t, affordability.Assess(user, product, 0.3), affordability.Assess(user, product, 0.6))
+}
@@ -0,0 +1,87 @@
+package unit_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"loan-eligibility-engine/internal/models"
+	"loan-eligibility-engine/internal/services/matcher"
+)
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+func TestDiffBacktest_AddedDroppedAndRankChanges(t *testing.T) {
+	stored := []models.Match{
+		{UserID: 1, ProductID: 1, MatchScore: 80, Status: models.MatchStatusEligible},
+		{UserID: 1, ProductID: 2, MatchScore: 70, Status: models.MatchStatusNotified},
+		{UserID: 1, ProductID: 3, MatchScore: 60, Status: models.MatchStatusEligible},
+		{UserID: 2, ProductID: 1, MatchScore: 50, Status: models.MatchStatusExpired}, // not current
+	}
+	replayed := []*models.MatchCreate{
+		{UserID: 1, ProductID: 1, MatchScore: 65},
+		{UserID: 1, ProductID: 2, MatchScore: 75},
+		{UserID: 2, ProductID: 1, MatchScore: 55},
+	}
+
+	report := matcher.DiffBacktest(stored, replayed)
+
+	require.Len(t, report.Changes, 4)
+	assert.Equal(t, 3, report.StoredMatches)
+	assert.Equal(t, 3, report.ReplayedMatches)
+	assert.Equal(t, 1, report.Added)
+	assert.Equal(t, 1, report.Dropped)
+	assert.Equal(t, 2, report.RankChanged)
+	assert.InDelta(t, -5.0, report.AvgScoreDelta, 0.001) // (-15 + 5) / 2
+
+	// Changes are ordered by user, then product
+	assert.Equal(t, models.BacktestChange{
+		UserID: 1, ProductID: 1, ChangeType: models.BacktestChangeRank,
+		OldScore: floatPtr(80.0), NewScore: floatPtr(65.0), OldRank: 1, NewRank: 2,
+	}, report.Changes[0])
+	assert.Equal(t, models.BacktestChangeRank, report.Changes[1].ChangeType)
+	assert.Equal(t, 1, report.Changes[1].NewRank)
+	assert.Equal(t, models.BacktestChange{
+		UserID: 1, ProductID: 3, ChangeType: models.BacktestChangeDropped,
+		OldScore: floatPtr(60.0), OldRank: 3,
+	}, report.Changes[2])
+	assert.Equal(t, models.BacktestChangeAdded, report.Changes[3].ChangeType)
+}
+
+func TestDiffBacktest_NoChanges(t *testing.T) {
+	stored := []models.Match{{UserID: 1, ProductID: 1, MatchScore: 80, Status: models.MatchStatusEligible}}
+	replayed := []*models.MatchCreate{{UserID: 1, ProductID: 1, MatchScore: 82}}
+
+	report := matcher.DiffBacktest(stored, replayed)
+
+	assert.Empty(t, report.Changes)
+	assert.InDelta(t, 2.0, report.AvgScoreDelta, 0.001)
+}
+
+func TestNewScoreDistribution(t *testing.T) {
+	d := models.NewScoreDistribution([]float64{95, 40, 100, 45, 62})
+
+	assert.Equal(t, 5, d.Count)
+	assert.InDelta(t, 68.4, d.Mean, 0.001)
+	assert.Equal(t, 62.0, d.Median)
+	assert.Equal(t, [10]int{0, 0, 0, 0, 2, 0, 1, 0, 0, 2}, d.Buckets)
+
+	assert.Equal(t, models.ScoreDistribution{}, models.NewScoreDistribution(nil))
+}
+
+func TestDiffBacktest_PendingReviewIsCurrent(t *testing.T) {
+	stored := []models.Match{
+		{UserID: 1, ProductID: 1, MatchScore: 70, Status: models.MatchStatusPendingReview, MatchSource: models.MatchSourceLLMCheck},
+		{UserID: 1, ProductID: 2, MatchScore: 60, Status: models.MatchStatusNotEligible, MatchSource: models.MatchSourceManual},
+	}
+
+	report := matcher.DiffBacktest(stored, nil)
+
+	assert.Equal(t, 1, report.StoredMatches)
+	require.Len(t, report.Changes, 1)
+	assert.Equal(t, models.BacktestChangeDropped, report.Changes[0].ChangeType)
+	assert.Equal(t, int64(1), report.Changes[0].ProductID)
+}
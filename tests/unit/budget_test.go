@@ -0,0 +1,85 @@
+package unit_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"loan-eligibility-engine/internal/services/matcher"
+)
+
+func candidate(userID, productID int64, score float64) *matcher.MatchCandidate {
+	return &matcher.MatchCandidate{UserID: userID, ProductID: productID, EligibilityScore: score}
+}
+
+func pairs(candidates []*matcher.MatchCandidate) [][2]int64 {
+	out := make([][2]int64, len(candidates))
+	for i, c := range candidates {
+		out[i] = [2]int64{c.UserID, c.ProductID}
+	}
+	return out
+}
+
+func TestAllocateLLMBudget_UnderBudgetSelectsAll(t *testing.T) {
+	candidates := []*matcher.MatchCandidate{candidate(1, 1, 50), candidate(1, 2, 90)}
+
+	selected, unreviewed := matcher.AllocateLLMBudget(candidates, 3, 10)
+
+	assert.Equal(t, [][2]int64{{1, 2}, {1, 1}}, pairs(selected))
+	assert.Empty(t, unreviewed)
+}
+
+func TestAllocateLLMBudget_GuaranteesTopKPerUser(t *testing.T) {
+	// User 1 has four high scoring candidates, user 2 has two low scoring ones
+	candidates := []*matcher.MatchCandidate{
+		candidate(1, 1, 95), candidate(1, 2, 94), candidate(1, 3, 93), candidate(1, 4, 92),
+		candidate(2, 1, 40), candidate(2, 2, 30),
+	}
+
+	selected, unreviewed := matcher.AllocateLLMBudget(candidates, 1, 4)
+
+	// User 2's best candidate is guaranteed; the rest of the budget goes by score
+	assert.Equal(t, [][2]int64{{1, 1}, {1, 2}, {1, 3}, {2, 1}}, pairs(selected))
+	assert.Equal(t, [][2]int64{{1, 4}, {2, 2}}, pairs(unreviewed))
+}
+
+func TestAllocateLLMBudget_ServesFirstChoicesBeforeSecond(t *testing.T) {
+	// Budget is smaller than users * topK
+	candidates := []*matcher.MatchCandidate{
+		candidate(1, 1, 90), candidate(1, 2, 85),
+		candidate(2, 1, 60), candidate(2, 2, 55),
+		candidate(3, 1, 70), candidate(3, 2, 65),
+	}
+
+	selected, unreviewed := matcher.AllocateLLMBudget(candidates, 2, 4)
+
+	assert.Equal(t, [][2]int64{{1, 1}, {1, 2}, {3, 1}, {2, 1}}, pairs(selected))
+	assert.Equal(t, [][2]int64{{3, 2}, {2, 2}}, pairs(unreviewed))
+}
+
+func TestAllocateLLMBudget_NoBudgetMeansUnlimited(t *testing.T) {
+	candidates := []*matcher.MatchCandidate{candidate(1, 1, 10), candidate(2, 1, 20), candidate(3, 1, 30)}
+
+	selected, unreviewed := matcher.AllocateLLMBudget(candidates, 1, 0)
+
+	assert.Len(t, selected, 3)
+	assert.Empty(t, unreviewed)
+}
+
+func TestChunkLLMBudget_SplitsRemainingAcrossChunks(t *testing.T) {
+	// 100 calls over 10 chunks of 1000 users
+	assert.Equal(t, 10, matcher.ChunkLLMBudget(100, 0, 1000, 10000))
+	// Unspent calls roll over to the remaining chunks
+	assert.Equal(t, 12, matcher.ChunkLLMBudget(100, 10, 1000, 7500))
+	// Rounds up so a small chunk still gets a call
+	assert.Equal(t, 1, matcher.ChunkLLMBudget(100, 0, 1, 200000))
+}
+
+func TestChunkLLMBudget_LastChunkGetsTheRest(t *testing.T) {
+	assert.Equal(t, 30, matcher.ChunkLLMBudget(100, 70, 500, 500))
+}
+
+func TestChunkLLMBudget_ExhaustedAndUnlimited(t *testing.T) {
+	assert.Equal(t, 0, matcher.ChunkLLMBudget(100, 100, 1000, 5000))
+	assert.Equal(t, -1, matcher.ChunkLLMBudget(0, 0, 1000, 5000))
+}
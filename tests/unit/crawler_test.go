@@ -0,0 +1,475 @@
+package unit_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"loan-eligibility-engine/internal/models"
+	"loan-eligibility-engine/internal/services/crawler"
+)
+
+// crawlerFixture reads a saved page from testdata/crawler
+func crawlerFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "crawler", name))
+	require.NoError(t, err)
+	return data
+}
+
+// crawlerSite serves the given paths with fixed bodies, answering 404 for
+// anything else, and records the requested paths and user agents
+type crawlerSite struct {
+	*httptest.Server
+	mu         sync.Mutex
+	requests   []string
+	userAgents []string
+}
+
+func newCrawlerSite(t *testing.T, pages map[string]string) *crawlerSite {
+	site := &crawlerSite{}
+	site.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		site.mu.Lock()
+		site.requests = append(site.requests, r.URL.Path)
+		site.userAgents = append(site.userAgents, r.UserAgent())
+		site.mu.Unlock()
+
+		body, ok := pages[r.URL.Path]
+		switch {
+		case !ok:
+			http.NotFound(w, r)
+		case strings.HasPrefix(body, "redirect:"):
+			http.Redirect(w, r, strings.TrimPrefix(body, "redirect:"), http.StatusFound)
+		case strings.HasPrefix(body, "status:500"):
+			http.Error(w, "unavailable", http.StatusInternalServerError)
+		default:
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte(body))
+		}
+	}))
+	t.Cleanup(site.Close)
+	return site
+}
+
+func (s *crawlerSite) paths() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// bankProductRules are extraction rules for testdata/crawler/bank_products.html
+func bankProductRules() models.ExtractionRules {
+	field := func(selector, regex string) models.FieldRule {
+		return models.FieldRule{Selector: selector, Regex: regex}
+	}
+	return models.ExtractionRules{
+		Products: ".product-card",
+		Fields: map[string]models.FieldRule{
+			"product_name":               field("h2.name", ""),
+			"interest_rate_min":          field(".rate", `(\d+(?:\.\d+)?)%`),
+			"interest_rate_max":          field(".rate", `–\s*(\d+(?:\.\d+)?)%`),
+			"loan_amount_min":            field(".amount", `from\s*₹?(\d[\d,.]*(?:\s*(?:lakh|crore))?)`),
+			"loan_amount_max":            field(".amount > b", ""),
+			"tenure_min_months":          field(".tenure", `(\d+) to`),
+			"tenure_max_months":          field(".tenure", `to (\d+) months`),
+			"min_monthly_income":         field(".eligibility", `salary\s*₹?([\d,]+)`),
+			"min_credit_score":           field(".eligibility", `CIBIL (\d+)`),
+			"min_age":                    field(".eligibility", `Age (\d+)-`),
+			"max_age":                    field(".eligibility", `Age \d+-(\d+)`),
+			"accepted_employment_status": field(".who", ""),
+			"processing_fee_percent":     field(".fee", `([\d.]+)%`),
+		},
+		RateSlabs: []models.RateSlabRule{
+			{MinCreditScore: 700, MaxCreditScore: 749, InterestRate: field(`table.rate-card tr:contains("700 - 749") > td + td`, "")},
+			{MinCreditScore: 750, MaxCreditScore: 799, InterestRate: field(`table.rate-card tr:contains("750 - 799") > td + td`, "")},
+			{MinCreditScore: 800, MaxCreditScore: 900, InterestRate: field(`table.rate-card tr:contains("800 and above") > td + td`, "")},
+		},
+	}
+}
+
+func testCrawlerSource(url string, rules models.ExtractionRules) *models.CrawlerSource {
+	return &models.CrawlerSource{
+		ID:           1,
+		Name:         "example-bank",
+		URL:          url,
+		ProviderName: "Example Bank",
+		ProductType:  models.LoanProductTypePersonal,
+		Currency:     models.CurrencyINR,
+		Rules:        rules,
+		IsActive:     true,
+	}
+}
+
+// fastFetcher is a fetcher without a rate limit
+func fastFetcher() *crawler.Fetcher {
+	return crawler.NewFetcher(crawler.FetcherOptions{UserAgent: "TestBot/1.0", Timeout: 5 * time.Second})
+}
+
+func TestParseHTML_IgnoresScriptsAndComments(t *testing.T) {
+	doc, err := crawler.ParseHTML(string(crawlerFixture(t, "bank_products.html")))
+	require.NoError(t, err)
+
+	sel, err := crawler.CompileSelector(".product-card")
+	require.NoError(t, err)
+	cards := sel.MatchAll(doc)
+	require.Len(t, cards, 3)
+	id, _ := crawler.Attr(cards[0], "id")
+	assert.Equal(t, "flexi", id)
+
+	// Unclosed list items end at the next item
+	sel, err = crawler.CompileSelector("nav li")
+	require.NoError(t, err)
+	var items []string
+	for _, n := range sel.MatchAll(doc) {
+		items = append(items, crawler.Text(n))
+	}
+	assert.Equal(t, []string{"Home", "Loans", "Contact"}, items)
+
+	assert.NotContains(t, crawler.Text(doc), "dataLayer")
+	assert.NotContains(t, crawler.Text(doc), "Commented out")
+}
+
+func TestSelector_Matches(t *testing.T) {
+	doc, err := crawler.ParseHTML(`
+		<div id="main" class="page loans">
+			<h2 class="title">Personal</h2>
+			<p data-rate="10.5">First</p>
+			<p class="note">Second <a href="/apply/now">Apply</a></p>
+			<section><p>Nested</p></section>
+		</div>
+		<p class="note">Outside</p>`)
+	require.NoError(t, err)
+
+	tests := []struct {
+		selector string
+		want     []string
+	}{
+		{"#main > p", []string{"First", "Second Apply"}},
+		{"div p", []string{"First", "Second Apply", "Nested"}},
+		{".page.loans .title", []string{"Personal"}},
+		{"p.note", []string{"Second Apply", "Outside"}},
+		{"h2 + p", []string{"First"}},
+		{"[data-rate]", []string{"First"}},
+		{`p[data-rate="10.5"]`, []string{"First"}},
+		{`a[href^="/apply"]`, []string{"Apply"}},
+		{`a[href$=now]`, []string{"Apply"}},
+		{`a[href*="ply/n"]`, []string{"Apply"}},
+		{`div[class~=loans] > h2`, []string{"Personal"}},
+		{"p:contains(SECOND)", []string{"Second Apply"}},
+		{`p:contains("second apply")`, []string{"Second Apply"}},
+		{"#main > :first-child", []string{"Personal"}},
+		{"h2, section p", []string{"Personal", "Nested"}},
+		{"table", nil},
+	}
+	for _, tt := range tests {
+		sel, err := crawler.CompileSelector(tt.selector)
+		require.NoError(t, err, tt.selector)
+
+		var got []string
+		for _, n := range sel.MatchAll(doc) {
+			got = append(got, crawler.Text(n))
+		}
+		assert.Equal(t, tt.want, got, tt.selector)
+	}
+}
+
+func TestSelector_Invalid(t *testing.T) {
+	for _, selector := range []string{"", "div >", "p[data-rate", "p::before", "p:contains(", "p:contains(750)"} {
+		_, err := crawler.CompileSelector(selector)
+		assert.Error(t, err, "selector %q", selector)
+	}
+}
+
+func TestParseNumber(t *testing.T) {
+	tests := map[string]float64{
+		"10.50% p.a.":     10.5,
+		"₹40,00,000":      4000000,
+		"Rs. 40 lakh":     4000000,
+		"up to 1.2 crore": 12000000,
+		"2 Cr":            20000000,
+		"50K":             50000,
+		"5 lacs":          500000,
+		".75%":            0.75,
+	}
+	for text, want := range tests {
+		got, err := crawler.ParseNumber(text)
+		require.NoError(t, err, text)
+		assert.InDelta(t, want, got, 0.0001, text)
+	}
+
+	_, err := crawler.ParseNumber("Rates announced soon")
+	assert.Error(t, err)
+}
+
+func TestRules_ExtractsProductsAndRateSlabs(t *testing.T) {
+	rules, err := crawler.CompileRules(bankProductRules())
+	require.NoError(t, err)
+
+	source := testCrawlerSource("https://bank.example/loans", bankProductRules())
+	products, productErrors, err := rules.Extract(source, crawlerFixture(t, "bank_products.html"))
+	require.NoError(t, err)
+	require.Len(t, products, 2)
+
+	// The card without rates is reported, not saved
+	require.Len(t, productErrors, 1)
+	assert.Contains(t, productErrors[0].Error(), "product 3")
+	assert.Contains(t, productErrors[0].Error(), "interest_rate_min")
+
+	flexi := products[0]
+	assert.Equal(t, "Flexi Personal Loan", flexi.ProductName)
+	assert.Equal(t, "Example Bank", flexi.ProviderName)
+	assert.Equal(t, models.LoanProductTypePersonal, flexi.ProductType)
+	assert.Equal(t, models.CurrencyINR, flexi.Currency)
+	assert.Equal(t, "https://bank.example/loans", flexi.SourceURL)
+	assert.Equal(t, 10.5, flexi.InterestRateMin)
+	assert.Equal(t, 21.0, flexi.InterestRateMax)
+	assert.Equal(t, 50000.0, flexi.LoanAmountMin)
+	assert.Equal(t, 4000000.0, flexi.LoanAmountMax)
+	assert.Equal(t, 12, flexi.TenureMinMonths)
+	assert.Equal(t, 60, flexi.TenureMaxMonths)
+	assert.Equal(t, 25000.0, flexi.MinMonthlyIncome)
+	assert.Equal(t, 700, flexi.MinCreditScore)
+	assert.Equal(t, 21, flexi.MinAge)
+	assert.Equal(t, 60, flexi.MaxAge)
+	assert.Equal(t, []models.EmploymentStatus{models.EmploymentStatusEmployed, models.EmploymentStatusSelfEmployed},
+		flexi.AcceptedEmploymentStatus)
+	require.NotNil(t, flexi.ProcessingFeePercent)
+	assert.Equal(t, 2.5, *flexi.ProcessingFeePercent)
+
+	require.Len(t, flexi.RateSlabs, 3)
+	assert.Equal(t, 700, flexi.RateSlabs[0].MinCreditScore)
+	assert.Equal(t, 16.5, flexi.RateSlabs[0].InterestRate)
+	assert.Equal(t, 13.25, flexi.RateSlabs[1].InterestRate)
+	assert.Equal(t, 900, flexi.RateSlabs[2].MaxCreditScore)
+	assert.Equal(t, 11.5, flexi.RateSlabs[2].InterestRate)
+
+	express := products[1]
+	assert.Equal(t, "Express Personal Loan", express.ProductName)
+	assert.Equal(t, 100000.0, express.LoanAmountMin)
+	assert.Equal(t, 12000000.0, express.LoanAmountMax)
+	assert.Equal(t, 6, express.TenureMinMonths)
+	assert.Equal(t, []models.EmploymentStatus{
+		models.EmploymentStatusEmployed, models.EmploymentStatusSelfEmployed, models.EmploymentStatusRetired,
+	}, express.AcceptedEmploymentStatus)
+	assert.Equal(t, 14.5, express.RateSlabs[0].InterestRate)
+}
+
+func TestRules_WholePageWithDefaults(t *testing.T) {
+	rules := models.ExtractionRules{Fields: map[string]models.FieldRule{
+		"interest_rate_min":          {Regex: `(?i)starting at (\d+(?:\.\d+)?)%`},
+		"loan_amount_min":            {Default: "10000"},
+		"loan_amount_max":            {Regex: `(?i)up to (Rs\.\s*\d+ lakh)`},
+		"tenure_min_months":          {Selector: ".features li:first-child", Regex: `from (\d+) months`},
+		"tenure_max_months":          {Selector: ".features li", Regex: `to (\d+) months`},
+		"min_monthly_income":         {Default: "15000"},
+		"min_credit_score":           {Default: "650"},
+		"min_age":                    {Default: "21"},
+		"max_age":                    {Default: "65"},
+		"accepted_employment_status": {Selector: `.features li:contains("open to")`, Regex: `(?i)open to (.+)`},
+	}}
+	compiled, err := crawler.CompileRules(rules)
+	require.NoError(t, err)
+
+	source := testCrawlerSource("https://bank.example/business", rules)
+	source.ProductType = models.LoanProductTypeBusiness
+	products, productErrors, err := compiled.Extract(source, crawlerFixture(t, "single_product.html"))
+	require.NoError(t, err)
+	require.Empty(t, productErrors)
+	require.Len(t, products, 1)
+
+	product := products[0]
+	assert.Equal(t, "Example Bank Business Loan", product.ProductName, "named after the provider and type")
+	assert.Equal(t, 9.25, product.InterestRateMin)
+	assert.Equal(t, 9.25, product.InterestRateMax, "maximum rate defaults to the minimum")
+	assert.Equal(t, 10000.0, product.LoanAmountMin)
+	assert.Equal(t, 2500000.0, product.LoanAmountMax)
+	assert.Equal(t, 3, product.TenureMinMonths)
+	assert.Equal(t, 36, product.TenureMaxMonths)
+	assert.Equal(t, []models.EmploymentStatus{models.EmploymentStatusEmployed, models.EmploymentStatusSelfEmployed},
+		product.AcceptedEmploymentStatus)
+	assert.Empty(t, product.RateSlabs)
+}
+
+func TestCompileRules_Invalid(t *testing.T) {
+	tests := map[string]models.ExtractionRules{
+		"no fields":        {},
+		"unknown field":    {Fields: map[string]models.FieldRule{"apr": {Default: "12"}}},
+		"empty rule":       {Fields: map[string]models.FieldRule{"min_age": {}}},
+		"bad selector":     {Fields: map[string]models.FieldRule{"min_age": {Selector: "p["}}},
+		"bad regex":        {Fields: map[string]models.FieldRule{"min_age": {Regex: "(\\d+"}}},
+		"missing group":    {Fields: map[string]models.FieldRule{"min_age": {Regex: "(\\d+)", Group: 2}}},
+		"bad products":     {Products: "div >", Fields: map[string]models.FieldRule{"min_age": {Default: "21"}}},
+		"bad slab":         {Fields: map[string]models.FieldRule{"min_age": {Default: "21"}}, RateSlabs: []models.RateSlabRule{{}}},
+		"negative scaling": {Fields: map[string]models.FieldRule{"min_age": {Default: "21", Scale: -1}}},
+	}
+	for name, rules := range tests {
+		_, err := crawler.CompileRules(rules)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestFetcher_RobotsGroupsAndPatterns(t *testing.T) {
+	site := newCrawlerSite(t, map[string]string{
+		"/robots.txt": `
+# Example rules
+User-agent: *
+Disallow: /
+
+User-agent: TestBot
+Disallow: /private/
+Allow: /private/rates
+Disallow: /*.pdf$
+Disallow: /search
+`,
+		"/loans":              "<p>Loans</p>",
+		"/private/page":       "<p>Secret</p>",
+		"/private/rates":      "<p>Rates</p>",
+		"/docs/terms.pdf":     "%PDF",
+		"/docs/terms.pdf.htm": "<p>Terms</p>",
+		"/search":             "<p>Search</p>",
+	})
+	fetcher := fastFetcher()
+	ctx := context.Background()
+
+	// The group naming the crawler applies, not the * group
+	_, err := fetcher.Fetch(ctx, site.URL+"/loans")
+	assert.NoError(t, err)
+
+	// Longest match wins
+	_, err = fetcher.Fetch(ctx, site.URL+"/private/page")
+	assert.ErrorIs(t, err, crawler.ErrDisallowed)
+	_, err = fetcher.Fetch(ctx, site.URL+"/private/rates")
+	assert.NoError(t, err)
+
+	// Wildcards, end anchors and queries
+	_, err = fetcher.Fetch(ctx, site.URL+"/docs/terms.pdf")
+	assert.ErrorIs(t, err, crawler.ErrDisallowed)
+	_, err = fetcher.Fetch(ctx, site.URL+"/docs/terms.pdf.htm")
+	assert.NoError(t, err)
+	_, err = fetcher.Fetch(ctx, site.URL+"/search?q=loan")
+	assert.ErrorIs(t, err, crawler.ErrDisallowed)
+}
+
+func TestFetcher_ObeysRobots(t *testing.T) {
+	site := newCrawlerSite(t, map[string]string{
+		"/robots.txt":     "User-agent: *\nDisallow: /private\n",
+		"/loans":          "<p>Loans</p>",
+		"/private/rates":  "<p>Secret</p>",
+		"/moved":          "redirect:/private/rates",
+		"/internal-error": "status:500",
+	})
+	fetcher := fastFetcher()
+	ctx := context.Background()
+
+	body, err := fetcher.Fetch(ctx, site.URL+"/loans")
+	require.NoError(t, err)
+	assert.Equal(t, "<p>Loans</p>", string(body))
+
+	_, err = fetcher.Fetch(ctx, site.URL+"/private/rates")
+	assert.ErrorIs(t, err, crawler.ErrDisallowed)
+
+	// A redirect into a disallowed path is not followed
+	_, err = fetcher.Fetch(ctx, site.URL+"/moved")
+	assert.ErrorIs(t, err, crawler.ErrDisallowed)
+
+	_, err = fetcher.Fetch(ctx, site.URL+"/internal-error")
+	assert.ErrorContains(t, err, "500")
+	_, err = fetcher.Fetch(ctx, site.URL+"/missing")
+	assert.ErrorContains(t, err, "404")
+
+	// robots.txt is read once per host, and every request identifies the crawler
+	assert.Equal(t, []string{"/robots.txt", "/loans", "/moved", "/internal-error", "/missing"}, site.paths())
+	for _, userAgent := range site.userAgents {
+		assert.Equal(t, "TestBot/1.0", userAgent)
+	}
+}
+
+func TestFetcher_RobotsErrors(t *testing.T) {
+	ctx := context.Background()
+
+	// No robots.txt allows everything
+	site := newCrawlerSite(t, map[string]string{"/loans": "<p>Loans</p>"})
+	_, err := fastFetcher().Fetch(ctx, site.URL+"/loans")
+	assert.NoError(t, err)
+
+	// A robots.txt the server fails to serve puts the host off limits
+	broken := newCrawlerSite(t, map[string]string{"/robots.txt": "status:500", "/loans": "<p>Loans</p>"})
+	_, err = fastFetcher().Fetch(ctx, broken.URL+"/loans")
+	assert.ErrorContains(t, err, "robots.txt")
+	assert.Equal(t, []string{"/robots.txt"}, broken.paths())
+}
+
+func TestFetcher_LimitsPageSize(t *testing.T) {
+	site := newCrawlerSite(t, map[string]string{"/big": strings.Repeat("x", 2048)})
+	fetcher := crawler.NewFetcher(crawler.FetcherOptions{Timeout: 5 * time.Second, MaxPageBytes: 1024})
+
+	_, err := fetcher.Fetch(context.Background(), site.URL+"/big")
+	assert.ErrorContains(t, err, "larger than 1024 bytes")
+}
+
+func TestFetcher_RateLimitsPerHost(t *testing.T) {
+	site := newCrawlerSite(t, map[string]string{"/a": "a", "/b": "b", "/c": "c"})
+	fetcher := crawler.NewFetcher(crawler.FetcherOptions{Timeout: 5 * time.Second, RequestsPerMinute: 1200})
+
+	start := time.Now()
+	for _, path := range []string{"/a", "/b", "/c"} {
+		_, err := fetcher.Fetch(context.Background(), site.URL+path)
+		require.NoError(t, err)
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond, "requests are spaced 50ms apart")
+}
+
+func TestFetcher_HonoursCrawlDelay(t *testing.T) {
+	site := newCrawlerSite(t, map[string]string{
+		"/robots.txt": "User-agent: *\nCrawl-delay: 0.1\n",
+		"/a":          "a",
+		"/b":          "b",
+	})
+	fetcher := fastFetcher()
+
+	start := time.Now()
+	for _, path := range []string{"/a", "/b"} {
+		_, err := fetcher.Fetch(context.Background(), site.URL+path)
+		require.NoError(t, err)
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond, "an unlimited fetcher still waits the crawl delay")
+
+	// The wait gives up when the context ends
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := fetcher.Fetch(ctx, site.URL+"/a")
+	assert.Error(t, err)
+}
+
+func TestCrawl_FetchesAndExtracts(t *testing.T) {
+	site := newCrawlerSite(t, map[string]string{
+		"/personal-loans": string(crawlerFixture(t, "bank_products.html")),
+		"/empty":          "<html><body><p>Nothing here</p></body></html>",
+	})
+	ctx := context.Background()
+	fetcher := fastFetcher()
+
+	source := testCrawlerSource(site.URL+"/personal-loans", bankProductRules())
+	products, productErrors, err := crawler.Crawl(ctx, fetcher, source)
+	require.NoError(t, err)
+	assert.Len(t, products, 2)
+	assert.Len(t, productErrors, 1)
+
+	// A page without the product elements fails the source
+	source.URL = site.URL + "/empty"
+	_, _, err = crawler.Crawl(ctx, fetcher, source)
+	assert.ErrorContains(t, err, "no elements match")
+
+	source.Rules = models.ExtractionRules{Fields: map[string]models.FieldRule{"min_age": {Selector: "p["}}}
+	_, _, err = crawler.Crawl(ctx, fetcher, source)
+	assert.ErrorContains(t, err, "invalid extraction rules")
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"loan-eligibility-engine/internal/models"
 	"loan-eligibility-engine/internal/utils"
 )
 
@@ -45,6 +46,68 @@ USR001,rahul@example.com,50000,750,employed,30`
 	assert.Equal(t, "rahul@example.com", users[0].Email)
 }
 
+func TestCSVParser_OptionalObligations(t *testing.T) {
+	csvContent := `user_id,email,monthly_income,credit_score,employment_status,age,current_emi,cc_outstanding,num_loans
+USR001,rahul@example.com,50000,750,employed,30,"12,000",40000,2
+USR002,priya@example.com,60000,720,self_employed,28,,,`
+
+	parser := utils.NewCSVParser()
+	users, errors := parser.ParseUsers(csvContent, "batch-123")
+
+	require.Empty(t, errors, "Expected no parse errors")
+	require.Len(t, users, 2, "Expected 2 users")
+
+	assert.Equal(t, 12000.0, users[0].ExistingEMI)
+	assert.Equal(t, 40000.0, users[0].CreditCardOutstanding)
+	assert.Equal(t, 2, users[0].ActiveLoans)
+
+	// Empty cells, like missing columns, mean no obligations
+	assert.Zero(t, users[1].ExistingEMI)
+	assert.Zero(t, users[1].ActiveLoans)
+}
+
+func TestCSVParser_NegativeObligationsRejected(t *testing.T) {
+	csvContent := `user_id,email,monthly_income,credit_score,employment_status,age,existing_emi
+USR001,rahul@example.com,50000,750,employed,30,-500`
+
+	parser := utils.NewCSVParser()
+	users, errors := parser.ParseUsers(csvContent, "batch-123")
+
+	assert.Empty(t, users)
+	assert.NotEmpty(t, errors)
+}
+
+func TestCSVParser_RequestedTerms(t *testing.T) {
+	csvContent := `user_id,email,monthly_income,credit_score,employment_status,age,loan_amount,tenure,purpose
+USR001,rahul@example.com,50000,750,employed,30,"5,00,000",36,Car Loan
+USR002,priya@example.com,60000,720,self_employed,28,,,`
+
+	parser := utils.NewCSVParser()
+	users, errors := parser.ParseUsers(csvContent, "batch-123")
+
+	require.Empty(t, errors, "Expected no parse errors")
+	require.Len(t, users, 2, "Expected 2 users")
+
+	assert.Equal(t, 500000.0, users[0].RequestedAmount)
+	assert.Equal(t, 36, users[0].RequestedTenureMonths)
+	assert.Equal(t, models.LoanProductTypeAuto, users[0].LoanPurpose)
+
+	// Users without a request are matched against every product
+	assert.Zero(t, users[1].RequestedAmount)
+	assert.Empty(t, users[1].LoanPurpose)
+}
+
+func TestCSVParser_UnknownLoanPurposeRejected(t *testing.T) {
+	csvContent := `user_id,email,monthly_income,credit_score,employment_status,age,loan_purpose
+USR001,rahul@example.com,50000,750,employed,30,crypto`
+
+	parser := utils.NewCSVParser()
+	users, errors := parser.ParseUsers(csvContent, "batch-123")
+
+	assert.Empty(t, users)
+	assert.NotEmpty(t, errors)
+}
+
 func TestCSVParser_MissingRequiredColumns(t *testing.T) {
 	// Missing credit_score column
 	csvContent := `user_id,email,monthly_income,employment_status,age
@@ -258,3 +321,64 @@ USR001,rahul@example.com,50000`
 	assert.Contains(t, result.MissingColumns, "employment_status")
 	assert.Contains(t, result.MissingColumns, "age")
 }
+
+func TestCSVParser_DetectsCurrency(t *testing.T) {
+	csvContent := `user_id,email,monthly_income,credit_score,employment_status,age,requested_amount,locale
+USR001,rahul@example.com,"₹50,000",750,employed,30,500000,en_in
+USR002,anna@example.com,USD 4200,760,employed,34,$20000,en-US
+USR003,li@example.com,6000 SGD,780,employed,29,,
+USR004,ravi@example.com,45000,720,employed,41,,`
+
+	parser := utils.NewCSVParser()
+	users, errors := parser.ParseUsers(csvContent, "test-batch")
+
+	require.Empty(t, errors, "Expected no parse errors")
+	require.Len(t, users, 4)
+
+	assert.Equal(t, models.CurrencyINR, users[0].Currency)
+	assert.Equal(t, float64(50000), users[0].MonthlyIncome)
+	assert.Equal(t, "en-IN", users[0].Locale)
+
+	assert.Equal(t, models.CurrencyUSD, users[1].Currency)
+	assert.Equal(t, float64(4200), users[1].MonthlyIncome)
+	assert.Equal(t, float64(20000), users[1].RequestedAmount)
+	assert.Equal(t, "en-US", users[1].Locale)
+
+	assert.Equal(t, models.CurrencySGD, users[2].Currency)
+	assert.Equal(t, float64(6000), users[2].MonthlyIncome)
+
+	// No currency given: stored as the default currency
+	assert.Empty(t, users[3].Currency)
+}
+
+func TestCSVParser_CurrencyColumn(t *testing.T) {
+	csvContent := `user_id,email,monthly_income,credit_score,employment_status,age,currency
+USR001,anna@example.com,4200,760,employed,34,eur
+USR002,ben@example.com,£3100,760,employed,34,GBP`
+
+	parser := utils.NewCSVParser()
+	users, errors := parser.ParseUsers(csvContent, "test-batch")
+
+	require.Empty(t, errors, "Expected no parse errors")
+	require.Len(t, users, 2)
+	assert.Equal(t, models.CurrencyEUR, users[0].Currency)
+	assert.Equal(t, models.CurrencyGBP, users[1].Currency)
+}
+
+func TestCSVParser_RejectsMixedCurrencies(t *testing.T) {
+	csvContent := `user_id,email,monthly_income,credit_score,employment_status,age,requested_amount,currency
+USR001,anna@example.com,$4200,760,employed,34,€20000,
+USR002,ben@example.com,£3100,760,employed,34,,INR
+USR003,cara@example.com,3100,760,employed,34,,XYZ
+USR004,dev@example.com,52000,760,employed,34,,INR`
+
+	parser := utils.NewCSVParser()
+	users, errors := parser.ParseUsers(csvContent, "test-batch")
+
+	require.Len(t, users, 1)
+	assert.Equal(t, "USR004", users[0].UserID)
+	require.Len(t, errors, 3)
+	assert.ErrorIs(t, errors[0], utils.ErrMixedCurrency)
+	assert.ErrorIs(t, errors[1], utils.ErrMixedCurrency)
+	assert.Contains(t, errors[2].Error(), "invalid currency")
+}
@@ -0,0 +1,57 @@
+// Package unit_test contains unit tests for the loan eligibility engine
+package unit_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"loan-eligibility-engine/internal/models"
+	"loan-eligibility-engine/internal/utils"
+)
+
+func TestFormatINR(t *testing.T) {
+	cases := map[float64]string{
+		0:          "₹0",
+		950:        "₹950",
+		18000:      "₹18,000",
+		250000:     "₹2,50,000",
+		2500000:    "₹25,00,000",
+		40000000.4: "₹4,00,00,000",
+		-25000:     "-₹25,000",
+	}
+
+	for amount, expected := range cases {
+		assert.Equal(t, expected, utils.FormatINR(amount), "amount %v", amount)
+	}
+}
+
+func TestFormatMoney_Locales(t *testing.T) {
+	assert.Equal(t, "₹12,34,567", utils.FormatMoney(1234567, models.CurrencyINR, "en-IN"))
+	assert.Equal(t, "$1,234,567", utils.FormatMoney(1234567, models.CurrencyUSD, "en-US"))
+	assert.Equal(t, "1.234.567 €", utils.FormatMoney(1234567, models.CurrencyEUR, "de-DE"))
+	assert.Equal(t, "£950", utils.FormatMoney(950, models.CurrencyGBP, "en-GB"))
+	// Rupees in a US locale keep the symbol but drop Indian grouping
+	assert.Equal(t, "₹1,234,567", utils.FormatMoney(1234567, models.CurrencyINR, "en-US"))
+}
+
+func TestFormatMoney_DefaultsToCurrencyLocale(t *testing.T) {
+	assert.Equal(t, "₹25,00,000", utils.FormatMoney(2500000, models.CurrencyINR, ""))
+	assert.Equal(t, "S$2,500,000", utils.FormatMoney(2500000, models.CurrencySGD, "xx-YY"))
+	assert.Equal(t, "AED 4,200", utils.FormatMoney(4200, models.CurrencyAED, ""))
+}
+
+func TestNormalizeLocale(t *testing.T) {
+	locale, ok := utils.NormalizeLocale("en_in")
+	assert.True(t, ok)
+	assert.Equal(t, "en-IN", locale)
+
+	locale, ok = utils.NormalizeLocale("DE-de")
+	assert.True(t, ok)
+	assert.Equal(t, "de-DE", locale)
+
+	_, ok = utils.NormalizeLocale("pt-BR")
+	assert.False(t, ok)
+	_, ok = utils.NormalizeLocale("english")
+	assert.False(t, ok)
+}
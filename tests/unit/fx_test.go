@@ -0,0 +1,104 @@
+package unit_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"loan-eligibility-engine/internal/models"
+	"loan-eligibility-engine/internal/services/fx"
+)
+
+func testFXTable() *fx.Table {
+	return fx.NewTable([]*models.FXRate{
+		{Currency: models.CurrencyINR, RateToBase: 1},
+		{Currency: models.CurrencyUSD, RateToBase: 80},
+		{Currency: models.CurrencyEUR, RateToBase: 90},
+	})
+}
+
+func TestFXTable_Rate(t *testing.T) {
+	rates := testFXTable()
+
+	rate, ok := rates.Rate(models.CurrencyUSD, models.CurrencyINR)
+	require.True(t, ok)
+	assert.Equal(t, 80.0, rate)
+
+	amount, ok := rates.Convert(9000, models.CurrencyINR, models.CurrencyEUR)
+	require.True(t, ok)
+	assert.InDelta(t, 100, amount, 0.0001)
+
+	// An empty currency is the default currency
+	rate, ok = rates.Rate("", models.CurrencyINR)
+	require.True(t, ok)
+	assert.Equal(t, 1.0, rate)
+}
+
+func TestFXTable_MissingRate(t *testing.T) {
+	rates := testFXTable()
+
+	_, ok := rates.Rate(models.CurrencyGBP, models.CurrencyINR)
+	assert.False(t, ok)
+
+	// A nil table only converts between equal currencies
+	var none *fx.Table
+	_, ok = none.Rate(models.CurrencyUSD, models.CurrencyINR)
+	assert.False(t, ok)
+	rate, ok := none.Rate(models.CurrencyUSD, models.CurrencyUSD)
+	assert.True(t, ok)
+	assert.Equal(t, 1.0, rate)
+}
+
+func TestFXTable_UserIn(t *testing.T) {
+	rates := testFXTable()
+	user := mockUser(map[string]interface{}{
+		"monthly_income":   5000.0,
+		"existing_emi":     500.0,
+		"requested_amount": 20000.0,
+	})
+	user.Currency = models.CurrencyUSD
+
+	converted, ok := rates.UserIn(user, models.CurrencyINR)
+	require.True(t, ok)
+	assert.Equal(t, models.CurrencyINR, converted.Currency)
+	assert.Equal(t, 400000.0, converted.MonthlyIncome)
+	assert.Equal(t, 40000.0, converted.ExistingEMI)
+	assert.Equal(t, 1600000.0, converted.RequestedAmount)
+	assert.Equal(t, user.CreditScore, converted.CreditScore)
+
+	// The original user is unchanged
+	assert.Equal(t, 5000.0, user.MonthlyIncome)
+	assert.Equal(t, models.CurrencyUSD, user.Currency)
+
+	same, ok := rates.UserIn(user, models.CurrencyUSD)
+	require.True(t, ok)
+	assert.Same(t, user, same)
+
+	_, ok = rates.UserIn(user, models.CurrencyGBP)
+	assert.False(t, ok)
+}
+
+func TestFXTable_CostIn(t *testing.T) {
+	rates := testFXTable()
+	cost := models.BorrowingCost{
+		Amount:        800000,
+		TenureMonths:  24,
+		InterestRate:  12,
+		EMI:           37658.96,
+		TotalInterest: 103815.04,
+		TotalFees:     16000,
+		TotalCost:     119815.04,
+		APR:           13.9,
+	}
+
+	usd, ok := rates.CostIn(cost, models.CurrencyINR, models.CurrencyUSD)
+	require.True(t, ok)
+	assert.Equal(t, 10000.0, usd.Amount)
+	assert.Equal(t, 470.74, usd.EMI)
+	assert.Equal(t, 200.0, usd.TotalFees)
+	assert.Equal(t, 1497.69, usd.TotalCost)
+	assert.Equal(t, 12.0, usd.InterestRate)
+	assert.Equal(t, 13.9, usd.APR)
+	assert.Equal(t, 24, usd.TenureMonths)
+}
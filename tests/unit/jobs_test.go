@@ -0,0 +1,212 @@
+package unit_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"loan-eligibility-engine/internal/models"
+	"loan-eligibility-engine/internal/services/database"
+	"loan-eligibility-engine/internal/services/jobs"
+	"loan-eligibility-engine/internal/utils"
+)
+
+// fakeJobStore hands out one job and records how it finished
+type fakeJobStore struct {
+	mu           sync.Mutex
+	job          *models.Job
+	claimedTypes []models.JobType
+	heartbeat    func() (bool, error)
+
+	completed bool
+	result    json.RawMessage
+	failed    bool
+	failMsg   string
+	retryAt   *time.Time
+	cancelled bool
+	done      int
+	total     int
+}
+
+func (f *fakeJobStore) Claim(ctx context.Context, workerID string, types []models.JobType, lease time.Duration) (*models.Job, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.claimedTypes = types
+	job := f.job
+	f.job = nil
+	return job, nil
+}
+
+func (f *fakeJobStore) Heartbeat(ctx context.Context, id int64, workerID string, lease time.Duration, done, total int) (bool, error) {
+	if f.heartbeat == nil {
+		return false, nil
+	}
+	return f.heartbeat()
+}
+
+func (f *fakeJobStore) Complete(ctx context.Context, id int64, workerID string, result json.RawMessage, done, total int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.completed, f.result, f.done, f.total = true, result, done, total
+	return nil
+}
+
+func (f *fakeJobStore) Fail(ctx context.Context, id int64, workerID, errMsg string, retryAt *time.Time) (models.JobStatus, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failed, f.failMsg, f.retryAt = true, errMsg, retryAt
+	if retryAt != nil {
+		return models.JobStatusQueued, nil
+	}
+	return models.JobStatusFailed, nil
+}
+
+func (f *fakeJobStore) MarkCancelled(ctx context.Context, id int64, workerID string, done, total int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.cancelled, f.done, f.total = true, done, total
+	return nil
+}
+
+func newTestRunner(t *testing.T, store *fakeJobStore) *jobs.Runner {
+	t.Helper()
+	require.NoError(t, utils.InitLogger("error"))
+	return jobs.NewRunner(store, "test", jobs.Options{
+		Lease:        30 * time.Millisecond,
+		RetryBackoff: time.Minute,
+	})
+}
+
+func TestRunner_CompletesJobWithResultAndProgress(t *testing.T) {
+	store := &fakeJobStore{job: &models.Job{ID: 1, Type: models.JobTypeIngest, Attempts: 1}}
+	runner := newTestRunner(t, store)
+	runner.Handle(models.JobTypeIngest, func(ctx context.Context, job *models.Job, progress jobs.ProgressFunc) (interface{}, error) {
+		progress(10, 10)
+		return map[string]int{"saved_users": 10}, nil
+	})
+
+	ran, err := runner.RunOnce(context.Background())
+
+	require.NoError(t, err)
+	assert.True(t, ran)
+	assert.Equal(t, []models.JobType{models.JobTypeIngest}, store.claimedTypes)
+	assert.True(t, store.completed)
+	assert.JSONEq(t, `{"saved_users": 10}`, string(store.result))
+	assert.Equal(t, 10, store.done)
+	assert.Equal(t, 10, store.total)
+}
+
+func TestRunner_NoRunnableJob(t *testing.T) {
+	store := &fakeJobStore{}
+	runner := newTestRunner(t, store)
+	runner.Handle(models.JobTypeMatch, func(ctx context.Context, job *models.Job, progress jobs.ProgressFunc) (interface{}, error) {
+		t.Fatal("handler must not run")
+		return nil, nil
+	})
+
+	ran, err := runner.RunOnce(context.Background())
+
+	require.NoError(t, err)
+	assert.False(t, ran)
+}
+
+func TestRunner_RetriesWithBackoff(t *testing.T) {
+	store := &fakeJobStore{job: &models.Job{ID: 1, Type: models.JobTypeMatch, Attempts: 3}}
+	runner := newTestRunner(t, store)
+	runner.Handle(models.JobTypeMatch, func(ctx context.Context, job *models.Job, progress jobs.ProgressFunc) (interface{}, error) {
+		return nil, errors.New("database unavailable")
+	})
+
+	before := time.Now()
+	_, err := runner.RunOnce(context.Background())
+
+	require.NoError(t, err)
+	assert.True(t, store.failed)
+	assert.Equal(t, "database unavailable", store.failMsg)
+	require.NotNil(t, store.retryAt)
+	// Third attempt: one minute doubled twice
+	assert.WithinDuration(t, before.Add(4*time.Minute), *store.retryAt, 5*time.Second)
+}
+
+func TestRunner_PermanentErrorIsNotRetried(t *testing.T) {
+	store := &fakeJobStore{job: &models.Job{ID: 1, Type: models.JobTypeNotify, Attempts: 1}}
+	runner := newTestRunner(t, store)
+	runner.Handle(models.JobTypeNotify, func(ctx context.Context, job *models.Job, progress jobs.ProgressFunc) (interface{}, error) {
+		return nil, jobs.Permanent(errors.New("no matches found"))
+	})
+
+	_, err := runner.RunOnce(context.Background())
+
+	require.NoError(t, err)
+	assert.True(t, store.failed)
+	assert.Nil(t, store.retryAt)
+}
+
+func TestIsPermanent(t *testing.T) {
+	err := jobs.Permanent(errors.New("invalid payload"))
+
+	assert.True(t, jobs.IsPermanent(err))
+	assert.True(t, jobs.IsPermanent(fmt.Errorf("ingest: %w", err)), "wrapped")
+	assert.False(t, jobs.IsPermanent(errors.New("database unavailable")))
+}
+
+func TestRunner_PanicFailsJob(t *testing.T) {
+	store := &fakeJobStore{job: &models.Job{ID: 1, Type: models.JobTypeNotify, Attempts: 1}}
+	runner := newTestRunner(t, store)
+	runner.Handle(models.JobTypeNotify, func(ctx context.Context, job *models.Job, progress jobs.ProgressFunc) (interface{}, error) {
+		panic("boom")
+	})
+
+	_, err := runner.RunOnce(context.Background())
+
+	require.NoError(t, err)
+	assert.True(t, store.failed)
+	assert.Contains(t, store.failMsg, "boom")
+}
+
+func TestRunner_CancellationStopsHandler(t *testing.T) {
+	store := &fakeJobStore{
+		job:       &models.Job{ID: 1, Type: models.JobTypeIngest, Attempts: 1},
+		heartbeat: func() (bool, error) { return true, nil },
+	}
+	runner := newTestRunner(t, store)
+	runner.Handle(models.JobTypeIngest, func(ctx context.Context, job *models.Job, progress jobs.ProgressFunc) (interface{}, error) {
+		progress(3, 10)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	_, err := runner.RunOnce(context.Background())
+
+	require.NoError(t, err)
+	assert.True(t, store.cancelled)
+	assert.False(t, store.failed)
+	assert.Equal(t, 3, store.done)
+	assert.Equal(t, 10, store.total)
+}
+
+func TestRunner_LostLeaseLeavesJobAlone(t *testing.T) {
+	store := &fakeJobStore{
+		job:       &models.Job{ID: 1, Type: models.JobTypeMatch, Attempts: 1},
+		heartbeat: func() (bool, error) { return false, database.ErrJobLeaseLost },
+	}
+	runner := newTestRunner(t, store)
+	runner.Handle(models.JobTypeMatch, func(ctx context.Context, job *models.Job, progress jobs.ProgressFunc) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	_, err := runner.RunOnce(context.Background())
+
+	require.NoError(t, err)
+	assert.False(t, store.completed)
+	assert.False(t, store.failed)
+	assert.False(t, store.cancelled)
+}
@@ -0,0 +1,377 @@
+package unit_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"loan-eligibility-engine/internal/config"
+	"loan-eligibility-engine/internal/models"
+	"loan-eligibility-engine/internal/services/llm"
+)
+
+const verdictJSON = `{"qualified": true, "confidence": 0.82, "reasoning": "stable income", "risk_factors": ["short tenure"]}`
+
+func TestGemini_SendsKeyInHeader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/models/gemini-test:generateContent", r.URL.Path)
+		assert.Empty(t, r.URL.RawQuery, "API key must not be sent in the query string")
+		assert.Equal(t, "secret", r.Header.Get("x-goog-api-key"))
+
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"candidates": []interface{}{
+				map[string]interface{}{
+					"content": map[string]interface{}{
+						"parts": []interface{}{map[string]interface{}{"text": "```json\n" + verdictJSON + "\n```"}},
+					},
+				},
+			},
+		})
+	}))
+	defer server.Close()
+
+	evaluator := llm.NewGemini("secret", server.URL, "gemini-test", server.Client())
+	resp, err := evaluator.EvaluateMatch(context.Background(), mockUser(nil), mockProduct(nil))
+	require.NoError(t, err)
+
+	assert.True(t, resp.Qualified)
+	assert.InDelta(t, 0.82, resp.Confidence, 1e-9)
+	assert.Equal(t, []string{"short tenure"}, resp.RiskFactors)
+	assert.Equal(t, "gemini/gemini-test", evaluator.Name())
+}
+
+func TestGemini_MalformedResponseIsError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"candidates": [{"content": "unexpected"}]}`))
+	}))
+	defer server.Close()
+
+	evaluator := llm.NewGemini("secret", server.URL, "", server.Client())
+	_, err := evaluator.EvaluateMatch(context.Background(), mockUser(nil), mockProduct(nil))
+	assert.Error(t, err)
+}
+
+// fixtureServer replies to each request with the next recorded response from
+// testdata/llm and records the request bodies
+func fixtureServer(t *testing.T, fixtures ...string) (*httptest.Server, *[]map[string]interface{}) {
+	t.Helper()
+	var requests []map[string]interface{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		requests = append(requests, body)
+
+		if len(requests) > len(fixtures) {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		data, err := os.ReadFile(filepath.Join("testdata", "llm", fixtures[len(requests)-1]))
+		require.NoError(t, err)
+		_, _ = w.Write(data)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &requests
+}
+
+func TestGemini_RecordedResponses(t *testing.T) {
+	tests := []struct {
+		name      string
+		fixtures  []string
+		wantErr   error
+		qualified bool
+		requests  int
+	}{
+		{"fenced json", []string{"gemini_ok.json"}, nil, true, 1},
+		{"braces in trailing text", []string{"gemini_trailing_text.json"}, nil, false, 1},
+		{"safety block", []string{"gemini_safety_blocked.json"}, llm.ErrBlocked, false, 1},
+		{"prompt block", []string{"gemini_prompt_blocked.json"}, llm.ErrBlocked, false, 1},
+		{"truncated at max tokens", []string{"gemini_max_tokens.json", "gemini_max_tokens.json"}, llm.ErrInvalidVerdict, false, 2},
+		{"confidence out of range", []string{"gemini_invalid_confidence.json", "gemini_invalid_confidence.json"}, llm.ErrInvalidVerdict, false, 2},
+		{"empty reasoning", []string{"gemini_empty_reasoning.json", "gemini_empty_reasoning.json"}, llm.ErrInvalidVerdict, false, 2},
+		{"prose without json", []string{"gemini_no_json.json", "gemini_no_json.json"}, llm.ErrInvalidVerdict, false, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := fixtureServer(t, tt.fixtures...)
+			evaluator := llm.NewGemini("secret", server.URL, "", server.Client())
+
+			resp, err := evaluator.EvaluateMatch(context.Background(), mockUser(nil), mockProduct(nil))
+
+			assert.Len(t, *requests, tt.requests)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.qualified, resp.Qualified)
+			assert.NotEmpty(t, resp.Reasoning)
+		})
+	}
+}
+
+func TestGemini_OKResponseDropsEmptyRiskFactors(t *testing.T) {
+	server, _ := fixtureServer(t, "gemini_ok.json")
+	evaluator := llm.NewGemini("secret", server.URL, "", server.Client())
+
+	resp, err := evaluator.EvaluateMatch(context.Background(), mockUser(nil), mockProduct(nil))
+	require.NoError(t, err)
+
+	assert.InDelta(t, 0.82, resp.Confidence, 1e-9)
+	assert.Equal(t, []string{"short credit history"}, resp.RiskFactors)
+}
+
+func TestGemini_CorrectiveRePrompt(t *testing.T) {
+	server, requests := fixtureServer(t, "gemini_invalid_confidence.json", "gemini_ok.json")
+	evaluator := llm.NewGemini("secret", server.URL, "", server.Client())
+
+	resp, err := evaluator.EvaluateMatch(context.Background(), mockUser(nil), mockProduct(nil))
+	require.NoError(t, err)
+	assert.True(t, resp.Qualified)
+
+	// The re-prompt replays the conversation and explains what was wrong
+	require.Len(t, *requests, 2)
+	contents := (*requests)[1]["contents"].([]interface{})
+	require.Len(t, contents, 3)
+
+	var roles []string
+	for _, c := range contents {
+		roles = append(roles, c.(map[string]interface{})["role"].(string))
+	}
+	assert.Equal(t, []string{"user", "model", "user"}, roles)
+
+	correction := contents[2].(map[string]interface{})["parts"].([]interface{})[0].(map[string]interface{})["text"].(string)
+	assert.Contains(t, correction, "outside [0, 1]")
+}
+
+func TestGemini_UnexpectedShapeIsNotRePrompted(t *testing.T) {
+	server, requests := fixtureServer(t, "gemini_unexpected_shape.json")
+	evaluator := llm.NewGemini("secret", server.URL, "", server.Client())
+
+	_, err := evaluator.EvaluateMatch(context.Background(), mockUser(nil), mockProduct(nil))
+
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, llm.ErrInvalidVerdict)
+	assert.Len(t, *requests, 1)
+}
+
+func TestGemini_SendHookRunsBeforeRePrompt(t *testing.T) {
+	server, requests := fixtureServer(t, "gemini_invalid_confidence.json", "gemini_ok.json")
+	evaluator := llm.NewGemini("secret", server.URL, "", server.Client())
+
+	var hooked []int
+	ctx := llm.WithSendHook(context.Background(), func(ctx context.Context) error {
+		hooked = append(hooked, len(*requests))
+		return nil
+	})
+
+	_, err := evaluator.EvaluateMatch(ctx, mockUser(nil), mockProduct(nil))
+	require.NoError(t, err)
+
+	// Once before each request, the re-prompt included
+	assert.Equal(t, []int{0, 1}, hooked)
+}
+
+func TestGemini_SendHookErrorStopsRePrompt(t *testing.T) {
+	server, requests := fixtureServer(t, "gemini_invalid_confidence.json", "gemini_ok.json")
+	evaluator := llm.NewGemini("secret", server.URL, "", server.Client())
+
+	errSpent := errors.New("budget spent")
+	ctx := llm.WithSendHook(context.Background(), func(ctx context.Context) error {
+		if len(*requests) > 0 {
+			return errSpent
+		}
+		return nil
+	})
+
+	_, err := evaluator.EvaluateMatch(ctx, mockUser(nil), mockProduct(nil))
+	assert.ErrorIs(t, err, errSpent)
+	assert.Len(t, *requests, 1)
+}
+
+func TestOpenAI_BlockedResponses(t *testing.T) {
+	for _, fixture := range []string{"openai_content_filter.json", "openai_refusal.json"} {
+		t.Run(fixture, func(t *testing.T) {
+			server, requests := fixtureServer(t, fixture)
+			evaluator := llm.NewOpenAI(llm.ProviderOpenAI, "sk-test", server.URL, "", server.Client())
+
+			_, err := evaluator.EvaluateMatch(context.Background(), mockUser(nil), mockProduct(nil))
+
+			assert.ErrorIs(t, err, llm.ErrBlocked)
+			assert.Len(t, *requests, 1)
+		})
+	}
+}
+
+func TestOpenAI_ChatCompletions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/chat/completions", r.URL.Path)
+		assert.Equal(t, "Bearer sk-test", r.Header.Get("Authorization"))
+
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "gpt-test", body["model"])
+
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []interface{}{
+				map[string]interface{}{"message": map[string]string{"role": "assistant", "content": verdictJSON}},
+			},
+		})
+	}))
+	defer server.Close()
+
+	evaluator := llm.NewOpenAI(llm.ProviderOpenAI, "sk-test", server.URL, "gpt-test", server.Client())
+	resp, err := evaluator.EvaluateMatch(context.Background(), mockUser(nil), mockProduct(nil))
+	require.NoError(t, err)
+	assert.True(t, resp.Qualified)
+	assert.Equal(t, "stable income", resp.Reasoning)
+}
+
+func TestOpenAI_LocalEndpointWithoutKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("Authorization"))
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []interface{}{
+				map[string]interface{}{"message": map[string]string{"content": verdictJSON}},
+			},
+		})
+	}))
+	defer server.Close()
+
+	evaluator, err := llm.New(&config.Config{LLMProvider: "local", LLMBaseURL: server.URL, LLMTimeoutSeconds: 5})
+	require.NoError(t, err)
+	assert.Equal(t, "local/"+llm.DefaultLocalModel, evaluator.Name())
+
+	resp, err := evaluator.EvaluateMatch(context.Background(), mockUser(nil), mockProduct(nil))
+	require.NoError(t, err)
+	assert.True(t, resp.Qualified)
+}
+
+func TestLLM_APIErrorCarriesRetryAfter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	evaluator := llm.NewOpenAI(llm.ProviderOpenAI, "sk-test", server.URL, "", server.Client())
+	_, err := evaluator.EvaluateMatch(context.Background(), mockUser(nil), mockProduct(nil))
+
+	var apiErr *llm.APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.True(t, apiErr.Retryable())
+	assert.Equal(t, 7*time.Second, apiErr.RetryAfter)
+	assert.False(t, (&llm.APIError{StatusCode: http.StatusBadRequest}).Retryable())
+}
+
+func TestLLM_NewSelectsProvider(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      config.Config
+		expected string
+		wantErr  bool
+	}{
+		{"no keys uses stub", config.Config{}, "stub", false},
+		{"gemini key", config.Config{GeminiAPIKey: "g"}, "gemini/" + llm.DefaultGeminiModel, false},
+		{"openai key", config.Config{OpenAIAPIKey: "o"}, "openai/" + llm.DefaultOpenAIModel, false},
+		{"explicit provider wins", config.Config{LLMProvider: "openai", GeminiAPIKey: "g", OpenAIAPIKey: "o", LLMModel: "gpt-x"}, "openai/gpt-x", false},
+		{"explicit provider without key", config.Config{LLMProvider: "gemini"}, "", true},
+		{"unknown provider", config.Config{LLMProvider: "bard"}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evaluator, err := llm.New(&tt.cfg)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, evaluator.Name())
+		})
+	}
+}
+
+func TestStub_IsDeterministic(t *testing.T) {
+	stub := llm.NewStub()
+	ctx := context.Background()
+
+	good, err := stub.EvaluateMatch(ctx, mockUser(map[string]interface{}{"credit_score": 800}), mockProduct(nil))
+	require.NoError(t, err)
+	again, err := stub.EvaluateMatch(ctx, mockUser(map[string]interface{}{"credit_score": 800}), mockProduct(nil))
+	require.NoError(t, err)
+
+	assert.True(t, good.Qualified)
+	assert.Equal(t, good, again)
+	assert.Greater(t, good.Confidence, 0.5)
+
+	weak, err := stub.EvaluateMatch(ctx, mockUser(map[string]interface{}{"credit_score": 650}), mockProduct(nil))
+	require.NoError(t, err)
+	assert.False(t, weak.Qualified)
+	assert.Contains(t, weak.RiskFactors, "credit score below product minimum")
+}
+
+func TestLLM_CacheKey(t *testing.T) {
+	user := mockUser(nil)
+	product := mockProduct(nil)
+	key := llm.CacheKey("gemini/gemini-pro", user, product)
+
+	assert.Len(t, key, 64)
+	assert.Equal(t, key, llm.CacheKey("gemini/gemini-pro", mockUser(nil), mockProduct(nil)), "key must be stable")
+	assert.Equal(t, key, llm.CacheKey("gemini/gemini-pro", mockUser(map[string]interface{}{"user_id": "USR999"}), product),
+		"identical financial profiles share a key")
+
+	assert.NotEqual(t, key, llm.CacheKey("openai/gpt-4o-mini", user, product), "provider is part of the key")
+	assert.NotEqual(t, key, llm.CacheKey("gemini/gemini-pro", mockUser(map[string]interface{}{"monthly_income": 60000.0}), product))
+	assert.NotEqual(t, key, llm.CacheKey("gemini/gemini-pro", user, mockProduct(map[string]interface{}{"min_credit_score": 720})),
+		"changed product terms must miss the cache")
+}
+
+func TestLLM_CacheKeyCoversPromptFields(t *testing.T) {
+	user := mockUser(nil)
+	key := llm.CacheKey("gemini/gemini-pro", user, mockProduct(nil))
+
+	fee := 1.5
+	changes := map[string]func(p *models.LoanProduct){
+		"product type":        func(p *models.LoanProduct) { p.ProductType = models.LoanProductTypeHome },
+		"minimum tenure":      func(p *models.LoanProduct) { p.TenureMinMonths = 6 },
+		"maximum tenure":      func(p *models.LoanProduct) { p.TenureMaxMonths = 84 },
+		"processing fee":      func(p *models.LoanProduct) { p.ProcessingFeePercent = &fee },
+		"accepted employment": func(p *models.LoanProduct) { p.AcceptedEmploymentStatus = p.AcceptedEmploymentStatus[:1] },
+	}
+	for name, change := range changes {
+		product := mockProduct(nil)
+		before := llm.BuildPrompt(user, product)
+		change(product)
+
+		assert.NotEqual(t, before, llm.BuildPrompt(user, product), "%s should be in the prompt", name)
+		assert.NotEqual(t, key, llm.CacheKey("gemini/gemini-pro", user, product), "changed %s must miss the cache", name)
+	}
+}
+
+func TestLLM_BuildPromptFormatsProductCurrency(t *testing.T) {
+	user := mockUser(map[string]interface{}{"monthly_income": 4200.0})
+	user.Locale = "en-US"
+	product := mockProduct(nil)
+	product.Currency = models.CurrencyUSD
+
+	prompt := llm.BuildPrompt(user, product)
+
+	assert.Contains(t, prompt, "Monthly Income: $4,200")
+	assert.Contains(t, prompt, "Loan Amount Range: $50,000 - $2,500,000")
+	assert.NotContains(t, prompt, "₹")
+
+	assert.NotEqual(t, llm.CacheKey("stub", user, mockProduct(nil)), llm.CacheKey("stub", user, product),
+		"the product currency is part of the key")
+}
@@ -41,6 +41,21 @@ func mockUser(overrides map[string]interface{}) *models.User {
 	if v, ok := overrides["employment_status"]; ok {
 		user.EmploymentStatus = v.(models.EmploymentStatus)
 	}
+	if v, ok := overrides["existing_emi"]; ok {
+		user.ExistingEMI = v.(float64)
+	}
+	if v, ok := overrides["credit_card_outstanding"]; ok {
+		user.CreditCardOutstanding = v.(float64)
+	}
+	if v, ok := overrides["requested_amount"]; ok {
+		user.RequestedAmount = v.(float64)
+	}
+	if v, ok := overrides["requested_tenure_months"]; ok {
+		user.RequestedTenureMonths = v.(int)
+	}
+	if v, ok := overrides["loan_purpose"]; ok {
+		user.LoanPurpose = v.(models.LoanProductType)
+	}
 
 	return user
 }
@@ -0,0 +1,185 @@
+package unit_test
+
+import (
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"loan-eligibility-engine/internal/services/migrations"
+)
+
+func migrationFS(files ...string) fstest.MapFS {
+	fsys := fstest.MapFS{}
+	for _, name := range files {
+		fsys[name] = &fstest.MapFile{Data: []byte("-- " + name + "\nSELECT 1;\n")}
+	}
+	return fsys
+}
+
+func TestMigrations_LoadFSOrdersByVersion(t *testing.T) {
+	fsys := migrationFS(
+		"0010_add_batches.up.sql", "0010_add_batches.down.sql",
+		"0002_add_index.up.sql", "0002_add_index.down.sql",
+		"0001_initial_schema.up.sql", "0001_initial_schema.down.sql",
+	)
+
+	loaded, err := migrations.LoadFS(fsys)
+	require.NoError(t, err)
+	require.Len(t, loaded, 3)
+
+	assert.Equal(t, []int{1, 2, 10}, []int{loaded[0].Version, loaded[1].Version, loaded[2].Version})
+	assert.Equal(t, "add_index", loaded[1].Name)
+	assert.Contains(t, loaded[1].Up, "0002_add_index.up.sql")
+	assert.Contains(t, loaded[1].Down, "0002_add_index.down.sql")
+	assert.Equal(t, migrations.Checksum(loaded[1].Up), loaded[1].Checksum)
+}
+
+func TestMigrations_LoadFSRejectsBadSets(t *testing.T) {
+	tests := map[string]fstest.MapFS{
+		"missing down":   migrationFS("0001_initial_schema.up.sql"),
+		"missing up":     migrationFS("0001_initial_schema.down.sql"),
+		"bad name":       migrationFS("initial_schema.sql"),
+		"zero version":   migrationFS("0000_initial_schema.up.sql", "0000_initial_schema.down.sql"),
+		"shared version": migrationFS("0001_a.up.sql", "0001_a.down.sql", "0001_b.up.sql", "0001_b.down.sql"),
+	}
+
+	for name, fsys := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := migrations.LoadFS(fsys)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestMigrations_EmbeddedNeverDropData(t *testing.T) {
+	loaded, err := migrations.Load()
+	require.NoError(t, err)
+	require.NotEmpty(t, loaded)
+	assert.Equal(t, 1, loaded[0].Version)
+
+	destructive := regexp.MustCompile(`(?i)\b(DROP\s+(TABLE|COLUMN|SCHEMA)|TRUNCATE|DELETE\s+FROM\s+\w+\s*;)`)
+	for _, migration := range loaded {
+		assert.False(t, destructive.MatchString(migration.Up),
+			"up migration %04d_%s drops data", migration.Version, migration.Name)
+	}
+}
+
+var (
+	createTableRe = regexp.MustCompile(`(?s)CREATE TABLE (?:IF NOT EXISTS )?(\w+) \((.*?)\n\);`)
+	alterTableRe  = regexp.MustCompile(`(?s)ALTER TABLE (\w+)\s([^;]*);`)
+	addColumnRe   = regexp.MustCompile(`ADD COLUMN IF NOT EXISTS (\w+)`)
+)
+
+// tableColumns returns the columns of each table created in a schema script
+func tableColumns(schema string) map[string][]string {
+	tables := make(map[string][]string)
+	for _, m := range createTableRe.FindAllStringSubmatch(schema, -1) {
+		for _, line := range strings.Split(m[2], "\n") {
+			fields := strings.Fields(line)
+			if len(fields) == 0 || strings.HasPrefix(fields[0], "--") {
+				continue
+			}
+			switch word := strings.ToUpper(fields[0]); {
+			case word == "CONSTRAINT", word == "PRIMARY", word == "CHECK", word == "FOREIGN",
+				strings.HasPrefix(word, "UNIQUE"):
+				continue
+			}
+			tables[m[1]] = append(tables[m[1]], fields[0])
+		}
+	}
+	return tables
+}
+
+// The initial migration adopts databases built by the former
+// scripts/init_database.sql, so every column it has since gained must be
+// added to those tables, before the indexes and triggers that use it
+func TestMigrations_InitialSchemaAdoptsLegacyDatabase(t *testing.T) {
+	legacySQL, err := os.ReadFile("testdata/migrations/legacy_init_database.sql")
+	require.NoError(t, err)
+
+	loaded, err := migrations.Load()
+	require.NoError(t, err)
+	initial := loaded[0].Up
+
+	added := make(map[string]map[string]int)
+	for _, m := range alterTableRe.FindAllStringSubmatchIndex(initial, -1) {
+		table := initial[m[2]:m[3]]
+		for _, col := range addColumnRe.FindAllStringSubmatch(initial[m[4]:m[5]], -1) {
+			if added[table] == nil {
+				added[table] = make(map[string]int)
+			}
+			added[table][col[1]] = m[0]
+		}
+	}
+
+	legacy := tableColumns(string(legacySQL))
+	current := tableColumns(initial)
+	require.NotEmpty(t, legacy["matches"])
+
+	for table, legacyColumns := range legacy {
+		had := make(map[string]bool)
+		for _, col := range legacyColumns {
+			had[col] = true
+		}
+
+		firstIndex := strings.Index(initial, " ON "+table+"(")
+		for _, col := range current[table] {
+			if had[col] {
+				continue
+			}
+			at, ok := added[table][col]
+			if assert.True(t, ok, "%s.%s is not added to legacy databases", table, col) && firstIndex >= 0 {
+				assert.Less(t, at, firstIndex, "%s.%s is added after the table's indexes", table, col)
+			}
+		}
+	}
+}
+
+func TestMigrations_Pending(t *testing.T) {
+	loaded, err := migrations.LoadFS(migrationFS(
+		"0001_initial_schema.up.sql", "0001_initial_schema.down.sql",
+		"0002_add_index.up.sql", "0002_add_index.down.sql",
+	))
+	require.NoError(t, err)
+
+	pending, err := migrations.Pending(loaded, nil)
+	require.NoError(t, err)
+	assert.Len(t, pending, 2)
+
+	applied := []migrations.Applied{{Version: 1, Name: "initial_schema", Checksum: loaded[0].Checksum}}
+	pending, err = migrations.Pending(loaded, applied)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, 2, pending[0].Version)
+
+	applied[0].Checksum = migrations.Checksum("edited")
+	_, err = migrations.Pending(loaded, applied)
+	assert.ErrorIs(t, err, migrations.ErrChecksumMismatch)
+}
+
+func TestMigrations_States(t *testing.T) {
+	loaded, err := migrations.LoadFS(migrationFS(
+		"0001_initial_schema.up.sql", "0001_initial_schema.down.sql",
+		"0002_add_index.up.sql", "0002_add_index.down.sql",
+	))
+	require.NoError(t, err)
+
+	at := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	states := migrations.States(loaded, []migrations.Applied{
+		{Version: 1, Name: "initial_schema", Checksum: migrations.Checksum("edited"), AppliedAt: at},
+		{Version: 3, Name: "from_newer_release", Checksum: "x", AppliedAt: at},
+	})
+
+	require.Len(t, states, 3)
+	assert.True(t, states[0].Applied)
+	assert.True(t, states[0].Modified)
+	assert.Equal(t, at, *states[0].AppliedAt)
+	assert.False(t, states[1].Applied, "0002 is pending")
+	assert.True(t, states[2].Unknown)
+}
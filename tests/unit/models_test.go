@@ -2,7 +2,9 @@
 package unit_test
 
 import (
+	"regexp"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -74,6 +76,34 @@ func TestNormalizeEmploymentStatus(t *testing.T) {
 	}
 }
 
+func TestNormalizeLoanPurpose(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected models.LoanProductType
+	}{
+		{"personal", models.LoanProductTypePersonal},
+		{"Personal Loan", models.LoanProductTypePersonal},
+		{"wedding", models.LoanProductTypePersonal},
+		{"home-loan", models.LoanProductTypeHome},
+		{"mortgage", models.LoanProductTypeHome},
+		{"Car", models.LoanProductTypeAuto},
+		{"two wheeler", models.LoanProductTypeAuto},
+		{"tuition", models.LoanProductTypeEducation},
+		{"working capital", models.LoanProductTypeBusiness},
+		{"", models.LoanProductType("")},
+		{"crypto", models.LoanProductType("crypto")}, // Unknown purposes fail validation
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.expected, models.NormalizeLoanPurpose(tt.input))
+		})
+	}
+
+	assert.True(t, models.LoanProductTypeHome.IsValid())
+	assert.False(t, models.LoanProductType("crypto").IsValid())
+}
+
 func TestValidateUserCreate_Valid(t *testing.T) {
 	user := &models.UserCreate{
 		UserID:           "USR001",
@@ -249,6 +279,66 @@ func TestLoanProduct_ToSummary(t *testing.T) {
 	assert.Equal(t, product.MinCreditScore, summary.MinCreditScore)
 }
 
+func TestLoanProduct_WithChanges(t *testing.T) {
+	maxScore := 850
+	product := &models.LoanProduct{
+		ID:                       1,
+		ProductName:              "Personal Loan",
+		MinMonthlyIncome:         25000,
+		MinCreditScore:           700,
+		MaxCreditScore:           &maxScore,
+		AcceptedEmploymentStatus: []models.EmploymentStatus{models.EmploymentStatusEmployed},
+	}
+
+	updated, err := product.WithChanges([]byte(`{"min_credit_score": 720, "max_credit_score": 800, "accepted_employment_status": ["self_employed"]}`))
+	assert.NoError(t, err)
+
+	assert.Equal(t, 720, updated.MinCreditScore)
+	assert.Equal(t, 800, *updated.MaxCreditScore)
+	assert.Equal(t, []models.EmploymentStatus{models.EmploymentStatusSelfEmployed}, updated.AcceptedEmploymentStatus)
+	assert.Equal(t, 25000.0, updated.MinMonthlyIncome)
+	assert.Equal(t, int64(1), updated.ID)
+
+	// The original product is untouched
+	assert.Equal(t, 700, product.MinCreditScore)
+	assert.Equal(t, 850, *product.MaxCreditScore)
+	assert.Equal(t, []models.EmploymentStatus{models.EmploymentStatusEmployed}, product.AcceptedEmploymentStatus)
+}
+
+func TestLoanProduct_WithChanges_InvalidJSON(t *testing.T) {
+	product := &models.LoanProduct{ID: 1}
+
+	_, err := product.WithChanges([]byte(`{"min_credit_score": "high"}`))
+	assert.Error(t, err)
+}
+
+func TestPolicySimulation_Record(t *testing.T) {
+	score := func(v float64) *float64 { return &v }
+	sim := &models.PolicySimulation{}
+	reasons := []models.EligibilityCheck{{Criterion: "credit_score", Message: "credit score 710 below minimum 720"}}
+
+	sim.Record(&models.User{ID: 1}, nil, score(60), nil, 1)     // gained
+	sim.Record(&models.User{ID: 2}, nil, score(55), nil, 1)     // gained, beyond the sample
+	sim.Record(&models.User{ID: 3}, score(70), nil, reasons, 1) // lost
+	sim.Record(&models.User{ID: 4}, score(80), score(84), nil, 1)
+	sim.Record(&models.User{ID: 5}, score(80), score(80), nil, 1)
+	sim.Record(&models.User{ID: 6}, nil, nil, reasons, 1)
+
+	assert.Equal(t, 6, sim.UsersEvaluated)
+	assert.Equal(t, 3, sim.EligibleBefore)
+	assert.Equal(t, 4, sim.EligibleAfter)
+	assert.Equal(t, 2, sim.Gained)
+	assert.Equal(t, 1, sim.Lost)
+	assert.Equal(t, 2, sim.StillEligible)
+	assert.Equal(t, 1, sim.ScoreChanged)
+	assert.InDelta(t, 2.0, sim.AvgScoreDelta, 1e-9)
+
+	assert.Len(t, sim.SampleGained, 1)
+	assert.Equal(t, int64(1), sim.SampleGained[0].UserID)
+	assert.Equal(t, reasons, sim.SampleLost[0].Reasons)
+	assert.Equal(t, int64(4), sim.SampleScoreChanged[0].UserID)
+}
+
 func TestMatchStatus_Constants(t *testing.T) {
 	assert.Equal(t, models.MatchStatus("pending"), models.MatchStatusPending)
 	assert.Equal(t, models.MatchStatus("eligible"), models.MatchStatusEligible)
@@ -287,3 +377,187 @@ func TestMatchCreate_Defaults(t *testing.T) {
 	assert.False(t, match.AgeEligible)
 	assert.False(t, match.EmploymentEligible)
 }
+
+func TestReviewDecision_Status(t *testing.T) {
+	assert.Equal(t, models.MatchStatusEligible, models.ReviewDecisionApproved.Status())
+	assert.Equal(t, models.MatchStatusNotEligible, models.ReviewDecisionRejected.Status())
+}
+
+func TestValidateRateSlab_DefaultsCreditBand(t *testing.T) {
+	slab := &models.RateSlabCreate{InterestRate: 12.5}
+
+	assert.NoError(t, models.ValidateRateSlab(slab))
+	assert.Equal(t, 300, slab.MinCreditScore)
+	assert.Equal(t, 900, slab.MaxCreditScore)
+}
+
+func TestValidateRateSlab_Invalid(t *testing.T) {
+	maxIncome := 20000.0
+	fee := 120.0
+
+	tests := []struct {
+		name     string
+		slab     models.RateSlabCreate
+		expected error
+	}{
+		{"inverted credit band", models.RateSlabCreate{MinCreditScore: 800, MaxCreditScore: 750, InterestRate: 12}, models.ErrInvalidSlabCreditBand},
+		{"credit score above 900", models.RateSlabCreate{MinCreditScore: 750, MaxCreditScore: 950, InterestRate: 12}, models.ErrInvalidSlabCreditBand},
+		{"inverted income band", models.RateSlabCreate{MinMonthlyIncome: 50000, MaxMonthlyIncome: &maxIncome, InterestRate: 12}, models.ErrInvalidSlabIncomeBand},
+		{"zero rate", models.RateSlabCreate{}, models.ErrInvalidSlabRate},
+		{"fee above 100", models.RateSlabCreate{InterestRate: 12, ProcessingFeePercent: &fee}, models.ErrInvalidSlabRate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slab := tt.slab
+			assert.ErrorIs(t, models.ValidateRateSlab(&slab), tt.expected)
+		})
+	}
+}
+
+func TestRateSlab_Covers(t *testing.T) {
+	maxIncome := 100000.0
+	slab := &models.RateSlab{MinCreditScore: 750, MaxCreditScore: 799, MinMonthlyIncome: 30000, MaxMonthlyIncome: &maxIncome}
+
+	assert.True(t, slab.Covers(750, 30000))
+	assert.True(t, slab.Covers(799, 100000))
+	assert.False(t, slab.Covers(800, 50000))
+	assert.False(t, slab.Covers(760, 29999))
+	assert.False(t, slab.Covers(760, 100001))
+
+	slab.MaxMonthlyIncome = nil
+	assert.True(t, slab.Covers(760, 1000000))
+}
+
+func TestOfferRanking_IsValid(t *testing.T) {
+	assert.True(t, models.OfferRankingScore.IsValid())
+	assert.True(t, models.OfferRankingCost.IsValid())
+	assert.False(t, models.OfferRanking("apr").IsValid())
+}
+
+func TestNormalizeCurrency(t *testing.T) {
+	cases := map[string]models.Currency{
+		"inr":    models.CurrencyINR,
+		"Rs.":    models.CurrencyINR,
+		"₹":      models.CurrencyINR,
+		" usd ":  models.CurrencyUSD,
+		"$":      models.CurrencyUSD,
+		"€":      models.CurrencyEUR,
+		"pounds": models.CurrencyGBP,
+		"S$":     models.CurrencySGD,
+		"dirham": models.CurrencyAED,
+	}
+	for input, expected := range cases {
+		assert.Equal(t, expected, models.NormalizeCurrency(input), "input %q", input)
+	}
+	assert.False(t, models.NormalizeCurrency("yen").IsValid())
+}
+
+func TestValidateFXRate(t *testing.T) {
+	rate := &models.FXRate{Currency: "usd", RateToBase: 83}
+	assert.NoError(t, models.ValidateFXRate(rate))
+	assert.Equal(t, models.CurrencyUSD, rate.Currency)
+
+	assert.ErrorIs(t, models.ValidateFXRate(&models.FXRate{Currency: "XYZ", RateToBase: 1}), models.ErrInvalidCurrency)
+	assert.ErrorIs(t, models.ValidateFXRate(&models.FXRate{Currency: "EUR", RateToBase: 0}), models.ErrInvalidFXRate)
+}
+
+func TestNewBatchID(t *testing.T) {
+	now := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+
+	id := models.NewBatchID(now)
+	assert.Regexp(t, regexp.MustCompile(`^batch_20261016T093000_[0-9a-f]{8}$`), id)
+	assert.LessOrEqual(t, len(id), 50, "fits upload_batches.batch_id")
+	assert.NotEqual(t, id, models.NewBatchID(now), "IDs in the same second differ")
+}
+
+func TestNewBatchProgress(t *testing.T) {
+	batch := &models.UploadBatch{Status: models.BatchStatusInserting, ValidRows: 400, SavedRows: 100}
+	assert.Equal(t, models.BatchProgress{Stage: models.BatchStatusInserting, Done: 100, Total: 400, Percent: 25},
+		models.NewBatchProgress(batch, 0, 0))
+
+	batch.Status = models.BatchStatusMatching
+	assert.Equal(t, models.BatchProgress{Stage: models.BatchStatusMatching, Done: 1, Total: 3, Percent: 33.3},
+		models.NewBatchProgress(batch, 1, 3))
+
+	batch.Status = models.BatchStatusCompleted
+	assert.Equal(t, 100.0, models.NewBatchProgress(batch, 0, 0).Percent)
+
+	batch.Status = models.BatchStatusFailed
+	assert.Zero(t, models.NewBatchProgress(batch, 0, 0).Percent)
+	assert.True(t, batch.Status.IsFinished())
+	assert.False(t, models.BatchStatusMatching.IsFinished())
+}
+
+func validLoanProduct() *models.LoanProductCreate {
+	return &models.LoanProductCreate{
+		ProductName:              "Flexi Personal Loan",
+		ProviderName:             "Example Bank",
+		ProductType:              models.LoanProductTypePersonal,
+		InterestRateMin:          10.5,
+		InterestRateMax:          21,
+		LoanAmountMin:            50000,
+		LoanAmountMax:            4000000,
+		TenureMinMonths:          12,
+		TenureMaxMonths:          60,
+		MinMonthlyIncome:         25000,
+		MinCreditScore:           700,
+		MinAge:                   21,
+		MaxAge:                   60,
+		AcceptedEmploymentStatus: []models.EmploymentStatus{"Salaried", "self-employed"},
+		Currency:                 "inr",
+	}
+}
+
+func TestValidateLoanProduct(t *testing.T) {
+	product := validLoanProduct()
+	assert.NoError(t, models.ValidateLoanProduct(product))
+	assert.Equal(t, []models.EmploymentStatus{models.EmploymentStatusEmployed, models.EmploymentStatusSelfEmployed},
+		product.AcceptedEmploymentStatus)
+	assert.Equal(t, models.CurrencyINR, product.Currency)
+
+	tests := []struct {
+		name   string
+		change func(p *models.LoanProductCreate)
+		want   error
+	}{
+		{"no name", func(p *models.LoanProductCreate) { p.ProductName = " " }, models.ErrInvalidProductName},
+		{"unknown type", func(p *models.LoanProductCreate) { p.ProductType = "gold" }, models.ErrInvalidProductType},
+		{"zero rate", func(p *models.LoanProductCreate) { p.InterestRateMin = 0 }, models.ErrInvalidProductRate},
+		{"rates reversed", func(p *models.LoanProductCreate) { p.InterestRateMax = 9 }, models.ErrInvalidProductRate},
+		{"amounts reversed", func(p *models.LoanProductCreate) { p.LoanAmountMax = 10000 }, models.ErrInvalidProductAmount},
+		{"no tenure", func(p *models.LoanProductCreate) { p.TenureMinMonths = 0 }, models.ErrInvalidProductTenure},
+		{"credit score", func(p *models.LoanProductCreate) { p.MinCreditScore = 950 }, models.ErrInvalidProductCriteria},
+		{"ages reversed", func(p *models.LoanProductCreate) { p.MinAge = 65 }, models.ErrInvalidProductCriteria},
+		{"no employment", func(p *models.LoanProductCreate) { p.AcceptedEmploymentStatus = nil }, models.ErrInvalidEmploymentStatus},
+		{"bad employment", func(p *models.LoanProductCreate) {
+			p.AcceptedEmploymentStatus = []models.EmploymentStatus{"astronaut"}
+		}, models.ErrInvalidEmploymentStatus},
+		{"bad currency", func(p *models.LoanProductCreate) { p.Currency = "XYZ" }, models.ErrInvalidCurrency},
+	}
+	for _, tt := range tests {
+		product := validLoanProduct()
+		tt.change(product)
+		assert.ErrorIs(t, models.ValidateLoanProduct(product), tt.want, tt.name)
+	}
+}
+
+func TestValidateCrawlerSource(t *testing.T) {
+	source := &models.CrawlerSourceCreate{
+		Name:         " example-personal ",
+		URL:          "https://bank.example/loans",
+		ProviderName: "Example Bank",
+		Currency:     "usd",
+	}
+	assert.NoError(t, models.ValidateCrawlerSource(source))
+	assert.Equal(t, "example-personal", source.Name)
+	assert.Equal(t, models.LoanProductTypePersonal, source.ProductType)
+	assert.Equal(t, models.CurrencyUSD, source.Currency)
+
+	assert.ErrorIs(t, models.ValidateCrawlerSource(&models.CrawlerSourceCreate{
+		Name: "ftp", URL: "ftp://bank.example/loans", ProviderName: "Example Bank",
+	}), models.ErrInvalidCrawlerSource)
+	assert.ErrorIs(t, models.ValidateCrawlerSource(&models.CrawlerSourceCreate{
+		Name: "gold", URL: "https://bank.example/gold", ProviderName: "Example Bank", ProductType: "gold",
+	}), models.ErrInvalidProductType)
+}
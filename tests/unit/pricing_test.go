@@ -0,0 +1,151 @@
+package unit_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"loan-eligibility-engine/internal/models"
+	"loan-eligibility-engine/internal/services/affordability"
+	"loan-eligibility-engine/internal/services/pricing"
+)
+
+func TestPricing_LowestCoveringSlabWins(t *testing.T) {
+	user := mockUser(map[string]interface{}{"credit_score": 780})
+	product := mockProduct(nil)
+	slabs := []*models.RateSlab{
+		{ProductID: 1, MinCreditScore: 700, MaxCreditScore: 799, InterestRate: 14.5},
+		{ProductID: 1, MinCreditScore: 750, MaxCreditScore: 900, InterestRate: 12.25, ProcessingFeePercent: floatPtr(1.5)},
+		{ProductID: 1, MinCreditScore: 800, MaxCreditScore: 900, InterestRate: 10.5},
+		// Another product's slab never applies
+		{ProductID: 2, MinCreditScore: 300, MaxCreditScore: 900, InterestRate: 9.0},
+	}
+
+	estimate := pricing.Estimate(user, product, slabs)
+
+	assert.Equal(t, 12.25, estimate.EstimatedRate)
+	require.NotNil(t, estimate.ProcessingFeePercent)
+	assert.Equal(t, 1.5, *estimate.ProcessingFeePercent)
+}
+
+func TestPricing_SlabFallsBackToProductFee(t *testing.T) {
+	user := mockUser(map[string]interface{}{"credit_score": 760})
+	product := mockProduct(nil)
+	product.ProcessingFeePercent = floatPtr(2.0)
+	slabs := []*models.RateSlab{{ProductID: 1, MinCreditScore: 750, MaxCreditScore: 799, InterestRate: 13.0}}
+
+	estimate := pricing.Estimate(user, product, slabs)
+
+	assert.Equal(t, 13.0, estimate.EstimatedRate)
+	require.NotNil(t, estimate.ProcessingFeePercent)
+	assert.Equal(t, 2.0, *estimate.ProcessingFeePercent)
+}
+
+func TestPricing_IncomeBand(t *testing.T) {
+	product := mockProduct(nil)
+	slabs := []*models.RateSlab{
+		{ProductID: 1, MinCreditScore: 750, MaxCreditScore: 900, MaxMonthlyIncome: floatPtr(99999.99), InterestRate: 12.0},
+		{ProductID: 1, MinCreditScore: 750, MaxCreditScore: 900, MinMonthlyIncome: 100000, InterestRate: 11.0},
+	}
+
+	low := pricing.Estimate(mockUser(map[string]interface{}{"credit_score": 800, "monthly_income": float64(50000)}), product, slabs)
+	high := pricing.Estimate(mockUser(map[string]interface{}{"credit_score": 800, "monthly_income": float64(150000)}), product, slabs)
+
+	assert.Equal(t, 12.0, low.EstimatedRate)
+	assert.Equal(t, 11.0, high.EstimatedRate)
+}
+
+func TestPricing_InterpolatesWithoutSlab(t *testing.T) {
+	// Product rates run 10.5-18 from credit score 700 to the 900 ceiling
+	product := mockProduct(nil)
+
+	tests := []struct {
+		creditScore int
+		expected    float64
+	}{
+		{700, 18.0},
+		{800, 14.25},
+		{900, 10.5},
+		{650, 18.0},
+	}
+
+	for _, tt := range tests {
+		user := mockUser(map[string]interface{}{"credit_score": tt.creditScore})
+		estimate := pricing.Estimate(user, product, nil)
+		assert.Equal(t, tt.expected, estimate.EstimatedRate, "credit score %d", tt.creditScore)
+		assert.Nil(t, estimate.ProcessingFeePercent)
+	}
+}
+
+func TestPricing_CostWithoutFeeHasAPREqualToRate(t *testing.T) {
+	cost := pricing.Cost(100000, 12, 12, nil)
+
+	assert.Equal(t, 8884.88, cost.EMI)
+	assert.InDelta(t, 6618.55, cost.TotalInterest, 0.01)
+	assert.Equal(t, 0.0, cost.TotalFees)
+	assert.Equal(t, cost.TotalInterest, cost.TotalCost)
+	assert.InDelta(t, 12.0, cost.APR, 0.01)
+}
+
+func TestPricing_CostFeeRaisesAPR(t *testing.T) {
+	cost := pricing.Cost(100000, 12, 12, floatPtr(2))
+
+	assert.Equal(t, 2000.0, cost.TotalFees)
+	assert.InDelta(t, cost.TotalInterest+2000, cost.TotalCost, 0.01)
+	assert.Greater(t, cost.APR, 12.0)
+	// The EMIs at the APR repay the amount net of the fee
+	assert.InDelta(t, 98000, affordability.Principal(8884.88, cost.APR, 12), 5)
+}
+
+func TestPricing_PriceUsesEstimateOrProductRate(t *testing.T) {
+	product := mockProduct(nil)
+	product.ProcessingFeePercent = floatPtr(1)
+
+	estimated := &models.Match{ID: 7, ProductID: 1, MatchScore: 80, RateEstimate: models.RateEstimate{EstimatedRate: 12}}
+	offer, ok := pricing.Price(estimated, product, 500000, 36)
+	require.True(t, ok)
+	assert.Equal(t, int64(7), offer.MatchID)
+	assert.Equal(t, 12.0, offer.InterestRate)
+	assert.Equal(t, 5000.0, offer.TotalFees)
+
+	legacy := &models.Match{ID: 8, ProductID: 1}
+	offer, ok = pricing.Price(legacy, product, 500000, 36)
+	require.True(t, ok)
+	assert.Equal(t, product.InterestRateMax, offer.InterestRate)
+}
+
+func TestPricing_PriceRejectsTermsProductDoesNotLend(t *testing.T) {
+	product := mockProduct(nil)
+	match := &models.Match{ProductID: 1, RateEstimate: models.RateEstimate{EstimatedRate: 12}}
+
+	_, ok := pricing.Price(match, product, 10000, 36)
+	assert.False(t, ok, "amount below product minimum")
+	_, ok = pricing.Price(match, product, 500000, 84)
+	assert.False(t, ok, "tenure above product maximum")
+}
+
+func TestPricing_PriceZeroMaxAmountIsUnbounded(t *testing.T) {
+	product := mockProduct(nil)
+	product.LoanAmountMax = 0
+	match := &models.Match{ProductID: 1, RateEstimate: models.RateEstimate{EstimatedRate: 12}}
+
+	offer, ok := pricing.Price(match, product, 5000000, 36)
+	require.True(t, ok)
+	assert.Equal(t, 5000000.0, offer.Amount)
+	_, ok = pricing.Price(match, product, 10000, 36)
+	assert.False(t, ok, "the minimum still applies")
+}
+
+func TestPricing_Rank(t *testing.T) {
+	cheap := &models.Offer{ProductName: "cheap", MatchScore: 70, BorrowingCost: models.BorrowingCost{TotalCost: 50000, APR: 11}}
+	costly := &models.Offer{ProductName: "costly", MatchScore: 90, BorrowingCost: models.BorrowingCost{TotalCost: 80000, APR: 15}}
+	lowerAPR := &models.Offer{ProductName: "lower_apr", MatchScore: 60, BorrowingCost: models.BorrowingCost{TotalCost: 50000, APR: 10}}
+
+	offers := []*models.Offer{costly, cheap, lowerAPR}
+	pricing.Rank(offers, models.OfferRankingCost)
+	assert.Equal(t, []*models.Offer{lowerAPR, cheap, costly}, offers)
+
+	pricing.Rank(offers, models.OfferRankingScore)
+	assert.Equal(t, []*models.Offer{costly, cheap, lowerAPR}, offers)
+}
@@ -0,0 +1,114 @@
+package unit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"loan-eligibility-engine/internal/models"
+)
+
+var timelineStart = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func productVersion(productID int64, version int, provider string, days int, rateMin, rateMax float64) *models.LoanProductVersion {
+	return &models.LoanProductVersion{
+		ProductID:       productID,
+		Version:         version,
+		ProductName:     "Personal Loan",
+		ProviderName:    provider,
+		InterestRateMin: rateMin,
+		InterestRateMax: rateMax,
+		IsActive:        true,
+		CreatedAt:       timelineStart.AddDate(0, 0, days),
+	}
+}
+
+func TestProductChangeSource_IsValid(t *testing.T) {
+	assert.True(t, models.ProductChangeSourceCrawler.IsValid())
+	assert.True(t, models.ProductChangeSourceAdmin.IsValid())
+	assert.True(t, models.ProductChangeSourceImport.IsValid())
+	assert.False(t, models.ProductChangeSource("manual").IsValid())
+	assert.False(t, models.ProductChangeSource("").IsValid())
+}
+
+func TestBuildRateTimelines_GroupsByProviderAndProduct(t *testing.T) {
+	timelines := models.BuildRateTimelines([]*models.LoanProductVersion{
+		productVersion(1, 1, "HDFC Bank", 0, 10.5, 21),
+		productVersion(1, 2, "HDFC Bank", 10, 10.75, 21),
+		productVersion(2, 1, "HDFC Bank", 3, 8.5, 9.5),
+		productVersion(3, 1, "ICICI Bank", 0, 10.75, 19),
+	}, time.Time{})
+
+	require.Len(t, timelines, 2)
+	assert.Equal(t, "HDFC Bank", timelines[0].ProviderName)
+	require.Len(t, timelines[0].Products, 2)
+	assert.Equal(t, int64(1), timelines[0].Products[0].ProductID)
+	assert.Len(t, timelines[0].Products[0].Points, 2)
+	assert.Equal(t, int64(2), timelines[0].Products[1].ProductID)
+	assert.Len(t, timelines[0].Products[1].Points, 1)
+
+	assert.Equal(t, "ICICI Bank", timelines[1].ProviderName)
+	require.Len(t, timelines[1].Products, 1)
+	assert.Equal(t, 10.75, timelines[1].Products[0].Points[0].InterestRateMin)
+}
+
+func TestBuildRateTimelines_SkipsVersionsWithoutRateChange(t *testing.T) {
+	deactivated := productVersion(1, 4, "HDFC Bank", 30, 11, 21)
+	deactivated.IsActive = false
+
+	timelines := models.BuildRateTimelines([]*models.LoanProductVersion{
+		productVersion(1, 1, "HDFC Bank", 0, 10.5, 21),
+		productVersion(1, 2, "HDFC Bank", 5, 10.5, 21), // another term changed
+		productVersion(1, 3, "HDFC Bank", 10, 11, 21),
+		deactivated,
+	}, time.Time{})
+
+	require.Len(t, timelines, 1)
+	points := timelines[0].Products[0].Points
+	require.Len(t, points, 3)
+	assert.Equal(t, []int{1, 3, 4}, []int{points[0].Version, points[1].Version, points[2].Version})
+	assert.Equal(t, timelineStart.AddDate(0, 0, 10), points[1].At)
+	assert.False(t, points[2].IsActive)
+}
+
+func TestBuildRateTimelines_FoldsVersionsBeforeFrom(t *testing.T) {
+	from := timelineStart.AddDate(0, 0, 15)
+
+	timelines := models.BuildRateTimelines([]*models.LoanProductVersion{
+		productVersion(1, 1, "HDFC Bank", 0, 10.5, 21),
+		productVersion(1, 2, "HDFC Bank", 10, 11, 21),
+		productVersion(1, 3, "HDFC Bank", 20, 11, 21),
+		productVersion(1, 4, "HDFC Bank", 25, 11.5, 22),
+	}, from)
+
+	require.Len(t, timelines, 1)
+	points := timelines[0].Products[0].Points
+	require.Len(t, points, 2)
+
+	// The rates in effect at from, then the next change
+	assert.Equal(t, from, points[0].At)
+	assert.Equal(t, 2, points[0].Version)
+	assert.Equal(t, 11.0, points[0].InterestRateMin)
+	assert.Equal(t, 4, points[1].Version)
+	assert.Equal(t, 22.0, points[1].InterestRateMax)
+}
+
+func TestBuildRateTimelines_UsesLatestProductName(t *testing.T) {
+	renamed := productVersion(1, 2, "HDFC Bank", 5, 10.5, 21)
+	renamed.ProductName = "HDFC Xpress Personal Loan"
+
+	timelines := models.BuildRateTimelines([]*models.LoanProductVersion{
+		productVersion(1, 1, "HDFC Bank", 0, 10.5, 21),
+		renamed,
+	}, time.Time{})
+
+	require.Len(t, timelines, 1)
+	assert.Equal(t, "HDFC Xpress Personal Loan", timelines[0].Products[0].ProductName)
+	assert.Len(t, timelines[0].Products[0].Points, 1)
+}
+
+func TestBuildRateTimelines_Empty(t *testing.T) {
+	assert.Empty(t, models.BuildRateTimelines(nil, time.Time{}))
+}
@@ -0,0 +1,107 @@
+package unit_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"loan-eligibility-engine/internal/models"
+	"loan-eligibility-engine/internal/services/ranking"
+)
+
+func rankingEntry(provider string, productType models.LoanProductType, rateMin, rateMax, amountMin, amountMax float64) ranking.Entry {
+	return ranking.Entry{
+		Provider:    provider,
+		ProductType: productType,
+		RateMin:     rateMin,
+		RateMax:     rateMax,
+		AmountMin:   amountMin,
+		AmountMax:   amountMax,
+	}
+}
+
+// pickIndexes lists the input indexes of the picks in order
+func pickIndexes(result ranking.Result) []int {
+	indexes := make([]int, len(result.Picks))
+	for i, pick := range result.Picks {
+		indexes[i] = pick.Index
+	}
+	return indexes
+}
+
+func TestRanking_IsDuplicate(t *testing.T) {
+	base := rankingEntry("HDFC Bank", models.LoanProductTypePersonal, 10.5, 21, 50000, 4000000)
+
+	assert.True(t, ranking.IsDuplicate(base, rankingEntry(" hdfc bank", models.LoanProductTypePersonal, 11, 18, 100000, 2500000)))
+	assert.False(t, ranking.IsDuplicate(base, rankingEntry("ICICI Bank", models.LoanProductTypePersonal, 11, 18, 100000, 2500000)),
+		"different provider")
+	assert.False(t, ranking.IsDuplicate(base, rankingEntry("HDFC Bank", models.LoanProductTypeHome, 11, 18, 100000, 2500000)),
+		"different product type")
+	assert.False(t, ranking.IsDuplicate(base, rankingEntry("HDFC Bank", models.LoanProductTypePersonal, 22, 26, 100000, 2500000)),
+		"rate ranges do not overlap")
+	assert.False(t, ranking.IsDuplicate(base, rankingEntry("HDFC Bank", models.LoanProductTypePersonal, 11, 18, 5000000, 7500000)),
+		"amount ranges do not overlap")
+}
+
+func TestRanking_ZeroOptionsKeepOrder(t *testing.T) {
+	entries := []ranking.Entry{
+		rankingEntry("HDFC Bank", models.LoanProductTypePersonal, 10, 20, 50000, 1000000),
+		rankingEntry("HDFC Bank", models.LoanProductTypePersonal, 10, 20, 50000, 1000000),
+		rankingEntry("SBI", models.LoanProductTypeHome, 8, 9, 500000, 10000000),
+	}
+
+	result := ranking.Diversify(entries, ranking.Options{})
+
+	assert.Equal(t, []int{0, 1, 2}, pickIndexes(result))
+	assert.Zero(t, result.Collapsed)
+	assert.Zero(t, result.Capped)
+}
+
+func TestRanking_CollapsesDuplicatesIntoBest(t *testing.T) {
+	entries := []ranking.Entry{
+		rankingEntry("HDFC Bank", models.LoanProductTypePersonal, 10.5, 21, 50000, 4000000),
+		rankingEntry("ICICI Bank", models.LoanProductTypePersonal, 10.75, 19, 50000, 3000000),
+		rankingEntry("HDFC Bank", models.LoanProductTypePersonal, 11, 18, 100000, 2500000),
+		rankingEntry("HDFC Bank", models.LoanProductTypePersonal, 10.9, 16, 50000, 1500000),
+	}
+
+	result := ranking.Diversify(entries, ranking.Options{CollapseDuplicates: true})
+
+	require.Len(t, result.Picks, 2)
+	assert.Equal(t, 0, result.Picks[0].Index)
+	assert.Equal(t, []int{2, 3}, result.Picks[0].Duplicates)
+	assert.Equal(t, 1, result.Picks[1].Index)
+	assert.Empty(t, result.Picks[1].Duplicates)
+	assert.Equal(t, 2, result.Collapsed)
+}
+
+func TestRanking_CapsEachProvider(t *testing.T) {
+	entries := []ranking.Entry{
+		rankingEntry("HDFC Bank", models.LoanProductTypePersonal, 10, 12, 50000, 500000),
+		rankingEntry("HDFC Bank", models.LoanProductTypePersonal, 14, 16, 50000, 500000),
+		rankingEntry("HDFC Bank", models.LoanProductTypePersonal, 18, 20, 50000, 500000),
+		rankingEntry("SBI", models.LoanProductTypePersonal, 11, 15, 25000, 2000000),
+	}
+
+	result := ranking.Diversify(entries, ranking.Options{MaxPerProvider: 2, CollapseDuplicates: true})
+
+	assert.Equal(t, []int{0, 1, 3}, pickIndexes(result))
+	assert.Equal(t, 1, result.Capped)
+	assert.Zero(t, result.Collapsed, "distinct rate ranges are not duplicates")
+}
+
+func TestRanking_InterleavesProductTypes(t *testing.T) {
+	entries := []ranking.Entry{
+		rankingEntry("HDFC Bank", models.LoanProductTypePersonal, 10, 12, 50000, 500000),
+		rankingEntry("ICICI Bank", models.LoanProductTypePersonal, 10, 12, 50000, 500000),
+		rankingEntry("SBI", models.LoanProductTypePersonal, 10, 12, 50000, 500000),
+		rankingEntry("Axis Bank", models.LoanProductTypeHome, 8, 9, 500000, 10000000),
+		rankingEntry("Kotak Mahindra", models.LoanProductTypeBusiness, 14, 18, 100000, 5000000),
+	}
+
+	result := ranking.Diversify(entries, ranking.Options{DiversifyTypes: true, Limit: 4})
+
+	assert.Equal(t, []int{0, 3, 4, 1}, pickIndexes(result))
+	assert.Zero(t, result.Capped, "the limit is not counted as capped")
+}
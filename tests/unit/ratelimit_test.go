@@ -0,0 +1,49 @@
+// Package unit_test contains unit tests for the loan eligibility engine
+package unit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"loan-eligibility-engine/internal/utils"
+)
+
+func TestTokenBucket_AllowsBurstThenThrottles(t *testing.T) {
+	// 6000/min = one token every 10ms, burst of 3
+	bucket := utils.NewTokenBucket(6000, 3)
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, bucket.Wait(ctx))
+	}
+	assert.Less(t, time.Since(start), 10*time.Millisecond, "burst should not block")
+
+	start = time.Now()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, bucket.Wait(ctx))
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 25*time.Millisecond, "tokens beyond the burst should be throttled")
+}
+
+func TestTokenBucket_HonoursContextCancellation(t *testing.T) {
+	bucket := utils.NewTokenBucket(1, 1)
+	require.NoError(t, bucket.Wait(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := bucket.Wait(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestTokenBucket_ZeroRateDisablesLimiting(t *testing.T) {
+	bucket := utils.NewTokenBucket(0, 1)
+	for i := 0; i < 100; i++ {
+		require.NoError(t, bucket.Wait(context.Background()))
+	}
+}
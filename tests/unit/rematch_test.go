@@ -0,0 +1,112 @@
+package unit_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"loan-eligibility-engine/internal/models"
+	"loan-eligibility-engine/internal/services/matcher"
+)
+
+func storedMatch(id, userID int64, score float64, status models.MatchStatus) models.Match {
+	return models.Match{ID: id, UserID: userID, ProductID: 7, MatchScore: score, Status: status}
+}
+
+func freshMatch(userID int64, score float64, status models.MatchStatus) *models.MatchCreate {
+	return &models.MatchCreate{UserID: userID, ProductID: 7, MatchScore: score, Status: status}
+}
+
+func changeTypes(changes []models.RematchChange) map[int64]models.RematchChangeType {
+	out := make(map[int64]models.RematchChangeType, len(changes))
+	for _, c := range changes {
+		out[c.UserID] = c.ChangeType
+	}
+	return out
+}
+
+func TestDiffProductMatches_AddedRemovedRescored(t *testing.T) {
+	existing := []models.Match{
+		storedMatch(1, 1, 80, models.MatchStatusEligible), // unchanged
+		storedMatch(2, 2, 70, models.MatchStatusEligible), // rescored
+		storedMatch(3, 3, 60, models.MatchStatusEligible), // no longer matches
+		storedMatch(4, 4, 50, models.MatchStatusExpired),  // re-qualifies
+	}
+	fresh := []*models.MatchCreate{
+		freshMatch(1, 80, models.MatchStatusEligible),
+		freshMatch(2, 75, models.MatchStatusEligible),
+		freshMatch(4, 55, models.MatchStatusEligible),
+		freshMatch(5, 90, models.MatchStatusUnreviewed),
+	}
+
+	changes, expired := matcher.DiffProductMatches(existing, fresh)
+
+	assert.Equal(t, map[int64]models.RematchChangeType{
+		2: models.RematchChangeRescored,
+		3: models.RematchChangeRemoved,
+		4: models.RematchChangeAdded,
+		5: models.RematchChangeAdded,
+	}, changeTypes(changes))
+	assert.Equal(t, []int64{3}, expired)
+
+	for _, c := range changes {
+		if c.UserID == 2 {
+			require.NotNil(t, c.OldScore)
+			require.NotNil(t, c.NewScore)
+			assert.Equal(t, 70.0, *c.OldScore)
+			assert.Equal(t, 75.0, *c.NewScore)
+		}
+	}
+}
+
+func TestDiffProductMatches_KeepsNotifiedStatus(t *testing.T) {
+	existing := []models.Match{storedMatch(1, 1, 80, models.MatchStatusNotified)}
+	fresh := []*models.MatchCreate{freshMatch(1, 80, models.MatchStatusEligible)}
+
+	changes, expired := matcher.DiffProductMatches(existing, fresh)
+
+	assert.Empty(t, changes)
+	assert.Empty(t, expired)
+	assert.Equal(t, models.MatchStatusNotified, fresh[0].Status)
+}
+
+func TestDiffProductMatches_DeactivatedProductExpiresAll(t *testing.T) {
+	existing := []models.Match{
+		storedMatch(1, 1, 80, models.MatchStatusNotified),
+		storedMatch(2, 2, 70, models.MatchStatusUnreviewed),
+		storedMatch(3, 3, 60, models.MatchStatusNotEligible),
+	}
+
+	changes, expired := matcher.DiffProductMatches(existing, nil)
+
+	assert.ElementsMatch(t, []int64{1, 2}, expired)
+	assert.Len(t, changes, 2)
+	for _, c := range changes {
+		assert.Equal(t, models.RematchChangeRemoved, c.ChangeType)
+	}
+}
+
+func TestDiffProductMatches_SkipsReviewerDecisions(t *testing.T) {
+	rejected := storedMatch(1, 1, 40, models.MatchStatusNotEligible)
+	rejected.MatchSource = models.MatchSourceManual
+	approved := storedMatch(2, 2, 55, models.MatchStatusEligible)
+	approved.MatchSource = models.MatchSourceManual
+	lapsed := storedMatch(3, 3, 50, models.MatchStatusExpired)
+	lapsed.MatchSource = models.MatchSourceManual
+
+	fresh := []*models.MatchCreate{
+		freshMatch(1, 85, models.MatchStatusEligible),
+		freshMatch(2, 90, models.MatchStatusPendingReview),
+		freshMatch(3, 70, models.MatchStatusEligible),
+	}
+
+	changes, expired := matcher.DiffProductMatches([]models.Match{rejected, approved, lapsed}, fresh)
+
+	// A rejection is not re-added and an approval is not rescored; an
+	// expired decision no longer holds
+	assert.Equal(t, map[int64]models.RematchChangeType{
+		3: models.RematchChangeAdded,
+	}, changeTypes(changes))
+	assert.Empty(t, expired)
+}
@@ -0,0 +1,187 @@
+// Package unit_test contains tests for the eligibility rules engine
+package unit_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"loan-eligibility-engine/internal/models"
+	"loan-eligibility-engine/internal/services/affordability"
+	"loan-eligibility-engine/internal/services/rules"
+)
+
+func evalRule(t *testing.T, expression string, env rules.Env) bool {
+	t.Helper()
+	rule, err := rules.Compile("test", expression)
+	require.NoError(t, err)
+	passed, err := rule.Eval(env)
+	require.NoError(t, err)
+	return passed
+}
+
+func TestRules_Comparisons(t *testing.T) {
+	env := rules.Env{"foir": 0.42, "credit_score": 750}
+
+	assert.True(t, evalRule(t, "foir <= 0.55", env))
+	assert.False(t, evalRule(t, "foir > 0.55", env))
+	assert.True(t, evalRule(t, "credit_score == 750", env))
+	assert.True(t, evalRule(t, "credit_score != 700", env))
+}
+
+func TestRules_Arithmetic(t *testing.T) {
+	env := rules.Env{"age": 35, "tenure_years": 5.0}
+
+	assert.True(t, evalRule(t, "age + tenure_years <= 60", env))
+	assert.False(t, evalRule(t, "age + tenure_years * 6 <= 60", env))
+	assert.True(t, evalRule(t, "(age + tenure_years) * 2 == 80", env))
+	assert.True(t, evalRule(t, "-age < 0", env))
+}
+
+func TestRules_InOperator(t *testing.T) {
+	env := rules.Env{"employment": "self_employed"}
+
+	assert.True(t, evalRule(t, "employment in [employed, self_employed]", env))
+	assert.True(t, evalRule(t, "employment in ['Employed', 'SELF_EMPLOYED']", env))
+	assert.False(t, evalRule(t, "employment in [retired, student]", env))
+	assert.True(t, evalRule(t, "employment not in [retired, student]", env))
+}
+
+func TestRules_LogicalOperators(t *testing.T) {
+	env := rules.Env{"credit_score": 680, "monthly_income": 150000}
+
+	assert.True(t, evalRule(t, "credit_score >= 750 or monthly_income >= 100000", env))
+	assert.False(t, evalRule(t, "credit_score >= 750 and monthly_income >= 100000", env))
+	assert.True(t, evalRule(t, "not (credit_score >= 750)", env))
+	assert.True(t, evalRule(t, "credit_score >= 600 && !(monthly_income < 1000)", env))
+}
+
+func TestRules_CompileErrors(t *testing.T) {
+	for _, expr := range []string{"", "foir <=", "age + ", "(age > 1", "employment in [a, b", "age @ 3", "'open"} {
+		_, err := rules.Compile("bad", expr)
+		assert.Error(t, err, "expected compile error for %q", expr)
+	}
+}
+
+func TestRules_EvalErrors(t *testing.T) {
+	rule, err := rules.Compile("unknown", "missing_var > 1")
+	require.NoError(t, err)
+	_, err = rule.Eval(rules.Env{})
+	assert.Error(t, err)
+
+	rule, err = rules.Compile("not_bool", "age + 1")
+	require.NoError(t, err)
+	_, err = rule.Eval(rules.Env{"age": 30})
+	assert.Error(t, err)
+}
+
+func TestRules_Validate(t *testing.T) {
+	assert.NoError(t, rules.Validate("ok", "foir <= 0.55 and employment in [employed]"))
+	assert.Error(t, rules.Validate("typo", "fior <= 0.55"))
+}
+
+func TestRuleSet_EvaluateRecordsEachRule(t *testing.T) {
+	stored := []*models.EligibilityRule{
+		{Name: "age_at_maturity", Expression: "age + tenure_years <= 60", Priority: 2, IsActive: true},
+		{Name: "foir_limit", Expression: "foir <= 0.55", Priority: 1, IsActive: true},
+		{Name: "inactive", Expression: "false", Priority: 0, IsActive: false},
+	}
+
+	set, errs := rules.FromModels(stored)
+	require.Empty(t, errs)
+	require.Len(t, set, 2)
+	assert.Equal(t, "foir_limit", set[0].Name, "rules should be ordered by priority")
+
+	results, passed := set.Evaluate(rules.Env{"age": 58, "tenure_years": 5.0, "foir": 0.3})
+	assert.False(t, passed)
+	require.Len(t, results, 2)
+	assert.True(t, results[0].Passed)
+	assert.False(t, results[1].Passed)
+	assert.Equal(t, "age_at_maturity", results[1].Rule)
+}
+
+func TestRuleSet_EvaluationErrorFailsRule(t *testing.T) {
+	rule, err := rules.Compile("broken", "unknown_field > 0")
+	require.NoError(t, err)
+
+	results, passed := rules.RuleSet{rule}.Evaluate(rules.Env{})
+	assert.False(t, passed)
+	require.Len(t, results, 1)
+	assert.NotEmpty(t, results[0].Error)
+}
+
+func TestRuleSet_InvalidRulesFailClosed(t *testing.T) {
+	stored := []*models.EligibilityRule{
+		{Name: "unbalanced", Expression: "(foir <= 0.55", Priority: 1, IsActive: true},
+		{Name: "dangling", Expression: "credit_score >=", Priority: 2, IsActive: true},
+	}
+
+	set, errs := rules.FromModels(stored)
+	assert.Len(t, errs, 2)
+	require.Len(t, set, 2, "invalid rules must stay in the set")
+
+	user := mockUser(map[string]interface{}{"monthly_income": float64(500000)})
+	env := rules.NewEnv(user, mockProduct(nil), 0.5)
+	results, passed := set.WithFOIRLimit().Evaluate(env)
+	assert.False(t, passed, "a product whose rules are all invalid must match no one")
+	require.Len(t, results, 3)
+	for _, r := range results[:2] {
+		assert.False(t, r.Passed)
+		assert.Contains(t, r.Error, "invalid rule")
+	}
+	assert.True(t, results[2].Passed)
+}
+
+func TestRules_NewEnvAndDefaultRuleSet(t *testing.T) {
+	user := mockUser(map[string]interface{}{"monthly_income": float64(50000)})
+	product := mockProduct(nil)
+
+	env := rules.NewEnv(user, product, 0.5)
+	assert.Equal(t, 5.0, env["tenure_years"])
+	assert.InDelta(t, affordability.EMI(product.LoanAmountMin, product.InterestRateMax, 60), env["emi"], 0.001)
+
+	_, passed := rules.DefaultRuleSet().Evaluate(env)
+	assert.True(t, passed)
+
+	poor := mockUser(map[string]interface{}{"monthly_income": float64(500)})
+	_, passed = rules.DefaultRuleSet().Evaluate(rules.NewEnv(poor, product, 0.5))
+	assert.False(t, passed)
+}
+
+func TestRules_RequestedTermsVariables(t *testing.T) {
+	user := mockUser(map[string]interface{}{
+		"requested_amount":        float64(300000),
+		"requested_tenure_months": 24,
+		"loan_purpose":            models.LoanProductTypeHome,
+	})
+	env := rules.NewEnv(user, mockProduct(nil), 0.5)
+
+	assert.True(t, evalRule(t, "requested_amount <= loan_amount_max and requested_tenure_months >= 12", env))
+	assert.False(t, evalRule(t, "loan_purpose == product_type", env))
+}
+
+func TestRules_FOIRLimitCountsObligations(t *testing.T) {
+	product := mockProduct(nil)
+	set := rules.DefaultRuleSet()
+
+	_, passed := set.WithFOIRLimit().Evaluate(rules.NewEnv(mockUser(nil), product, 0.5))
+	assert.True(t, passed)
+
+	// The new EMI alone fits, but not on top of existing EMIs
+	indebted := mockUser(map[string]interface{}{"existing_emi": float64(24000)})
+	env := rules.NewEnv(indebted, product, 0.5)
+	assert.Equal(t, 0.5, env["max_foir"])
+	results, passed := set.WithFOIRLimit().Evaluate(env)
+	assert.False(t, passed)
+	require.Len(t, results, 2)
+	assert.True(t, results[0].Passed)
+	assert.False(t, results[1].Passed)
+
+	// A product's own limit replaces the default
+	limit := 0.9
+	product.MaxFOIR = &limit
+	_, passed = set.WithFOIRLimit().Evaluate(rules.NewEnv(indebted, product, 0.5))
+	assert.True(t, passed)
+	assert.Len(t, set, 1, "the rule set itself is unchanged")
+}
@@ -0,0 +1,131 @@
+package unit_test
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"loan-eligibility-engine/internal/services/scoring"
+)
+
+func TestScoringDefault_Score(t *testing.T) {
+	user := mockUser(nil)
+	product := mockProduct(nil)
+
+	// base 20 + credit 50/200*40 + income 25000/50000*30 + age (1-5.5/19.5)*10
+	score := scoring.Default().Score(user, product)
+
+	assert.InDelta(t, 20+10+15+7.1795, score, 0.001)
+}
+
+func TestScoringDefault_ComponentsAreCapped(t *testing.T) {
+	user := mockUser(map[string]interface{}{
+		"monthly_income": float64(1000000),
+		"credit_score":   900,
+		"age":            40,
+	})
+	product := mockProduct(map[string]interface{}{"min_age": 20, "max_age": 60})
+
+	assert.InDelta(t, 100.0, scoring.Default().Score(user, product), 0.001)
+}
+
+func TestScoringDefault_PenalisesLeverage(t *testing.T) {
+	product := mockProduct(nil)
+	base := scoring.Default().Score(mockUser(nil), product)
+
+	// A quarter of income already goes to EMIs: half the way to the ceiling
+	user := mockUser(map[string]interface{}{"existing_emi": float64(12500)})
+	assert.InDelta(t, base-10, scoring.Default().Score(user, product), 0.001)
+
+	// Leverage at the ceiling takes the full penalty, and scores stay positive
+	user = mockUser(map[string]interface{}{"existing_emi": float64(40000)})
+	assert.InDelta(t, base-20, scoring.Default().Score(user, product), 0.001)
+	broke := mockUser(map[string]interface{}{"credit_score": 700, "monthly_income": float64(25000), "existing_emi": float64(20000)})
+	assert.GreaterOrEqual(t, scoring.Default().Score(broke, product), 0.0)
+}
+
+func TestScoringDefault_PenalisesDistantTerms(t *testing.T) {
+	product := mockProduct(nil)
+	base := scoring.Default().Score(mockUser(nil), product)
+
+	// The product's maximum covers the request
+	user := mockUser(map[string]interface{}{"requested_amount": float64(2500000), "requested_tenure_months": 36})
+	assert.InDelta(t, base, scoring.Default().Score(user, product), 0.001)
+
+	// A 25L product for a 50k request is 50x too large, on a 100x spread
+	user = mockUser(map[string]interface{}{"requested_amount": float64(50000)})
+	assert.InDelta(t, base-15*math.Log(50)/math.Log(100), scoring.Default().Score(user, product), 0.001)
+
+	// Asking for 120 months is 60 months past the product's longest tenure
+	user = mockUser(map[string]interface{}{"requested_tenure_months": 120})
+	assert.InDelta(t, base-7.5, scoring.Default().Score(user, product), 0.001)
+}
+
+func TestScoringCurve_Apply(t *testing.T) {
+	assert.InDelta(t, 0.25, scoring.CurveLinear.Apply(0.25), 0.0001)
+	assert.InDelta(t, 0.5, scoring.CurveSqrt.Apply(0.25), 0.0001)
+	assert.InDelta(t, 0.0625, scoring.CurveSquare.Apply(0.25), 0.0001)
+	assert.Equal(t, 1.0, scoring.CurveSqrt.Apply(4))
+	assert.Equal(t, 0.0, scoring.CurveSquare.Apply(-1))
+}
+
+func TestScoringParse_FillsDefaults(t *testing.T) {
+	model, err := scoring.Parse([]byte(`{"version": "v2", "base": 10, "credit": {"weight": 60, "curve": "sqrt"}, "income": {"weight": 30}}`))
+
+	require.NoError(t, err)
+	assert.Equal(t, "v2", model.Version)
+	assert.Equal(t, scoring.CurveSqrt, model.Credit.Curve)
+	assert.Equal(t, scoring.CurveLinear, model.Income.Curve)
+	assert.Equal(t, 900, model.CreditCeiling)
+	assert.Equal(t, 2.0, model.IncomeMultiple)
+	assert.Zero(t, model.Leverage.Weight, "models without leverage apply no penalty")
+}
+
+func TestScoringParse_RejectsInvalidModels(t *testing.T) {
+	cases := map[string]string{
+		"no version":      `{"base": 20}`,
+		"weights over":    `{"version": "v2", "base": 50, "credit": {"weight": 60}}`,
+		"negative weight": `{"version": "v2", "age": {"weight": -5}}`,
+		"unknown curve":   `{"version": "v2", "credit": {"weight": 40, "curve": "cubic"}}`,
+		"bad ceiling":     `{"version": "v2", "credit_ceiling": 1000}`,
+		"bad leverage":    `{"version": "v2", "leverage_ceiling": -1}`,
+		"invalid json":    `{"version": `,
+	}
+
+	for name, data := range cases {
+		_, err := scoring.Parse([]byte(data))
+		assert.Error(t, err, name)
+	}
+}
+
+func TestScoringSource_ReloadsChangedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scoring_model.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"version": "v1", "base": 20}`), 0o644))
+
+	source := scoring.NewSource(path)
+	model, err := source.Model()
+	require.NoError(t, err)
+	assert.Equal(t, "v1", model.Version)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"version": "v2", "base": 30}`), 0o644))
+	later := time.Now().Add(time.Second)
+	require.NoError(t, os.Chtimes(path, later, later))
+
+	model, err = source.Model()
+	require.NoError(t, err)
+	assert.Equal(t, "v2", model.Version)
+
+	// An invalid edit keeps serving the last good model
+	require.NoError(t, os.WriteFile(path, []byte(`{"base": 30}`), 0o644))
+	later = later.Add(time.Second)
+	require.NoError(t, os.Chtimes(path, later, later))
+
+	model, err = source.Model()
+	assert.Error(t, err)
+	assert.Equal(t, "v2", model.Version)
+}